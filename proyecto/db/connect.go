@@ -1,47 +1,80 @@
 /*
 @Autor: Kevin Pérez
-@Descripcion: Módulo para establecer la conexión a la base de datos MySQL.
+@Descripcion: Módulo para establecer la conexión a la base de datos (MySQL o SQLite).
 */
 
 package db
 
 import (
 	"database/sql" // Paquete para trabajar con bases de datos SQL.
+	"errors"       // Paquete para inspeccionar errores envueltos.
 	"fmt"          // Paquete para formatear cadenas.
+	"io/fs"        // Paquete con el error que indica que un archivo no existe.
 	"log"          // Paquete para logging de errores y mensajes.
 	"os"           // Paquete para interactuar con el sistema operativo (ej. variables de entorno).
+	"strings"      // Paquete para normalizar el nombre del driver.
 
 	_ "github.com/go-sql-driver/mysql" // Driver de MySQL para Go. El guion bajo indica que se importa solo para sus efectos secundarios (inicializar el driver).
 	"github.com/joho/godotenv"         // Paquete para cargar variables de entorno desde un archivo .env.
+	_ "modernc.org/sqlite"             // Driver de SQLite escrito en Go puro (no necesita cgo ni un servidor).
 )
 
-// Connect establece una conexión a la base de datos MySQL.
+// Drivers de base de datos soportados por la variable de entorno DB_DRIVER.
+const (
+	DriverMySQL  = "mysql"  // Servidor MySQL configurado con DB_USER, DB_PASSWORD, DB_HOST, DB_PORT y DB_NAME.
+	DriverSQLite = "sqlite" // Archivo SQLite indicado en DB_PATH.
+)
+
+// rutaSQLitePorDefecto es el archivo que se usa cuando DB_DRIVER=sqlite y no se define DB_PATH.
+const rutaSQLitePorDefecto = "biblioteca.db"
+
+// Driver devuelve el driver configurado en DB_DRIVER, o MySQL si la variable no está definida.
+// Debe llamarse después de Connect para que las variables del archivo .env estén cargadas.
+func Driver() string {
+	driver := strings.ToLower(strings.TrimSpace(os.Getenv("DB_DRIVER")))
+	if driver == "" {
+		return DriverMySQL
+	}
+	return driver
+}
+
+// Connect establece una conexión a la base de datos indicada por DB_DRIVER.
 func Connect() (*sql.DB, error) {
 
 	// Carga las variables de entorno desde el archivo .env.
 	// Esto permite mantener la configuración de la base de datos fuera del código fuente.
+	// Si el archivo no existe se usan las variables de entorno del sistema (útil en CI).
 	err := godotenv.Load()
 	if err != nil {
-
-		// Si hay un error al cargar el archivo .env, se devuelve el error.
-
-		return nil, fmt.Errorf("error al cargar el archivo .env: %w", err)
-
+		if !errors.Is(err, fs.ErrNotExist) {
+			// Si el archivo existe pero no se puede leer, se devuelve el error.
+			return nil, fmt.Errorf("error al cargar el archivo .env: %w", err)
+		}
+		log.Println("No se encontró el archivo .env, se usan las variables de entorno del sistema.")
 	}
 
-	// Construye la cadena de conexión DSN (Data Source Name) usando las variables de entorno.
-	// Esto incluye el usuario, contraseña, host, puerto y nombre de la base de datos.
-	dns := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s",
-		os.Getenv("DB_USER"),     // Usuario de la base de datos.
-		os.Getenv("DB_PASSWORD"), // Contraseña de la base de datos.
-		os.Getenv("DB_HOST"),     // Host de la base de datos (ej. localhost).
-		os.Getenv("DB_PORT"),     // Puerto de la base de datos (ej. 3306).
-		os.Getenv("DB_NAME"),     // Nombre de la base de datos a la que conectarse.
-	)
+	var db *sql.DB
+	switch Driver() {
+	case DriverMySQL:
+		// Construye la cadena de conexión DSN (Data Source Name) usando las variables de entorno.
+		// Esto incluye el usuario, contraseña, host, puerto y nombre de la base de datos.
+		// parseTime=true permite escanear las columnas DATETIME directamente en time.Time.
+		dns := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
+			os.Getenv("DB_USER"),     // Usuario de la base de datos.
+			os.Getenv("DB_PASSWORD"), // Contraseña de la base de datos.
+			os.Getenv("DB_HOST"),     // Host de la base de datos (ej. localhost).
+			os.Getenv("DB_PORT"),     // Puerto de la base de datos (ej. 3306).
+			os.Getenv("DB_NAME"),     // Nombre de la base de datos a la que conectarse.
+		)
 
-	// Abre una conexión a la base de datos MySQL.
-	// sql.Open no establece la conexión inmediatamente, solo valida los parámetros.
-	db, err := sql.Open("mysql", dns)
+		// Abre una conexión a la base de datos MySQL.
+		// sql.Open no establece la conexión inmediatamente, solo valida los parámetros.
+		db, err = sql.Open("mysql", dns)
+	case DriverSQLite:
+		db, err = OpenSQLite(os.Getenv("DB_PATH"))
+	default:
+		return nil, fmt.Errorf("driver de base de datos no soportado: %q (use %q o %q)", Driver(), DriverMySQL, DriverSQLite)
+	}
 	if err != nil {
 		// Si hay un error al abrir la conexión, se devuelve el error.
 		return nil, fmt.Errorf("error al abrir la conexión a la base de datos: %w", err)
@@ -55,6 +88,25 @@ func Connect() (*sql.DB, error) {
 	}
 
 	// Si todo es exitoso, se imprime un mensaje de éxito y se devuelve la conexión.
-	log.Println("Conexión abierta a la base de datos exitosamente.")
+	log.Printf("Conexión abierta a la base de datos (%s) exitosamente.", Driver())
+	return db, nil
+}
+
+// OpenSQLite abre el archivo SQLite indicado, o biblioteca.db si la ruta está vacía.
+// El archivo se crea si no existe. Se exporta para que las pruebas puedan abrir bases temporales.
+func OpenSQLite(ruta string) (*sql.DB, error) {
+	if ruta == "" {
+		ruta = rutaSQLitePorDefecto
+	}
+	// busy_timeout evita errores "database is locked" cuando varias solicitudes escriben a la vez,
+	// y foreign_keys activa las claves foráneas, que SQLite desactiva por defecto.
+	db, err := sql.Open("sqlite", "file:"+ruta+"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+	if ruta == ":memory:" {
+		// Cada conexión a ":memory:" es una base distinta, así que se usa una sola.
+		db.SetMaxOpenConns(1)
+	}
 	return db, nil
 }
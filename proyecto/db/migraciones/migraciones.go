@@ -0,0 +1,233 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo de migraciones versionadas del esquema de la base de datos (MySQL y SQLite).
+*/
+
+package migraciones
+
+import (
+	"database/sql" // Paquete para trabajar con bases de datos SQL.
+	"embed"        // Paquete para incluir los archivos .sql dentro del binario.
+	"fmt"          // Paquete para formatear cadenas.
+	"io/fs"        // Paquete para recorrer los archivos embebidos.
+	"log"          // Paquete para logging de las migraciones aplicadas.
+	"path"         // Paquete para manipular rutas dentro del sistema de archivos embebido.
+	"sort"         // Paquete para ordenar las migraciones por versión.
+	"strconv"      // Paquete para convertir el número de versión.
+	"strings"      // Paquete para separar las sentencias SQL.
+	"time"         // Paquete para registrar la fecha de aplicación.
+)
+
+// archivos contiene las migraciones de cada dialecto, en carpetas con el nombre del driver.
+// Cada migración se compone de dos archivos: NNNN_nombre.up.sql y NNNN_nombre.down.sql.
+//
+//go:embed mysql/*.sql sqlite/*.sql
+var archivos embed.FS
+
+// Migracion representa un cambio versionado del esquema con su SQL de aplicación y de reversión.
+type Migracion struct {
+	Version int    // Número de versión, tomado del prefijo del archivo.
+	Nombre  string // Nombre descriptivo, tomado del resto del nombre del archivo.
+	Up      string // SQL que aplica la migración.
+	Down    string // SQL que revierte la migración.
+}
+
+// Estado describe si una migración ya fue aplicada en la base de datos.
+type Estado struct {
+	Migracion
+	Aplicada   bool      // Indica si la versión está registrada en schema_migrations.
+	AplicadaEn time.Time // Fecha en que se aplicó (cero si está pendiente).
+}
+
+// crearTablaVersiones crea la tabla que registra las versiones aplicadas. El SQL es válido en MySQL y SQLite.
+const crearTablaVersiones = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	Version INT NOT NULL PRIMARY KEY,
+	Nombre VARCHAR(255) NOT NULL,
+	AplicadaEn DATETIME NOT NULL
+)`
+
+// Cargar lee las migraciones embebidas del dialecto indicado, ordenadas por versión.
+func Cargar(driver string) ([]Migracion, error) {
+	entradas, err := fs.ReadDir(archivos, driver)
+	if err != nil {
+		return nil, fmt.Errorf("no hay migraciones para el driver %q: %w", driver, err)
+	}
+
+	porVersion := make(map[int]*Migracion)
+	for _, entrada := range entradas {
+		// El nombre tiene la forma 0001_crear_libros.up.sql.
+		base := strings.TrimSuffix(entrada.Name(), ".sql")
+		nombre, direccion := strings.TrimSuffix(strings.TrimSuffix(base, ".up"), ".down"), path.Ext(base)
+		prefijo, descripcion, ok := strings.Cut(nombre, "_")
+		version, err := strconv.Atoi(prefijo)
+		if !ok || err != nil || (direccion != ".up" && direccion != ".down") {
+			return nil, fmt.Errorf("nombre de migración inválido: %s", entrada.Name())
+		}
+
+		contenido, err := archivos.ReadFile(path.Join(driver, entrada.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error al leer la migración %s: %w", entrada.Name(), err)
+		}
+
+		m, existe := porVersion[version]
+		if !existe {
+			m = &Migracion{Version: version, Nombre: descripcion}
+			porVersion[version] = m
+		}
+		if direccion == ".up" {
+			m.Up = string(contenido)
+		} else {
+			m.Down = string(contenido)
+		}
+	}
+
+	var migraciones []Migracion
+	for _, m := range porVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("la migración %04d_%s debe tener archivos .up.sql y .down.sql", m.Version, m.Nombre)
+		}
+		migraciones = append(migraciones, *m)
+	}
+	sort.Slice(migraciones, func(i, j int) bool { return migraciones[i].Version < migraciones[j].Version })
+	return migraciones, nil
+}
+
+// Status devuelve todas las migraciones del dialecto indicando cuáles están aplicadas.
+func Status(db *sql.DB, driver string) ([]Estado, error) {
+	migraciones, err := Cargar(driver)
+	if err != nil {
+		return nil, err
+	}
+	aplicadas, err := versionesAplicadas(db)
+	if err != nil {
+		return nil, err
+	}
+
+	estados := make([]Estado, 0, len(migraciones))
+	for _, m := range migraciones {
+		fecha, aplicada := aplicadas[m.Version]
+		estados = append(estados, Estado{Migracion: m, Aplicada: aplicada, AplicadaEn: fecha})
+	}
+	return estados, nil
+}
+
+// Up aplica en orden todas las migraciones pendientes y devuelve cuántas se aplicaron.
+func Up(db *sql.DB, driver string) (int, error) {
+	estados, err := Status(db, driver)
+	if err != nil {
+		return 0, err
+	}
+
+	aplicadas := 0
+	for _, estado := range estados {
+		if estado.Aplicada {
+			continue
+		}
+		m := estado.Migracion
+		err := ejecutar(db, m.Up, func(tx *sql.Tx) error {
+			_, err := tx.Exec("INSERT INTO schema_migrations (Version, Nombre, AplicadaEn) VALUES (?, ?, ?)", m.Version, m.Nombre, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return aplicadas, fmt.Errorf("error al aplicar la migración %04d_%s: %w", m.Version, m.Nombre, err)
+		}
+		log.Printf("Migración %04d_%s aplicada.", m.Version, m.Nombre)
+		aplicadas++
+	}
+	return aplicadas, nil
+}
+
+// Down revierte la última migración aplicada. Devuelve false si no había ninguna por revertir.
+func Down(db *sql.DB, driver string) (bool, error) {
+	estados, err := Status(db, driver)
+	if err != nil {
+		return false, err
+	}
+
+	// Busca la versión aplicada más alta.
+	for i := len(estados) - 1; i >= 0; i-- {
+		if !estados[i].Aplicada {
+			continue
+		}
+		m := estados[i].Migracion
+		err := ejecutar(db, m.Down, func(tx *sql.Tx) error {
+			_, err := tx.Exec("DELETE FROM schema_migrations WHERE Version = ?", m.Version)
+			return err
+		})
+		if err != nil {
+			return false, fmt.Errorf("error al revertir la migración %04d_%s: %w", m.Version, m.Nombre, err)
+		}
+		log.Printf("Migración %04d_%s revertida.", m.Version, m.Nombre)
+		return true, nil
+	}
+	return false, nil
+}
+
+// versionesAplicadas crea la tabla schema_migrations si hace falta y devuelve las versiones registradas.
+func versionesAplicadas(db *sql.DB) (map[int]time.Time, error) {
+	if _, err := db.Exec(crearTablaVersiones); err != nil {
+		return nil, fmt.Errorf("error al crear la tabla schema_migrations: %w", err)
+	}
+
+	rows, err := db.Query("SELECT Version, AplicadaEn FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	aplicadas := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var fecha time.Time
+		if err := rows.Scan(&version, &fecha); err != nil {
+			return nil, fmt.Errorf("error al escanear schema_migrations: %w", err)
+		}
+		aplicadas[version] = fecha
+	}
+	return aplicadas, rows.Err()
+}
+
+// ejecutar corre cada sentencia del script y luego la función registrar, dentro de una misma transacción.
+// En SQLite el cambio completo es atómico; MySQL confirma implícitamente las sentencias DDL,
+// por lo que allí la transacción solo protege los cambios de datos.
+func ejecutar(db *sql.DB, script string, registrar func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya se confirmó.
+
+	for _, sentencia := range sentencias(script) {
+		if _, err := tx.Exec(sentencia); err != nil {
+			return fmt.Errorf("%w\n%s", err, sentencia)
+		}
+	}
+	if err := registrar(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// sentencias separa un script en sentencias individuales, ya que el driver de MySQL
+// no acepta varias sentencias en una sola llamada. Se eliminan las líneas de comentario "--".
+// Las sentencias terminan en ";" al final de una línea.
+func sentencias(script string) []string {
+	var resultado []string
+	var actual strings.Builder
+	for _, linea := range strings.Split(script, "\n") {
+		recortada := strings.TrimSpace(linea)
+		if recortada == "" || strings.HasPrefix(recortada, "--") {
+			continue
+		}
+		actual.WriteString(linea)
+		actual.WriteString("\n")
+		if strings.HasSuffix(recortada, ";") {
+			resultado = append(resultado, strings.TrimSpace(actual.String()))
+			actual.Reset()
+		}
+	}
+	if resto := strings.TrimSpace(actual.String()); resto != "" {
+		resultado = append(resultado, resto)
+	}
+	return resultado
+}
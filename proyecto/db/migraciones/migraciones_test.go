@@ -0,0 +1,266 @@
+package migraciones
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"proyecto/db"
+)
+
+func TestCargarDialectos(t *testing.T) {
+	mysql, err := Cargar(db.DriverMySQL)
+	if err != nil {
+		t.Fatalf("Cargar(mysql): %v", err)
+	}
+	sqlite, err := Cargar(db.DriverSQLite)
+	if err != nil {
+		t.Fatalf("Cargar(sqlite): %v", err)
+	}
+	// Cada dialecto debe tener exactamente las mismas versiones.
+	if len(mysql) != len(sqlite) {
+		t.Fatalf("mysql tiene %d migraciones y sqlite %d", len(mysql), len(sqlite))
+	}
+	for i := range mysql {
+		if mysql[i].Version != sqlite[i].Version || mysql[i].Nombre != sqlite[i].Nombre {
+			t.Errorf("migración %d distinta: %04d_%s / %04d_%s", i, mysql[i].Version, mysql[i].Nombre, sqlite[i].Version, sqlite[i].Nombre)
+		}
+	}
+	if _, err := Cargar("postgres"); err == nil {
+		t.Error("Cargar de un driver sin migraciones no devolvió error")
+	}
+}
+
+func TestUpDownSQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	total, _ := Cargar(db.DriverSQLite)
+	aplicadas, err := Up(conexion, db.DriverSQLite)
+	if err != nil || aplicadas != len(total) {
+		t.Fatalf("Up = %d, %v; se esperaban %d", aplicadas, err, len(total))
+	}
+	// Volver a ejecutar Up no debe aplicar nada.
+	if aplicadas, err := Up(conexion, db.DriverSQLite); err != nil || aplicadas != 0 {
+		t.Fatalf("segundo Up = %d, %v", aplicadas, err)
+	}
+
+	estados, err := Status(conexion, db.DriverSQLite)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, e := range estados {
+		if !e.Aplicada || e.AplicadaEn.IsZero() {
+			t.Errorf("la migración %04d_%s no figura como aplicada: %+v", e.Version, e.Nombre, e)
+		}
+	}
+
+	// Revierte todas las migraciones, una por una, y verifica que la tabla libros desaparece.
+	for range total {
+		if revertida, err := Down(conexion, db.DriverSQLite); err != nil || !revertida {
+			t.Fatalf("Down = %v, %v", revertida, err)
+		}
+	}
+	if revertida, err := Down(conexion, db.DriverSQLite); err != nil || revertida {
+		t.Fatalf("Down sin migraciones aplicadas = %v, %v", revertida, err)
+	}
+	if _, err := conexion.Exec("SELECT COUNT(*) FROM libros"); err == nil {
+		t.Error("la tabla libros sigue existiendo después de revertir todas las migraciones")
+	}
+}
+
+func TestSentencias(t *testing.T) {
+	script := "-- comentario\nCREATE TABLE a (\n  x INT\n);\n\nINSERT INTO a VALUES (1);\nDROP TABLE a"
+	got := sentencias(script)
+	if len(got) != 3 || got[0] != "CREATE TABLE a (\n  x INT\n);" || got[2] != "DROP TABLE a" {
+		t.Errorf("sentencias = %q", got)
+	}
+}
+
+// nuevaDBPrueba abre una base SQLite vacía en un directorio temporal de la prueba, sin migraciones aplicadas.
+// La conexión se cierra al terminar la prueba.
+func nuevaDBPrueba(t *testing.T) *sql.DB {
+	t.Helper()
+	conexion, err := db.OpenSQLite(filepath.Join(t.TempDir(), "migraciones.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { conexion.Close() })
+	return conexion
+}
+
+// nuevaDBEnVersion abre una base de prueba con las migraciones aplicadas hasta la versión indicada,
+// para cargar datos con el formato anterior a la migración siguiente.
+func nuevaDBEnVersion(t *testing.T, version int) *sql.DB {
+	t.Helper()
+	conexion := nuevaDBPrueba(t)
+	if _, err := Up(conexion, db.DriverSQLite); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	revertirHasta(t, conexion, version)
+	return conexion
+}
+
+// aplicarHasta aplica las migraciones pendientes hasta la versión indicada, inclusive, para probar la
+// conversión de datos de una migración sin que las siguientes la modifiquen.
+func aplicarHasta(t *testing.T, conexion *sql.DB, version int) {
+	t.Helper()
+	estados, err := Status(conexion, db.DriverSQLite)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, e := range estados {
+		if e.Aplicada || e.Version > version {
+			continue
+		}
+		m := e.Migracion
+		err := ejecutar(conexion, m.Up, func(tx *sql.Tx) error {
+			_, err := tx.Exec("INSERT INTO schema_migrations (Version, Nombre, AplicadaEn) VALUES (?, ?, ?)", m.Version, m.Nombre, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			t.Fatalf("aplicar la migración %04d: %v", m.Version, err)
+		}
+	}
+}
+
+// revertirHasta revierte migraciones hasta que la versión indicada sea la última aplicada.
+func revertirHasta(t *testing.T, conexion *sql.DB, version int) {
+	t.Helper()
+	for {
+		estados, err := Status(conexion, db.DriverSQLite)
+		if err != nil {
+			t.Fatalf("Status: %v", err)
+		}
+		ultima := 0
+		for _, e := range estados {
+			if e.Aplicada {
+				ultima = e.Version
+			}
+		}
+		if ultima <= version {
+			return
+		}
+		if _, err := Down(conexion, db.DriverSQLite); err != nil {
+			t.Fatalf("Down: %v", err)
+		}
+	}
+}
+
+func TestPrestadoBooleanoConvierteDatos(t *testing.T) {
+	conexion := nuevaDBEnVersion(t, 1)
+
+	// Filas con el formato de texto anterior a la migración 0002.
+	_, err := conexion.Exec(`INSERT INTO libros (Titulo, Autor, AnioPublicacion, Editorial, Prestado)
+		VALUES ('Rayuela', 'Cortázar', 1963, 'Sudamericana', 'Si'), ('Ficciones', 'Borges', 1944, 'Sur', 'No')`)
+	if err != nil {
+		t.Fatalf("insertar libros: %v", err)
+	}
+	aplicarHasta(t, conexion, 2)
+
+	var prestado, disponible bool
+	conexion.QueryRow("SELECT Prestado FROM libros WHERE Titulo = 'Rayuela'").Scan(&prestado)
+	conexion.QueryRow("SELECT Prestado FROM libros WHERE Titulo = 'Ficciones'").Scan(&disponible)
+	if !prestado || disponible {
+		t.Errorf("conversión incorrecta: Rayuela=%v Ficciones=%v", prestado, disponible)
+	}
+
+	// Al revertir se recuperan los valores de texto.
+	revertirHasta(t, conexion, 1)
+	var texto string
+	conexion.QueryRow("SELECT Prestado FROM libros WHERE Titulo = 'Rayuela'").Scan(&texto)
+	if texto != "Si" {
+		t.Errorf("Prestado de Rayuela después de revertir = %q, se esperaba \"Si\"", texto)
+	}
+}
+
+func TestCrearSociosConvierteDatos(t *testing.T) {
+	conexion := nuevaDBEnVersion(t, 3)
+
+	// Préstamos con el nombre del socio en texto, como antes de la migración 0004.
+	_, err := conexion.Exec(`INSERT INTO libros (Titulo, Autor, AnioPublicacion, Editorial) VALUES ('Rayuela', 'Cortázar', 1963, 'Sudamericana')`)
+	if err != nil {
+		t.Fatalf("insertar libro: %v", err)
+	}
+	_, err = conexion.Exec(`INSERT INTO prestamos (LibroId, Socio, FechaPrestamo, FechaVencimiento, FechaDevolucion) VALUES
+		(1, 'Ana Torres', '2024-01-10 10:00:00', '2024-01-24 23:59:59', '2024-01-20 10:00:00'),
+		(1, 'Luis Gómez', '2024-02-01 10:00:00', '2024-02-15 23:59:59', NULL),
+		(1, 'Ana Torres', '2024-03-01 10:00:00', '2024-03-15 23:59:59', '2024-03-05 10:00:00')`)
+	if err != nil {
+		t.Fatalf("insertar préstamos: %v", err)
+	}
+	if _, err := Up(conexion, db.DriverSQLite); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	// Un socio por nombre distinto, y cada préstamo apunta al socio correcto.
+	var socios int
+	conexion.QueryRow("SELECT COUNT(*) FROM socios").Scan(&socios)
+	if socios != 2 {
+		t.Errorf("socios creados = %d, se esperaban 2", socios)
+	}
+	var prestamosAna int
+	conexion.QueryRow("SELECT COUNT(*) FROM prestamos p JOIN socios s ON s.Id = p.SocioId WHERE s.Nombre = 'Ana Torres'").Scan(&prestamosAna)
+	if prestamosAna != 2 {
+		t.Errorf("préstamos de Ana Torres = %d, se esperaban 2", prestamosAna)
+	}
+
+	// Al revertir se recupera el nombre en cada préstamo.
+	revertirHasta(t, conexion, 3)
+	var nombre string
+	conexion.QueryRow("SELECT Socio FROM prestamos WHERE Id = 2").Scan(&nombre)
+	if nombre != "Luis Gómez" {
+		t.Errorf("Socio del préstamo 2 después de revertir = %q, se esperaba \"Luis Gómez\"", nombre)
+	}
+}
+
+func TestCrearEjemplaresConvierteDatos(t *testing.T) {
+	conexion := nuevaDBEnVersion(t, 4)
+
+	// Un libro prestado con su préstamo activo y otro marcado como prestado a mano, sin préstamo,
+	// como antes de la migración 0005.
+	_, err := conexion.Exec(`INSERT INTO libros (Titulo, Autor, AnioPublicacion, Editorial, Prestado) VALUES
+		('Rayuela', 'Cortázar', 1963, 'Sudamericana', TRUE), ('Ficciones', 'Borges', 1944, 'Sur', FALSE),
+		('El Aleph', 'Borges', 1949, 'Losada', TRUE)`)
+	if err != nil {
+		t.Fatalf("insertar libros: %v", err)
+	}
+	_, err = conexion.Exec(`INSERT INTO socios (Nombre, FechaAlta) VALUES ('Ana Torres', '2024-01-01 10:00:00')`)
+	if err != nil {
+		t.Fatalf("insertar socio: %v", err)
+	}
+	_, err = conexion.Exec(`INSERT INTO prestamos (LibroId, SocioId, FechaPrestamo, FechaVencimiento) VALUES (1, 1, '2024-02-01 10:00:00', '2024-02-15 23:59:59')`)
+	if err != nil {
+		t.Fatalf("insertar préstamo: %v", err)
+	}
+	if _, err := Up(conexion, db.DriverSQLite); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	// Cada libro tiene una copia con su estado de préstamo, y el préstamo apunta a la copia de su libro.
+	var codigo string
+	var prestado bool
+	var adquisicion time.Time
+	err = conexion.QueryRow("SELECT CodigoBarras, Prestado, FechaAdquisicion FROM ejemplares WHERE LibroId = 1").Scan(&codigo, &prestado, &adquisicion)
+	if err != nil || codigo != "L000001-1" || !prestado || adquisicion.IsZero() {
+		t.Errorf("ejemplar del libro 1 = %q, %v, %v, %v", codigo, prestado, adquisicion, err)
+	}
+	// Solo queda prestada la copia que respalda un préstamo activo.
+	var prestadas int
+	conexion.QueryRow("SELECT COUNT(*) FROM ejemplares WHERE Prestado = TRUE").Scan(&prestadas)
+	if prestadas != 1 {
+		t.Errorf("ejemplares prestados = %d, se esperaba 1", prestadas)
+	}
+	var ejemplarId int
+	conexion.QueryRow("SELECT EjemplarId FROM prestamos WHERE Id = 1").Scan(&ejemplarId)
+	if ejemplarId != 1 {
+		t.Errorf("EjemplarId del préstamo 1 = %d, se esperaba 1", ejemplarId)
+	}
+
+	// Al revertir, el libro vuelve a figurar como prestado.
+	revertirHasta(t, conexion, 4)
+	var librosPrestados int
+	conexion.QueryRow("SELECT COUNT(*) FROM libros WHERE Prestado = TRUE").Scan(&librosPrestados)
+	if librosPrestados != 1 {
+		t.Errorf("libros prestados después de revertir = %d, se esperaba 1", librosPrestados)
+	}
+}
@@ -0,0 +1,10 @@
+-- Tabla principal de libros. Los nombres de columna coinciden con los que usa models/libros_sql.go.
+-- IF NOT EXISTS permite adoptar bases de datos en las que la tabla se creó a mano.
+CREATE TABLE IF NOT EXISTS libros (
+    Id INT AUTO_INCREMENT PRIMARY KEY,
+    Titulo VARCHAR(255) NOT NULL,
+    Autor VARCHAR(255) NOT NULL,
+    AnioPublicacion INT NOT NULL,
+    Editorial VARCHAR(255) NOT NULL,
+    Prestado VARCHAR(2) NOT NULL DEFAULT 'No'
+);
@@ -0,0 +1,5 @@
+-- Vuelve a guardar Prestado como texto "Si"/"No".
+ALTER TABLE libros ADD COLUMN PrestadoTexto VARCHAR(2) NOT NULL DEFAULT 'No';
+UPDATE libros SET PrestadoTexto = 'Si' WHERE Prestado = TRUE;
+ALTER TABLE libros DROP COLUMN Prestado;
+ALTER TABLE libros CHANGE COLUMN PrestadoTexto Prestado VARCHAR(2) NOT NULL DEFAULT 'No';
@@ -0,0 +1,6 @@
+-- Convierte Prestado de texto ("Si"/"No") a booleano, conservando el estado de cada libro.
+-- También acepta filas que ya guardaban 1/0 si la tabla se creó a mano con una columna BOOLEAN.
+ALTER TABLE libros ADD COLUMN PrestadoBool BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE libros SET PrestadoBool = TRUE WHERE LOWER(TRIM(Prestado)) IN ('si', 'sí', 's', 'true', '1');
+ALTER TABLE libros DROP COLUMN Prestado;
+ALTER TABLE libros CHANGE COLUMN PrestadoBool Prestado BOOLEAN NOT NULL DEFAULT FALSE;
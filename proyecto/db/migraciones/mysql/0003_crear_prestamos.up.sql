@@ -0,0 +1,11 @@
+-- Préstamos de libros a socios. FechaDevolucion es NULL mientras el libro no se devuelve.
+-- Al eliminar un libro se elimina también su historial de préstamos.
+CREATE TABLE prestamos (
+    Id INT AUTO_INCREMENT PRIMARY KEY,
+    LibroId INT NOT NULL,
+    Socio VARCHAR(255) NOT NULL,
+    FechaPrestamo DATETIME NOT NULL,
+    FechaVencimiento DATETIME NOT NULL,
+    FechaDevolucion DATETIME NULL,
+    CONSTRAINT fk_prestamos_libro FOREIGN KEY (LibroId) REFERENCES libros (Id) ON DELETE CASCADE
+);
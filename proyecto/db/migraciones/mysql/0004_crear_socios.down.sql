@@ -0,0 +1,7 @@
+-- Vuelve a guardar el nombre del socio en cada préstamo y elimina la tabla socios.
+ALTER TABLE prestamos ADD COLUMN Socio VARCHAR(255) NULL AFTER LibroId;
+UPDATE prestamos p JOIN socios s ON s.Id = p.SocioId SET p.Socio = s.Nombre;
+ALTER TABLE prestamos MODIFY Socio VARCHAR(255) NOT NULL;
+ALTER TABLE prestamos DROP FOREIGN KEY fk_prestamos_socio;
+ALTER TABLE prestamos DROP COLUMN SocioId;
+DROP TABLE socios;
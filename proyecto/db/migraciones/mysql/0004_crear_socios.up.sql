@@ -0,0 +1,17 @@
+-- Socios de la biblioteca. Los préstamos pasan a referenciar al socio por su ID en lugar de guardar su nombre.
+CREATE TABLE socios (
+    Id INT AUTO_INCREMENT PRIMARY KEY,
+    Nombre VARCHAR(255) NOT NULL,
+    Email VARCHAR(255) NOT NULL DEFAULT '',
+    Telefono VARCHAR(50) NOT NULL DEFAULT '',
+    Direccion VARCHAR(255) NOT NULL DEFAULT '',
+    Estado VARCHAR(20) NOT NULL DEFAULT 'Activo',
+    FechaAlta DATETIME NOT NULL
+);
+-- Crea un socio por cada nombre distinto de los préstamos existentes, con el alta en su primer préstamo.
+INSERT INTO socios (Nombre, FechaAlta) SELECT Socio, MIN(FechaPrestamo) FROM prestamos GROUP BY Socio;
+ALTER TABLE prestamos ADD COLUMN SocioId INT NULL AFTER LibroId;
+UPDATE prestamos p JOIN socios s ON s.Nombre = p.Socio SET p.SocioId = s.Id;
+ALTER TABLE prestamos MODIFY SocioId INT NOT NULL;
+ALTER TABLE prestamos ADD CONSTRAINT fk_prestamos_socio FOREIGN KEY (SocioId) REFERENCES socios (Id);
+ALTER TABLE prestamos DROP COLUMN Socio;
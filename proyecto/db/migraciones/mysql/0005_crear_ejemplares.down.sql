@@ -0,0 +1,8 @@
+-- Vuelve a guardar la disponibilidad en la tabla libros: un libro queda prestado si no le queda ninguna copia libre.
+ALTER TABLE libros ADD COLUMN Prestado BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE libros l SET l.Prestado = TRUE
+    WHERE EXISTS (SELECT 1 FROM ejemplares e WHERE e.LibroId = l.Id)
+    AND NOT EXISTS (SELECT 1 FROM ejemplares e WHERE e.LibroId = l.Id AND e.Prestado = FALSE);
+ALTER TABLE prestamos DROP FOREIGN KEY fk_prestamos_ejemplar;
+ALTER TABLE prestamos DROP COLUMN EjemplarId;
+DROP TABLE ejemplares;
@@ -0,0 +1,23 @@
+-- Copias físicas de cada libro. La disponibilidad pasa de la tabla libros a cada ejemplar,
+-- y cada préstamo indica qué copia se llevó el socio.
+CREATE TABLE ejemplares (
+    Id INT AUTO_INCREMENT PRIMARY KEY,
+    LibroId INT NOT NULL,
+    CodigoBarras VARCHAR(50) NOT NULL,
+    Ubicacion VARCHAR(100) NOT NULL DEFAULT '',
+    Condicion VARCHAR(20) NOT NULL DEFAULT 'Bueno',
+    FechaAdquisicion DATETIME NOT NULL,
+    Prestado BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT uq_ejemplares_codigo UNIQUE (CodigoBarras),
+    CONSTRAINT fk_ejemplares_libro FOREIGN KEY (LibroId) REFERENCES libros (Id) ON DELETE CASCADE
+);
+-- Cada libro existente pasa a tener una copia, prestada solo si el libro tiene un préstamo activo: la columna
+-- Prestado de libros se editaba a mano y una copia prestada sin préstamo no se podría devolver ni eliminar.
+INSERT INTO ejemplares (LibroId, CodigoBarras, FechaAdquisicion, Prestado)
+    SELECT Id, CONCAT('L', LPAD(Id, 6, '0'), '-1'), NOW(),
+        EXISTS (SELECT 1 FROM prestamos p WHERE p.LibroId = libros.Id AND p.FechaDevolucion IS NULL) FROM libros;
+ALTER TABLE prestamos ADD COLUMN EjemplarId INT NULL AFTER LibroId;
+UPDATE prestamos p JOIN ejemplares e ON e.LibroId = p.LibroId SET p.EjemplarId = e.Id;
+ALTER TABLE prestamos MODIFY EjemplarId INT NOT NULL;
+ALTER TABLE prestamos ADD CONSTRAINT fk_prestamos_ejemplar FOREIGN KEY (EjemplarId) REFERENCES ejemplares (Id) ON DELETE CASCADE;
+ALTER TABLE libros DROP COLUMN Prestado;
@@ -0,0 +1,2 @@
+DROP TABLE multas;
+ALTER TABLE prestamos DROP COLUMN Atrasado;
@@ -0,0 +1,13 @@
+-- Préstamos vencidos y sus multas. La tarea de atrasos marca los préstamos vencidos
+-- y guarda una multa por préstamo, que deja de cambiar cuando el libro se devuelve.
+ALTER TABLE prestamos ADD COLUMN Atrasado BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE TABLE multas (
+    Id INT AUTO_INCREMENT PRIMARY KEY,
+    PrestamoId INT NOT NULL,
+    DiasAtraso INT NOT NULL,
+    Monto DECIMAL(10, 2) NOT NULL,
+    Definitiva BOOLEAN NOT NULL DEFAULT FALSE,
+    FechaCalculo DATETIME NOT NULL,
+    CONSTRAINT uq_multas_prestamo UNIQUE (PrestamoId),
+    CONSTRAINT fk_multas_prestamo FOREIGN KEY (PrestamoId) REFERENCES prestamos (Id) ON DELETE CASCADE
+);
@@ -0,0 +1,15 @@
+-- Cola de reservas de los libros sin copias disponibles. Una reserva asignada aparta la copia
+-- indicada en EjemplarId hasta FechaExpiracion; las reservas cerradas conservan la copia como historial.
+CREATE TABLE reservas (
+    Id INT AUTO_INCREMENT PRIMARY KEY,
+    LibroId INT NOT NULL,
+    SocioId INT NOT NULL,
+    Estado VARCHAR(20) NOT NULL DEFAULT 'Pendiente',
+    FechaReserva DATETIME NOT NULL,
+    EjemplarId INT NULL,
+    FechaExpiracion DATETIME NULL,
+    INDEX idx_reservas_libro_estado (LibroId, Estado),
+    CONSTRAINT fk_reservas_libro FOREIGN KEY (LibroId) REFERENCES libros (Id) ON DELETE CASCADE,
+    CONSTRAINT fk_reservas_socio FOREIGN KEY (SocioId) REFERENCES socios (Id) ON DELETE CASCADE,
+    CONSTRAINT fk_reservas_ejemplar FOREIGN KEY (EjemplarId) REFERENCES ejemplares (Id) ON DELETE SET NULL
+);
@@ -0,0 +1,18 @@
+-- Cuentas de los bibliotecarios que usan la interfaz web. La contraseña se guarda como hash bcrypt.
+CREATE TABLE usuarios (
+    Id INT AUTO_INCREMENT PRIMARY KEY,
+    Usuario VARCHAR(50) NOT NULL,
+    Nombre VARCHAR(255) NOT NULL,
+    HashContrasena VARCHAR(100) NOT NULL,
+    FechaAlta DATETIME NOT NULL,
+    CONSTRAINT uq_usuarios_usuario UNIQUE (Usuario)
+);
+-- Sesiones iniciadas. El Id es el hash SHA-256 del token de la cookie: quien lea la tabla no puede usar las sesiones.
+CREATE TABLE sesiones (
+    Id CHAR(64) PRIMARY KEY,
+    UsuarioId INT NOT NULL,
+    FechaCreacion DATETIME NOT NULL,
+    FechaExpiracion DATETIME NOT NULL,
+    INDEX idx_sesiones_expiracion (FechaExpiracion),
+    CONSTRAINT fk_sesiones_usuario FOREIGN KEY (UsuarioId) REFERENCES usuarios (Id) ON DELETE CASCADE
+);
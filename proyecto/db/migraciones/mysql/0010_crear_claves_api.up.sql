@@ -0,0 +1,13 @@
+-- Claves con que los clientes de la API se autentican sin iniciar sesión.
+-- Solo se guarda el hash SHA-256 de la clave; el Prefijo permite reconocerla en la lista.
+-- Alcances guarda los alcances separados por espacios (por ejemplo "libros:read libros:write").
+CREATE TABLE claves_api (
+    Id INT AUTO_INCREMENT PRIMARY KEY,
+    Nombre VARCHAR(100) NOT NULL,
+    Prefijo VARCHAR(20) NOT NULL,
+    HashClave CHAR(64) NOT NULL,
+    Alcances VARCHAR(255) NOT NULL,
+    FechaCreacion DATETIME NOT NULL,
+    FechaRevocacion DATETIME NULL,
+    CONSTRAINT uq_claves_api_hash UNIQUE (HashClave)
+);
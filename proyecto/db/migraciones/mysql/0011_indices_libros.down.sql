@@ -0,0 +1,4 @@
+DROP INDEX idx_libros_titulo ON libros;
+DROP INDEX idx_libros_autor ON libros;
+DROP INDEX idx_libros_editorial ON libros;
+DROP INDEX idx_libros_anio ON libros;
@@ -0,0 +1,6 @@
+-- Índices para ordenar y filtrar la lista paginada de libros (GET /api/libros) sin recorrer toda la tabla.
+-- La búsqueda por texto usa LIKE '%...%', que no aprovecha los índices; el orden y el rango de años sí.
+CREATE INDEX idx_libros_titulo ON libros (Titulo);
+CREATE INDEX idx_libros_autor ON libros (Autor);
+CREATE INDEX idx_libros_editorial ON libros (Editorial);
+CREATE INDEX idx_libros_anio ON libros (AnioPublicacion);
@@ -0,0 +1 @@
+DROP INDEX idx_libros_busqueda ON libros;
@@ -0,0 +1,3 @@
+-- Índice de texto completo para la búsqueda de libros (GET /api/libros/search). La intercalación por defecto
+-- de MySQL 8 (utf8mb4_0900_ai_ci) no distingue mayúsculas ni tildes: "garcia marquez" encuentra "García Márquez".
+CREATE FULLTEXT INDEX idx_libros_busqueda ON libros (Titulo, Autor, Editorial);
@@ -0,0 +1,10 @@
+-- Tabla principal de libros. Los nombres de columna coinciden con los que usa models/libros_sql.go.
+-- IF NOT EXISTS permite adoptar bases de datos en las que la tabla se creó a mano.
+CREATE TABLE IF NOT EXISTS libros (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    Titulo TEXT NOT NULL,
+    Autor TEXT NOT NULL,
+    AnioPublicacion INTEGER NOT NULL,
+    Editorial TEXT NOT NULL,
+    Prestado TEXT NOT NULL DEFAULT 'No'
+);
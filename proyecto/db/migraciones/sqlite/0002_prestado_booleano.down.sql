@@ -0,0 +1,5 @@
+-- Vuelve a guardar Prestado como texto "Si"/"No".
+ALTER TABLE libros ADD COLUMN PrestadoTexto TEXT NOT NULL DEFAULT 'No';
+UPDATE libros SET PrestadoTexto = 'Si' WHERE Prestado = TRUE;
+ALTER TABLE libros DROP COLUMN Prestado;
+ALTER TABLE libros RENAME COLUMN PrestadoTexto TO Prestado;
@@ -0,0 +1,11 @@
+-- Préstamos de libros a socios. FechaDevolucion es NULL mientras el libro no se devuelve.
+-- Al eliminar un libro se elimina también su historial de préstamos.
+CREATE TABLE prestamos (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    LibroId INTEGER NOT NULL REFERENCES libros (Id) ON DELETE CASCADE,
+    Socio TEXT NOT NULL,
+    FechaPrestamo DATETIME NOT NULL,
+    FechaVencimiento DATETIME NOT NULL,
+    FechaDevolucion DATETIME
+);
+CREATE INDEX idx_prestamos_libro ON prestamos (LibroId);
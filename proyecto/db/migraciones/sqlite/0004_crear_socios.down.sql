@@ -0,0 +1,16 @@
+-- Vuelve a guardar el nombre del socio en cada préstamo y elimina la tabla socios.
+CREATE TABLE prestamos_anterior (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    LibroId INTEGER NOT NULL REFERENCES libros (Id) ON DELETE CASCADE,
+    Socio TEXT NOT NULL,
+    FechaPrestamo DATETIME NOT NULL,
+    FechaVencimiento DATETIME NOT NULL,
+    FechaDevolucion DATETIME
+);
+INSERT INTO prestamos_anterior (Id, LibroId, Socio, FechaPrestamo, FechaVencimiento, FechaDevolucion)
+    SELECT p.Id, p.LibroId, s.Nombre, p.FechaPrestamo, p.FechaVencimiento, p.FechaDevolucion
+    FROM prestamos p JOIN socios s ON s.Id = p.SocioId;
+DROP TABLE prestamos;
+ALTER TABLE prestamos_anterior RENAME TO prestamos;
+CREATE INDEX idx_prestamos_libro ON prestamos (LibroId);
+DROP TABLE socios;
@@ -0,0 +1,28 @@
+-- Socios de la biblioteca. Los préstamos pasan a referenciar al socio por su ID en lugar de guardar su nombre.
+CREATE TABLE socios (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    Nombre TEXT NOT NULL,
+    Email TEXT NOT NULL DEFAULT '',
+    Telefono TEXT NOT NULL DEFAULT '',
+    Direccion TEXT NOT NULL DEFAULT '',
+    Estado TEXT NOT NULL DEFAULT 'Activo',
+    FechaAlta DATETIME NOT NULL
+);
+-- Crea un socio por cada nombre distinto de los préstamos existentes, con el alta en su primer préstamo.
+INSERT INTO socios (Nombre, FechaAlta) SELECT Socio, MIN(FechaPrestamo) FROM prestamos GROUP BY Socio;
+-- SQLite no permite agregar una clave foránea a una tabla existente, así que se reconstruye prestamos.
+CREATE TABLE prestamos_nueva (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    LibroId INTEGER NOT NULL REFERENCES libros (Id) ON DELETE CASCADE,
+    SocioId INTEGER NOT NULL REFERENCES socios (Id),
+    FechaPrestamo DATETIME NOT NULL,
+    FechaVencimiento DATETIME NOT NULL,
+    FechaDevolucion DATETIME
+);
+INSERT INTO prestamos_nueva (Id, LibroId, SocioId, FechaPrestamo, FechaVencimiento, FechaDevolucion)
+    SELECT p.Id, p.LibroId, s.Id, p.FechaPrestamo, p.FechaVencimiento, p.FechaDevolucion
+    FROM prestamos p JOIN socios s ON s.Nombre = p.Socio;
+DROP TABLE prestamos;
+ALTER TABLE prestamos_nueva RENAME TO prestamos;
+CREATE INDEX idx_prestamos_libro ON prestamos (LibroId);
+CREATE INDEX idx_prestamos_socio ON prestamos (SocioId);
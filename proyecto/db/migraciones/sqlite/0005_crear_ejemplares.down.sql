@@ -0,0 +1,20 @@
+-- Vuelve a guardar la disponibilidad en la tabla libros: un libro queda prestado si no le queda ninguna copia libre.
+ALTER TABLE libros ADD COLUMN Prestado BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE libros SET Prestado = TRUE
+    WHERE EXISTS (SELECT 1 FROM ejemplares e WHERE e.LibroId = libros.Id)
+    AND NOT EXISTS (SELECT 1 FROM ejemplares e WHERE e.LibroId = libros.Id AND e.Prestado = FALSE);
+CREATE TABLE prestamos_anterior (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    LibroId INTEGER NOT NULL REFERENCES libros (Id) ON DELETE CASCADE,
+    SocioId INTEGER NOT NULL REFERENCES socios (Id),
+    FechaPrestamo DATETIME NOT NULL,
+    FechaVencimiento DATETIME NOT NULL,
+    FechaDevolucion DATETIME
+);
+INSERT INTO prestamos_anterior (Id, LibroId, SocioId, FechaPrestamo, FechaVencimiento, FechaDevolucion)
+    SELECT Id, LibroId, SocioId, FechaPrestamo, FechaVencimiento, FechaDevolucion FROM prestamos;
+DROP TABLE prestamos;
+ALTER TABLE prestamos_anterior RENAME TO prestamos;
+CREATE INDEX idx_prestamos_libro ON prestamos (LibroId);
+CREATE INDEX idx_prestamos_socio ON prestamos (SocioId);
+DROP TABLE ejemplares;
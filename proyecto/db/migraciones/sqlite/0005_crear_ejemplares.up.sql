@@ -0,0 +1,36 @@
+-- Copias físicas de cada libro. La disponibilidad pasa de la tabla libros a cada ejemplar,
+-- y cada préstamo indica qué copia se llevó el socio.
+CREATE TABLE ejemplares (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    LibroId INTEGER NOT NULL REFERENCES libros (Id) ON DELETE CASCADE,
+    CodigoBarras TEXT NOT NULL UNIQUE,
+    Ubicacion TEXT NOT NULL DEFAULT '',
+    Condicion TEXT NOT NULL DEFAULT 'Bueno',
+    FechaAdquisicion DATETIME NOT NULL,
+    Prestado BOOLEAN NOT NULL DEFAULT FALSE
+);
+CREATE INDEX idx_ejemplares_libro ON ejemplares (LibroId);
+-- Cada libro existente pasa a tener una copia, prestada solo si el libro tiene un préstamo activo: la columna
+-- Prestado de libros se editaba a mano y una copia prestada sin préstamo no se podría devolver ni eliminar.
+INSERT INTO ejemplares (LibroId, CodigoBarras, FechaAdquisicion, Prestado)
+    SELECT Id, 'L' || substr('000000' || Id, -6) || '-1', CURRENT_TIMESTAMP,
+        EXISTS (SELECT 1 FROM prestamos p WHERE p.LibroId = libros.Id AND p.FechaDevolucion IS NULL) FROM libros;
+-- SQLite no permite agregar una clave foránea a una tabla existente, así que se reconstruye prestamos.
+CREATE TABLE prestamos_nueva (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    LibroId INTEGER NOT NULL REFERENCES libros (Id) ON DELETE CASCADE,
+    EjemplarId INTEGER NOT NULL REFERENCES ejemplares (Id) ON DELETE CASCADE,
+    SocioId INTEGER NOT NULL REFERENCES socios (Id),
+    FechaPrestamo DATETIME NOT NULL,
+    FechaVencimiento DATETIME NOT NULL,
+    FechaDevolucion DATETIME
+);
+INSERT INTO prestamos_nueva (Id, LibroId, EjemplarId, SocioId, FechaPrestamo, FechaVencimiento, FechaDevolucion)
+    SELECT p.Id, p.LibroId, e.Id, p.SocioId, p.FechaPrestamo, p.FechaVencimiento, p.FechaDevolucion
+    FROM prestamos p JOIN ejemplares e ON e.LibroId = p.LibroId;
+DROP TABLE prestamos;
+ALTER TABLE prestamos_nueva RENAME TO prestamos;
+CREATE INDEX idx_prestamos_libro ON prestamos (LibroId);
+CREATE INDEX idx_prestamos_socio ON prestamos (SocioId);
+CREATE INDEX idx_prestamos_ejemplar ON prestamos (EjemplarId);
+ALTER TABLE libros DROP COLUMN Prestado;
@@ -0,0 +1,11 @@
+-- Préstamos vencidos y sus multas. La tarea de atrasos marca los préstamos vencidos
+-- y guarda una multa por préstamo, que deja de cambiar cuando el libro se devuelve.
+ALTER TABLE prestamos ADD COLUMN Atrasado BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE TABLE multas (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    PrestamoId INTEGER NOT NULL UNIQUE REFERENCES prestamos (Id) ON DELETE CASCADE,
+    DiasAtraso INTEGER NOT NULL,
+    Monto DECIMAL(10, 2) NOT NULL,
+    Definitiva BOOLEAN NOT NULL DEFAULT FALSE,
+    FechaCalculo DATETIME NOT NULL
+);
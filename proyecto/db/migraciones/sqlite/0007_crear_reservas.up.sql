@@ -0,0 +1,13 @@
+-- Cola de reservas de los libros sin copias disponibles. Una reserva asignada aparta la copia
+-- indicada en EjemplarId hasta FechaExpiracion; las reservas cerradas conservan la copia como historial.
+CREATE TABLE reservas (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    LibroId INTEGER NOT NULL REFERENCES libros (Id) ON DELETE CASCADE,
+    SocioId INTEGER NOT NULL REFERENCES socios (Id) ON DELETE CASCADE,
+    Estado TEXT NOT NULL DEFAULT 'Pendiente',
+    FechaReserva DATETIME NOT NULL,
+    EjemplarId INTEGER REFERENCES ejemplares (Id) ON DELETE SET NULL,
+    FechaExpiracion DATETIME
+);
+CREATE INDEX idx_reservas_libro_estado ON reservas (LibroId, Estado);
+CREATE INDEX idx_reservas_ejemplar ON reservas (EjemplarId);
@@ -0,0 +1,2 @@
+DROP TABLE sesiones;
+DROP TABLE usuarios;
@@ -0,0 +1,16 @@
+-- Cuentas de los bibliotecarios que usan la interfaz web. La contraseña se guarda como hash bcrypt.
+CREATE TABLE usuarios (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    Usuario TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    Nombre TEXT NOT NULL,
+    HashContrasena TEXT NOT NULL,
+    FechaAlta DATETIME NOT NULL
+);
+-- Sesiones iniciadas. El Id es el hash SHA-256 del token de la cookie: quien lea la tabla no puede usar las sesiones.
+CREATE TABLE sesiones (
+    Id TEXT PRIMARY KEY,
+    UsuarioId INTEGER NOT NULL REFERENCES usuarios (Id) ON DELETE CASCADE,
+    FechaCreacion DATETIME NOT NULL,
+    FechaExpiracion DATETIME NOT NULL
+);
+CREATE INDEX idx_sesiones_expiracion ON sesiones (FechaExpiracion);
@@ -0,0 +1,4 @@
+-- Rol de cada usuario (admin, bibliotecario o lector), que define lo que puede hacer en la aplicación.
+-- Las cuentas existentes tenían acceso completo, así que pasan a ser administradoras.
+ALTER TABLE usuarios ADD COLUMN Rol TEXT NOT NULL DEFAULT 'lector';
+UPDATE usuarios SET Rol = 'admin';
@@ -0,0 +1 @@
+DROP TABLE claves_api;
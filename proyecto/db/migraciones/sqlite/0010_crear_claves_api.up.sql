@@ -0,0 +1,12 @@
+-- Claves con que los clientes de la API se autentican sin iniciar sesión.
+-- Solo se guarda el hash SHA-256 de la clave; el Prefijo permite reconocerla en la lista.
+-- Alcances guarda los alcances separados por espacios (por ejemplo "libros:read libros:write").
+CREATE TABLE claves_api (
+    Id INTEGER PRIMARY KEY AUTOINCREMENT,
+    Nombre TEXT NOT NULL,
+    Prefijo TEXT NOT NULL,
+    HashClave TEXT NOT NULL UNIQUE,
+    Alcances TEXT NOT NULL,
+    FechaCreacion DATETIME NOT NULL,
+    FechaRevocacion DATETIME NULL
+);
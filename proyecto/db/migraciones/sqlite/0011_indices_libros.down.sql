@@ -0,0 +1,4 @@
+DROP INDEX idx_libros_titulo;
+DROP INDEX idx_libros_autor;
+DROP INDEX idx_libros_editorial;
+DROP INDEX idx_libros_anio;
@@ -0,0 +1 @@
+-- Nada que deshacer: la migración 0012 no cambia el esquema en SQLite.
@@ -0,0 +1,2 @@
+-- SQLite no tiene un índice de texto que ignore las tildes: la búsqueda de libros usa el índice en
+-- memoria de models.IndiceLibros. La migración existe para que ambos drivers tengan las mismas versiones.
@@ -0,0 +1,166 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que autentica a los clientes de la API con claves de API o tokens firmados, y emite esos tokens.
+*/
+
+package handlers
+
+import (
+	"context"         // Paquete para guardar la credencial en el contexto de la solicitud.
+	"errors"          // Paquete para reconocer los errores del modelo.
+	"io"              // Paquete para aceptar un cuerpo vacío al pedir un token.
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"proyecto/models" // Importa el paquete models donde se definen las claves, los alcances y los tokens.
+	"strings"         // Paquete para leer la cabecera Authorization.
+	"time"            // Paquete para informar el vencimiento del token.
+
+	"github.com/goccy/go-json" // Paquete para codificar/decodificar JSON de forma eficiente.
+)
+
+// CabeceraClaveAPI es la cabecera alternativa a Authorization: Bearer para enviar una clave de API.
+const CabeceraClaveAPI = "X-API-Key"
+
+// claveCredencial guarda en el contexto la credencial con que se autenticó un cliente de la API.
+const claveCredencial claveContexto = "credencial"
+
+// CredencialAPI identifica a un cliente de la API que se autenticó con una clave o un token, sin sesión.
+type CredencialAPI struct {
+	Clave models.ClaveAPI // Clave con que se autenticó; con un token, sus Alcances son los del token.
+	Token bool            // true si se usó un token firmado en lugar de la clave.
+}
+
+// CredencialActual devuelve la credencial de API de la solicitud.
+// El segundo valor es false si la solicitud no trae una clave ni un token (por ejemplo, usa la sesión).
+func CredencialActual(r *http.Request) (CredencialAPI, bool) {
+	credencial, ok := r.Context().Value(claveCredencial).(CredencialAPI)
+	return credencial, ok
+}
+
+// credencialDeCabecera devuelve la clave o el token enviados en Authorization: Bearer o en X-API-Key.
+// El segundo valor es false si la solicitud no trae ninguna de las dos cabeceras. Una cabecera Authorization
+// con otro esquema devuelve un texto vacío, que no es una credencial válida.
+func credencialDeCabecera(r *http.Request) (string, bool) {
+	if clave := r.Header.Get(CabeceraClaveAPI); clave != "" {
+		return clave, true
+	}
+	autorizacion := r.Header.Get("Authorization")
+	if autorizacion == "" {
+		return "", false
+	}
+	esquema, texto, _ := strings.Cut(autorizacion, " ")
+	if !strings.EqualFold(esquema, "Bearer") {
+		return "", true
+	}
+	return strings.TrimSpace(texto), true
+}
+
+// verificarCredencial reconoce una clave de API por su prefijo y cualquier otro texto como un token firmado.
+// Un token solo vale mientras la clave con que se emitió siga activa.
+func verificarCredencial(texto string, claves models.ClaveAPIRepository, tokens *models.FirmadorTokens) (CredencialAPI, error) {
+	if strings.HasPrefix(texto, models.PrefijoClaveAPI) {
+		clave, err := claves.ClaveActiva(texto)
+		return CredencialAPI{Clave: clave}, err
+	}
+
+	token, err := tokens.Verificar(texto)
+	if err != nil {
+		return CredencialAPI{}, err
+	}
+	clave, err := claves.GetClaveByID(token.ClaveId)
+	if err != nil || !clave.Activa() {
+		return CredencialAPI{}, models.ErrCredencialInvalida
+	}
+	// El token no puede tener más alcances que su clave.
+	var alcances []models.Alcance
+	for _, alcance := range token.Alcances {
+		if clave.Tiene(alcance) {
+			alcances = append(alcances, alcance)
+		}
+	}
+	clave.Alcances = alcances
+	return CredencialAPI{Clave: clave, Token: true}, nil
+}
+
+// AutenticarAPI reconoce a los clientes de la API que envían una clave o un token en Authorization: Bearer
+// (o la clave en X-API-Key) y guarda su credencial en el contexto (ver CredencialActual), para que
+// RequerirSesion no les pida una sesión. Una credencial inválida, vencida o revocada recibe 401 Unauthorized.
+// Las solicitudes sin credencial y las que no son de la API siguen sin cambios.
+func AutenticarAPI(claves models.ClaveAPIRepository, tokens *models.FirmadorTokens) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			texto, ok := credencialDeCabecera(r)
+			if !ok || !strings.HasPrefix(r.URL.Path, prefijoAPI) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			credencial, err := verificarCredencial(texto, claves, tokens)
+			if err != nil {
+				if !errors.Is(err, models.ErrCredencialInvalida) {
+					log.Printf("Error al verificar la credencial de la API: %v", err)
+					responderProblema(w, r, nuevoProblema(http.StatusInternalServerError, "No se pudo verificar la credencial"))
+					return
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+				responderProblema(w, r, nuevoProblema(http.StatusUnauthorized, "La clave de API o el token no son válidos, vencieron o fueron revocados"))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claveCredencial, credencial)))
+		})
+	}
+}
+
+// SolicitudToken es el cuerpo JSON, opcional, con que se pide un token con menos alcances que la clave.
+type SolicitudToken struct {
+	Alcances []models.Alcance // Alcances del token; si se omite, tiene todos los de la clave.
+}
+
+// RespuestaToken es el cuerpo JSON con el token emitido.
+type RespuestaToken struct {
+	Token    string           // Token que se envía en Authorization: Bearer.
+	Tipo     string           // Siempre "Bearer".
+	Expira   time.Time        // Momento a partir del cual el token deja de ser válido.
+	Alcances []models.Alcance // Alcances del token.
+}
+
+// ApiEmitirToken cambia una clave de API por un token firmado que vence en models.DuracionTokenAPI.
+// Solo se atiende a clientes autenticados con una clave: ni un token ni una sesión pueden pedir otro token.
+func ApiEmitirToken(tokens *models.FirmadorTokens) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		credencial, ok := CredencialActual(r)
+		if !ok || credencial.Token {
+			responderProblema(w, r, nuevoProblema(http.StatusForbidden, "Los tokens solo se emiten a partir de una clave de API"))
+			return
+		}
+
+		var solicitud SolicitudToken
+		if err := json.NewDecoder(r.Body).Decode(&solicitud); err != nil && !errors.Is(err, io.EOF) {
+			responderJSONInvalido(w, r, "la solicitud", err)
+			return
+		}
+		alcances := credencial.Clave.Alcances
+		if len(solicitud.Alcances) > 0 {
+			errores := models.ErroresValidacion{}
+			for _, alcance := range solicitud.Alcances {
+				if !credencial.Clave.Tiene(alcance) {
+					errores.Agregar(models.CampoAlcances, "La clave no tiene el alcance "+string(alcance))
+				}
+			}
+			if len(errores) > 0 {
+				responderError(w, r, errores, "emitir el token")
+				return
+			}
+			alcances = solicitud.Alcances
+		}
+
+		texto, token, err := tokens.Emitir(credencial.Clave.Id, alcances)
+		if err != nil {
+			responderError(w, r, err, "emitir el token")
+			return
+		}
+		// El token es una credencial: ningún intermediario debe guardarlo.
+		w.Header().Set("Cache-Control", "no-store")
+		escribirJSON(w, http.StatusOK, RespuestaToken{Token: texto, Tipo: "Bearer", Expira: token.Expira, Alcances: token.Alcances})
+	}
+}
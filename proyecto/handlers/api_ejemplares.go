@@ -0,0 +1,164 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que maneja los ejemplares (copias físicas) de un libro en la API.
+*/
+
+package handlers
+
+import (
+	"net/http"        // Paquete para manejar solicitudes y respuestas HTTP.
+	"proyecto/models" // Importa el paquete models donde se definen los ejemplares.
+	"strconv"         // Paquete para la conversión de cadenas a tipos numéricos.
+
+	"github.com/goccy/go-json" // Paquete para codificar/decodificar JSON de forma eficiente.
+	"github.com/gorilla/mux"   // Router HTTP para manejar las rutas de la aplicación.
+)
+
+// EjemplarEntrada es el cuerpo JSON aceptado al crear o actualizar un ejemplar.
+// FechaAdquisicion usa el formato "AAAA-MM-DD"; si se omite, se toma la fecha de hoy.
+type EjemplarEntrada struct {
+	CodigoBarras     string
+	Ubicacion        string
+	Condicion        string
+	FechaAdquisicion string
+}
+
+// ApiListarEjemplares maneja la solicitud para obtener las copias de un libro.
+func ApiListarEjemplares(libros models.LibroRepository, ejemplares models.EjemplarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		if _, err := libros.GetLibroByID(id); err != nil {
+			responderError(w, r, err, "recuperar el libro")
+			return
+		}
+		copias, err := ejemplares.GetEjemplaresByLibro(id)
+		if err != nil {
+			responderError(w, r, err, "recuperar los ejemplares")
+			return
+		}
+		if copias == nil {
+			copias = []models.Ejemplar{} // Devuelve [] en lugar de null cuando el libro no tiene copias.
+		}
+
+		escribirJSON(w, http.StatusOK, copias)
+	}
+}
+
+// ApiObtenerEjemplar maneja la solicitud para obtener un ejemplar específico por su ID.
+func ApiObtenerEjemplar(ejemplares models.EjemplarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		ejemplar, err := ejemplares.GetEjemplarByID(id)
+		if err != nil {
+			responderError(w, r, err, "recuperar el ejemplar")
+			return
+		}
+
+		escribirJSON(w, http.StatusOK, ejemplar)
+	}
+}
+
+// ApiCrearEjemplar maneja la solicitud para agregar una copia a un libro.
+func ApiCrearEjemplar(ejemplares models.EjemplarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		LibroId, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		var entrada EjemplarEntrada
+		if err := json.NewDecoder(r.Body).Decode(&entrada); err != nil {
+			responderJSONInvalido(w, r, "el ejemplar", err)
+			return
+		}
+		ejemplar, err := nuevoEjemplar(0, LibroId, entrada.CodigoBarras, entrada.Ubicacion, entrada.Condicion, entrada.FechaAdquisicion)
+		if err != nil {
+			responderDatosInvalidos(w, r, err)
+			return
+		}
+
+		creado, err := ejemplares.CreateEjemplar(ejemplar)
+		if err != nil {
+			responderError(w, r, err, "crear el ejemplar")
+			return
+		}
+
+		escribirCreado(w, "/api/ejemplares/"+strconv.Itoa(creado.Id), creado)
+	}
+}
+
+// ApiActualizarEjemplar maneja la solicitud para actualizar los datos de una copia.
+func ApiActualizarEjemplar(ejemplares models.EjemplarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		actual, err := ejemplares.GetEjemplarByID(id)
+		if err != nil {
+			responderError(w, r, err, "recuperar el ejemplar")
+			return
+		}
+
+		var entrada EjemplarEntrada
+		if err := json.NewDecoder(r.Body).Decode(&entrada); err != nil {
+			responderJSONInvalido(w, r, "el ejemplar", err)
+			return
+		}
+		ejemplar, err := nuevoEjemplar(id, actual.LibroId, entrada.CodigoBarras, entrada.Ubicacion, entrada.Condicion, entrada.FechaAdquisicion)
+		if err != nil {
+			responderDatosInvalidos(w, r, err)
+			return
+		}
+
+		if err := ejemplares.UpdateEjemplar(ejemplar); err != nil {
+			responderError(w, r, err, "actualizar el ejemplar")
+			return
+		}
+
+		// Vuelve a leer la copia para incluir el título y el estado de préstamo en la respuesta.
+		ejemplar, err = ejemplares.GetEjemplarByID(id)
+		if err != nil {
+			responderError(w, r, err, "recuperar el ejemplar")
+			return
+		}
+
+		escribirJSON(w, http.StatusOK, ejemplar)
+	}
+}
+
+// ApiEliminarEjemplar maneja la solicitud para eliminar una copia que no está prestada.
+func ApiEliminarEjemplar(ejemplares models.EjemplarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		err = ejemplares.DeleteEjemplar(id)
+		if err != nil {
+			responderError(w, r, err, "eliminar el ejemplar")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -1,169 +1,431 @@
-/*
-@Autor: Kevin Pérez
-@Descripcion: Módulo que maneja las operaciones CRUD para la entidad Libro en la API.
-*/
-
-package handlers
-
-import (
-	"net/http"        // Paquete para manejar solicitudes y respuestas HTTP.
-	"proyecto/models" // Importa el paquete models donde se define la estructura Libro y funciones CRUD.
-	"strconv"         // Paquete para la conversión de cadenas a tipos numéricos.
-
-	"github.com/goccy/go-json" // Paquete para codificar/decodificar JSON de forma eficiente.
-	"github.com/gorilla/mux"   // Router HTTP para manejar las rutas de la aplicación.
-)
-
-// LibroSimple es una estructura para representar una versión simplificada de un libro para la API.
-// Solo incluye los campos que se desean exponer públicamente en ciertas respuestas de la API.
-type LibroSimple struct {
-	Autor    string `json:"autor"`    // El autor del libro.
-	Titulo   string `json:"titulo"`   // El título del libro.
-	Prestado string `json:"prestado"` // El estado de préstamo del libro.
-}
-
-// ApiListarLibros maneja la solicitud para obtener una lista simplificada de todos los libros.
-func ApiListarLibros(w http.ResponseWriter, r *http.Request) {
-	// Obtiene todos los libros de la base de datos a través del modelo.
-	libros, err := models.GetAllLibros()
-	if err != nil {
-		// Si ocurre un error al recuperar los libros, se envía una respuesta de error 500.
-		http.Error(w, "Error al recuperar los libros: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Se crea una slice (arreglo dinámico) para almacenar la versión simplificada de los libros.
-	var datosSimples []LibroSimple
-	// Itera sobre cada libro obtenido y crea un objeto LibroSimple con los campos deseados.
-	for _, libro := range libros {
-		datosSimples = append(datosSimples, LibroSimple{
-			Autor:    libro.Autor,
-			Titulo:   libro.Titulo,
-			Prestado: libro.Prestado,
-		})
-
-	}
-
-	// Establece el encabezado Content-Type de la respuesta a "application/json".
-	w.Header().Set("Content-Type", "application/json")
-
-	// Codifica la slice de LibroSimple a formato JSON y la escribe en la respuesta.
-	if err := json.NewEncoder(w).Encode(datosSimples); err != nil {
-		http.Error(w, "Error al codificar la respuesta JSON: "+err.Error(), http.StatusInternalServerError)
-	}
-}
-
-// ApiObtenerLibro maneja la solicitud para obtener un libro específico por su ID.
-func ApiObtenerLibro(w http.ResponseWriter, r *http.Request) {
-	// Extrae las variables de la URL (en este caso, el ID del libro).
-	vars := mux.Vars(r)
-	// Convierte el ID de la URL (que es una cadena) a un entero.
-	id, err := strconv.Atoi(vars["Id"])
-	if err != nil {
-		// Si el ID no es un número válido, se envía una respuesta de error 400.
-		http.Error(w, "ID de libro inválido: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	// Obtiene el libro de la base de datos por su ID.
-	libro, err := models.GetLibroByID(id)
-	if err != nil {
-		// Si el libro no se encuentra o hay un error en la base de datos, se envía una respuesta de error.
-		http.Error(w, "Error al recuperar el libro: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Establece el encabezado Content-Type de la respuesta a "application/json".
-	w.Header().Set("Content-Type", "application/json")
-	// Codifica el objeto Libro a formato JSON y lo escribe en la respuesta.
-	if err := json.NewEncoder(w).Encode(libro); err != nil {
-		http.Error(w, "Error al codificar la respuesta JSON: "+err.Error(), http.StatusInternalServerError)
-	}
-}
-
-// ApiCrearLibro maneja la solicitud para crear un nuevo libro.
-func ApiCrearLibro(w http.ResponseWriter, r *http.Request) {
-	var libro models.Libro // Declara una variable de tipo Libro para decodificar el JSON del cuerpo de la solicitud.
-	// Decodifica el cuerpo de la solicitud JSON en la estructura Libro.
-	err := json.NewDecoder(r.Body).Decode(&libro)
-	if err != nil {
-		// Si el JSON es inválido o incompleto, se envía una respuesta de error 400.
-		http.Error(w, "Error al decodificar el JSON del libro: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	// Llama a la función CreateLibro del modelo para insertar el nuevo libro en la base de datos.
-	err = models.CreateLibro(libro.Autor, libro.Titulo, libro.AnioPublicacion, libro.Editorial, libro.Prestado)
-	if err != nil {
-		// Si hay un error al crear el libro en la base de datos, se envía una respuesta de error 500.
-		http.Error(w, "Error al crear el libro en la base de datos: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Si la creación es exitosa, se establece el código de estado HTTP 201 (Created).
-	w.WriteHeader(http.StatusCreated)
-
-	// Se codifica el libro creado (con su posible ID asignado por la DB si la estructura Libro lo incluyera)
-	// y se envía como respuesta JSON.
-	if err := json.NewEncoder(w).Encode(libro); err != nil {
-		http.Error(w, "Error al codificar la respuesta JSON: "+err.Error(), http.StatusInternalServerError)
-	}
-}
-
-// ApiActualizarLibro maneja la solicitud para actualizar un libro existente.
-func ApiActualizarLibro(w http.ResponseWriter, r *http.Request) {
-	// Extrae el ID del libro de la URL.
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["Id"])
-	if err != nil {
-		http.Error(w, "ID de libro inválido: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	var libro models.Libro // Estructura para decodificar el JSON de la solicitud.
-	// Decodifica el cuerpo de la solicitud JSON en la estructura Libro.
-	err = json.NewDecoder(r.Body).Decode(&libro)
-	if err != nil {
-		http.Error(w, "Error al decodificar el JSON del libro: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	// Asigna el ID de la URL al objeto libro, asegurando que se actualice el libro correcto.
-	libro.Id = id
-
-	// Llama a la función UpdateLibro del modelo para actualizar el libro en la base de datos.
-	err = models.UpdateLibro(libro)
-	if err != nil {
-		http.Error(w, "Error al actualizar el libro: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Si la actualización es exitosa, se envía un estado HTTP 200 (OK) y el libro actualizado.
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(libro); err != nil {
-		http.Error(w, "Error al codificar la respuesta JSON: "+err.Error(), http.StatusInternalServerError)
-	}
-}
-
-// ApiEliminarLibro maneja la solicitud para eliminar un libro por su ID.
-func ApiEliminarLibro(w http.ResponseWriter, r *http.Request) {
-
-	// Extrae el ID del libro de la URL.
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["Id"])
-	if err != nil {
-		http.Error(w, "ID de libro inválido: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	// Llama a la función DeleteLibro del modelo para eliminar el libro de la base de datos.
-	err = models.DeleteLibro(id)
-	if err != nil {
-		http.Error(w, "Error al eliminar el libro: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Si la eliminación es exitosa, se envía un estado HTTP 204 (No Content) para indicar que la acción fue exitosa
-	// pero no hay contenido que devolver.
-	w.WriteHeader(http.StatusNoContent)
-}
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que maneja las operaciones CRUD para la entidad Libro en la API.
+*/
+
+package handlers
+
+import (
+	"errors"          // Paquete para reconocer los errores de validación.
+	"fmt"             // Paquete para formatear los enlaces de paginación.
+	"net/http"        // Paquete para manejar solicitudes y respuestas HTTP.
+	"net/url"         // Paquete para leer los parámetros de la consulta de libros.
+	"proyecto/models" // Importa el paquete models donde se define la estructura Libro y funciones CRUD.
+	"slices"          // Paquete para validar el campo de las sugerencias.
+	"strconv"         // Paquete para la conversión de cadenas a tipos numéricos.
+	"strings"         // Paquete para limpiar los parámetros y unir los enlaces de paginación.
+
+	"github.com/goccy/go-json" // Paquete para codificar/decodificar JSON de forma eficiente.
+	"github.com/gorilla/mux"   // Router HTTP para manejar las rutas de la aplicación.
+)
+
+// LibroSimple es una estructura para representar una versión simplificada de un libro para la API.
+// Solo incluye los campos que se desean exponer públicamente en ciertas respuestas de la API.
+type LibroSimple struct {
+	Id          int    `json:"id"`          // ID del libro, para pedir sus datos completos.
+	Autor       string `json:"autor"`       // El autor del libro.
+	Titulo      string `json:"titulo"`      // El título del libro.
+	Prestado    bool   `json:"prestado"`    // Indica si no queda ningún ejemplar disponible.
+	Ejemplares  int    `json:"ejemplares"`  // Cantidad de copias del libro.
+	Disponibles int    `json:"disponibles"` // Cantidad de copias que se pueden prestar.
+}
+
+// nuevoLibroSimple crea la versión simplificada del libro.
+func nuevoLibroSimple(libro models.Libro) LibroSimple {
+	return LibroSimple{
+		Id:          libro.Id,
+		Autor:       libro.Autor,
+		Titulo:      libro.Titulo,
+		Prestado:    libro.Prestado,
+		Ejemplares:  libro.Ejemplares,
+		Disponibles: libro.Disponibles,
+	}
+}
+
+// LibroEncontrado es un resultado de la búsqueda de libros: el libro simplificado y su relevancia.
+type LibroEncontrado struct {
+	LibroSimple
+	Relevancia float64 `json:"relevancia"` // Cuanto mayor, mejor coincide el libro; solo sirve para comparar resultados de la misma búsqueda.
+}
+
+// LibroEntrada es el cuerpo JSON aceptado al crear o actualizar un libro: los datos bibliográficos de models.Libro.
+// No incluye Prestado: el primer ejemplar se crea disponible y después la disponibilidad la administran los préstamos.
+type LibroEntrada struct {
+	Titulo          string
+	Autor           string
+	AnioPublicacion int
+	Editorial       string
+}
+
+// Libro convierte los datos recibidos en un models.Libro con el ID indicado y lo valida.
+// Si algún campo es inválido devuelve models.ErroresValidacion con un mensaje por campo,
+// los mismos que ve el formulario web.
+func (e LibroEntrada) Libro(id int) (models.Libro, error) {
+	libro := models.Libro{
+		Id:              id,
+		Titulo:          e.Titulo,
+		Autor:           e.Autor,
+		AnioPublicacion: e.AnioPublicacion,
+		Editorial:       e.Editorial,
+	}.Normalizar()
+	return libro, validarLibro(libro, models.ErroresValidacion{})
+}
+
+// CabeceraTotal es la cabecera con la cantidad de libros que cumplen los filtros, contando todas las páginas.
+const CabeceraTotal = "X-Total-Count"
+
+// ApiListarLibros maneja la solicitud para obtener una página de la lista simplificada de libros.
+// Los parámetros de la URL (ver consultaLibrosDeURL) eligen los filtros, el orden y la página; la respuesta
+// trae el total en la cabecera X-Total-Count y los enlaces a las otras páginas en la cabecera Link.
+func ApiListarLibros(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		consulta, err := consultaLibrosDeURL(r.URL.Query())
+		if err != nil {
+			responderParametrosInvalidos(w, r, err)
+			return
+		}
+
+		// Obtiene la página de libros de la base de datos a través del modelo.
+		pagina, err := repo.ListarLibros(consulta)
+		if err != nil {
+			// Si ocurre un error al recuperar los libros, se envía una respuesta de error 500.
+			responderError(w, r, err, "recuperar los libros")
+			return
+		}
+
+		// Se crea una slice (arreglo dinámico) para almacenar la versión simplificada de los libros.
+		// Se crea vacía y no nil para que una página sin libros se codifique como [] y no como null.
+		datosSimples := make([]LibroSimple, 0, len(pagina.Libros))
+		// Itera sobre cada libro obtenido y crea un objeto LibroSimple con los campos deseados.
+		for _, libro := range pagina.Libros {
+			datosSimples = append(datosSimples, nuevoLibroSimple(libro))
+		}
+
+		w.Header().Set(CabeceraTotal, strconv.Itoa(pagina.Total))
+		if enlaces := enlacesPaginacion(r, consulta, pagina); len(enlaces) > 0 {
+			w.Header().Set("Link", strings.Join(enlaces, ", "))
+		}
+		// Codifica la slice de LibroSimple a formato JSON y la escribe en la respuesta.
+		escribirJSON(w, http.StatusOK, datosSimples)
+	}
+}
+
+// consultaLibrosDeURL lee la consulta de libros de los parámetros de la URL:
+//
+//	autor, editorial       texto que deben contener, sin distinguir mayúsculas.
+//	anioDesde, anioHasta   rango de años de publicación, ambos incluidos.
+//	prestado               Si o No (o true/false).
+//	orden, direccion       campo de models.CamposOrdenLibros y asc (por defecto) o desc.
+//	limite                 libros por página, de 1 a models.LimiteLibrosMaximo.
+//	desplazamiento         libros que se saltean (paginación por desplazamiento).
+//	cursor                 cursor de la página anterior (paginación por cursor).
+//
+// Si algún parámetro no es válido devuelve models.ErroresValidacion con un mensaje por parámetro.
+func consultaLibrosDeURL(valores url.Values) (models.ConsultaLibros, error) {
+	errores := models.ErroresValidacion{}
+	consulta := models.ConsultaLibros{
+		Autor:     strings.TrimSpace(valores.Get(models.ParametroAutor)),
+		Editorial: strings.TrimSpace(valores.Get(models.ParametroEditorial)),
+		Orden:     valores.Get(models.ParametroOrden),
+		Cursor:    valores.Get(models.ParametroCursor),
+	}
+	entero := func(parametro string, minimo int) int {
+		texto := valores.Get(parametro)
+		if texto == "" {
+			return 0
+		}
+		valor, err := strconv.Atoi(texto)
+		if err != nil {
+			errores.Agregar(parametro, "Debe ser un número entero")
+		} else if valor < minimo {
+			errores.Agregar(parametro, "No puede ser menor que "+strconv.Itoa(minimo))
+		}
+		return valor
+	}
+	consulta.AnioDesde = entero(models.ParametroAnioDesde, 1)
+	consulta.AnioHasta = entero(models.ParametroAnioHasta, 1)
+	consulta.Limite = entero(models.ParametroLimite, 1)
+	consulta.Desplazamiento = entero(models.ParametroDesplazamiento, 0)
+
+	if texto := valores.Get(models.ParametroPrestado); texto != "" {
+		prestado, err := models.ParsePrestado(texto)
+		if err != nil {
+			errores.Agregar(models.ParametroPrestado, "Debe ser Si o No")
+		}
+		consulta.Prestado = &prestado
+	}
+	switch strings.ToLower(valores.Get(models.ParametroDireccion)) {
+	case "", "asc":
+	case "desc":
+		consulta.Descendente = true
+	default:
+		errores.Agregar(models.ParametroDireccion, "Debe ser asc o desc")
+	}
+
+	// Los errores de lectura tienen prioridad sobre los de las reglas de la consulta, como en validarLibro.
+	var reglas models.ErroresValidacion
+	if errors.As(consulta.Validar(), &reglas) {
+		for parametro, mensaje := range reglas {
+			errores.Agregar(parametro, mensaje)
+		}
+	}
+	return consulta, errores.Err()
+}
+
+// enlacesPaginacion devuelve los enlaces de la cabecera Link (RFC 8288) a las otras páginas de la consulta.
+// Si la solicitud usa el parámetro desplazamiento se enlazan las páginas first, prev, next y last por
+// desplazamiento; si no, first y next por cursor, que es más eficiente en listas largas.
+// Los enlaces conservan los filtros, el orden y el límite de la solicitud.
+func enlacesPaginacion(r *http.Request, consulta models.ConsultaLibros, pagina models.PaginaLibros) []string {
+	enlace := func(rel string, parametro, valor string) string {
+		valores := r.URL.Query()
+		valores.Del(models.ParametroCursor)
+		valores.Del(models.ParametroDesplazamiento)
+		if parametro != "" {
+			valores.Set(parametro, valor)
+		}
+		return fmt.Sprintf("<%s?%s>; rel=%q", r.URL.Path, valores.Encode(), rel)
+	}
+
+	if !r.URL.Query().Has(models.ParametroDesplazamiento) {
+		enlaces := []string{enlace("first", "", "")}
+		if pagina.Siguiente != "" {
+			enlaces = append(enlaces, enlace("next", models.ParametroCursor, pagina.Siguiente))
+		}
+		return enlaces
+	}
+
+	limite := consulta.Limite
+	if limite == 0 {
+		limite = models.LimiteLibrosPorDefecto
+	}
+	enlaces := []string{enlace("first", models.ParametroDesplazamiento, "0")}
+	if consulta.Desplazamiento > 0 {
+		enlaces = append(enlaces, enlace("prev", models.ParametroDesplazamiento, strconv.Itoa(max(consulta.Desplazamiento-limite, 0))))
+	}
+	if consulta.Desplazamiento+limite < pagina.Total {
+		enlaces = append(enlaces, enlace("next", models.ParametroDesplazamiento, strconv.Itoa(consulta.Desplazamiento+limite)))
+	}
+	ultima := 0
+	if pagina.Total > 0 {
+		ultima = (pagina.Total - 1) / limite * limite
+	}
+	return append(enlaces, enlace("last", models.ParametroDesplazamiento, strconv.Itoa(ultima)))
+}
+
+// ApiBuscarLibros maneja la búsqueda de libros por texto: GET /api/libros/search?q=garcia marquez.
+// Busca las palabras de q en el título, el autor y la editorial, sin distinguir mayúsculas ni tildes,
+// y devuelve los libros de mayor a menor relevancia. El parámetro limite (de 1 a models.LimiteBusquedaMaximo)
+// acota la cantidad de resultados.
+func ApiBuscarLibros(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		texto, limite, err := busquedaDeURL(r.URL.Query(), models.LimiteBusquedaMaximo)
+		if err != nil {
+			responderParametrosInvalidos(w, r, err)
+			return
+		}
+
+		resultados, err := repo.BuscarLibros(texto, limite)
+		if err != nil {
+			responderError(w, r, err, "buscar los libros")
+			return
+		}
+
+		// Sin resultados se responde una lista vacía en lugar de null.
+		encontrados := make([]LibroEncontrado, 0, len(resultados))
+		for _, resultado := range resultados {
+			encontrados = append(encontrados, LibroEncontrado{LibroSimple: nuevoLibroSimple(resultado.Libro), Relevancia: resultado.Relevancia})
+		}
+		escribirJSON(w, http.StatusOK, encontrados)
+	}
+}
+
+// busquedaDeURL lee el texto de la búsqueda (parámetro q, obligatorio) y el límite de resultados (parámetro
+// limite, opcional, de 1 a maximo) de los parámetros de la URL. Si alguno no es válido devuelve
+// models.ErroresValidacion con un mensaje por parámetro.
+func busquedaDeURL(valores url.Values, maximo int) (string, int, error) {
+	errores := models.ErroresValidacion{}
+	texto := strings.TrimSpace(valores.Get(models.ParametroBusqueda))
+	if texto == "" {
+		errores.Agregar(models.ParametroBusqueda, "Es obligatorio")
+	}
+	limite := 0
+	if valor := valores.Get(models.ParametroLimite); valor != "" {
+		var err error
+		if limite, err = strconv.Atoi(valor); err != nil || limite < 1 || limite > maximo {
+			errores.Agregar(models.ParametroLimite, fmt.Sprintf("Debe ser un número entero entre 1 y %d", maximo))
+		}
+	}
+	return texto, limite, errores.Err()
+}
+
+// SugerenciaLibro es una sugerencia de GET /api/libros/suggest.
+type SugerenciaLibro struct {
+	Texto  string `json:"texto"`  // Título, autor o editorial tal como está guardado.
+	Campo  string `json:"campo"`  // Campo del valor: Titulo, Autor o Editorial.
+	Libros int    `json:"libros"` // Cantidad de libros que tienen ese valor.
+}
+
+// ApiSugerirLibros maneja las sugerencias mientras se escribe: GET /api/libros/suggest?q=marq.
+// Devuelve los títulos, autores y editoriales con alguna palabra que empieza con q, sin distinguir mayúsculas
+// ni tildes. El parámetro campo (Titulo, Autor o Editorial) restringe las sugerencias a un campo y limite
+// (de 1 a models.LimiteSugerenciasMaximo) acota su cantidad.
+func ApiSugerirLibros(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		errores := models.ErroresValidacion{}
+		texto, limite, err := busquedaDeURL(r.URL.Query(), models.LimiteSugerenciasMaximo)
+		errors.As(err, &errores)
+		campo := r.URL.Query().Get(models.ParametroCampo)
+		if campo != "" && !slices.Contains(models.CamposSugerencias, campo) {
+			errores.Agregar(models.ParametroCampo, "Debe ser uno de "+strings.Join(models.CamposSugerencias, ", "))
+		}
+		if err := errores.Err(); err != nil {
+			responderParametrosInvalidos(w, r, err)
+			return
+		}
+
+		sugerencias, err := repo.SugerirLibros(texto, campo, limite)
+		if err != nil {
+			responderError(w, r, err, "sugerir los libros")
+			return
+		}
+
+		// Sin sugerencias se responde una lista vacía en lugar de null.
+		respuesta := make([]SugerenciaLibro, 0, len(sugerencias))
+		for _, sugerencia := range sugerencias {
+			respuesta = append(respuesta, SugerenciaLibro{Texto: sugerencia.Texto, Campo: sugerencia.Campo, Libros: sugerencia.Libros})
+		}
+		// Las sugerencias se piden con cada tecla: el navegador puede reutilizarlas por unos segundos.
+		w.Header().Set("Cache-Control", "private, max-age=10")
+		escribirJSON(w, http.StatusOK, respuesta)
+	}
+}
+
+// ApiObtenerLibro maneja la solicitud para obtener un libro específico por su ID.
+func ApiObtenerLibro(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Extrae las variables de la URL (en este caso, el ID del libro).
+		vars := mux.Vars(r)
+		// Convierte el ID de la URL (que es una cadena) a un entero.
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			// Si el ID no es un número válido, se envía una respuesta de error 400.
+			responderIDInvalido(w, r)
+			return
+		}
+
+		// Obtiene el libro de la base de datos por su ID.
+		libro, err := repo.GetLibroByID(id)
+		if err != nil {
+			// Si hay un error en la base de datos, se informa sin mostrar su mensaje al cliente.
+			responderError(w, r, err, "recuperar el libro")
+			return
+		}
+
+		// Codifica el objeto Libro a formato JSON y lo escribe en la respuesta.
+		escribirJSON(w, http.StatusOK, libro)
+	}
+}
+
+// ApiCrearLibro maneja la solicitud para crear un nuevo libro.
+func ApiCrearLibro(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var entrada LibroEntrada // Declara una variable para decodificar el JSON del cuerpo de la solicitud.
+		// Decodifica el cuerpo de la solicitud JSON en la estructura LibroEntrada.
+		err := json.NewDecoder(r.Body).Decode(&entrada)
+		if err != nil {
+			// Si el JSON es inválido o incompleto, se envía una respuesta de error 400.
+			responderJSONInvalido(w, r, "el libro", err)
+			return
+		}
+
+		libro, err := entrada.Libro(0)
+		if err != nil {
+			// Los datos no cumplen las reglas del libro: se informa cada campo inválido.
+			responderDatosInvalidos(w, r, err)
+			return
+		}
+
+		// Llama a la función CreateLibro del modelo para insertar el nuevo libro en la base de datos.
+		creado, err := repo.CreateLibro(libro.Autor, libro.Titulo, libro.AnioPublicacion, libro.Editorial)
+		if err != nil {
+			// Si hay un error al crear el libro en la base de datos, se envía un problema 500 sin el error original.
+			responderError(w, r, err, "crear el libro")
+			return
+		}
+
+		// Si la creación es exitosa, se responde 201 (Created) con el libro guardado, con el ID que le asignó
+		// la base de datos, y su URL en la cabecera Location.
+		escribirCreado(w, "/api/libros/"+strconv.Itoa(creado.Id), creado)
+	}
+}
+
+// ApiActualizarLibro maneja la solicitud para actualizar un libro existente.
+func ApiActualizarLibro(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Extrae el ID del libro de la URL.
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		var entrada LibroEntrada // Estructura para decodificar el JSON de la solicitud.
+		// Decodifica el cuerpo de la solicitud JSON en la estructura LibroEntrada.
+		err = json.NewDecoder(r.Body).Decode(&entrada)
+		if err != nil {
+			responderJSONInvalido(w, r, "el libro", err)
+			return
+		}
+
+		// Asigna el ID de la URL al objeto libro, asegurando que se actualice el libro correcto.
+		libro, err := entrada.Libro(id)
+		if err != nil {
+			responderDatosInvalidos(w, r, err)
+			return
+		}
+
+		// Llama a la función UpdateLibro del modelo para actualizar el libro en la base de datos.
+		err = repo.UpdateLibro(libro)
+		if err != nil {
+			responderError(w, r, err, "actualizar el libro")
+			return
+		}
+
+		// Vuelve a leer el libro para responder con la disponibilidad real de sus ejemplares.
+		libro, err = repo.GetLibroByID(id)
+		if err != nil {
+			responderError(w, r, err, "recuperar el libro")
+			return
+		}
+
+		// Si la actualización es exitosa, se envía un estado HTTP 200 (OK) y el libro actualizado.
+		escribirJSON(w, http.StatusOK, libro)
+	}
+}
+
+// ApiEliminarLibro maneja la solicitud para eliminar un libro por su ID.
+func ApiEliminarLibro(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		// Extrae el ID del libro de la URL.
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		// Llama a la función DeleteLibro del modelo para eliminar el libro de la base de datos.
+		err = repo.DeleteLibro(id)
+		if err != nil {
+			responderError(w, r, err, "eliminar el libro")
+			return
+		}
+
+		// Si la eliminación es exitosa, se envía un estado HTTP 204 (No Content) para indicar que la acción fue exitosa
+		// pero no hay contenido que devolver.
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,28 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que expone en la API las multas calculadas por la revisión de atrasos.
+*/
+
+package handlers
+
+import (
+	"net/http"        // Paquete para manejar solicitudes y respuestas HTTP.
+	"proyecto/models" // Importa el paquete models donde se definen las multas.
+)
+
+// ApiListarMultas maneja la solicitud para obtener todas las multas por atraso.
+// Las multas las crea y actualiza la revisión periódica de atrasos, la API solo las consulta.
+func ApiListarMultas(multas models.MultaRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lista, err := multas.GetAllMultas()
+		if err != nil {
+			responderError(w, r, err, "recuperar las multas")
+			return
+		}
+		if lista == nil {
+			lista = []models.Multa{} // Devuelve [] en lugar de null cuando no hay multas.
+		}
+
+		escribirJSON(w, http.StatusOK, lista)
+	}
+}
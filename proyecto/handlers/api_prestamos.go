@@ -0,0 +1,107 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que maneja el préstamo y la devolución de libros en la API.
+*/
+
+package handlers
+
+import (
+	"net/http"        // Paquete para manejar solicitudes y respuestas HTTP.
+	"proyecto/models" // Importa el paquete models donde se definen los préstamos.
+	"strconv"         // Paquete para la conversión de cadenas a tipos numéricos.
+
+	"github.com/goccy/go-json" // Paquete para codificar/decodificar JSON de forma eficiente.
+	"github.com/gorilla/mux"   // Router HTTP para manejar las rutas de la aplicación.
+)
+
+// PrestamoEntrada es el cuerpo JSON aceptado al registrar un préstamo.
+// FechaVencimiento admite "AAAA-MM-DD" o RFC 3339; si se omite, el préstamo vence en DiasPrestamoPorDefecto días.
+type PrestamoEntrada struct {
+	LibroId          int
+	SocioId          int
+	FechaVencimiento string
+}
+
+// ApiListarPrestamos maneja la solicitud para obtener todos los préstamos.
+func ApiListarPrestamos(prestamos models.PrestamoRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lista, err := prestamos.GetAllPrestamos()
+		if err != nil {
+			responderError(w, r, err, "recuperar los préstamos")
+			return
+		}
+		if lista == nil {
+			lista = []models.Prestamo{} // Devuelve [] en lugar de null cuando no hay préstamos.
+		}
+
+		escribirJSON(w, http.StatusOK, lista)
+	}
+}
+
+// ApiObtenerPrestamo maneja la solicitud para obtener un préstamo específico por su ID.
+func ApiObtenerPrestamo(prestamos models.PrestamoRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		prestamo, err := prestamos.GetPrestamoByID(id)
+		if err != nil {
+			responderError(w, r, err, "recuperar el préstamo")
+			return
+		}
+
+		escribirJSON(w, http.StatusOK, prestamo)
+	}
+}
+
+// ApiCrearPrestamo maneja la solicitud para prestar un libro.
+func ApiCrearPrestamo(prestamos models.PrestamoRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var entrada PrestamoEntrada
+		if err := json.NewDecoder(r.Body).Decode(&entrada); err != nil {
+			responderJSONInvalido(w, r, "el préstamo", err)
+			return
+		}
+		if entrada.LibroId == 0 || entrada.SocioId == 0 {
+			responderProblema(w, r, nuevoProblema(http.StatusBadRequest, "LibroId y SocioId son obligatorios"))
+			return
+		}
+		FechaVencimiento, err := models.ParseFechaVencimiento(entrada.FechaVencimiento)
+		if err != nil {
+			responderDatosInvalidos(w, r, err)
+			return
+		}
+
+		prestamo, err := prestamos.PrestarLibro(entrada.LibroId, entrada.SocioId, FechaVencimiento)
+		if err != nil {
+			responderError(w, r, err, "prestar el libro")
+			return
+		}
+
+		escribirCreado(w, "/api/prestamos/"+strconv.Itoa(prestamo.Id), prestamo)
+	}
+}
+
+// ApiDevolverPrestamo maneja la solicitud para registrar la devolución de un préstamo.
+func ApiDevolverPrestamo(prestamos models.PrestamoRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		prestamo, err := prestamos.DevolverLibro(id)
+		if err != nil {
+			responderError(w, r, err, "registrar la devolución")
+			return
+		}
+
+		escribirJSON(w, http.StatusOK, prestamo)
+	}
+}
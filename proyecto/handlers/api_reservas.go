@@ -0,0 +1,132 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que maneja las reservas (cola de espera) de libros en la API.
+*/
+
+package handlers
+
+import (
+	"net/http"        // Paquete para manejar solicitudes y respuestas HTTP.
+	"proyecto/models" // Importa el paquete models donde se definen las reservas.
+	"strconv"         // Paquete para la conversión de cadenas a tipos numéricos.
+
+	"github.com/goccy/go-json" // Paquete para codificar/decodificar JSON de forma eficiente.
+	"github.com/gorilla/mux"   // Router HTTP para manejar las rutas de la aplicación.
+)
+
+// ReservaEntrada es el cuerpo JSON aceptado al crear una reserva.
+type ReservaEntrada struct {
+	LibroId int
+	SocioId int
+}
+
+// escribirReservas codifica una lista de reservas como JSON, usando [] en lugar de null cuando está vacía.
+func escribirReservas(w http.ResponseWriter, lista []models.Reserva) {
+	if lista == nil {
+		lista = []models.Reserva{}
+	}
+	escribirJSON(w, http.StatusOK, lista)
+}
+
+// ApiListarReservas maneja la solicitud para obtener las reservas abiertas de todos los libros.
+func ApiListarReservas(reservas models.ReservaRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lista, err := reservas.GetReservasAbiertas()
+		if err != nil {
+			responderError(w, r, err, "recuperar las reservas")
+			return
+		}
+		escribirReservas(w, lista)
+	}
+}
+
+// ApiReservasLibro maneja la solicitud para obtener la cola de reservas de un libro.
+func ApiReservasLibro(libros models.LibroRepository, reservas models.ReservaRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		if _, err := libros.GetLibroByID(id); err != nil {
+			responderError(w, r, err, "recuperar el libro")
+			return
+		}
+		lista, err := reservas.GetReservasByLibro(id)
+		if err != nil {
+			responderError(w, r, err, "recuperar las reservas")
+			return
+		}
+		escribirReservas(w, lista)
+	}
+}
+
+// ApiObtenerReserva maneja la solicitud para obtener una reserva específica por su ID.
+func ApiObtenerReserva(reservas models.ReservaRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		reserva, err := reservas.GetReservaByID(id)
+		if err != nil {
+			responderError(w, r, err, "recuperar la reserva")
+			return
+		}
+
+		escribirJSON(w, http.StatusOK, reserva)
+	}
+}
+
+// ApiCrearReserva maneja la solicitud para poner a un socio en la cola de un libro sin copias libres.
+func ApiCrearReserva(reservas models.ReservaRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var entrada ReservaEntrada
+		if err := json.NewDecoder(r.Body).Decode(&entrada); err != nil {
+			responderJSONInvalido(w, r, "la reserva", err)
+			return
+		}
+		if entrada.LibroId <= 0 || entrada.SocioId <= 0 {
+			responderProblema(w, r, nuevoProblema(http.StatusBadRequest, "LibroId y SocioId son obligatorios"))
+			return
+		}
+
+		reserva, err := reservas.CrearReserva(entrada.LibroId, entrada.SocioId)
+		if err != nil {
+			responderError(w, r, err, "reservar el libro")
+			return
+		}
+
+		escribirCreado(w, "/api/reservas/"+strconv.Itoa(reserva.Id), reserva)
+	}
+}
+
+// ApiCancelarReserva maneja la solicitud para cancelar una reserva abierta.
+// Si la reserva tenía una copia apartada, la copia pasa a la siguiente reserva de la cola.
+func ApiCancelarReserva(reservas models.ReservaRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		if _, err := reservas.GetReservaByID(id); err != nil {
+			responderError(w, r, err, "recuperar la reserva")
+			return
+		}
+		reserva, err := reservas.CancelarReserva(id)
+		if err != nil {
+			responderError(w, r, err, "cancelar la reserva")
+			return
+		}
+
+		escribirJSON(w, http.StatusOK, reserva)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que maneja las operaciones CRUD para la entidad Socio en la API.
+*/
+
+package handlers
+
+import (
+	"errors"          // Paquete para reconocer los errores del modelo.
+	"net/http"        // Paquete para manejar solicitudes y respuestas HTTP.
+	"proyecto/models" // Importa el paquete models donde se definen los socios.
+	"strconv"         // Paquete para la conversión de cadenas a tipos numéricos.
+	"strings"         // Paquete para limpiar los valores recibidos.
+
+	"github.com/goccy/go-json" // Paquete para codificar/decodificar JSON de forma eficiente.
+	"github.com/gorilla/mux"   // Router HTTP para manejar las rutas de la aplicación.
+)
+
+// SocioSimple es la versión resumida de un socio que devuelve el listado de la API.
+type SocioSimple struct {
+	Id     int    `json:"id"`     // ID del socio.
+	Nombre string `json:"nombre"` // Nombre completo del socio.
+	Estado string `json:"estado"` // Estado de la membresía.
+}
+
+// SocioEntrada es el cuerpo JSON aceptado al crear o actualizar un socio.
+// Si Estado se omite, el socio queda con la membresía activa.
+type SocioEntrada struct {
+	Nombre    string
+	Email     string
+	Telefono  string
+	Direccion string
+	Estado    string
+}
+
+// Socio convierte los datos recibidos en un models.Socio con el ID indicado y valida los campos.
+func (e SocioEntrada) Socio(id int) (models.Socio, error) {
+	socio := models.Socio{
+		Id:        id,
+		Nombre:    strings.TrimSpace(e.Nombre),
+		Email:     strings.TrimSpace(e.Email),
+		Telefono:  strings.TrimSpace(e.Telefono),
+		Direccion: strings.TrimSpace(e.Direccion),
+		Estado:    e.Estado,
+	}
+	if socio.Nombre == "" {
+		return socio, errors.New("Nombre es obligatorio")
+	}
+	if socio.Estado == "" {
+		socio.Estado = models.EstadoSocioActivo
+	}
+	if !models.EstadoSocioValido(socio.Estado) {
+		return socio, errors.New("Estado debe ser uno de: " + strings.Join(models.EstadosSocio, ", "))
+	}
+	return socio, nil
+}
+
+// ApiListarSocios maneja la solicitud para obtener una lista simplificada de todos los socios.
+func ApiListarSocios(repo models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		socios, err := repo.GetAllSocios()
+		if err != nil {
+			responderError(w, r, err, "recuperar los socios")
+			return
+		}
+
+		// Devuelve [] en lugar de null cuando no hay socios.
+		datosSimples := []SocioSimple{}
+		for _, socio := range socios {
+			datosSimples = append(datosSimples, SocioSimple{
+				Id:     socio.Id,
+				Nombre: socio.Nombre,
+				Estado: socio.Estado,
+			})
+		}
+
+		escribirJSON(w, http.StatusOK, datosSimples)
+	}
+}
+
+// ApiObtenerSocio maneja la solicitud para obtener un socio específico por su ID.
+func ApiObtenerSocio(repo models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		socio, err := repo.GetSocioByID(id)
+		if err != nil {
+			responderError(w, r, err, "recuperar el socio")
+			return
+		}
+
+		escribirJSON(w, http.StatusOK, socio)
+	}
+}
+
+// ApiCrearSocio maneja la solicitud para inscribir un nuevo socio.
+func ApiCrearSocio(repo models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var entrada SocioEntrada
+		if err := json.NewDecoder(r.Body).Decode(&entrada); err != nil {
+			responderJSONInvalido(w, r, "el socio", err)
+			return
+		}
+		socio, err := entrada.Socio(0)
+		if err != nil {
+			responderDatosInvalidos(w, r, err)
+			return
+		}
+
+		creado, err := repo.CreateSocio(socio)
+		if err != nil {
+			responderError(w, r, err, "crear el socio")
+			return
+		}
+
+		escribirCreado(w, "/api/socios/"+strconv.Itoa(creado.Id), creado)
+	}
+}
+
+// ApiActualizarSocio maneja la solicitud para actualizar un socio existente.
+func ApiActualizarSocio(repo models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		// UpdateSocio no informa si el socio no existe, así que se busca antes para responder 404.
+		actual, err := repo.GetSocioByID(id)
+		if err != nil {
+			responderError(w, r, err, "recuperar el socio")
+			return
+		}
+
+		var entrada SocioEntrada
+		if err := json.NewDecoder(r.Body).Decode(&entrada); err != nil {
+			responderJSONInvalido(w, r, "el socio", err)
+			return
+		}
+		socio, err := entrada.Socio(id)
+		if err != nil {
+			responderDatosInvalidos(w, r, err)
+			return
+		}
+
+		if err := repo.UpdateSocio(socio); err != nil {
+			responderError(w, r, err, "actualizar el socio")
+			return
+		}
+
+		socio.FechaAlta = actual.FechaAlta // La fecha de alta no cambia al actualizar.
+		escribirJSON(w, http.StatusOK, socio)
+	}
+}
+
+// ApiEliminarSocio maneja la solicitud para eliminar un socio sin préstamos.
+func ApiEliminarSocio(repo models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		err = repo.DeleteSocio(id)
+		if err != nil {
+			responderError(w, r, err, "eliminar el socio")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ApiPrestamosSocio maneja la solicitud para obtener el historial de préstamos de un socio.
+func ApiPrestamosSocio(socios models.SocioRepository, prestamos models.PrestamoRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			responderIDInvalido(w, r)
+			return
+		}
+
+		if _, err := socios.GetSocioByID(id); err != nil {
+			responderError(w, r, err, "recuperar el socio")
+			return
+		}
+		historial, err := prestamos.GetPrestamosBySocio(id)
+		if err != nil {
+			responderError(w, r, err, "recuperar los préstamos")
+			return
+		}
+		if historial == nil {
+			historial = []models.Prestamo{} // Devuelve [] en lugar de null cuando no hay préstamos.
+		}
+
+		escribirJSON(w, http.StatusOK, historial)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que verifica en cada ruta que el rol del usuario, o el alcance de la clave de API, permita la acción.
+*/
+
+package handlers
+
+import (
+	"fmt"             // Paquete para formatear el mensaje de alcance insuficiente.
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"proyecto/models" // Importa el paquete models donde se define la matriz de permisos.
+	"strings"         // Paquete para reconocer las rutas de la API.
+)
+
+// ConPermiso envuelve un manejador para que solo lo ejecuten los usuarios cuyo rol tiene el permiso indicado
+// (ver models.PermisosPorRol). Se registra ruta por ruta en nuevoRouter; los demás usuarios reciben
+// 403 Forbidden, en problem+json si la ruta es de la API. Debe ejecutarse después de RequerirSesion.
+func ConPermiso(permiso models.Permiso, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		usuario, ok := UsuarioActual(r)
+		if !ok || !usuario.Puede(permiso) {
+			log.Printf("Acceso denegado: el usuario %q (%s) no tiene el permiso %q para %s %s", usuario.Usuario, usuario.Rol, permiso, r.Method, r.URL.Path)
+			responderSinPermiso(w, r)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// ConAlcance protege una ruta de la API. Los clientes autenticados con una clave o un token (ver AutenticarAPI)
+// necesitan el alcance indicado; los usuarios con sesión, el permiso de su rol, como en ConPermiso.
+// Sin el alcance se responde 403 Forbidden en problem+json.
+func ConAlcance(alcance models.Alcance, permiso models.Permiso, next http.HandlerFunc) http.HandlerFunc {
+	porRol := ConPermiso(permiso, next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		credencial, ok := CredencialActual(r)
+		if !ok {
+			porRol(w, r)
+			return
+		}
+		if !credencial.Clave.Tiene(alcance) {
+			log.Printf("Acceso denegado: la clave de API %d (%s) no tiene el alcance %q para %s %s", credencial.Clave.Id, credencial.Clave.Nombre, alcance, r.Method, r.URL.Path)
+			responderProblema(w, r, nuevoProblema(http.StatusForbidden, fmt.Sprintf("La credencial no tiene el alcance %s", alcance)))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// responderSinPermiso responde 403 Forbidden, en problem+json para la API y en texto para la interfaz web.
+func responderSinPermiso(w http.ResponseWriter, r *http.Request) {
+	const mensaje = "Su rol no tiene permiso para realizar esta acción"
+	if !strings.HasPrefix(r.URL.Path, prefijoAPI) {
+		http.Error(w, mensaje+".", http.StatusForbidden)
+		return
+	}
+	responderProblema(w, r, nuevoProblema(http.StatusForbidden, mensaje))
+}
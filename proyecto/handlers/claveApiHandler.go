@@ -0,0 +1,119 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo con la página de administración de las claves de API: alta y revocación.
+*/
+
+package handlers
+
+import (
+	"bytes"           // Paquete para ejecutar la plantilla en memoria antes de responder.
+	"errors"          // Paquete para reconocer los errores de validación.
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"proyecto/models" // Importa el paquete models para interactuar con las claves de API.
+	"strconv"         // Paquete para conversión de tipos.
+	"strings"         // Paquete para limpiar los valores del formulario.
+
+	"github.com/gorilla/mux" // Router HTTP para manejar rutas.
+)
+
+// paginaClavesAPI contiene los datos de la plantilla clavesApi.html: la lista de claves y el formulario de alta.
+type paginaClavesAPI struct {
+	Claves     []models.ClaveAPI        // Claves activas y revocadas.
+	Alcances   []models.Alcance         // Opciones de las casillas de alcance.
+	Formulario models.ClaveAPI          // Datos escritos en el formulario, si se rechazó.
+	Marcados   map[models.Alcance]bool  // Alcances marcados en el formulario.
+	Errores    models.ErroresValidacion // Mensaje de error de cada campo inválido.
+	Nueva      string                   // Clave recién creada; solo se muestra en la respuesta que la crea.
+}
+
+// renderizarClavesAPI muestra la página de claves de API con el código de estado indicado.
+func renderizarClavesAPI(w http.ResponseWriter, r *http.Request, claves models.ClaveAPIRepository, pagina paginaClavesAPI, estado int) {
+	lista, err := claves.GetAllClaves()
+	if err != nil {
+		responderErrorWeb(w, r, err, "recuperar las claves de API")
+		return
+	}
+	pagina.Claves = lista
+	pagina.Alcances = models.Alcances
+
+	tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/clavesApi.html")
+	if err != nil {
+		log.Printf("Error al cargar el template: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+	var contenido bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&contenido, "base", pagina); err != nil {
+		log.Printf("Error al ejecutar el template: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// La página puede mostrar una clave recién creada, que no debe quedar en la caché del navegador.
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(estado)
+	contenido.WriteTo(w)
+}
+
+// RecuperarClavesAPI lista las claves de API junto con el formulario para crear una nueva.
+func RecuperarClavesAPI(claves models.ClaveAPIRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renderizarClavesAPI(w, r, claves, paginaClavesAPI{}, http.StatusOK)
+	}
+}
+
+// CreateClaveAPIPostHandler crea una clave de API y vuelve a mostrar la lista con la clave completa,
+// que no se guarda y no se puede recuperar después. Si los datos no son válidos responde 422 con un
+// mensaje junto a cada campo.
+func CreateClaveAPIPostHandler(claves models.ClaveAPIRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		formulario := models.ClaveAPI{Nombre: strings.TrimSpace(r.FormValue(models.CampoNombreClave))}
+		marcados := map[models.Alcance]bool{}
+		for _, valor := range r.Form[models.CampoAlcances] {
+			alcance := models.Alcance(valor)
+			formulario.Alcances = append(formulario.Alcances, alcance)
+			marcados[alcance] = true
+		}
+
+		clave, texto, err := claves.CrearClave(formulario)
+		if err != nil {
+			var errores models.ErroresValidacion
+			if errors.As(err, &errores) {
+				pagina := paginaClavesAPI{Formulario: formulario, Marcados: marcados, Errores: errores}
+				renderizarClavesAPI(w, r, claves, pagina, http.StatusUnprocessableEntity)
+				return
+			}
+			responderErrorWeb(w, r, err, "crear la clave de API")
+			return
+		}
+		log.Printf("Se creó la clave de API %d (%s) con los alcances %v.", clave.Id, clave.Nombre, clave.Alcances)
+		renderizarClavesAPI(w, r, claves, paginaClavesAPI{Nueva: texto}, http.StatusCreated)
+	}
+}
+
+// RevocarClaveAPIHandler revoca una clave de API y vuelve a la lista. Los tokens emitidos con la clave
+// dejan de valer al mismo tiempo.
+func RevocarClaveAPIHandler(claves models.ClaveAPIRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["Id"])
+		if err != nil {
+			http.Error(w, "ID de clave de API inválido", http.StatusBadRequest)
+			return
+		}
+		if _, err := claves.GetClaveByID(id); err != nil {
+			responderErrorWeb(w, r, err, "recuperar la clave de API")
+			return
+		}
+		if err := claves.RevocarClave(id); err != nil {
+			responderErrorWeb(w, r, err, "revocar la clave de API")
+			return
+		}
+		http.Redirect(w, r, "/claves-api", http.StatusSeeOther)
+	}
+}
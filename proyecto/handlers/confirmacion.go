@@ -0,0 +1,46 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que muestra la página de confirmación antes de eliminar un libro, un socio o un ejemplar.
+*/
+
+package handlers
+
+import (
+	"log"      // Paquete para logging.
+	"net/http" // Paquete para manejar solicitudes HTTP.
+)
+
+// detalleConfirmacion es un dato del registro que se muestra en la página de confirmación.
+type detalleConfirmacion struct {
+	Etiqueta string // Nombre del dato, por ejemplo "Autor".
+	Valor    string // Valor del dato.
+}
+
+// confirmacionEliminacion contiene los datos de la plantilla confirmarEliminacion.html.
+// La página muestra el registro y un formulario POST con _method=DELETE hacia Accion;
+// la eliminación nunca se hace con un GET, para que un enlace o un navegador que precarga páginas no borre datos.
+type confirmacionEliminacion struct {
+	Titulo      string                // Título de la página, por ejemplo "Eliminar Libro".
+	Nombre      string                // Nombre del registro que se va a eliminar.
+	Detalles    []detalleConfirmacion // Datos del registro.
+	Advertencia string                // Consecuencias de la eliminación (por ejemplo, los datos que se borran con él).
+	Bloqueo     string                // Si no está vacío, el motivo por el que no se puede eliminar; no se muestra el botón.
+	Accion      string                // URL a la que se envía la eliminación.
+	Volver      string                // URL a la que vuelve el botón Cancelar.
+	CampoMetodo string                // Nombre del campo oculto que indica el método DELETE.
+}
+
+// renderizarConfirmacion muestra la página de confirmación de una eliminación.
+func renderizarConfirmacion(w http.ResponseWriter, r *http.Request, datos confirmacionEliminacion) {
+	datos.CampoMetodo = CampoMetodo
+	tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/confirmarEliminacion.html")
+	if err != nil {
+		log.Printf("Error al cargar el template: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+	if err := tmpl.ExecuteTemplate(w, "base", datos); err != nil {
+		log.Printf("Error al ejecutar el template: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+	}
+}
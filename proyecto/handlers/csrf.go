@@ -0,0 +1,144 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que protege los formularios web contra solicitudes falsificadas desde otros sitios (CSRF).
+*/
+
+package handlers
+
+import (
+	"context"         // Paquete para guardar el token en el contexto de la solicitud.
+	"crypto/hmac"     // Paquete para derivar el token de la sesión.
+	"crypto/rand"     // Paquete para generar tokens impredecibles.
+	"crypto/sha256"   // Paquete con la función de hash del HMAC.
+	"crypto/subtle"   // Paquete para comparar tokens en tiempo constante.
+	"encoding/base64" // Paquete para representar el token como texto.
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"strings"         // Paquete para reconocer las rutas excluidas.
+)
+
+const (
+	// CookieCSRF es la cookie donde se guarda el token CSRF del navegador mientras no tiene una sesión iniciada.
+	CookieCSRF = "csrf_token"
+	// CampoCSRF es el campo oculto de los formularios que debe repetir el token CSRF.
+	CampoCSRF = "csrf_token"
+	// CabeceraCSRF permite enviar el token en una cabecera, para las solicitudes hechas desde JavaScript.
+	CabeceraCSRF = "X-CSRF-Token"
+	// prefijoAPI es el prefijo de las rutas de la API, que además de la sesión aceptan claves y tokens de API.
+	prefijoAPI = "/api/"
+)
+
+// claveContexto es el tipo de las claves que este paquete guarda en el contexto de una solicitud.
+type claveContexto string
+
+// claveTokenCSRF guarda en el contexto el token CSRF de la solicitud, para que las plantillas lo incluyan en sus formularios.
+const claveTokenCSRF claveContexto = "csrf"
+
+// ProtegerCSRF verifica que los POST, PUT y DELETE hechos con la sesión del navegador lleven su token CSRF.
+// Con una sesión iniciada el token se deriva de la cookie de sesión (ver TokenCSRFDeSesion), así que cambia
+// con cada inicio de sesión; antes de iniciarla, para el formulario de /login, se genera uno aleatorio que se
+// guarda en una cookie HttpOnly. Las plantillas lo repiten en cada formulario con {{ campoCSRF }}. Un sitio
+// ajeno puede hacer que el navegador envíe las cookies, pero no puede leerlas para escribir el token en el
+// formulario, así que su solicitud se rechaza con 403 Forbidden.
+// Las solicitudes a /api que usan la sesión deben enviar el token en la cabecera X-CSRF-Token; las que se
+// autentican con una clave o un token de API no se verifican, por lo que AutenticarAPI debe ejecutarse antes.
+// Las rutas de /static no se verifican.
+func ProtegerCSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/static/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		sesion := ""
+		if cookie, err := r.Cookie(CookieSesion); err == nil {
+			sesion = cookie.Value
+		}
+		api := strings.HasPrefix(r.URL.Path, prefijoAPI)
+		if api {
+			// Una clave o un token viajan en cabeceras que otro sitio no puede agregar, y sin sesión ni
+			// credencial RequerirSesion responde 401: solo hay que verificar la API usada con la sesión.
+			if _, ok := CredencialActual(r); ok || sesion == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		token := ""
+		if sesion != "" {
+			token = TokenCSRFDeSesion(sesion)
+		} else if cookie, err := r.Cookie(CookieCSRF); err == nil && cookie.Value != "" {
+			token = cookie.Value
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			// Las consultas no modifican datos; si el navegador todavía no tiene token, se le entrega uno.
+			if token == "" {
+				nuevo, err := renovarCookieCSRF(w)
+				if err != nil {
+					log.Printf("Error al generar el token CSRF: %v", err)
+					http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+					return
+				}
+				token = nuevo
+			}
+		default:
+			// La API solo acepta el token en la cabecera: sus cuerpos son JSON, no formularios.
+			enviado := r.Header.Get(CabeceraCSRF)
+			if enviado == "" && !api {
+				enviado = r.PostFormValue(CampoCSRF)
+			}
+			if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(enviado)) != 1 {
+				if api {
+					responderProblema(w, r, nuevoProblema(http.StatusForbidden, "Falta el token CSRF de la sesión en la cabecera "+CabeceraCSRF+" o no es válido"))
+					return
+				}
+				http.Error(w, "Solicitud rechazada: el token CSRF falta o no es válido. Recargue la página e inténtelo de nuevo.", http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claveTokenCSRF, token)))
+	})
+}
+
+// TokenCSRF devuelve el token CSRF de la solicitud, o una cadena vacía si no pasó por ProtegerCSRF.
+func TokenCSRF(r *http.Request) string {
+	token, _ := r.Context().Value(claveTokenCSRF).(string)
+	return token
+}
+
+// TokenCSRFDeSesion devuelve el token CSRF de la sesión con el token indicado: el HMAC-SHA256 del texto "csrf"
+// con el token de la sesión como clave. Las páginas pueden mostrarlo sin revelar el token de la sesión.
+func TokenCSRFDeSesion(sesion string) string {
+	mac := hmac.New(sha256.New, []byte(sesion))
+	mac.Write([]byte("csrf"))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// renovarCookieCSRF entrega al navegador una cookie CSRF nueva y devuelve su token. Además de la primera visita,
+// se usa al iniciar y al cerrar la sesión, para que un token que otro sitio haya plantado antes deje de servir.
+func renovarCookieCSRF(w http.ResponseWriter) (string, error) {
+	token, err := nuevoTokenCSRF()
+	if err != nil {
+		return "", err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieCSRF,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return token, nil
+}
+
+// nuevoTokenCSRF genera un token aleatorio de 32 bytes codificado en base64 apto para URL.
+func nuevoTokenCSRF() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
@@ -0,0 +1,275 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que maneja los ejemplares (copias físicas) de un libro en la interfaz web.
+*/
+
+package handlers
+
+import (
+	"errors"          // Paquete para reconocer los errores del modelo.
+	"fmt"             // Paquete para formatear los mensajes de error y las URL.
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"proyecto/models" // Importa el paquete models para interactuar con libros y ejemplares.
+	"strconv"         // Paquete para conversión de tipos.
+	"strings"         // Paquete para limpiar los valores del formulario.
+	"time"            // Paquete para la fecha máxima de adquisición.
+
+	"github.com/gorilla/mux" // Router HTTP para manejar rutas.
+)
+
+// nuevoEjemplar arma un models.Ejemplar con los datos recibidos de un formulario o de la API y valida los campos.
+// La condición vacía se toma como "Bueno" y la fecha vacía como la fecha de hoy.
+func nuevoEjemplar(id, LibroId int, CodigoBarras, Ubicacion, Condicion, FechaAdquisicion string) (models.Ejemplar, error) {
+	ejemplar := models.Ejemplar{
+		Id:           id,
+		LibroId:      LibroId,
+		CodigoBarras: strings.TrimSpace(CodigoBarras),
+		Ubicacion:    strings.TrimSpace(Ubicacion),
+		Condicion:    Condicion,
+	}
+	if ejemplar.CodigoBarras == "" {
+		return ejemplar, errors.New("el código de barras es obligatorio")
+	}
+	if ejemplar.Condicion == "" {
+		ejemplar.Condicion = models.CondicionBueno
+	}
+	if !models.CondicionEjemplarValida(ejemplar.Condicion) {
+		return ejemplar, fmt.Errorf("condición inválida: %q (use %s)", ejemplar.Condicion, strings.Join(models.CondicionesEjemplar, ", "))
+	}
+	fecha, err := models.ParseFechaAdquisicion(FechaAdquisicion)
+	if err != nil {
+		return ejemplar, err
+	}
+	ejemplar.FechaAdquisicion = fecha
+	return ejemplar, nil
+}
+
+// RecuperarEjemplares muestra las copias de un libro junto con el formulario para agregar una nueva.
+func RecuperarEjemplares(libros models.LibroRepository, ejemplares models.EjemplarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de libro inválido", http.StatusBadRequest)
+			return
+		}
+
+		libro, err := libros.GetLibroByID(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el libro")
+			return
+		}
+		copias, err := ejemplares.GetEjemplaresByLibro(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar los ejemplares")
+			return
+		}
+
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/ejemplares.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+
+		// Pasa el libro, sus copias y los valores sugeridos para el formulario de una copia nueva.
+		data := struct {
+			Libro          models.Libro
+			Ejemplares     []models.Ejemplar
+			Condiciones    []string
+			CodigoSugerido string
+			Hoy            string
+		}{
+			Libro:          libro,
+			Ejemplares:     copias,
+			Condiciones:    models.CondicionesEjemplar,
+			CodigoSugerido: models.CodigoBarrasSugerido(libro.Id, libro.Ejemplares+1),
+			Hoy:            time.Now().Format("2006-01-02"),
+		}
+
+		err = tmpl.ExecuteTemplate(w, "base", data)
+		if err != nil {
+			log.Printf("Error al ejecutar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// CreateEjemplarPostHandler procesa el formulario para agregar una copia a un libro.
+func CreateEjemplarPostHandler(ejemplares models.EjemplarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		vars := mux.Vars(r)
+		LibroId, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de libro inválido", http.StatusBadRequest)
+			return
+		}
+
+		ejemplar, err := nuevoEjemplar(0, LibroId, r.FormValue("CodigoBarras"), r.FormValue("Ubicacion"),
+			r.FormValue("Condicion"), r.FormValue("FechaAdquisicion"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if _, err := ejemplares.CreateEjemplar(ejemplar); err != nil {
+			responderErrorWeb(w, r, err, "crear el ejemplar")
+			return
+		}
+
+		http.Redirect(w, r, fmt.Sprintf("/libros/%d/ejemplares", LibroId), http.StatusSeeOther)
+	}
+}
+
+// UpdateEjemplarGetHandler muestra el formulario para editar una copia.
+func UpdateEjemplarGetHandler(ejemplares models.EjemplarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de ejemplar inválido", http.StatusBadRequest)
+			return
+		}
+
+		ejemplar, err := ejemplares.GetEjemplarByID(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el ejemplar")
+			return
+		}
+
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/editarEjemplar.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+
+		data := struct {
+			models.Ejemplar
+			Condiciones []string
+			Hoy         string
+		}{
+			Ejemplar:    ejemplar,
+			Condiciones: models.CondicionesEjemplar,
+			Hoy:         time.Now().Format("2006-01-02"),
+		}
+
+		err = tmpl.ExecuteTemplate(w, "base", data)
+		if err != nil {
+			log.Printf("Error al ejecutar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// UpdateEjemplarPostHandler procesa el formulario para actualizar una copia.
+func UpdateEjemplarPostHandler(ejemplares models.EjemplarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de ejemplar inválido", http.StatusBadRequest)
+			return
+		}
+
+		// Se lee la copia actual para saber a qué libro volver después de guardar.
+		actual, err := ejemplares.GetEjemplarByID(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el ejemplar")
+			return
+		}
+
+		ejemplar, err := nuevoEjemplar(id, actual.LibroId, r.FormValue("CodigoBarras"), r.FormValue("Ubicacion"),
+			r.FormValue("Condicion"), r.FormValue("FechaAdquisicion"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := ejemplares.UpdateEjemplar(ejemplar); err != nil {
+			responderErrorWeb(w, r, err, "actualizar el ejemplar")
+			return
+		}
+
+		http.Redirect(w, r, fmt.Sprintf("/libros/%d/ejemplares", actual.LibroId), http.StatusSeeOther)
+	}
+}
+
+// ConfirmarEliminarEjemplarHandler muestra los datos de la copia y pide confirmación antes de eliminarla.
+// Una copia prestada o apartada para una reserva no se puede eliminar, y la página lo explica.
+func ConfirmarEliminarEjemplarHandler(ejemplares models.EjemplarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de ejemplar inválido", http.StatusBadRequest)
+			return
+		}
+
+		ejemplar, err := ejemplares.GetEjemplarByID(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el ejemplar")
+			return
+		}
+
+		confirmacion := confirmacionEliminacion{
+			Titulo: "Eliminar Ejemplar",
+			Nombre: ejemplar.CodigoBarras,
+			Detalles: []detalleConfirmacion{
+				{Etiqueta: "Libro", Valor: ejemplar.Titulo},
+				{Etiqueta: "Ubicación", Valor: ejemplar.Ubicacion},
+				{Etiqueta: "Condición", Valor: ejemplar.Condicion},
+				{Etiqueta: "Adquirido", Valor: ejemplar.FechaAdquisicion.Format("02/01/2006")},
+			},
+			Advertencia: "También se eliminará el historial de préstamos de esta copia.",
+			Accion:      fmt.Sprintf("/ejemplares/eliminar/%d", ejemplar.Id),
+			Volver:      fmt.Sprintf("/libros/%d/ejemplares", ejemplar.LibroId),
+		}
+		switch {
+		case ejemplar.Prestado:
+			confirmacion.Bloqueo = "No se puede eliminar: " + models.ErrEjemplarPrestado.Error() + "."
+		case ejemplar.Reservado:
+			confirmacion.Bloqueo = "No se puede eliminar: " + models.ErrEjemplarReservado.Error() + "."
+		}
+		renderizarConfirmacion(w, r, confirmacion)
+	}
+}
+
+// DeleteEjemplarHandler maneja la solicitud para eliminar una copia que no está prestada.
+// Solo responde a POST y DELETE (los formularios envían _method=DELETE); un GET recibe 405.
+func DeleteEjemplarHandler(ejemplares models.EjemplarRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de ejemplar inválido", http.StatusBadRequest)
+			return
+		}
+
+		ejemplar, err := ejemplares.GetEjemplarByID(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el ejemplar")
+			return
+		}
+
+		err = ejemplares.DeleteEjemplar(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "eliminar el ejemplar")
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/libros/%d/ejemplares", ejemplar.LibroId), http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que muestra la página de recurso no encontrado y responde los errores de la interfaz web según su clase.
+*/
+
+package handlers
+
+import (
+	"errors"          // Paquete para reconocer la clase de los errores del modelo.
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"proyecto/models" // Importa el paquete models donde se definen las clases de error.
+)
+
+// paginaNoEncontrada contiene los datos de la plantilla noEncontrado.html.
+type paginaNoEncontrada struct {
+	Detalle string // Qué no se encontró, por ejemplo "libro con ID 99 no encontrado".
+	Volver  string // URL del botón Volver.
+}
+
+// renderizarNoEncontrado muestra la página de recurso no encontrado con el estado 404 Not Found.
+func renderizarNoEncontrado(w http.ResponseWriter, r *http.Request, detalle string) {
+	tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/noEncontrado.html")
+	if err != nil {
+		log.Printf("Error al cargar el template: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	if err := tmpl.ExecuteTemplate(w, "base", paginaNoEncontrada{Detalle: detalle, Volver: "/"}); err != nil {
+		log.Printf("Error al ejecutar el template: %v", err)
+	}
+}
+
+// PaginaNoEncontrada responde 404 con la página de recurso no encontrado a las rutas que no existen.
+func PaginaNoEncontrada() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renderizarNoEncontrado(w, r, "la página "+r.URL.Path+" no existe")
+	}
+}
+
+// responderErrorWeb informa el error de una operación de la interfaz web con el estado de su clase:
+// la página de no encontrado (404) si el registro no existe, 409 si la operación choca con el estado de
+// los datos, 400 si los datos no son válidos y 500 para todo lo demás. Un error interno solo se registra
+// en el log. accion describe la operación, por ejemplo "eliminar el socio".
+func responderErrorWeb(w http.ResponseWriter, r *http.Request, err error, accion string) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		renderizarNoEncontrado(w, r, err.Error())
+	case errors.Is(err, models.ErrConflict):
+		http.Error(w, "No se pudo "+accion+": "+err.Error(), http.StatusConflict)
+	case errors.Is(err, models.ErrValidation):
+		http.Error(w, "No se pudo "+accion+": "+err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("Error al %s (%s %s): %v", accion, r.Method, r.URL.Path, err)
+		http.Error(w, "Error interno del servidor: no se pudo "+accion+".", http.StatusInternalServerError)
+	}
+}
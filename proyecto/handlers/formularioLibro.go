@@ -0,0 +1,121 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define el formulario web de un libro, usado tanto para mostrar como para leer las páginas de creación y edición.
+*/
+
+package handlers
+
+import (
+	"bytes"           // Paquete para preparar la página antes de enviar el código de estado.
+	"errors"          // Paquete para reconocer los errores de validación.
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"proyecto/models" // Importa el paquete models donde se definen el libro y sus reglas.
+	"strconv"         // Paquete para convertir el año de publicación.
+	"strings"         // Paquete para normalizar los valores del formulario.
+)
+
+// campoFormulario describe un control del formulario de libro. La plantilla camposLibro.html dibuja cada control
+// a partir de esta descripción, y leer usa el mismo Nombre para recuperar el valor enviado, de modo que el
+// formulario que se muestra y el que se lee no pueden diferir.
+type campoFormulario struct {
+	Nombre   string // Atributo name e id del control; también es la clave del campo en ErroresValidacion y en la API.
+	Etiqueta string // Texto de la etiqueta del control.
+	Tipo     string // Tipo de control: "text" o "number".
+	Valor    string // Valor que se muestra: el guardado o, si hubo errores, el que envió el usuario.
+	Error    string // Mensaje de error del campo, vacío si es válido.
+	Minimo   int    // Valor mínimo de un campo numérico.
+	Maximo   int    // Longitud máxima de un texto o valor máximo de un campo numérico.
+
+	Sugerencias bool // Sugiere mientras se escribe los valores del campo que ya tienen otros libros (ver sugerencias.js).
+}
+
+// formularioLibro contiene los datos que muestran las plantillas crearLibro.html y editarLibro.html.
+// Los valores se guardan como texto para volver a mostrar exactamente lo que escribió el usuario
+// cuando el formulario tiene errores, aunque no sean válidos (por ejemplo, un año con letras).
+type formularioLibro struct {
+	Id          int                      // ID del libro que se edita, 0 al crear.
+	Ejemplares  int                      // Cantidad de ejemplares del libro que se edita.
+	Disponibles int                      // Cantidad de ejemplares disponibles del libro que se edita.
+	Campos      []campoFormulario        // Controles del formulario, en el orden en que se muestran.
+	Errores     models.ErroresValidacion // Errores de todos los campos; vacío al mostrar el formulario por primera vez.
+}
+
+// nuevoFormularioLibro prepara el formulario con los datos guardados de un libro (o vacío, al crear).
+// El formulario no tiene el estado de préstamo: la disponibilidad depende de los ejemplares y la administran los préstamos.
+func nuevoFormularioLibro(libro models.Libro) formularioLibro {
+	anio := ""
+	if libro.AnioPublicacion != 0 {
+		anio = strconv.Itoa(libro.AnioPublicacion)
+	}
+	return formularioLibro{
+		Id:          libro.Id,
+		Ejemplares:  libro.Ejemplares,
+		Disponibles: libro.Disponibles,
+		Campos: []campoFormulario{
+			{Nombre: models.CampoTitulo, Etiqueta: "Título", Tipo: "text", Valor: libro.Titulo, Maximo: models.LongitudMaximaTitulo},
+			{Nombre: models.CampoAutor, Etiqueta: "Autor", Tipo: "text", Valor: libro.Autor, Maximo: models.LongitudMaximaAutor, Sugerencias: true},
+			{Nombre: models.CampoAnioPublicacion, Etiqueta: "Año de Publicación", Tipo: "number", Valor: anio,
+				Minimo: models.AnioPublicacionMinimo, Maximo: models.AnioPublicacionMaximo()},
+			{Nombre: models.CampoEditorial, Etiqueta: "Editorial", Tipo: "text", Valor: libro.Editorial, Maximo: models.LongitudMaximaEditorial, Sugerencias: true},
+		},
+	}
+}
+
+// leer recupera los valores enviados para cada campo del formulario y los valida con las mismas reglas que la API.
+// Los valores quedan en el formulario para volver a mostrarlos; si alguno es inválido, el error se guarda
+// junto a su campo y se devuelve models.ErroresValidacion.
+func (f *formularioLibro) leer(r *http.Request) (models.Libro, error) {
+	valores := make(map[string]string, len(f.Campos))
+	for i := range f.Campos {
+		f.Campos[i].Valor = r.FormValue(f.Campos[i].Nombre)
+		valores[f.Campos[i].Nombre] = f.Campos[i].Valor
+	}
+
+	errores := models.ErroresValidacion{}
+	libro := models.Libro{
+		Id:        f.Id,
+		Titulo:    valores[models.CampoTitulo],
+		Autor:     valores[models.CampoAutor],
+		Editorial: valores[models.CampoEditorial],
+	}.Normalizar()
+
+	// El año llega como texto; si no es un número, ese es el error del campo y no el rango.
+	if anio := strings.TrimSpace(valores[models.CampoAnioPublicacion]); anio == "" {
+		errores.Agregar(models.CampoAnioPublicacion, "Es obligatorio")
+	} else if valor, err := strconv.Atoi(anio); err != nil {
+		errores.Agregar(models.CampoAnioPublicacion, "Debe ser un número válido")
+	} else {
+		libro.AnioPublicacion = valor
+	}
+
+	err := validarLibro(libro, errores)
+	errors.As(err, &f.Errores)
+	for i := range f.Campos {
+		f.Campos[i].Error = f.Errores[f.Campos[i].Nombre]
+	}
+	return libro, err
+}
+
+// renderizar muestra la plantilla del formulario con el código de estado indicado.
+// Los controles se dibujan con la plantilla compartida camposLibro.html.
+func (f formularioLibro) renderizar(w http.ResponseWriter, r *http.Request, plantilla string, estado int) {
+	tmpl, err := cargarPlantilla(r, "templates/base.html", plantilla, "templates/camposLibro.html")
+	if err != nil {
+		// Si hay un error al cargar las plantillas, se registra el error y se envía una respuesta de error 500.
+		log.Printf("Error al cargar el template: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+
+	// Ejecuta la plantilla en memoria para poder responder 500 si falla, antes de escribir el código de estado.
+	var contenido bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&contenido, "base", f); err != nil {
+		log.Printf("Error al ejecutar el template: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(estado)
+	contenido.WriteTo(w)
+}
@@ -1,82 +1,64 @@
-/*
-@Author: kevin Perez
-@Descripcion: Este es el manejador de la pagina de inicio del proyecto, donde se carga la plantilla base y la plantilla home.
-*/
-
-package handlers
-
-import (
-	"database/sql"
-	"fmt" // ¡Importa fmt para usar Printf en la depuración!
-	"html/template"
-	"log"
-	"net/http"
-	// Asegúrate de que esta ruta sea correcta para tu paquete models
-)
-
-// Estructura para pasar datos al template del dashboard
-type DashboardData struct {
-	TotalBooks     int
-	AvailableBooks int
-	BorrowedBooks  int
-}
-
-// HomeHandler ahora recibe una conexión a la base de datos
-func HomeHandler(db *sql.DB) http.HandlerFunc {
-	tmpl := template.Must(template.ParseFiles(
-		"templates/base.html",
-		"templates/home.html",
-	))
-
-	return func(w http.ResponseWriter, r *http.Request) {
-		var totalBooks int
-		// Contar el total de libros
-		err := db.QueryRow("SELECT COUNT(*) FROM libros").Scan(&totalBooks)
-		if err != nil {
-			log.Printf("ERROR BD: Error al contar libros totales: %v", err) // Mensaje de error más claro
-			http.Error(w, "Error interno del servidor al obtener datos del dashboard", http.StatusInternalServerError)
-			return
-		}
-		// --- LÍNEA DE DEPURACIÓN CLAVE ---
-		fmt.Printf("DEBUG HOME: Total de Libros obtenidos de DB: %d\n", totalBooks)
-
-		var availableBooks int
-		// Contar libros no prestados (disponibles)
-		err = db.QueryRow("SELECT COUNT(*) FROM libros WHERE prestado = FALSE").Scan(&availableBooks)
-		if err != nil {
-			log.Printf("ERROR BD: Error al contar libros disponibles: %v", err) // Mensaje de error más claro
-			http.Error(w, "Error interno del servidor al obtener datos del dashboard", http.StatusInternalServerError)
-			return
-		}
-		// --- LÍNEA DE DEPURACIÓN CLAVE ---
-		fmt.Printf("DEBUG HOME: Libros Disponibles obtenidos de DB: %d\n", availableBooks)
-
-		var borrowedBooks int
-		// Contar libros prestados
-		err = db.QueryRow("SELECT COUNT(*) FROM libros WHERE prestado = TRUE").Scan(&borrowedBooks)
-		if err != nil {
-			log.Printf("ERROR BD: Error al contar libros prestados: %v", err) // Mensaje de error más claro
-			http.Error(w, "Error interno del servidor al obtener datos del dashboard", http.StatusInternalServerError)
-			return
-		}
-		// --- LÍNEA DE DEPURACIÓN CLAVE ---
-		fmt.Printf("DEBUG HOME: Libros Prestados obtenidos de DB: %d\n", borrowedBooks)
-
-		// Crear la estructura de datos para el template
-		data := DashboardData{
-			TotalBooks:     totalBooks,
-			AvailableBooks: availableBooks,
-			BorrowedBooks:  borrowedBooks,
-		}
-
-		// --- LÍNEA DE DEPURACIÓN CLAVE ---
-		fmt.Printf("DEBUG HOME: Datos enviados al template: %+v\n", data)
-
-		// Ejecutar el template con los datos obtenidos
-		err = tmpl.ExecuteTemplate(w, "base", data)
-		if err != nil {
-			log.Printf("ERROR TEMPLATE: Error al ejecutar el template home: %v", err) // Mensaje de error más claro
-			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
-		}
-	}
-}
+/*
+@Author: kevin Perez
+@Descripcion: Este es el manejador de la pagina de inicio del proyecto, donde se carga la plantilla base y la plantilla home.
+*/
+
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"proyecto/models" // Repositorio de libros del que se obtienen los contadores.
+)
+
+// Estructura para pasar datos al template del dashboard
+// Los contadores de disponibilidad se calculan por ejemplar (copia física), no por título.
+type DashboardData struct {
+	TotalTitles    int
+	TotalBooks     int
+	AvailableBooks int
+	BorrowedBooks  int
+	OverdueLoans   int // Préstamos sin devolver que la revisión de atrasos marcó como atrasados.
+}
+
+// HomeHandler recibe los repositorios de libros y de multas de los que se obtienen los contadores del dashboard
+func HomeHandler(repo models.LibroRepository, multas models.MultaRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/home.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+
+		// Contar los títulos y el total de ejemplares, los disponibles y los prestados
+		resumen, err := repo.ContarLibros()
+		if err != nil {
+			log.Printf("ERROR BD: Error al obtener los contadores del dashboard: %v", err) // Mensaje de error más claro
+			http.Error(w, "Error interno del servidor al obtener datos del dashboard", http.StatusInternalServerError)
+			return
+		}
+		atrasados, err := multas.ContarAtrasados()
+		if err != nil {
+			log.Printf("ERROR BD: Error al contar los préstamos atrasados: %v", err)
+			http.Error(w, "Error interno del servidor al obtener datos del dashboard", http.StatusInternalServerError)
+			return
+		}
+
+		// Crear la estructura de datos para el template
+		data := DashboardData{
+			TotalTitles:    resumen.Titulos,
+			TotalBooks:     resumen.Total,
+			AvailableBooks: resumen.Disponibles,
+			BorrowedBooks:  resumen.Prestados,
+			OverdueLoans:   atrasados,
+		}
+
+		// Ejecutar el template con los datos obtenidos
+		err = tmpl.ExecuteTemplate(w, "base", data)
+		if err != nil {
+			log.Printf("ERROR TEMPLATE: Error al ejecutar el template home: %v", err) // Mensaje de error más claro
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		}
+	}
+}
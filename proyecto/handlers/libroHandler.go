@@ -1,244 +1,365 @@
-/*
-@Autor: Kevin Pérez
-@Descipcion: Módulo que maneja las operaciones CRUD para la entidad Libro en la interfaz web.
-*/
-
-package handlers
-
-import (
-	"fmt"             // Paquete para formatear cadenas.
-	"html/template"   // Paquete para trabajar con plantillas HTML.
-	"log"             // Paquete para logging.
-	"net/http"        // Paquete para manejar solicitudes HTTP.
-	"proyecto/models" // Importa el paquete models para interactuar con los datos de libros.
-	"strconv"         // Paquete para conversión de tipos.
-	"time"            // Paquete para obtener la fecha y hora actual.
-
-	"github.com/gorilla/mux" // Router HTTP para manejar rutas.
-)
-
-// RecuperarLibros maneja la solicitud para listar todos los libros en la interfaz web.
-func RecuperarLibros(w http.ResponseWriter, r *http.Request) {
-	// Obtiene todos los libros de la base de datos.
-	libros, err := models.GetAllLibros()
-	if err != nil {
-		// Si hay un error, se envía una respuesta de error 500.
-		http.Error(w, "Error al recuperar los libros: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Parsea los archivos de plantilla base.html y libros.html.
-	tmpl, err := template.ParseFiles("templates/base.html", "templates/libros.html")
-	if err != nil {
-		// Si hay un error al cargar las plantillas, se registra el error y se envía una respuesta de error 500.
-		log.Printf("Error al cargar el template: %v", err)
-		http.Error(w, "Error al cargar el template", http.StatusInternalServerError)
-		return
-	}
-
-	// Imprime los libros en la consola del servidor (útil para depuración).
-	fmt.Println(libros)
-
-	// Ejecuta la plantilla "base" pasando los libros como datos.
-	err = tmpl.ExecuteTemplate(w, "base", libros)
-	if err != nil {
-		// Si hay un error al ejecutar la plantilla, se envía una respuesta de error 500.
-		http.Error(w, "Error al ejecutar el template: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-}
-
-// CreateLibroGetHandler muestra el formulario HTML para crear un nuevo libro.
-func CreateLibroGetHandler(w http.ResponseWriter, r *http.Request) {
-	// Parsea los archivos de plantilla base.html y crearLibro.html.
-	tmpl, err := template.ParseFiles("templates/base.html", "templates/crearLibro.html")
-	if err != nil {
-		// Si hay un error al cargar las plantillas, se registra el error y se envía una respuesta de error 500.
-		log.Printf("Error al cargar el template: %v", err)
-		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
-		return
-	}
-
-	// Pasa el año actual a la plantilla para el valor máximo del campo AnioPublicacion.
-	data := struct {
-		CurrentYear int
-	}{
-		CurrentYear: time.Now().Year(),
-	}
-
-	// Ejecuta la plantilla "base" sin pasar datos inicialmente.
-	err = tmpl.ExecuteTemplate(w, "base", data)
-	if err != nil {
-		// Si hay un error al ejecutar la plantilla, se registra el error y se envía una respuesta de error 500.
-		log.Printf("Error al ejecutar el template: %v", err)
-		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
-		return
-	}
-}
-
-// CreateLibroPostHandler procesa los datos del formulario para crear un nuevo libro.
-func CreateLibroPostHandler(w http.ResponseWriter, r *http.Request) {
-	// Verifica que la solicitud sea de tipo POST.
-	if r.Method != http.MethodPost {
-		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Parsea el formulario para acceder a los valores enviados.
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	// Recupera los valores de los campos del formulario.
-	Autor := r.FormValue("Autor")
-	Titulo := r.FormValue("Titulo")
-	AnioPublicacionStr := r.FormValue("AnioPublicacion")
-	Editorial := r.FormValue("Editorial")
-	Prestado := r.FormValue("Prestado")
-
-	// Validaciones básicas de los campos del formulario.
-	if Titulo == "" || Autor == "" || AnioPublicacionStr == "" || Editorial == "" || Prestado == "" {
-		http.Error(w, "Todos los campos son obligatorios", http.StatusBadRequest)
-		return
-	}
-
-	// Convierte el año de publicación de string a int.
-	AnioPublicacion, err := strconv.Atoi(AnioPublicacionStr)
-	if err != nil {
-		http.Error(w, "El año de publicación debe ser un número válido", http.StatusBadRequest)
-		return
-	}
-
-	// Llama a la función CreateLibro del modelo para guardar el libro en la base de datos.
-	err = models.CreateLibro(Autor, Titulo, AnioPublicacion, Editorial, Prestado)
-	if err != nil {
-		http.Error(w, "Error al crear el libro: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Redirige al usuario a la lista de libros después de una creación exitosa.
-	http.Redirect(w, r, "/libros", http.StatusSeeOther)
-
-}
-
-// UpdateLibroGetHandler muestra el formulario para editar un libro existente.
-func UpdateLibroGetHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["Id"])
-	if err != nil {
-		http.Error(w, "ID de libro inválido", http.StatusBadRequest)
-		return
-	}
-
-	libro, err := models.GetLibroByID(id)
-
-	if err != nil {
-		http.Error(w, "Libro no encontrado: "+err.Error(), http.StatusNotFound)
-		return
-	}
-
-	tmpl, err := template.ParseFiles("templates/base.html", "templates/editarLibro.html")
-
-	if err != nil {
-		log.Printf("Error al cargar el template: %v", err)
-		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
-		return
-	}
-
-	// Estructura para pasar el libro y el año actual a la plantilla.
-
-	data := struct {
-		models.Libro
-		CurrentYear int
-	}{
-		Libro:       libro,
-		CurrentYear: time.Now().Year(),
-	}
-
-	err = tmpl.ExecuteTemplate(w, "base", data)
-
-	if err != nil {
-		log.Printf("Error al ejecutar el template: %v", err)
-		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
-		return
-	}
-
-}
-
-// UpdateLibroPostHandler procesa los datos del formulario para actualizar un libro.
-
-func UpdateLibroPostHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
-		return
-	}
-
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["Id"])
-	if err != nil {
-		http.Error(w, "ID de libro inválido", http.StatusBadRequest)
-		return
-	}
-
-	Autor := r.FormValue("Autor")
-	Titulo := r.FormValue("Titulo")
-	AnioPublicacionStr := r.FormValue("AnioPublicacion")
-	Editorial := r.FormValue("Editorial")
-	Prestado := r.FormValue("Prestado")
-
-	if Titulo == "" || Autor == "" || AnioPublicacionStr == "" || Editorial == "" || Prestado == "" {
-		http.Error(w, "Todos los campos son obligatorios", http.StatusBadRequest)
-		return
-	}
-
-	AnioPublicacion, err := strconv.Atoi(AnioPublicacionStr)
-
-	if err != nil {
-		http.Error(w, "El año de publicación debe ser un número válido", http.StatusBadRequest)
-		return
-	}
-
-	// Crea una instancia de Libro con los datos actualizados.
-
-	libro := models.Libro{
-		Id:              id,
-		Autor:           Autor,
-		Titulo:          Titulo,
-		AnioPublicacion: AnioPublicacion,
-		Editorial:       Editorial,
-		Prestado:        Prestado,
-	}
-
-	err = models.UpdateLibro(libro)
-
-	if err != nil {
-		http.Error(w, "Error al actualizar el libro: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	http.Redirect(w, r, "/libros", http.StatusSeeOther)
-}
-
-// DeleteLibroHandler maneja la solicitud para eliminar un libro.
-func DeleteLibroHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["Id"])
-	if err != nil {
-		http.Error(w, "ID de libro inválido", http.StatusBadRequest)
-		return
-	}
-
-	err = models.DeleteLibro(id)
-
-	if err != nil {
-		http.Error(w, "Error al eliminar el libro: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	http.Redirect(w, r, "/libros", http.StatusSeeOther)
-}
+/*
+@Autor: Kevin Pérez
+@Descipcion: Módulo que maneja las operaciones CRUD para la entidad Libro en la interfaz web.
+*/
+
+package handlers
+
+import (
+	"errors"          // Paquete para reconocer los errores de los parámetros de la lista.
+	"fmt"             // Paquete para formatear cadenas.
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"net/url"         // Paquete para conservar los filtros de la lista en los enlaces.
+	"proyecto/models" // Importa el paquete models para interactuar con los datos de libros.
+	"strconv"         // Paquete para conversión de tipos.
+	"strings"         // Paquete para detectar los filtros vacíos.
+
+	"github.com/gorilla/mux" // Router HTTP para manejar rutas.
+)
+
+// TamanosPaginaLibros son las opciones de libros por página de la lista web.
+var TamanosPaginaLibros = []int{10, 25, models.LimiteLibrosPorDefecto, 100}
+
+// listaLibros contiene los datos de la plantilla libros.html: una página de libros junto con el estado
+// de los filtros, el orden y la paginación, que se guarda en los parámetros de la URL (ver consultaLibrosDeURL)
+// para que la página se pueda recargar, compartir o volver atrás sin perderlo.
+type listaLibros struct {
+	Libros       []models.Libro           // Libros de la página.
+	Total        int                      // Cantidad de libros que cumplen los filtros.
+	Filtros      url.Values               // Parámetros de la solicitud, para volver a mostrarlos en la barra de filtros.
+	Filtrada     bool                     // Indica si se aplicó algún filtro, para el mensaje de la lista vacía.
+	Errores      models.ErroresValidacion // Mensaje de error de cada parámetro inválido.
+	Columnas     []columnaLibros          // Encabezados de la tabla con su enlace para ordenar.
+	Limite       int                      // Libros por página.
+	Tamanos      []int                    // Opciones de libros por página.
+	PaginaActual int                      // Número de la página, desde 1.
+	Paginas      int                      // Cantidad de páginas.
+	Anterior     string                   // Enlace a la página anterior; vacío en la primera.
+	Siguiente    string                   // Enlace a la página siguiente; vacío en la última.
+}
+
+// columnaLibros es un encabezado de la tabla de libros. Al hacer clic se ordena por su campo, o se
+// invierte la dirección si la lista ya está ordenada por él.
+type columnaLibros struct {
+	Titulo    string // Texto del encabezado.
+	Enlace    string // URL de la lista ordenada por la columna; vacía si no se puede ordenar.
+	Indicador string // ▲ o ▼ si la lista está ordenada por la columna.
+}
+
+// columnasLibros son los encabezados de la tabla de libros y el campo por el que ordena cada uno.
+var columnasLibros = []struct{ titulo, campo string }{
+	{"ID", "Id"},
+	{"Título", models.CampoTitulo},
+	{"Autor", models.CampoAutor},
+	{"Año Publicación", models.CampoAnioPublicacion},
+	{"Editorial", models.CampoEditorial},
+	{"Ejemplares", "Disponibles"},
+	{"Acciones", ""},
+}
+
+// enlaceLibros devuelve la URL de la lista de libros con los parámetros recibidos, reemplazando los
+// pares parámetro-valor indicados. Un valor vacío quita el parámetro.
+func enlaceLibros(valores url.Values, pares ...string) string {
+	copia := url.Values{}
+	for parametro, lista := range valores {
+		copia[parametro] = lista
+	}
+	for i := 0; i+1 < len(pares); i += 2 {
+		if pares[i+1] == "" {
+			copia.Del(pares[i])
+		} else {
+			copia.Set(pares[i], pares[i+1])
+		}
+	}
+	if len(copia) == 0 {
+		return "/libros"
+	}
+	return "/libros?" + copia.Encode()
+}
+
+// RecuperarLibros maneja la solicitud para listar los libros en la interfaz web, una página a la vez.
+// Usa los mismos parámetros y la misma consulta que GET /api/libros; la paginación es por desplazamiento
+// para poder mostrar el número de página. Si algún parámetro no es válido responde 400 con la lista vacía
+// y el mensaje junto al filtro correspondiente.
+func RecuperarLibros(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		valores := r.URL.Query()
+		valores.Del(models.ParametroCursor)
+		lista := listaLibros{Filtros: valores, Tamanos: TamanosPaginaLibros, PaginaActual: 1, Paginas: 1}
+		for _, parametro := range []string{models.ParametroAutor, models.ParametroEditorial, models.ParametroAnioDesde, models.ParametroAnioHasta, models.ParametroPrestado} {
+			lista.Filtrada = lista.Filtrada || strings.TrimSpace(valores.Get(parametro)) != ""
+		}
+
+		estado := http.StatusOK
+		consulta, err := consultaLibrosDeURL(valores)
+		if consulta.Limite == 0 {
+			consulta.Limite = models.LimiteLibrosPorDefecto
+		}
+		lista.Limite = consulta.Limite
+		if errors.As(err, &lista.Errores) {
+			estado = http.StatusBadRequest
+		} else {
+			// Obtiene la página de libros de la base de datos.
+			pagina, err := repo.ListarLibros(consulta)
+			if err != nil {
+				// Si hay un error, se envía una respuesta de error 500.
+				responderErrorWeb(w, r, err, "recuperar los libros")
+				return
+			}
+			lista.Libros, lista.Total = pagina.Libros, pagina.Total
+			lista.PaginaActual = consulta.Desplazamiento/consulta.Limite + 1
+			lista.Paginas = max((pagina.Total+consulta.Limite-1)/consulta.Limite, 1)
+			if consulta.Desplazamiento > 0 {
+				lista.Anterior = enlaceLibros(valores, models.ParametroDesplazamiento, strconv.Itoa(max(consulta.Desplazamiento-consulta.Limite, 0)))
+			}
+			if consulta.Desplazamiento+consulta.Limite < pagina.Total {
+				lista.Siguiente = enlaceLibros(valores, models.ParametroDesplazamiento, strconv.Itoa(consulta.Desplazamiento+consulta.Limite))
+			}
+		}
+
+		// Cada encabezado ordena por su campo desde la primera página; si ya es el orden actual, lo invierte.
+		orden := consulta.Orden
+		if orden == "" {
+			orden = models.CamposOrdenLibros[0]
+		}
+		for _, columna := range columnasLibros {
+			encabezado := columnaLibros{Titulo: columna.titulo}
+			if columna.campo != "" {
+				direccion := ""
+				if columna.campo == orden {
+					encabezado.Indicador = "▲"
+					direccion = "desc"
+					if consulta.Descendente {
+						encabezado.Indicador, direccion = "▼", ""
+					}
+				}
+				encabezado.Enlace = enlaceLibros(valores, models.ParametroOrden, columna.campo, models.ParametroDireccion, direccion, models.ParametroDesplazamiento, "")
+			}
+			lista.Columnas = append(lista.Columnas, encabezado)
+		}
+
+		// Parsea los archivos de plantilla base.html y libros.html.
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/libros.html")
+		if err != nil {
+			// Si hay un error al cargar las plantillas, se registra el error y se envía una respuesta de error 500.
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error al cargar el template", http.StatusInternalServerError)
+			return
+		}
+
+		// Ejecuta la plantilla "base" pasando la página de libros como datos.
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(estado)
+		err = tmpl.ExecuteTemplate(w, "base", lista)
+		if err != nil {
+			// Si la plantilla falla a mitad de la respuesta ya no se puede cambiar el estado; solo se registra.
+			log.Printf("Error al ejecutar el template: %v", err)
+		}
+	}
+}
+
+// resultadosBusqueda contiene los datos de la plantilla busqueda.html.
+type resultadosBusqueda struct {
+	Texto      string                     // Texto buscado, tal como se escribió.
+	Resultados []models.ResultadoBusqueda // Libros encontrados, de mayor a menor relevancia.
+	Errores    models.ErroresValidacion   // Mensaje de error de cada parámetro inválido.
+}
+
+// BuscarLibrosHandler muestra los libros que coinciden con el texto del cuadro de búsqueda del encabezado
+// (parámetro q), de mayor a menor relevancia. Sin texto muestra solo el formulario de búsqueda.
+func BuscarLibrosHandler(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		estado := http.StatusOK
+		datos := resultadosBusqueda{Texto: strings.TrimSpace(r.URL.Query().Get(models.ParametroBusqueda))}
+		if datos.Texto != "" {
+			texto, limite, err := busquedaDeURL(r.URL.Query(), models.LimiteBusquedaMaximo)
+			if errors.As(err, &datos.Errores) {
+				estado = http.StatusBadRequest
+			} else if datos.Resultados, err = repo.BuscarLibros(texto, limite); err != nil {
+				responderErrorWeb(w, r, err, "buscar los libros")
+				return
+			}
+		}
+
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/busqueda.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error al cargar el template", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(estado)
+		if err := tmpl.ExecuteTemplate(w, "base", datos); err != nil {
+			// Si la plantilla falla a mitad de la respuesta ya no se puede cambiar el estado; solo se registra.
+			log.Printf("Error al ejecutar el template: %v", err)
+		}
+	}
+}
+
+// CreateLibroGetHandler muestra el formulario HTML para crear un nuevo libro.
+func CreateLibroGetHandler(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Muestra el formulario vacío; el primer ejemplar se registra disponible.
+		nuevoFormularioLibro(models.Libro{}).renderizar(w, r, "templates/crearLibro.html", http.StatusOK)
+	}
+}
+
+// CreateLibroPostHandler procesa los datos del formulario para crear un nuevo libro.
+func CreateLibroPostHandler(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Verifica que la solicitud sea de tipo POST.
+		if r.Method != http.MethodPost {
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Parsea el formulario para acceder a los valores enviados.
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Recupera y valida los campos del formulario con las mismas reglas que la API.
+		formulario := nuevoFormularioLibro(models.Libro{})
+		libro, err := formulario.leer(r)
+		if err != nil {
+			// Vuelve a mostrar el formulario con lo que escribió el usuario y el error de cada campo.
+			formulario.renderizar(w, r, "templates/crearLibro.html", http.StatusUnprocessableEntity)
+			return
+		}
+
+		// Llama a la función CreateLibro del modelo para guardar el libro en la base de datos.
+		_, err = repo.CreateLibro(libro.Autor, libro.Titulo, libro.AnioPublicacion, libro.Editorial)
+		if err != nil {
+			responderErrorWeb(w, r, err, "crear el libro")
+			return
+		}
+
+		// Redirige al usuario a la lista de libros después de una creación exitosa.
+		http.Redirect(w, r, "/libros", http.StatusSeeOther)
+
+	}
+}
+
+// UpdateLibroGetHandler muestra el formulario para editar un libro existente.
+func UpdateLibroGetHandler(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de libro inválido", http.StatusBadRequest)
+			return
+		}
+
+		libro, err := repo.GetLibroByID(id)
+
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el libro")
+			return
+		}
+
+		nuevoFormularioLibro(libro).renderizar(w, r, "templates/editarLibro.html", http.StatusOK)
+	}
+}
+
+// UpdateLibroPostHandler procesa los datos del formulario para actualizar un libro.
+
+func UpdateLibroPostHandler(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de libro inválido", http.StatusBadRequest)
+			return
+		}
+
+		actual, err := repo.GetLibroByID(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el libro")
+			return
+		}
+
+		// El estado de préstamo no se edita en este formulario: depende de los ejemplares y lo administran los préstamos.
+		formulario := nuevoFormularioLibro(actual)
+		libro, err := formulario.leer(r)
+		if err != nil {
+			// Vuelve a mostrar el formulario con lo que escribió el usuario; los contadores de ejemplares son los guardados.
+			formulario.renderizar(w, r, "templates/editarLibro.html", http.StatusUnprocessableEntity)
+			return
+		}
+
+		err = repo.UpdateLibro(libro)
+
+		if err != nil {
+			responderErrorWeb(w, r, err, "actualizar el libro")
+			return
+		}
+
+		http.Redirect(w, r, "/libros", http.StatusSeeOther)
+	}
+}
+
+// ConfirmarEliminarLibroHandler muestra los datos del libro y pide confirmación antes de eliminarlo.
+func ConfirmarEliminarLibroHandler(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de libro inválido", http.StatusBadRequest)
+			return
+		}
+
+		libro, err := repo.GetLibroByID(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el libro")
+			return
+		}
+
+		renderizarConfirmacion(w, r, confirmacionEliminacion{
+			Titulo: "Eliminar Libro",
+			Nombre: libro.Titulo,
+			Detalles: []detalleConfirmacion{
+				{Etiqueta: "Autor", Valor: libro.Autor},
+				{Etiqueta: "Año de Publicación", Valor: strconv.Itoa(libro.AnioPublicacion)},
+				{Etiqueta: "Editorial", Valor: libro.Editorial},
+				{Etiqueta: "Ejemplares", Valor: fmt.Sprintf("%d de %d disponibles", libro.Disponibles, libro.Ejemplares)},
+			},
+			// Los ejemplares, préstamos, multas y reservas del libro se eliminan en cascada.
+			Advertencia: fmt.Sprintf("También se eliminarán sus %d ejemplares con su historial de préstamos y sus reservas.", libro.Ejemplares),
+			Accion:      fmt.Sprintf("/libros/eliminar/%d", libro.Id),
+			Volver:      "/libros",
+		})
+	}
+}
+
+// DeleteLibroHandler maneja la solicitud para eliminar un libro.
+// Solo responde a POST y DELETE (los formularios envían _method=DELETE); un GET recibe 405.
+func DeleteLibroHandler(repo models.LibroRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de libro inválido", http.StatusBadRequest)
+			return
+		}
+
+		err = repo.DeleteLibro(id)
+
+		if err != nil {
+			responderErrorWeb(w, r, err, "eliminar el libro")
+			return
+		}
+		http.Redirect(w, r, "/libros", http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,32 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo con los middlewares HTTP que se aplican a todas las rutas de la aplicación.
+*/
+
+package handlers
+
+import (
+	"net/http" // Paquete para manejar solicitudes HTTP.
+	"strings"  // Paquete para normalizar el método solicitado.
+)
+
+// CampoMetodo es el campo oculto con el que un formulario HTML indica el método que quiere usar.
+const CampoMetodo = "_method"
+
+// SobrescribirMetodo permite que los formularios HTML, que solo pueden enviar GET y POST, usen DELETE o PUT:
+// un POST con el campo oculto _method=DELETE se atiende como un DELETE.
+// Debe envolver al enrutador, porque Gorilla Mux elige la ruta según el método antes de ejecutar sus middlewares.
+// No se aplica a la API: sus clientes envían el método real, y un formulario de otro sitio no debe poder
+// convertirse en un DELETE de la API, que solo acepta el token CSRF en la cabecera X-CSRF-Token.
+func SobrescribirMetodo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// PostFormValue solo lee el cuerpo de los formularios; un POST con JSON no se ve afectado.
+		if r.Method == http.MethodPost && !strings.HasPrefix(r.URL.Path, prefijoAPI) {
+			switch metodo := strings.ToUpper(r.PostFormValue(CampoMetodo)); metodo {
+			case http.MethodDelete, http.MethodPut:
+				r.Method = metodo
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
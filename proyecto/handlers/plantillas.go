@@ -0,0 +1,39 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que carga las plantillas HTML con las funciones auxiliares comunes a todas las páginas.
+*/
+
+package handlers
+
+import (
+	"html/template"   // Paquete para trabajar con plantillas HTML.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"path/filepath"   // Paquete para obtener el nombre de la plantilla principal.
+	"proyecto/models" // Importa el paquete models para el usuario de la sesión.
+)
+
+// cargarPlantilla parsea los archivos de plantilla indicados con las funciones auxiliares de la solicitud:
+//
+//	{{ campoCSRF }} escribe el campo oculto con el token CSRF; todo formulario POST debe incluirlo.
+//	{{ usuarioActual }} devuelve el usuario de la sesión, o nil en la página de inicio de sesión.
+//	{{ if puede "editar" }} indica si el rol del usuario tiene el permiso, para mostrar solo las opciones permitidas.
+//
+// Las funciones dependen de la solicitud, por eso las plantillas se cargan en cada una.
+func cargarPlantilla(r *http.Request, archivos ...string) (*template.Template, error) {
+	funciones := template.FuncMap{
+		"campoCSRF": func() template.HTML {
+			return template.HTML(`<input type="hidden" name="` + CampoCSRF + `" value="` + template.HTMLEscapeString(TokenCSRF(r)) + `">`)
+		},
+		"usuarioActual": func() *models.Usuario {
+			if usuario, ok := UsuarioActual(r); ok {
+				return &usuario
+			}
+			return nil
+		},
+		"puede": func(permiso string) bool {
+			usuario, ok := UsuarioActual(r)
+			return ok && usuario.Puede(models.Permiso(permiso))
+		},
+	}
+	return template.New(filepath.Base(archivos[0])).Funcs(funciones).ParseFiles(archivos...)
+}
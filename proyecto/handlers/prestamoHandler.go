@@ -0,0 +1,172 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que maneja el préstamo y la devolución de libros en la interfaz web.
+*/
+
+package handlers
+
+import (
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"proyecto/models" // Importa el paquete models para interactuar con libros y préstamos.
+	"strconv"         // Paquete para conversión de tipos.
+	"time"            // Paquete para calcular la fecha de vencimiento sugerida.
+
+	"github.com/gorilla/mux" // Router HTTP para manejar rutas.
+)
+
+// RecuperarPrestamos maneja la solicitud para listar todos los préstamos en la interfaz web.
+func RecuperarPrestamos(prestamos models.PrestamoRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtiene todos los préstamos de la base de datos.
+		lista, err := prestamos.GetAllPrestamos()
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar los préstamos")
+			return
+		}
+
+		// Parsea los archivos de plantilla base.html y prestamos.html.
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/prestamos.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error al cargar el template", http.StatusInternalServerError)
+			return
+		}
+
+		// Ejecuta la plantilla "base" pasando los préstamos como datos.
+		err = tmpl.ExecuteTemplate(w, "base", lista)
+		if err != nil {
+			http.Error(w, "Error al ejecutar el template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// sociosActivos devuelve los socios con la membresía activa, los únicos que pueden pedir o reservar libros.
+func sociosActivos(socios models.SocioRepository) ([]models.Socio, error) {
+	inscritos, err := socios.GetAllSocios()
+	if err != nil {
+		return nil, err
+	}
+	var activos []models.Socio
+	for _, socio := range inscritos {
+		if socio.Estado == models.EstadoSocioActivo {
+			activos = append(activos, socio)
+		}
+	}
+	return activos, nil
+}
+
+// CreatePrestamoGetHandler muestra el formulario HTML para prestar un libro disponible a un socio activo.
+// Si la URL incluye ?LibroId= o ?SocioId=, ese libro o socio aparece seleccionado.
+func CreatePrestamoGetHandler(libros models.LibroRepository, socios models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		todos, err := libros.GetAllLibros()
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar los libros")
+			return
+		}
+
+		// Solo se pueden prestar los libros que tienen alguna copia libre.
+		var disponibles []models.Libro
+		for _, libro := range todos {
+			if libro.Disponibles > 0 {
+				disponibles = append(disponibles, libro)
+			}
+		}
+
+		// Solo los socios con la membresía activa pueden llevarse libros.
+		activos, err := sociosActivos(socios)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar los socios")
+			return
+		}
+
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/crearPrestamo.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+
+		// Pasa los libros disponibles, los socios activos, las opciones preseleccionadas y la fecha de vencimiento sugerida.
+		libroSeleccionado, _ := strconv.Atoi(r.URL.Query().Get("LibroId"))
+		socioSeleccionado, _ := strconv.Atoi(r.URL.Query().Get("SocioId"))
+		data := struct {
+			Libros           []models.Libro
+			Socios           []models.Socio
+			LibroId          int
+			SocioId          int
+			FechaMinima      string
+			FechaVencimiento string
+		}{
+			Libros:           disponibles,
+			Socios:           activos,
+			LibroId:          libroSeleccionado,
+			SocioId:          socioSeleccionado,
+			FechaMinima:      time.Now().Format("2006-01-02"),
+			FechaVencimiento: time.Now().AddDate(0, 0, models.DiasPrestamoPorDefecto).Format("2006-01-02"),
+		}
+
+		err = tmpl.ExecuteTemplate(w, "base", data)
+		if err != nil {
+			log.Printf("Error al ejecutar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// CreatePrestamoPostHandler procesa el formulario de préstamo de un libro.
+func CreatePrestamoPostHandler(prestamos models.PrestamoRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		LibroId, err := strconv.Atoi(r.FormValue("LibroId"))
+		if err != nil {
+			http.Error(w, "Debe seleccionar un libro", http.StatusBadRequest)
+			return
+		}
+		SocioId, err := strconv.Atoi(r.FormValue("SocioId"))
+		if err != nil {
+			http.Error(w, "Debe seleccionar un socio", http.StatusBadRequest)
+			return
+		}
+		FechaVencimiento, err := models.ParseFechaVencimiento(r.FormValue("FechaVencimiento"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Registra el préstamo; el libro queda marcado como prestado en la misma operación.
+		_, err = prestamos.PrestarLibro(LibroId, SocioId, FechaVencimiento)
+		if err != nil {
+			responderErrorWeb(w, r, err, "prestar el libro")
+			return
+		}
+
+		http.Redirect(w, r, "/prestamos", http.StatusSeeOther)
+	}
+}
+
+// DevolverPrestamoHandler registra la devolución de un préstamo y vuelve a la lista de préstamos.
+func DevolverPrestamoHandler(prestamos models.PrestamoRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de préstamo inválido", http.StatusBadRequest)
+			return
+		}
+
+		_, err = prestamos.DevolverLibro(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "registrar la devolución")
+			return
+		}
+		http.Redirect(w, r, "/prestamos", http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que informa los errores de la API como documentos application/problem+json (RFC 7807).
+*/
+
+package handlers
+
+import (
+	"errors"          // Paquete para reconocer los errores del modelo.
+	"fmt"             // Paquete para formatear el detalle de los problemas.
+	"log"             // Paquete para registrar los errores internos que no se muestran al cliente.
+	"net/http"        // Paquete para manejar solicitudes y respuestas HTTP.
+	"proyecto/models" // Importa el paquete models donde se definen los errores del dominio.
+
+	"github.com/goccy/go-json" // Paquete para codificar JSON de forma eficiente.
+)
+
+// TipoProblemaJSON es el Content-Type de las respuestas de error de la API.
+const TipoProblemaJSON = "application/problem+json"
+
+// Problema es el cuerpo de una respuesta de error de la API, con los campos de RFC 7807.
+type Problema struct {
+	Type     string                   `json:"type"`               // URI que identifica la clase de problema.
+	Title    string                   `json:"title"`              // Resumen de la clase de problema; no cambia de una respuesta a otra.
+	Status   int                      `json:"status"`             // Código de estado HTTP de la respuesta.
+	Detail   string                   `json:"detail,omitempty"`   // Explicación de este caso en particular.
+	Instance string                   `json:"instance,omitempty"` // Ruta de la solicitud que falló.
+	Errores  models.ErroresValidacion `json:"errores,omitempty"`  // Mensaje de error de cada campo inválido; se omite si no hay campos que corregir.
+}
+
+// claseProblema es el tipo y el título con que se informa un código de estado.
+type claseProblema struct {
+	tipo   string
+	titulo string
+}
+
+// clasesProblema asocia cada código de estado que usa la API con su clase de problema.
+// Los demás códigos se informan con el tipo "about:blank" y el texto estándar del estado.
+var clasesProblema = map[int]claseProblema{
+	http.StatusBadRequest:          {"/problemas/solicitud-invalida", "La solicitud no es válida"},
+	http.StatusUnauthorized:        {"/problemas/no-autenticado", "Falta autenticarse"},
+	http.StatusForbidden:           {"/problemas/sin-permiso", "No tiene permiso"},
+	http.StatusNotFound:            {"/problemas/no-encontrado", "El recurso no existe"},
+	http.StatusConflict:            {"/problemas/conflicto", "La operación no es posible en el estado actual"},
+	http.StatusUnprocessableEntity: {"/problemas/datos-invalidos", "Los datos no son válidos"},
+	http.StatusInternalServerError: {"/problemas/error-interno", "Error interno del servidor"},
+}
+
+// nuevoProblema crea un problema del estado indicado, con el tipo y el título de su clase.
+func nuevoProblema(estado int, detalle string) Problema {
+	clase, ok := clasesProblema[estado]
+	if !ok {
+		clase = claseProblema{"about:blank", http.StatusText(estado)}
+	}
+	return Problema{Type: clase.tipo, Title: clase.titulo, Status: estado, Detail: detalle}
+}
+
+// responderProblema escribe el problema como application/problem+json, con la ruta de la solicitud en Instance.
+func responderProblema(w http.ResponseWriter, r *http.Request, problema Problema) {
+	if problema.Instance == "" {
+		problema.Instance = r.URL.Path
+	}
+	w.Header().Set("Content-Type", TipoProblemaJSON)
+	w.WriteHeader(problema.Status)
+	if err := json.NewEncoder(w).Encode(problema); err != nil {
+		log.Printf("Error al codificar el problema JSON: %v", err)
+	}
+}
+
+// responderError informa el error de una operación con el estado de su clase (ver models.ErrNotFound):
+// 404 si el registro no existe, 409 si la operación choca con el estado de los datos, 422 si los datos
+// no son válidos (con los errores de cada campo, si los hay) y 500 para todo lo demás.
+// Un error interno solo se registra en el log; el cliente recibe un detalle genérico, sin el mensaje
+// del controlador de la base de datos. accion describe la operación, por ejemplo "prestar el libro".
+func responderError(w http.ResponseWriter, r *http.Request, err error, accion string) {
+	var errores models.ErroresValidacion
+	switch {
+	case errors.As(err, &errores):
+		problema := nuevoProblema(http.StatusUnprocessableEntity, "Revise los campos indicados en errores")
+		problema.Errores = errores
+		responderProblema(w, r, problema)
+		return
+	case errors.Is(err, models.ErrNotFound):
+		responderProblema(w, r, nuevoProblema(http.StatusNotFound, fmt.Sprintf("No se pudo %s: %v", accion, err)))
+		return
+	case errors.Is(err, models.ErrConflict):
+		responderProblema(w, r, nuevoProblema(http.StatusConflict, fmt.Sprintf("No se pudo %s: %v", accion, err)))
+		return
+	case errors.Is(err, models.ErrValidation):
+		responderProblema(w, r, nuevoProblema(http.StatusUnprocessableEntity, fmt.Sprintf("No se pudo %s: %v", accion, err)))
+		return
+	}
+	log.Printf("Error al %s (%s %s): %v", accion, r.Method, r.URL.Path, err)
+	responderProblema(w, r, nuevoProblema(http.StatusInternalServerError, "No se pudo "+accion))
+}
+
+// responderDatosInvalidos informa los datos rechazados de una solicitud: 422 con los errores de cada campo
+// si el error es un models.ErroresValidacion y 400 con el mensaje del error en cualquier otro caso.
+func responderDatosInvalidos(w http.ResponseWriter, r *http.Request, err error) {
+	var errores models.ErroresValidacion
+	if errors.As(err, &errores) {
+		responderError(w, r, err, "validar los datos")
+		return
+	}
+	responderProblema(w, r, nuevoProblema(http.StatusBadRequest, err.Error()))
+}
+
+// responderParametrosInvalidos responde 400 Bad Request a una solicitud con parámetros de URL inválidos,
+// con el mensaje de cada parámetro en errores si el error es un models.ErroresValidacion.
+func responderParametrosInvalidos(w http.ResponseWriter, r *http.Request, err error) {
+	problema := nuevoProblema(http.StatusBadRequest, err.Error())
+	if errors.As(err, &problema.Errores) {
+		problema.Detail = "Revise los parámetros indicados en errores"
+	}
+	responderProblema(w, r, problema)
+}
+
+// responderIDInvalido responde 400 Bad Request a una ruta cuyo ID no es un número.
+func responderIDInvalido(w http.ResponseWriter, r *http.Request) {
+	responderProblema(w, r, nuevoProblema(http.StatusBadRequest, "El ID de la ruta debe ser un número entero"))
+}
+
+// responderJSONInvalido responde 400 Bad Request a un cuerpo que no se pudo decodificar.
+func responderJSONInvalido(w http.ResponseWriter, r *http.Request, recurso string, err error) {
+	responderProblema(w, r, nuevoProblema(http.StatusBadRequest, fmt.Sprintf("El cuerpo no es un JSON válido para %s: %v", recurso, err)))
+}
+
+// escribirJSON responde el estado indicado con el valor codificado en JSON. Si la codificación falla
+// a mitad de la respuesta ya no se puede cambiar el estado, así que el error solo se registra.
+func escribirJSON(w http.ResponseWriter, estado int, valor any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(estado)
+	if err := json.NewEncoder(w).Encode(valor); err != nil {
+		log.Printf("Error al codificar la respuesta JSON: %v", err)
+	}
+}
+
+// escribirCreado responde 201 Created con el recurso recién guardado y su URL en la cabecera Location,
+// para que el cliente conozca el ID asignado sin tener que buscarlo.
+func escribirCreado(w http.ResponseWriter, ubicacion string, valor any) {
+	w.Header().Set("Location", ubicacion)
+	escribirJSON(w, http.StatusCreated, valor)
+}
+
+// ProblemaRutaNoEncontrada responde 404 en problem+json a las rutas de la API que no existen.
+// mux también lo usa cuando la ruta existe pero no acepta el método de la solicitud.
+func ProblemaRutaNoEncontrada() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responderProblema(w, r, nuevoProblema(http.StatusNotFound, "La API no atiende "+r.Method+" "+r.URL.Path))
+	})
+}
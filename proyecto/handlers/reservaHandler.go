@@ -0,0 +1,146 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que maneja las reservas (cola de espera) de libros en la interfaz web.
+*/
+
+package handlers
+
+import (
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"proyecto/models" // Importa el paquete models para interactuar con libros, socios y reservas.
+	"strconv"         // Paquete para conversión de tipos.
+
+	"github.com/gorilla/mux" // Router HTTP para manejar rutas.
+)
+
+// RecuperarReservas maneja la solicitud para listar las reservas abiertas, agrupadas por libro en orden de llegada.
+func RecuperarReservas(reservas models.ReservaRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lista, err := reservas.GetReservasAbiertas()
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar las reservas")
+			return
+		}
+
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/reservas.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error al cargar el template", http.StatusInternalServerError)
+			return
+		}
+
+		err = tmpl.ExecuteTemplate(w, "base", lista)
+		if err != nil {
+			http.Error(w, "Error al ejecutar el template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// CreateReservaGetHandler muestra el formulario HTML para poner a un socio activo en la cola de un libro sin copias libres.
+// Si la URL incluye ?LibroId= o ?SocioId=, ese libro o socio aparece seleccionado.
+func CreateReservaGetHandler(libros models.LibroRepository, socios models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		todos, err := libros.GetAllLibros()
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar los libros")
+			return
+		}
+
+		// Solo se reservan los libros que no tienen ninguna copia libre; los demás se prestan directamente.
+		var agotados []models.Libro
+		for _, libro := range todos {
+			if libro.Disponibles == 0 {
+				agotados = append(agotados, libro)
+			}
+		}
+
+		activos, err := sociosActivos(socios)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar los socios")
+			return
+		}
+
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/crearReserva.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+
+		libroSeleccionado, _ := strconv.Atoi(r.URL.Query().Get("LibroId"))
+		socioSeleccionado, _ := strconv.Atoi(r.URL.Query().Get("SocioId"))
+		data := struct {
+			Libros        []models.Libro
+			Socios        []models.Socio
+			LibroId       int
+			SocioId       int
+			DiasRetencion int
+		}{
+			Libros:        agotados,
+			Socios:        activos,
+			LibroId:       libroSeleccionado,
+			SocioId:       socioSeleccionado,
+			DiasRetencion: models.DiasRetencionReserva,
+		}
+
+		err = tmpl.ExecuteTemplate(w, "base", data)
+		if err != nil {
+			log.Printf("Error al ejecutar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// CreateReservaPostHandler procesa el formulario de reserva de un libro.
+func CreateReservaPostHandler(reservas models.ReservaRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		LibroId, err := strconv.Atoi(r.FormValue("LibroId"))
+		if err != nil {
+			http.Error(w, "Debe seleccionar un libro", http.StatusBadRequest)
+			return
+		}
+		SocioId, err := strconv.Atoi(r.FormValue("SocioId"))
+		if err != nil {
+			http.Error(w, "Debe seleccionar un socio", http.StatusBadRequest)
+			return
+		}
+
+		_, err = reservas.CrearReserva(LibroId, SocioId)
+		if err != nil {
+			responderErrorWeb(w, r, err, "reservar el libro")
+			return
+		}
+
+		http.Redirect(w, r, "/reservas", http.StatusSeeOther)
+	}
+}
+
+// CancelarReservaHandler cancela una reserva abierta y vuelve a la lista de reservas.
+func CancelarReservaHandler(reservas models.ReservaRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de reserva inválido", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := reservas.GetReservaByID(id); err != nil {
+			responderErrorWeb(w, r, err, "recuperar la reserva")
+			return
+		}
+		if _, err := reservas.CancelarReserva(id); err != nil {
+			responderErrorWeb(w, r, err, "cancelar la reserva")
+			return
+		}
+		http.Redirect(w, r, "/reservas", http.StatusSeeOther)
+	}
+}
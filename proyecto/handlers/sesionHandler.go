@@ -0,0 +1,204 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que maneja el inicio y el cierre de sesión, y el middleware que exige una sesión en las demás rutas.
+*/
+
+package handlers
+
+import (
+	"context"         // Paquete para guardar el usuario en el contexto de la solicitud.
+	"errors"          // Paquete para reconocer los errores del modelo.
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"net/url"         // Paquete para recordar la página pedida antes de iniciar sesión.
+	"proyecto/models" // Importa el paquete models para interactuar con usuarios y sesiones.
+	"strings"         // Paquete para reconocer las rutas excluidas.
+	"time"            // Paquete para el vencimiento de la cookie de sesión.
+)
+
+const (
+	// CookieSesion es la cookie donde el navegador guarda el token de su sesión.
+	CookieSesion = "sesion"
+	// RutaLogin es la página de inicio de sesión, la única que no exige una sesión además de /static.
+	RutaLogin = "/login"
+	// campoSiguiente guarda la página a la que se vuelve después de iniciar sesión.
+	campoSiguiente = "siguiente"
+)
+
+// claveUsuario guarda en el contexto el usuario de la sesión, para los manejadores y las plantillas.
+const claveUsuario claveContexto = "usuario"
+
+// UsuarioActual devuelve el usuario que inició la sesión de la solicitud.
+// El segundo valor es false si la solicitud no pasó por RequerirSesion.
+func UsuarioActual(r *http.Request) (models.Usuario, bool) {
+	usuario, ok := r.Context().Value(claveUsuario).(models.Usuario)
+	return usuario, ok
+}
+
+// usuarioDeCookie devuelve el usuario de la sesión indicada en la cookie de la solicitud, si sigue vigente.
+func usuarioDeCookie(r *http.Request, sesiones models.SesionRepository) (models.Usuario, error) {
+	cookie, err := r.Cookie(CookieSesion)
+	if err != nil || cookie.Value == "" {
+		return models.Usuario{}, models.ErrSesionInvalida
+	}
+	return sesiones.UsuarioDeSesion(cookie.Value)
+}
+
+// RequerirSesion exige una sesión iniciada en todas las rutas excepto /static, la página de inicio de sesión
+// y las solicitudes de la API autenticadas por AutenticarAPI, que debe ejecutarse antes.
+// La interfaz web envía a los visitantes sin sesión a /login, recordando la página que pedían;
+// la API responde 401 Unauthorized en JSON. El usuario de la sesión queda en el contexto (ver UsuarioActual).
+func RequerirSesion(sesiones models.SesionRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == RutaLogin || strings.HasPrefix(r.URL.Path, "/static/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// Los clientes de la API que ya se autenticaron con una clave o un token no necesitan sesión.
+			if _, ok := CredencialActual(r); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			usuario, err := usuarioDeCookie(r, sesiones)
+			if err != nil {
+				if !errors.Is(err, models.ErrSesionInvalida) {
+					log.Printf("Error al verificar la sesión: %v", err)
+					http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+					return
+				}
+				if strings.HasPrefix(r.URL.Path, prefijoAPI) {
+					responderNoAutenticado(w, r)
+					return
+				}
+				// Solo las consultas se pueden repetir después de iniciar sesión; un formulario enviado se pierde.
+				destino := RutaLogin
+				if r.Method == http.MethodGet {
+					destino += "?" + url.Values{campoSiguiente: {r.URL.RequestURI()}}.Encode()
+				}
+				http.Redirect(w, r, destino, http.StatusSeeOther)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claveUsuario, usuario)))
+		})
+	}
+}
+
+// responderNoAutenticado responde 401 Unauthorized en problem+json a una solicitud de la API sin sesión ni credencial.
+// La cabecera WWW-Authenticate indica que la API acepta claves y tokens con el esquema Bearer.
+func responderNoAutenticado(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	responderProblema(w, r, nuevoProblema(http.StatusUnauthorized, "Debe iniciar sesión o enviar una clave de API para usar la API"))
+}
+
+// destinoSeguro devuelve la página a la que se vuelve después de iniciar sesión.
+// Solo se aceptan rutas locales, para que un enlace a /login no pueda redirigir a otro sitio.
+func destinoSeguro(siguiente string) string {
+	if !strings.HasPrefix(siguiente, "/") || strings.HasPrefix(siguiente, "//") || strings.HasPrefix(siguiente, "/\\") {
+		return "/"
+	}
+	return siguiente
+}
+
+// datosLogin son los datos de la página de inicio de sesión.
+type datosLogin struct {
+	Usuario   string // Nombre de usuario escrito, se conserva si la contraseña es incorrecta.
+	Siguiente string // Página a la que se vuelve después de iniciar sesión.
+	Error     string // Mensaje que se muestra si las credenciales no son válidas.
+}
+
+// renderizarLogin muestra la página de inicio de sesión con el estado HTTP indicado.
+func renderizarLogin(w http.ResponseWriter, r *http.Request, estado int, datos datosLogin) {
+	tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/login.html")
+	if err != nil {
+		log.Printf("Error al cargar el template: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(estado)
+	if err := tmpl.ExecuteTemplate(w, "base", datos); err != nil {
+		log.Printf("Error al ejecutar el template: %v", err)
+	}
+}
+
+// LoginGetHandler muestra el formulario de inicio de sesión. Si la sesión ya está iniciada, vuelve al inicio.
+func LoginGetHandler(sesiones models.SesionRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		siguiente := destinoSeguro(r.URL.Query().Get(campoSiguiente))
+		if _, err := usuarioDeCookie(r, sesiones); err == nil {
+			http.Redirect(w, r, siguiente, http.StatusSeeOther)
+			return
+		}
+		renderizarLogin(w, r, http.StatusOK, datosLogin{Siguiente: siguiente})
+	}
+}
+
+// LoginPostHandler verifica las credenciales, inicia la sesión y vuelve a la página que se había pedido.
+func LoginPostHandler(usuarios models.UsuarioRepository, sesiones models.SesionRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		datos := datosLogin{Usuario: strings.TrimSpace(r.FormValue("Usuario")), Siguiente: destinoSeguro(r.FormValue(campoSiguiente))}
+
+		usuario, err := usuarios.Autenticar(datos.Usuario, r.FormValue("Contrasena"))
+		if err != nil {
+			if errors.Is(err, models.ErrCredencialesInvalidas) {
+				datos.Error = "Usuario o contraseña incorrectos."
+				renderizarLogin(w, r, http.StatusUnauthorized, datos)
+				return
+			}
+			log.Printf("Error al autenticar al usuario %q: %v", datos.Usuario, err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+
+		// Cada inicio de sesión aprovecha para limpiar las sesiones vencidas de todos los usuarios.
+		if _, err := sesiones.EliminarSesionesVencidas(); err != nil {
+			log.Printf("Error al eliminar las sesiones vencidas: %v", err)
+		}
+		token, err := sesiones.CrearSesion(usuario.Id)
+		if err != nil {
+			log.Printf("Error al iniciar la sesión del usuario %d: %v", usuario.Id, err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+		// Con la sesión el token CSRF pasa a derivarse de ella; el de antes del login se reemplaza.
+		if _, err := renovarCookieCSRF(w); err != nil {
+			log.Printf("Error al generar el token CSRF: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     CookieSesion,
+			Value:    token,
+			Path:     "/",
+			Expires:  time.Now().Add(models.DuracionSesion),
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		log.Printf("El usuario %q inició sesión.", usuario.Usuario)
+		http.Redirect(w, r, datos.Siguiente, http.StatusSeeOther)
+	}
+}
+
+// LogoutHandler cierra la sesión del navegador, le entrega un token CSRF nuevo y vuelve a la página de inicio de sesión.
+func LogoutHandler(sesiones models.SesionRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie(CookieSesion); err == nil {
+			if err := sesiones.EliminarSesion(cookie.Value); err != nil {
+				http.Error(w, "Error al cerrar la sesión: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		http.SetCookie(w, &http.Cookie{Name: CookieSesion, Value: "", Path: "/", MaxAge: -1, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+		if _, err := renovarCookieCSRF(w); err != nil {
+			log.Printf("Error al generar el token CSRF: %v", err)
+		}
+		http.Redirect(w, r, RutaLogin, http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,294 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que maneja las operaciones CRUD para la entidad Socio en la interfaz web.
+*/
+
+package handlers
+
+import (
+	"errors"          // Paquete para reconocer los errores del modelo.
+	"fmt"             // Paquete para formatear los mensajes de error.
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"proyecto/models" // Importa el paquete models para interactuar con los socios.
+	"strconv"         // Paquete para conversión de tipos.
+	"strings"         // Paquete para limpiar los valores del formulario.
+
+	"github.com/gorilla/mux" // Router HTTP para manejar rutas.
+)
+
+// socioDesdeFormulario lee los campos del formulario de socio y valida los obligatorios.
+func socioDesdeFormulario(r *http.Request, id int) (models.Socio, error) {
+	socio := models.Socio{
+		Id:        id,
+		Nombre:    strings.TrimSpace(r.FormValue("Nombre")),
+		Email:     strings.TrimSpace(r.FormValue("Email")),
+		Telefono:  strings.TrimSpace(r.FormValue("Telefono")),
+		Direccion: strings.TrimSpace(r.FormValue("Direccion")),
+		Estado:    r.FormValue("Estado"),
+	}
+	if socio.Nombre == "" {
+		return socio, errors.New("el nombre del socio es obligatorio")
+	}
+	if socio.Estado == "" {
+		socio.Estado = models.EstadoSocioActivo // Un socio nuevo empieza con la membresía activa.
+	}
+	if !models.EstadoSocioValido(socio.Estado) {
+		return socio, fmt.Errorf("estado de membresía inválido: %q", socio.Estado)
+	}
+	return socio, nil
+}
+
+// RecuperarSocios maneja la solicitud para listar todos los socios en la interfaz web.
+func RecuperarSocios(repo models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtiene todos los socios de la base de datos.
+		socios, err := repo.GetAllSocios()
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar los socios")
+			return
+		}
+
+		// Parsea los archivos de plantilla base.html y socios.html.
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/socios.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error al cargar el template", http.StatusInternalServerError)
+			return
+		}
+
+		// Ejecuta la plantilla "base" pasando los socios como datos.
+		err = tmpl.ExecuteTemplate(w, "base", socios)
+		if err != nil {
+			http.Error(w, "Error al ejecutar el template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// CreateSocioGetHandler muestra el formulario HTML para inscribir un nuevo socio.
+func CreateSocioGetHandler(repo models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/crearSocio.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+
+		// Pasa los estados de membresía para el select del formulario.
+		data := struct {
+			Estados []string
+		}{
+			Estados: models.EstadosSocio,
+		}
+
+		err = tmpl.ExecuteTemplate(w, "base", data)
+		if err != nil {
+			log.Printf("Error al ejecutar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// CreateSocioPostHandler procesa los datos del formulario para inscribir un nuevo socio.
+func CreateSocioPostHandler(repo models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		socio, err := socioDesdeFormulario(r, 0)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if _, err := repo.CreateSocio(socio); err != nil {
+			responderErrorWeb(w, r, err, "crear el socio")
+			return
+		}
+
+		// Redirige al usuario a la lista de socios después de una creación exitosa.
+		http.Redirect(w, r, "/socios", http.StatusSeeOther)
+	}
+}
+
+// UpdateSocioGetHandler muestra el formulario para editar un socio existente.
+func UpdateSocioGetHandler(repo models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de socio inválido", http.StatusBadRequest)
+			return
+		}
+
+		socio, err := repo.GetSocioByID(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el socio")
+			return
+		}
+
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/editarSocio.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+
+		// Estructura para pasar el socio y los estados de membresía a la plantilla.
+		data := struct {
+			models.Socio
+			Estados []string
+		}{
+			Socio:   socio,
+			Estados: models.EstadosSocio,
+		}
+
+		err = tmpl.ExecuteTemplate(w, "base", data)
+		if err != nil {
+			log.Printf("Error al ejecutar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// UpdateSocioPostHandler procesa los datos del formulario para actualizar un socio.
+func UpdateSocioPostHandler(repo models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de socio inválido", http.StatusBadRequest)
+			return
+		}
+
+		socio, err := socioDesdeFormulario(r, id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.UpdateSocio(socio); err != nil {
+			responderErrorWeb(w, r, err, "actualizar el socio")
+			return
+		}
+
+		http.Redirect(w, r, "/socios", http.StatusSeeOther)
+	}
+}
+
+// DeleteSocioHandler maneja la solicitud para eliminar un socio sin préstamos.
+// Solo responde a POST y DELETE (los formularios envían _method=DELETE); un GET recibe 405.
+func DeleteSocioHandler(repo models.SocioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de socio inválido", http.StatusBadRequest)
+			return
+		}
+
+		err = repo.DeleteSocio(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "eliminar el socio")
+			return
+		}
+		http.Redirect(w, r, "/socios", http.StatusSeeOther)
+	}
+}
+
+// ConfirmarEliminarSocioHandler muestra los datos del socio y pide confirmación antes de eliminarlo.
+// Si el socio tiene préstamos registrados, la página explica que no se puede eliminar.
+func ConfirmarEliminarSocioHandler(socios models.SocioRepository, prestamos models.PrestamoRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de socio inválido", http.StatusBadRequest)
+			return
+		}
+
+		socio, err := socios.GetSocioByID(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el socio")
+			return
+		}
+		historial, err := prestamos.GetPrestamosBySocio(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar los préstamos del socio")
+			return
+		}
+
+		confirmacion := confirmacionEliminacion{
+			Titulo: "Eliminar Socio",
+			Nombre: socio.Nombre,
+			Detalles: []detalleConfirmacion{
+				{Etiqueta: "Email", Valor: socio.Email},
+				{Etiqueta: "Teléfono", Valor: socio.Telefono},
+				{Etiqueta: "Estado", Valor: socio.Estado},
+				{Etiqueta: "Préstamos registrados", Valor: strconv.Itoa(len(historial))},
+			},
+			Accion: fmt.Sprintf("/socios/eliminar/%d", socio.Id),
+			Volver: "/socios",
+		}
+		if len(historial) > 0 {
+			confirmacion.Bloqueo = "No se puede eliminar: " + models.ErrSocioConPrestamos.Error() + "."
+		}
+		renderizarConfirmacion(w, r, confirmacion)
+	}
+}
+
+// HistorialSocioHandler muestra los datos de un socio y su historial de préstamos.
+func HistorialSocioHandler(socios models.SocioRepository, prestamos models.PrestamoRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["Id"])
+		if err != nil {
+			http.Error(w, "ID de socio inválido", http.StatusBadRequest)
+			return
+		}
+
+		socio, err := socios.GetSocioByID(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el socio")
+			return
+		}
+		historial, err := prestamos.GetPrestamosBySocio(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar los préstamos")
+			return
+		}
+
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/socioPrestamos.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+
+		data := struct {
+			Socio     models.Socio
+			Prestamos []models.Prestamo
+		}{
+			Socio:     socio,
+			Prestamos: historial,
+		}
+
+		err = tmpl.ExecuteTemplate(w, "base", data)
+		if err != nil {
+			log.Printf("Error al ejecutar el template: %v", err)
+			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			return
+		}
+	}
+}
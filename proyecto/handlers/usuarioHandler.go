@@ -0,0 +1,224 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo con las páginas de administración de usuarios: alta, cambio de rol y eliminación.
+*/
+
+package handlers
+
+import (
+	"bytes"           // Paquete para ejecutar la plantilla en memoria antes de responder.
+	"errors"          // Paquete para reconocer los errores del modelo.
+	"fmt"             // Paquete para formatear las URL.
+	"log"             // Paquete para logging.
+	"net/http"        // Paquete para manejar solicitudes HTTP.
+	"proyecto/models" // Importa el paquete models para interactuar con los usuarios.
+	"strconv"         // Paquete para conversión de tipos.
+	"strings"         // Paquete para limpiar los valores del formulario.
+	"unicode/utf8"    // Paquete para contar los caracteres de la contraseña.
+
+	"github.com/gorilla/mux" // Router HTTP para manejar rutas.
+)
+
+// RecuperarUsuarios lista los usuarios con un selector para cambiar el rol de cada uno.
+func RecuperarUsuarios(usuarios models.UsuarioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lista, err := usuarios.GetAllUsuarios()
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar los usuarios")
+			return
+		}
+
+		tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/usuarios.html")
+		if err != nil {
+			log.Printf("Error al cargar el template: %v", err)
+			http.Error(w, "Error al cargar el template", http.StatusInternalServerError)
+			return
+		}
+
+		// El usuario actual no puede cambiar su propio rol ni eliminarse, para no quedarse sin acceso.
+		actual, _ := UsuarioActual(r)
+		data := struct {
+			Usuarios []models.Usuario
+			Roles    []string
+			ActualId int
+		}{
+			Usuarios: lista,
+			Roles:    models.Roles,
+			ActualId: actual.Id,
+		}
+		if err := tmpl.ExecuteTemplate(w, "base", data); err != nil {
+			http.Error(w, "Error al ejecutar el template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// formularioUsuario contiene los datos de la plantilla crearUsuario.html.
+// La contraseña nunca se vuelve a mostrar, aunque el formulario se rechace.
+type formularioUsuario struct {
+	Usuario models.Usuario           // Datos escritos en el formulario.
+	Roles   []string                 // Opciones del select de rol.
+	Errores models.ErroresValidacion // Mensaje de error de cada campo inválido.
+}
+
+// renderizar muestra el formulario de alta de usuario con el código de estado indicado.
+func (f formularioUsuario) renderizar(w http.ResponseWriter, r *http.Request, estado int) {
+	tmpl, err := cargarPlantilla(r, "templates/base.html", "templates/crearUsuario.html")
+	if err != nil {
+		log.Printf("Error al cargar el template: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+
+	// Ejecuta la plantilla en memoria para poder responder 500 si falla, antes de escribir el código de estado.
+	var contenido bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&contenido, "base", f); err != nil {
+		log.Printf("Error al ejecutar el template: %v", err)
+		http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(estado)
+	contenido.WriteTo(w)
+}
+
+// CreateUsuarioGetHandler muestra el formulario para crear un usuario. El rol sugerido es bibliotecario.
+func CreateUsuarioGetHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		formulario := formularioUsuario{Usuario: models.Usuario{Rol: models.RolBibliotecario}, Roles: models.Roles}
+		formulario.renderizar(w, r, http.StatusOK)
+	}
+}
+
+// CreateUsuarioPostHandler crea un usuario con el rol elegido. Si los datos no son válidos,
+// vuelve a mostrar el formulario con un mensaje junto a cada campo y responde 422 Unprocessable Entity.
+func CreateUsuarioPostHandler(usuarios models.UsuarioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error al parsear el formulario: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		formulario := formularioUsuario{
+			Usuario: models.Usuario{
+				Usuario: strings.TrimSpace(r.FormValue(models.CampoUsuario)),
+				Nombre:  strings.TrimSpace(r.FormValue(models.CampoNombreUsuario)),
+				Rol:     r.FormValue(models.CampoRol),
+			},
+			Roles:   models.Roles,
+			Errores: models.ErroresValidacion{},
+		}
+		var reglas models.ErroresValidacion
+		if errors.As(formulario.Usuario.Validar(), &reglas) {
+			formulario.Errores = reglas
+		}
+		contrasena := r.FormValue(models.CampoContrasena)
+		if utf8.RuneCountInString(contrasena) < models.LongitudMinimaContrasena {
+			formulario.Errores.Agregar(models.CampoContrasena, fmt.Sprintf("Debe tener al menos %d caracteres", models.LongitudMinimaContrasena))
+		} else if contrasena != r.FormValue("ConfirmarContrasena") {
+			formulario.Errores.Agregar("ConfirmarContrasena", "No coincide con la contraseña")
+		}
+		if len(formulario.Errores) > 0 {
+			formulario.renderizar(w, r, http.StatusUnprocessableEntity)
+			return
+		}
+
+		if _, err := usuarios.CrearUsuario(formulario.Usuario, contrasena); err != nil {
+			if errors.Is(err, models.ErrUsuarioDuplicado) {
+				formulario.Errores.Agregar(models.CampoUsuario, "Ya existe un usuario con ese nombre")
+				formulario.renderizar(w, r, http.StatusUnprocessableEntity)
+				return
+			}
+			responderErrorWeb(w, r, err, "crear el usuario")
+			return
+		}
+		http.Redirect(w, r, "/usuarios", http.StatusSeeOther)
+	}
+}
+
+// idUsuarioAjeno lee el ID de la URL y verifica que no sea el del usuario actual, que no puede cambiar
+// su propio rol ni eliminarse. Si el ID no es válido responde el error y devuelve false.
+func idUsuarioAjeno(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["Id"])
+	if err != nil {
+		http.Error(w, "ID de usuario inválido", http.StatusBadRequest)
+		return 0, false
+	}
+	if actual, _ := UsuarioActual(r); actual.Id == id {
+		http.Error(w, "No puede cambiar su propio rol ni eliminar su propia cuenta; pídaselo a otro administrador.", http.StatusConflict)
+		return 0, false
+	}
+	return id, true
+}
+
+// CambiarRolUsuarioHandler cambia el rol de un usuario y vuelve a la lista de usuarios.
+// El cambio se aplica también a las sesiones que el usuario ya tiene iniciadas.
+func CambiarRolUsuarioHandler(usuarios models.UsuarioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := idUsuarioAjeno(w, r)
+		if !ok {
+			return
+		}
+		if _, err := usuarios.GetUsuarioByID(id); err != nil {
+			responderErrorWeb(w, r, err, "recuperar el usuario")
+			return
+		}
+		if err := usuarios.ActualizarRol(id, r.FormValue(models.CampoRol)); err != nil {
+			responderErrorWeb(w, r, err, "cambiar el rol")
+			return
+		}
+		http.Redirect(w, r, "/usuarios", http.StatusSeeOther)
+	}
+}
+
+// ConfirmarEliminarUsuarioHandler muestra los datos del usuario y pide confirmación antes de eliminarlo.
+func ConfirmarEliminarUsuarioHandler(usuarios models.UsuarioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["Id"])
+		if err != nil {
+			http.Error(w, "ID de usuario inválido", http.StatusBadRequest)
+			return
+		}
+		usuario, err := usuarios.GetUsuarioByID(id)
+		if err != nil {
+			responderErrorWeb(w, r, err, "recuperar el usuario")
+			return
+		}
+
+		confirmacion := confirmacionEliminacion{
+			Titulo: "Eliminar Usuario",
+			Nombre: usuario.Nombre,
+			Detalles: []detalleConfirmacion{
+				{Etiqueta: "Usuario", Valor: usuario.Usuario},
+				{Etiqueta: "Rol", Valor: usuario.Rol},
+				{Etiqueta: "Alta", Valor: usuario.FechaAlta.Format("02/01/2006")},
+			},
+			Advertencia: "Se cerrarán todas las sesiones iniciadas con esta cuenta.",
+			Accion:      fmt.Sprintf("/usuarios/eliminar/%d", usuario.Id),
+			Volver:      "/usuarios",
+		}
+		if actual, _ := UsuarioActual(r); actual.Id == usuario.Id {
+			confirmacion.Bloqueo = "No puede eliminar su propia cuenta."
+		}
+		renderizarConfirmacion(w, r, confirmacion)
+	}
+}
+
+// DeleteUsuarioHandler elimina un usuario junto con sus sesiones y vuelve a la lista de usuarios.
+func DeleteUsuarioHandler(usuarios models.UsuarioRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := idUsuarioAjeno(w, r)
+		if !ok {
+			return
+		}
+		if _, err := usuarios.GetUsuarioByID(id); err != nil {
+			responderErrorWeb(w, r, err, "recuperar el usuario")
+			return
+		}
+		if err := usuarios.EliminarUsuario(id); err != nil {
+			responderErrorWeb(w, r, err, "eliminar el usuario")
+			return
+		}
+		http.Redirect(w, r, "/usuarios", http.StatusSeeOther)
+	}
+}
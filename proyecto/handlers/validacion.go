@@ -0,0 +1,23 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que valida los datos de un libro reuniendo los errores de cada campo.
+*/
+
+package handlers
+
+import (
+	"errors"          // Paquete para reconocer los errores de validación.
+	"proyecto/models" // Importa el paquete models donde se definen las reglas del libro.
+)
+
+// validarLibro completa los errores de lectura de los campos con las reglas de models.Libro.Validar.
+// Los errores de lectura tienen prioridad: un año que no es un número no se informa además como fuera de rango.
+func validarLibro(libro models.Libro, errores models.ErroresValidacion) error {
+	var reglas models.ErroresValidacion
+	if errors.As(libro.Validar(), &reglas) {
+		for campo, mensaje := range reglas {
+			errores.Agregar(campo, mensaje)
+		}
+	}
+	return errores.Err()
+}
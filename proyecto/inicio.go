@@ -6,57 +6,293 @@
 package main
 
 import (
-	"log"               // Paquete para logging.
-	"net/http"          // Paquete para manejar solicitudes y respuestas HTTP.
-	"proyecto/db"       // Importa el paquete db para la conexión a la base de datos.
-	"proyecto/handlers" // Importa el paquete handlers que contiene los manejadores de rutas.
+	"context"                 // Paquete para el contexto de la tarea de atrasos.
+	"database/sql"            // Paquete para recibir la conexión a la base de datos.
+	"flag"                    // Paquete para leer las opciones de la línea de comandos.
+	"log"                     // Paquete para logging.
+	"net/http"                // Paquete para manejar solicitudes y respuestas HTTP.
+	"os"                      // Paquete para leer el secreto de los tokens de la API.
+	"proyecto/db"             // Importa el paquete db para la conexión a la base de datos.
+	"proyecto/db/migraciones" // Importa las migraciones del esquema de la base de datos.
+	"proyecto/handlers"       // Importa el paquete handlers que contiene los manejadores de rutas.
+	"proyecto/models"         // Importa el paquete models que define los repositorios de datos.
+	"proyecto/tareas"         // Importa las tareas en segundo plano, como la revisión de atrasos.
+	"time"                    // Paquete para las fechas de los préstamos de ejemplo.
 
 	"github.com/gorilla/mux" // Router HTTP para Go.
 )
 
 func main() {
-	// Establece la conexión a la base de datos al inicio de la aplicación.
-	// Si la conexión falla, el programa terminará (panic).
-	database, err := db.Connect()
+	// La opción -memoria permite iniciar la aplicación sin base de datos (modo demo).
+	// Los datos se guardan en memoria y se pierden al detener el servidor.
+	memoria := flag.Bool("memoria", false, "usar un almacenamiento en memoria en lugar de la base de datos (modo demo)")
+	flag.Parse()
+
+	var repos repositorios
+	if *memoria {
+		if flag.Arg(0) == "migrate" {
+			log.Fatal("El comando migrate necesita una base de datos, no se puede usar con -memoria.")
+		}
+		log.Println("Modo demo: los datos se guardan en memoria.")
+		repos = nuevosRepositoriosDemo()
+	} else {
+		// Establece la conexión a la base de datos al inicio de la aplicación.
+		// El driver (MySQL o SQLite) se elige con la variable de entorno DB_DRIVER.
+		// Si la conexión falla, el programa terminará (panic).
+		database, err := db.Connect()
+		if err != nil {
+			log.Fatalf("No se pudo conectar a la base de datos: %v", err) // Usa Fatalf para terminar el programa con un mensaje.
+		}
+		// `defer database.Close()` asegura que la conexión a la base de datos se cierre cuando la función main termine.
+		defer database.Close()
+		log.Println("Conexión a la base de datos establecida correctamente.")
+
+		// `proyecto migrate up|down|status` administra el esquema y termina sin iniciar el servidor.
+		if flag.Arg(0) == "migrate" {
+			if err := comandoMigrate(database, db.Driver(), flag.Args()[1:]); err != nil {
+				log.Fatalf("Error en migrate: %v", err)
+			}
+			return
+		}
+
+		// Aplica las migraciones pendientes para que el esquema esté al día antes de atender solicitudes.
+		if _, err := migraciones.Up(database, db.Driver()); err != nil {
+			log.Fatalf("No se pudieron aplicar las migraciones: %v", err)
+		}
+
+		// Crea los repositorios sobre la conexión compartida.
+		// Todos los manejadores reutilizan el mismo pool de conexiones en lugar de abrir uno por solicitud.
+		repos = nuevosRepositoriosSQL(database, db.Driver())
+	}
+
+	// Sin usuarios nadie podría entrar a la interfaz web: se crea la primera cuenta con ADMIN_USUARIO y ADMIN_CONTRASENA.
+	if err := crearUsuarioInicial(repos.usuarios); err != nil {
+		log.Fatalf("No se pudo crear el usuario inicial: %v", err)
+	}
+
+	// Inicia la revisión periódica de préstamos vencidos. La tarifa, los días de gracia y el intervalo
+	// se configuran con MULTA_TARIFA_DIARIA, MULTA_DIAS_GRACIA y REVISION_ATRASOS_INTERVALO.
+	configMultas, intervalo, err := tareas.ConfigDesdeEntorno()
 	if err != nil {
-		log.Fatalf("No se pudo conectar a la base de datos: %v", err) // Usa Fatalf para terminar el programa con un mensaje.
+		log.Fatalf("Configuración de multas inválida: %v", err)
+	}
+	tareas.IniciarRevisionAtrasos(context.Background(), repos.multas, configMultas, intervalo)
+	// Con el mismo intervalo se liberan las copias apartadas por reservas que no se retiraron a tiempo.
+	tareas.IniciarVencimientoReservas(context.Background(), repos.reservas, intervalo)
+
+	r := nuevoRouter(repos)
+
+	// Mensaje de log que indica que el servidor se está iniciando.
+	log.Println("Servidor iniciado en http://localhost:8000")
+	// Inicia el servidor HTTP en el puerto 8080.
+	// `log.Fatal` se usa aquí para que si el servidor no puede arrancar (ej. puerto ya en uso),
+	// el error se registre y el programa termine.
+	log.Fatal(http.ListenAndServe(":8000", r))
+}
+
+// repositorios agrupa los repositorios de datos que usan los manejadores, junto con el firmador
+// de los tokens de la API, que hace el papel de un almacén de tokens sin guardar nada.
+type repositorios struct {
+	libros     models.LibroRepository
+	ejemplares models.EjemplarRepository
+	socios     models.SocioRepository
+	prestamos  models.PrestamoRepository
+	multas     models.MultaRepository
+	reservas   models.ReservaRepository
+	usuarios   models.UsuarioRepository
+	sesiones   models.SesionRepository
+	claves     models.ClaveAPIRepository
+	tokens     *models.FirmadorTokens
+}
+
+// nuevosRepositoriosSQL crea los repositorios sobre la conexión a la base de datos del driver indicado.
+// Con MySQL la búsqueda de libros usa el índice FULLTEXT; con SQLite, un índice en memoria.
+func nuevosRepositoriosSQL(database *sql.DB, driver string) repositorios {
+	libros := models.NewSQLLibroRepository(database)
+	if driver == db.DriverMySQL {
+		libros = models.NewSQLLibroRepositoryTextoCompleto(database)
+	}
+	return repositorios{
+		libros:     libros,
+		ejemplares: models.NewSQLEjemplarRepository(database),
+		socios:     models.NewSQLSocioRepository(database),
+		prestamos:  models.NewSQLPrestamoRepository(database),
+		multas:     models.NewSQLMultaRepository(database),
+		reservas:   models.NewSQLReservaRepository(database),
+		usuarios:   models.NewSQLUsuarioRepository(database),
+		sesiones:   models.NewSQLSesionRepository(database),
+		claves:     models.NewSQLClaveAPIRepository(database),
+		tokens:     nuevoFirmadorTokens(os.Getenv("TOKEN_API_SECRETO")),
 	}
-	// `defer database.Close()` asegura que la conexión a la base de datos se cierre cuando la función main termine.
-	defer database.Close()
-	log.Println("Conexión a la base de datos establecida correctamente.")
+}
+
+// nuevosRepositoriosMemoria crea repositorios en memoria vacíos, usados en el modo demo y en las pruebas.
+// Todos comparten la misma MemoriaDB, igual que los repositorios SQL comparten la conexión.
+func nuevosRepositoriosMemoria() repositorios {
+	mdb := models.NewMemoriaDB()
+	return repositorios{
+		libros:     models.NewMemoryLibroRepository(mdb),
+		ejemplares: models.NewMemoryEjemplarRepository(mdb),
+		socios:     models.NewMemorySocioRepository(mdb),
+		prestamos:  models.NewMemoryPrestamoRepository(mdb),
+		multas:     models.NewMemoryMultaRepository(mdb),
+		reservas:   models.NewMemoryReservaRepository(mdb),
+		usuarios:   models.NewMemoryUsuarioRepository(mdb),
+		sesiones:   models.NewMemorySesionRepository(mdb),
+		claves:     models.NewMemoryClaveAPIRepository(mdb),
+		tokens:     nuevoFirmadorTokens(os.Getenv("TOKEN_API_SECRETO")),
+	}
+}
+
+// nuevoRouter registra todas las rutas de la aplicación sobre los repositorios recibidos.
+// Se separa de main para que las pruebas puedan levantar el mismo enrutador con repositorios en memoria.
+// Devuelve el enrutador envuelto en los middlewares que deben ejecutarse antes de elegir la ruta.
+func nuevoRouter(repos repositorios) http.Handler {
+	libros, ejemplares, socios, prestamos, multas, reservas := repos.libros, repos.ejemplares, repos.socios, repos.prestamos, repos.multas, repos.reservas
+	usuarios, sesiones, claves, tokens := repos.usuarios, repos.sesiones, repos.claves, repos.tokens
 
 	// Crea un nuevo enrutador de Gorilla Mux.
 	// Mux es un enrutador HTTP que nos permite definir rutas de URL de manera flexible.
 	r := mux.NewRouter()
+	// Los clientes de la API pueden autenticarse con una clave de API o un token firmado en lugar de una sesión.
+	r.Use(handlers.AutenticarAPI(claves, tokens))
+	// Los POST, PUT y DELETE hechos con la sesión del navegador, también los de la API, deben llevar el token
+	// CSRF de la sesión; los clientes con clave o token de API quedan excluidos.
+	r.Use(handlers.ProtegerCSRF)
+	// Todas las rutas, incluida la API, exigen una sesión iniciada o una credencial de API; solo /static y /login quedan libres.
+	r.Use(handlers.RequerirSesion(sesiones))
+	// Además, cada ruta se envuelve en handlers.ConPermiso con el permiso que exige (ver models.PermisosPorRol);
+	// las de la API, en handlers.ConAlcance, que para las claves de API verifica el alcance en lugar del rol.
 
 	// Sirve archivos estáticos desde el directorio "static"
 	// Esto permite que el navegador cargue CSS, JavaScript, imágenes, etc.
 	// Por ejemplo, una solicitud a /static/style.css buscará el archivo en el directorio "static/style.css".
 	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+	// Las páginas que no existen muestran la plantilla noEncontrado.html con 404. mux no aplica los
+	// middlewares de r.Use al NotFoundHandler, así que se envuelve en los de la sesión y el token CSRF.
+	r.NotFoundHandler = handlers.ProtegerCSRF(handlers.RequerirSesion(sesiones)(handlers.PaginaNoEncontrada()))
+
+	// Rutas para iniciar y cerrar la sesión.
+	r.HandleFunc(handlers.RutaLogin, handlers.LoginGetHandler(sesiones)).Methods("GET")             // Muestra el formulario de inicio de sesión.
+	r.HandleFunc(handlers.RutaLogin, handlers.LoginPostHandler(usuarios, sesiones)).Methods("POST") // Verifica las credenciales e inicia la sesión.
+	r.HandleFunc("/logout", handlers.LogoutHandler(sesiones)).Methods("POST")                       // Cierra la sesión.
 
 	// Rutas para la interfaz web (HTML).
 	// Cada HandleFunc asocia una URL con una función manejadora y un método HTTP (GET, POST, etc.).
-	r.HandleFunc("/", handlers.HomeHandler(database)).Methods("GET")                     // Ruta para la página de inicio.
-	r.HandleFunc("/libros", handlers.RecuperarLibros).Methods("GET")                     // Ruta para listar todos los libros.
-	r.HandleFunc("/libros/crear", handlers.CreateLibroGetHandler).Methods("GET")         // Muestra el formulario para crear un libro.
-	r.HandleFunc("/libros/crear", handlers.CreateLibroPostHandler).Methods("POST")       // Procesa el envío del formulario para crear un libro.
-	r.HandleFunc("/libros/editar/{Id}", handlers.UpdateLibroGetHandler).Methods("GET")   // Muestra el formulario para editar un libro por su ID.
-	r.HandleFunc("/libros/editar/{Id}", handlers.UpdateLibroPostHandler).Methods("POST") // Procesa el envío del formulario para actualizar un libro.
-	r.HandleFunc("/libros/eliminar/{Id}", handlers.DeleteLibroHandler).Methods("GET")    // Elimina un libro por su ID.
+	r.HandleFunc("/", handlers.ConPermiso(models.PermisoVer, handlers.HomeHandler(libros, multas))).Methods("GET")                                    // Ruta para la página de inicio.
+	r.HandleFunc("/libros", handlers.ConPermiso(models.PermisoVer, handlers.RecuperarLibros(libros))).Methods("GET")                                  // Ruta para listar todos los libros.
+	r.HandleFunc("/libros/buscar", handlers.ConPermiso(models.PermisoVer, handlers.BuscarLibrosHandler(libros))).Methods("GET")                       // Busca libros por título, autor o editorial.
+	r.HandleFunc("/libros/crear", handlers.ConPermiso(models.PermisoEditar, handlers.CreateLibroGetHandler(libros))).Methods("GET")                   // Muestra el formulario para crear un libro.
+	r.HandleFunc("/libros/crear", handlers.ConPermiso(models.PermisoEditar, handlers.CreateLibroPostHandler(libros))).Methods("POST")                 // Procesa el envío del formulario para crear un libro.
+	r.HandleFunc("/libros/editar/{Id}", handlers.ConPermiso(models.PermisoEditar, handlers.UpdateLibroGetHandler(libros))).Methods("GET")             // Muestra el formulario para editar un libro por su ID.
+	r.HandleFunc("/libros/editar/{Id}", handlers.ConPermiso(models.PermisoEditar, handlers.UpdateLibroPostHandler(libros))).Methods("POST")           // Procesa el envío del formulario para actualizar un libro.
+	r.HandleFunc("/libros/{Id}/eliminar", handlers.ConPermiso(models.PermisoEliminar, handlers.ConfirmarEliminarLibroHandler(libros))).Methods("GET") // Pide confirmación para eliminar un libro.
+	r.HandleFunc("/libros/eliminar/{Id}", handlers.ConPermiso(models.PermisoEliminar, handlers.DeleteLibroHandler(libros))).Methods("POST", "DELETE") // Elimina un libro por su ID; un GET recibe 405.
+
+	// Rutas para los ejemplares (copias físicas) de cada libro.
+	r.HandleFunc("/libros/{Id}/ejemplares", handlers.ConPermiso(models.PermisoVer, handlers.RecuperarEjemplares(libros, ejemplares))).Methods("GET")             // Lista las copias de un libro.
+	r.HandleFunc("/libros/{Id}/ejemplares", handlers.ConPermiso(models.PermisoEditar, handlers.CreateEjemplarPostHandler(ejemplares))).Methods("POST")           // Agrega una copia a un libro.
+	r.HandleFunc("/ejemplares/editar/{Id}", handlers.ConPermiso(models.PermisoEditar, handlers.UpdateEjemplarGetHandler(ejemplares))).Methods("GET")             // Muestra el formulario para editar una copia.
+	r.HandleFunc("/ejemplares/editar/{Id}", handlers.ConPermiso(models.PermisoEditar, handlers.UpdateEjemplarPostHandler(ejemplares))).Methods("POST")           // Procesa el formulario para actualizar una copia.
+	r.HandleFunc("/ejemplares/{Id}/eliminar", handlers.ConPermiso(models.PermisoEliminar, handlers.ConfirmarEliminarEjemplarHandler(ejemplares))).Methods("GET") // Pide confirmación para eliminar una copia.
+	r.HandleFunc("/ejemplares/eliminar/{Id}", handlers.ConPermiso(models.PermisoEliminar, handlers.DeleteEjemplarHandler(ejemplares))).Methods("POST", "DELETE") // Elimina una copia que no está prestada.
+
+	// Rutas para los socios de la biblioteca.
+	r.HandleFunc("/socios", handlers.ConPermiso(models.PermisoVer, handlers.RecuperarSocios(socios))).Methods("GET")                                             // Lista todos los socios.
+	r.HandleFunc("/socios/crear", handlers.ConPermiso(models.PermisoEditar, handlers.CreateSocioGetHandler(socios))).Methods("GET")                              // Muestra el formulario para inscribir un socio.
+	r.HandleFunc("/socios/crear", handlers.ConPermiso(models.PermisoEditar, handlers.CreateSocioPostHandler(socios))).Methods("POST")                            // Procesa el formulario para inscribir un socio.
+	r.HandleFunc("/socios/editar/{Id}", handlers.ConPermiso(models.PermisoEditar, handlers.UpdateSocioGetHandler(socios))).Methods("GET")                        // Muestra el formulario para editar un socio.
+	r.HandleFunc("/socios/editar/{Id}", handlers.ConPermiso(models.PermisoEditar, handlers.UpdateSocioPostHandler(socios))).Methods("POST")                      // Procesa el formulario para actualizar un socio.
+	r.HandleFunc("/socios/{Id}/eliminar", handlers.ConPermiso(models.PermisoEliminar, handlers.ConfirmarEliminarSocioHandler(socios, prestamos))).Methods("GET") // Pide confirmación para eliminar un socio.
+	r.HandleFunc("/socios/eliminar/{Id}", handlers.ConPermiso(models.PermisoEliminar, handlers.DeleteSocioHandler(socios))).Methods("POST", "DELETE")            // Elimina un socio sin préstamos.
+	r.HandleFunc("/socios/{Id}/prestamos", handlers.ConPermiso(models.PermisoVer, handlers.HistorialSocioHandler(socios, prestamos))).Methods("GET")             // Historial de préstamos de un socio.
+
+	// Rutas para los préstamos. Prestar y devolver actualizan también el estado del libro.
+	r.HandleFunc("/prestamos", handlers.ConPermiso(models.PermisoVer, handlers.RecuperarPrestamos(prestamos))).Methods("GET")                         // Lista todos los préstamos.
+	r.HandleFunc("/prestamos/crear", handlers.ConPermiso(models.PermisoPrestar, handlers.CreatePrestamoGetHandler(libros, socios))).Methods("GET")    // Muestra el formulario para prestar un libro.
+	r.HandleFunc("/prestamos/crear", handlers.ConPermiso(models.PermisoPrestar, handlers.CreatePrestamoPostHandler(prestamos))).Methods("POST")       // Registra el préstamo de un libro.
+	r.HandleFunc("/prestamos/devolver/{Id}", handlers.ConPermiso(models.PermisoPrestar, handlers.DevolverPrestamoHandler(prestamos))).Methods("POST") // Registra la devolución de un préstamo.
+
+	// Rutas para las reservas. Al devolver una copia, queda apartada para la reserva más antigua del libro.
+	r.HandleFunc("/reservas", handlers.ConPermiso(models.PermisoVer, handlers.RecuperarReservas(reservas))).Methods("GET")                         // Lista las reservas abiertas.
+	r.HandleFunc("/reservas/crear", handlers.ConPermiso(models.PermisoPrestar, handlers.CreateReservaGetHandler(libros, socios))).Methods("GET")   // Muestra el formulario para reservar un libro.
+	r.HandleFunc("/reservas/crear", handlers.ConPermiso(models.PermisoPrestar, handlers.CreateReservaPostHandler(reservas))).Methods("POST")       // Pone a un socio en la cola de un libro.
+	r.HandleFunc("/reservas/cancelar/{Id}", handlers.ConPermiso(models.PermisoPrestar, handlers.CancelarReservaHandler(reservas))).Methods("POST") // Cancela una reserva abierta.
+
+	// Administración de las cuentas de usuario; solo para el rol admin.
+	r.HandleFunc("/usuarios", handlers.ConPermiso(models.PermisoAdministrarUsuarios, handlers.RecuperarUsuarios(usuarios))).Methods("GET")                             // Lista los usuarios y sus roles.
+	r.HandleFunc("/usuarios/crear", handlers.ConPermiso(models.PermisoAdministrarUsuarios, handlers.CreateUsuarioGetHandler())).Methods("GET")                         // Muestra el formulario para crear un usuario.
+	r.HandleFunc("/usuarios/crear", handlers.ConPermiso(models.PermisoAdministrarUsuarios, handlers.CreateUsuarioPostHandler(usuarios))).Methods("POST")               // Crea un usuario con el rol elegido.
+	r.HandleFunc("/usuarios/{Id}/rol", handlers.ConPermiso(models.PermisoAdministrarUsuarios, handlers.CambiarRolUsuarioHandler(usuarios))).Methods("POST")            // Cambia el rol de un usuario.
+	r.HandleFunc("/usuarios/{Id}/eliminar", handlers.ConPermiso(models.PermisoAdministrarUsuarios, handlers.ConfirmarEliminarUsuarioHandler(usuarios))).Methods("GET") // Pide confirmación para eliminar un usuario.
+	r.HandleFunc("/usuarios/eliminar/{Id}", handlers.ConPermiso(models.PermisoAdministrarUsuarios, handlers.DeleteUsuarioHandler(usuarios))).Methods("POST", "DELETE") // Elimina un usuario y sus sesiones.
+
+	// Administración de las claves de API; solo para el rol admin.
+	r.HandleFunc("/claves-api", handlers.ConPermiso(models.PermisoAdministrarClaves, handlers.RecuperarClavesAPI(claves))).Methods("GET")                   // Lista las claves de API.
+	r.HandleFunc("/claves-api/crear", handlers.ConPermiso(models.PermisoAdministrarClaves, handlers.CreateClaveAPIPostHandler(claves))).Methods("POST")     // Crea una clave y la muestra una sola vez.
+	r.HandleFunc("/claves-api/revocar/{Id}", handlers.ConPermiso(models.PermisoAdministrarClaves, handlers.RevocarClaveAPIHandler(claves))).Methods("POST") // Revoca una clave y sus tokens.
 
 	// Rutas para la API (para aplicaciones cliente-servidor, ej. JavaScript frontend)
 	// Se crea un sub-enrutador para las rutas de la API, todas comenzarán con /api.
 	apiRouter := r.PathPrefix("/api").Subrouter()
-	apiRouter.HandleFunc("/libros", handlers.ApiListarLibros).Methods("GET")          // API para listar todos los libros.
-	apiRouter.HandleFunc("/libros/{Id}", handlers.ApiObtenerLibro).Methods("GET")     // API para obtener un libro por ID.
-	apiRouter.HandleFunc("/libros", handlers.ApiCrearLibro).Methods("POST")           // API para crear un nuevo libro.
-	apiRouter.HandleFunc("/libros/{Id}", handlers.ApiActualizarLibro).Methods("PUT")  // API para actualizar un libro existente.
-	apiRouter.HandleFunc("/libros/{Id}", handlers.ApiEliminarLibro).Methods("DELETE") // API para eliminar un libro.
+	apiRouter.NotFoundHandler = handlers.ProblemaRutaNoEncontrada()                                                                                                                          // Las rutas inexistentes de la API responden 404 en problem+json.
+	apiRouter.HandleFunc("/tokens", handlers.ApiEmitirToken(tokens)).Methods("POST")                                                                                                         // Cambia una clave de API por un token firmado.
+	apiRouter.HandleFunc("/libros", handlers.ConAlcance(models.AlcanceLibrosLeer, models.PermisoVer, handlers.ApiListarLibros(libros))).Methods("GET")                                       // API para listar todos los libros.
+	apiRouter.HandleFunc("/libros/suggest", handlers.ConAlcance(models.AlcanceLibrosLeer, models.PermisoVer, handlers.ApiSugerirLibros(libros))).Methods("GET")                              // API para sugerir títulos, autores y editoriales mientras se escribe.
+	apiRouter.HandleFunc("/libros/search", handlers.ConAlcance(models.AlcanceLibrosLeer, models.PermisoVer, handlers.ApiBuscarLibros(libros))).Methods("GET")                                // API para buscar libros por texto; va antes de /libros/{Id}.
+	apiRouter.HandleFunc("/libros/{Id}", handlers.ConAlcance(models.AlcanceLibrosLeer, models.PermisoVer, handlers.ApiObtenerLibro(libros))).Methods("GET")                                  // API para obtener un libro por ID.
+	apiRouter.HandleFunc("/libros", handlers.ConAlcance(models.AlcanceLibrosEscribir, models.PermisoEditar, handlers.ApiCrearLibro(libros))).Methods("POST")                                 // API para crear un nuevo libro.
+	apiRouter.HandleFunc("/libros/{Id}", handlers.ConAlcance(models.AlcanceLibrosEscribir, models.PermisoEditar, handlers.ApiActualizarLibro(libros))).Methods("PUT")                        // API para actualizar un libro existente.
+	apiRouter.HandleFunc("/libros/{Id}", handlers.ConAlcance(models.AlcanceLibrosEscribir, models.PermisoEliminar, handlers.ApiEliminarLibro(libros))).Methods("DELETE")                     // API para eliminar un libro.
+	apiRouter.HandleFunc("/libros/{Id}/ejemplares", handlers.ConAlcance(models.AlcanceLibrosLeer, models.PermisoVer, handlers.ApiListarEjemplares(libros, ejemplares))).Methods("GET")       // API para listar las copias de un libro.
+	apiRouter.HandleFunc("/libros/{Id}/ejemplares", handlers.ConAlcance(models.AlcanceLibrosEscribir, models.PermisoEditar, handlers.ApiCrearEjemplar(ejemplares))).Methods("POST")          // API para agregar una copia a un libro.
+	apiRouter.HandleFunc("/ejemplares/{Id}", handlers.ConAlcance(models.AlcanceLibrosLeer, models.PermisoVer, handlers.ApiObtenerEjemplar(ejemplares))).Methods("GET")                       // API para obtener una copia por ID.
+	apiRouter.HandleFunc("/ejemplares/{Id}", handlers.ConAlcance(models.AlcanceLibrosEscribir, models.PermisoEditar, handlers.ApiActualizarEjemplar(ejemplares))).Methods("PUT")             // API para actualizar una copia.
+	apiRouter.HandleFunc("/ejemplares/{Id}", handlers.ConAlcance(models.AlcanceLibrosEscribir, models.PermisoEliminar, handlers.ApiEliminarEjemplar(ejemplares))).Methods("DELETE")          // API para eliminar una copia que no está prestada.
+	apiRouter.HandleFunc("/socios", handlers.ConAlcance(models.AlcanceSociosLeer, models.PermisoVer, handlers.ApiListarSocios(socios))).Methods("GET")                                       // API para listar todos los socios.
+	apiRouter.HandleFunc("/socios/{Id}", handlers.ConAlcance(models.AlcanceSociosLeer, models.PermisoVer, handlers.ApiObtenerSocio(socios))).Methods("GET")                                  // API para obtener un socio por ID.
+	apiRouter.HandleFunc("/socios", handlers.ConAlcance(models.AlcanceSociosEscribir, models.PermisoEditar, handlers.ApiCrearSocio(socios))).Methods("POST")                                 // API para inscribir un socio.
+	apiRouter.HandleFunc("/socios/{Id}", handlers.ConAlcance(models.AlcanceSociosEscribir, models.PermisoEditar, handlers.ApiActualizarSocio(socios))).Methods("PUT")                        // API para actualizar un socio.
+	apiRouter.HandleFunc("/socios/{Id}", handlers.ConAlcance(models.AlcanceSociosEscribir, models.PermisoEliminar, handlers.ApiEliminarSocio(socios))).Methods("DELETE")                     // API para eliminar un socio sin préstamos.
+	apiRouter.HandleFunc("/socios/{Id}/prestamos", handlers.ConAlcance(models.AlcancePrestamosLeer, models.PermisoVer, handlers.ApiPrestamosSocio(socios, prestamos))).Methods("GET")        // API para el historial de préstamos de un socio.
+	apiRouter.HandleFunc("/prestamos", handlers.ConAlcance(models.AlcancePrestamosLeer, models.PermisoVer, handlers.ApiListarPrestamos(prestamos))).Methods("GET")                           // API para listar los préstamos.
+	apiRouter.HandleFunc("/prestamos/{Id}", handlers.ConAlcance(models.AlcancePrestamosLeer, models.PermisoVer, handlers.ApiObtenerPrestamo(prestamos))).Methods("GET")                      // API para obtener un préstamo por ID.
+	apiRouter.HandleFunc("/prestamos", handlers.ConAlcance(models.AlcancePrestamosEscribir, models.PermisoPrestar, handlers.ApiCrearPrestamo(prestamos))).Methods("POST")                    // API para prestar un libro.
+	apiRouter.HandleFunc("/prestamos/{Id}/devolucion", handlers.ConAlcance(models.AlcancePrestamosEscribir, models.PermisoPrestar, handlers.ApiDevolverPrestamo(prestamos))).Methods("POST") // API para registrar una devolución.
+	apiRouter.HandleFunc("/multas", handlers.ConAlcance(models.AlcancePrestamosLeer, models.PermisoVer, handlers.ApiListarMultas(multas))).Methods("GET")                                    // API para listar las multas por atraso.
+	apiRouter.HandleFunc("/reservas", handlers.ConAlcance(models.AlcancePrestamosLeer, models.PermisoVer, handlers.ApiListarReservas(reservas))).Methods("GET")                              // API para listar las reservas abiertas.
+	apiRouter.HandleFunc("/reservas/{Id}", handlers.ConAlcance(models.AlcancePrestamosLeer, models.PermisoVer, handlers.ApiObtenerReserva(reservas))).Methods("GET")                         // API para obtener una reserva por ID.
+	apiRouter.HandleFunc("/reservas", handlers.ConAlcance(models.AlcancePrestamosEscribir, models.PermisoPrestar, handlers.ApiCrearReserva(reservas))).Methods("POST")                       // API para reservar un libro sin copias libres.
+	apiRouter.HandleFunc("/reservas/{Id}/cancelacion", handlers.ConAlcance(models.AlcancePrestamosEscribir, models.PermisoPrestar, handlers.ApiCancelarReserva(reservas))).Methods("POST")   // API para cancelar una reserva.
+	apiRouter.HandleFunc("/libros/{Id}/reservas", handlers.ConAlcance(models.AlcancePrestamosLeer, models.PermisoVer, handlers.ApiReservasLibro(libros, reservas))).Methods("GET")           // API para la cola de reservas de un libro.
 
-	// Mensaje de log que indica que el servidor se está iniciando.
-	log.Println("Servidor iniciado en http://localhost:8000")
-	// Inicia el servidor HTTP en el puerto 8080.
-	// `log.Fatal` se usa aquí para que si el servidor no puede arrancar (ej. puerto ya en uso),
-	// el error se registre y el programa termine.
-	log.Fatal(http.ListenAndServe(":8000", r))
+	// Los formularios HTML envían las eliminaciones como POST con _method=DELETE.
+	return handlers.SobrescribirMetodo(r)
+}
+
+// Credenciales de la cuenta que se crea en el modo demo.
+const (
+	usuarioDemo    = "demo"
+	contrasenaDemo = "biblioteca"
+)
+
+// nuevosRepositoriosDemo crea repositorios en memoria con algunos datos de ejemplo para el modo demo.
+func nuevosRepositoriosDemo() repositorios {
+	repos := nuevosRepositoriosMemoria()
+	repos.libros.CreateLibro("Gabriel García Márquez", "Cien años de soledad", 1967, "Sudamericana")
+	repos.libros.CreateLibro("Julio Cortázar", "Rayuela", 1963, "Sudamericana")
+	repos.libros.CreateLibro("Jorge Luis Borges", "Ficciones", 1944, "Sur")
+	repos.socios.CreateSocio(models.Socio{Nombre: "Ana Torres", Email: "ana.torres@example.com", Estado: models.EstadoSocioActivo})
+	repos.socios.CreateSocio(models.Socio{Nombre: "Luis Gómez", Telefono: "0991234567", Estado: models.EstadoSocioActivo})
+	// Rayuela tiene una segunda copia, así que sigue disponible después del préstamo de ejemplo.
+	repos.ejemplares.CreateEjemplar(models.Ejemplar{LibroId: 2, CodigoBarras: models.CodigoBarrasSugerido(2, 2), Ubicacion: "Estante B", Condicion: models.CondicionNuevo, FechaAdquisicion: time.Now()})
+	repos.prestamos.PrestarLibro(2, 1, time.Now().AddDate(0, 0, models.DiasPrestamoPorDefecto))
+	// Un préstamo vencido hace cinco días, para que la revisión de atrasos tenga algo que mostrar.
+	repos.prestamos.PrestarLibro(3, 2, time.Now().AddDate(0, 0, -5))
+	// Ana espera Ficciones, que Luis todavía no devuelve.
+	repos.reservas.CrearReserva(3, 1)
+	// Cuenta para entrar a la interfaz web en el modo demo.
+	repos.usuarios.CrearUsuario(models.Usuario{Usuario: usuarioDemo, Nombre: "Bibliotecario Demo", Rol: models.RolAdmin}, contrasenaDemo)
+	log.Printf("Modo demo: inicie sesión con el usuario %q y la contraseña %q.", usuarioDemo, contrasenaDemo)
+	return repos
 }
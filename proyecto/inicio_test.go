@@ -0,0 +1,1516 @@
+package main
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"proyecto/db"
+	"proyecto/db/migraciones"
+	"proyecto/handlers"
+	"proyecto/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// nuevoServidorPrueba levanta el enrutador completo sobre repositorios en memoria con un libro y dos socios cargados.
+// Las solicitudes que no traen su propia cookie de sesión reciben la de una sesión ya iniciada, como un navegador
+// que pasó por /login; las pruebas de la sesión usan nuevoRouter directamente.
+func nuevoServidorPrueba(t *testing.T) (repositorios, http.Handler) {
+	t.Helper()
+	repos := nuevosRepositoriosMemoria()
+	if _, err := repos.libros.CreateLibro("Julio Cortázar", "Rayuela", 1963, "Sudamericana"); err != nil {
+		t.Fatalf("CreateLibro: %v", err)
+	}
+	repos.socios.CreateSocio(models.Socio{Nombre: "Ana Torres", Estado: models.EstadoSocioActivo}) // ID 1
+	repos.socios.CreateSocio(models.Socio{Nombre: "Luis Gómez", Estado: models.EstadoSocioActivo}) // ID 2
+	usuario, err := repos.usuarios.CrearUsuario(models.Usuario{Usuario: "bibliotecario", Nombre: "Bibliotecaria de Prueba", Rol: models.RolAdmin}, contrasenaPrueba)
+	if err != nil {
+		t.Fatalf("CrearUsuario: %v", err)
+	}
+	token, err := repos.sesiones.CrearSesion(usuario.Id)
+	if err != nil {
+		t.Fatalf("CrearSesion: %v", err)
+	}
+	return repos, conSesion(nuevoRouter(repos), token)
+}
+
+// contrasenaPrueba es la contraseña del usuario que crea nuevoServidorPrueba.
+const contrasenaPrueba = "contraseña-de-prueba"
+
+// conSesion agrega la cookie de la sesión indicada a las solicitudes que no traen una. Si la solicitud envía
+// tokenPrueba en la cabecera CSRF, lo reemplaza por el token de esa sesión, como el que el navegador leyó de la página.
+func conSesion(h http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie(handlers.CookieSesion); err != nil {
+			r.AddCookie(&http.Cookie{Name: handlers.CookieSesion, Value: token})
+			if r.Header.Get(handlers.CabeceraCSRF) == tokenPrueba {
+				r.Header.Set(handlers.CabeceraCSRF, handlers.TokenCSRFDeSesion(token))
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// tokenPrueba es el token CSRF con que las pruebas envían sus solicitudes, como un navegador que ya cargó una
+// página de la aplicación. Sin sesión es el de la cookie CSRF; conSesion lo cambia por el de su sesión.
+const tokenPrueba = "token-csrf-de-prueba"
+
+// ejecutar envía una solicitud al enrutador y devuelve la respuesta grabada.
+// Las solicitudes llevan el token CSRF en la cabecera, así que pasan la verificación CSRF.
+func ejecutar(h http.Handler, metodo, ruta, tipo, cuerpo string) *httptest.ResponseRecorder {
+	req := nuevaSolicitud(metodo, ruta, tipo, cuerpo)
+	req.Header.Set(handlers.CabeceraCSRF, tokenPrueba)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// nuevaSolicitud arma una solicitud con la cookie CSRF de la sesión de prueba, sin repetir el token.
+func nuevaSolicitud(metodo, ruta, tipo, cuerpo string) *http.Request {
+	req := httptest.NewRequest(metodo, ruta, strings.NewReader(cuerpo))
+	if tipo != "" {
+		req.Header.Set("Content-Type", tipo)
+	}
+	req.AddCookie(&http.Cookie{Name: handlers.CookieCSRF, Value: tokenPrueba})
+	return req
+}
+
+func TestRutasWeb(t *testing.T) {
+	formulario := url.Values{
+		"Titulo":          {"Ficciones"},
+		"Autor":           {"Jorge Luis Borges"},
+		"AnioPublicacion": {"1944"},
+		"Editorial":       {"Sur"},
+	}.Encode()
+	const tipoFormulario = "application/x-www-form-urlencoded"
+
+	casos := []struct {
+		nombre   string
+		metodo   string
+		ruta     string
+		tipo     string
+		cuerpo   string
+		estado   int
+		contiene string
+	}{
+		{"dashboard", "GET", "/", "", "", http.StatusOK, "Ejemplares Prestados"},
+		{"estaticos", "GET", "/static/style.css", "", "", http.StatusOK, ""},
+		{"listar", "GET", "/libros", "", "", http.StatusOK, "Rayuela"},
+		{"formulario crear", "GET", "/libros/crear", "", "", http.StatusOK, "Crear Nuevo Libro"},
+		{"crear", "POST", "/libros/crear", tipoFormulario, formulario, http.StatusSeeOther, ""},
+		{"crear incompleto", "POST", "/libros/crear", tipoFormulario, "Titulo=X", http.StatusUnprocessableEntity, `<span class="error-campo">Es obligatorio</span>`},
+		{"crear conserva lo escrito", "POST", "/libros/crear", tipoFormulario, "Titulo=El+Aleph&Autor=Borges", http.StatusUnprocessableEntity, `value="El Aleph"`},
+		{"crear año fuera de rango", "POST", "/libros/crear", tipoFormulario, "Titulo=X&Autor=Y&AnioPublicacion=1200&Editorial=Z", http.StatusUnprocessableEntity, "Debe estar entre 1500 y "},
+		{"crear año no numérico", "POST", "/libros/crear", tipoFormulario, "Titulo=X&Autor=Y&AnioPublicacion=mil&Editorial=Z", http.StatusUnprocessableEntity, `value="mil"`},
+		{"formulario editar", "GET", "/libros/editar/1", "", "", http.StatusOK, "Rayuela"},
+		{"formulario editar inexistente", "GET", "/libros/editar/99", "", "", http.StatusNotFound, ""},
+		{"editar sin editorial", "POST", "/libros/editar/1", tipoFormulario, "Titulo=X&Autor=Y&AnioPublicacion=1944", http.StatusUnprocessableEntity, "Es obligatorio"},
+		{"editar conserva lo escrito", "POST", "/libros/editar/1", tipoFormulario, "Titulo=Rayuela+(corregido)&AnioPublicacion=1963", http.StatusUnprocessableEntity, `value="Rayuela (corregido)"`},
+		{"editar inexistente", "POST", "/libros/editar/99", tipoFormulario, formulario, http.StatusNotFound, ""},
+		{"editar", "POST", "/libros/editar/1", tipoFormulario, formulario, http.StatusSeeOther, ""},
+		{"eliminar por GET", "GET", "/libros/eliminar/1", "", "", http.StatusMethodNotAllowed, ""},
+		{"confirmar eliminación", "GET", "/libros/1/eliminar", "", "", http.StatusOK, `<input type="hidden" name="_method" value="DELETE">`},
+		{"confirmar eliminación inexistente", "GET", "/libros/99/eliminar", "", "", http.StatusNotFound, ""},
+		{"eliminar", "POST", "/libros/eliminar/1", tipoFormulario, "_method=DELETE", http.StatusSeeOther, ""},
+	}
+
+	_, h := nuevoServidorPrueba(t)
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			rec := ejecutar(h, c.metodo, c.ruta, c.tipo, c.cuerpo)
+			if rec.Code != c.estado {
+				t.Fatalf("%s %s: estado %d, se esperaba %d (%s)", c.metodo, c.ruta, rec.Code, c.estado, rec.Body.String())
+			}
+			if c.contiene != "" && !strings.Contains(rec.Body.String(), c.contiene) {
+				t.Errorf("%s %s: la respuesta no contiene %q", c.metodo, c.ruta, c.contiene)
+			}
+		})
+	}
+}
+
+func TestRutasAPI(t *testing.T) {
+	const tipoJSON = "application/json"
+	casos := []struct {
+		nombre   string
+		metodo   string
+		ruta     string
+		cuerpo   string
+		estado   int
+		contiene string
+	}{
+		{"listar", "GET", "/api/libros", "", http.StatusOK, `"titulo":"Rayuela","prestado":false`},
+		{"obtener", "GET", "/api/libros/1", "", http.StatusOK, `"Titulo":"Rayuela"`},
+		{"obtener id inválido", "GET", "/api/libros/abc", "", http.StatusBadRequest, ""},
+		{"crear", "POST", "/api/libros", `{"Titulo":"Ficciones","Autor":"Borges","AnioPublicacion":1944,"Editorial":"Sur"}`, http.StatusCreated, "Ficciones"},
+		{"crear ignora prestado", "POST", "/api/libros", `{"Titulo":"El túnel","Autor":"Sabato","AnioPublicacion":1948,"Editorial":"Sur","Prestado":true}`, http.StatusCreated, `"Prestado":false`},
+		{"crear json inválido", "POST", "/api/libros", `{`, http.StatusBadRequest, ""},
+		{"crear sin título", "POST", "/api/libros", `{"Titulo":"  ","Autor":"Borges","AnioPublicacion":1944,"Editorial":"Sur"}`, http.StatusUnprocessableEntity, `"errores":{"Titulo":"Es obligatorio"}`},
+		{"crear año negativo", "POST", "/api/libros", `{"Titulo":"X","Autor":"Y","AnioPublicacion":-5,"Editorial":"Z"}`, http.StatusUnprocessableEntity, `"AnioPublicacion":"Debe estar entre 1500 y `},
+		{"crear autor demasiado largo", "POST", "/api/libros", `{"Titulo":"X","Autor":"` + strings.Repeat("a", 10*1024) + `","AnioPublicacion":1944,"Editorial":"Z"}`, http.StatusUnprocessableEntity, `"Autor":"No puede tener más de 255 caracteres"`},
+		{"actualizar sin datos", "PUT", "/api/libros/1", `{}`, http.StatusUnprocessableEntity, `"Editorial":"Es obligatorio"`},
+		{"actualizar", "PUT", "/api/libros/1", `{"Titulo":"Rayuela (ed. 2)","Autor":"Julio Cortázar","AnioPublicacion":1963,"Editorial":"Alfaguara"}`, http.StatusOK, `"Editorial":"Alfaguara"`},
+		{"eliminar", "DELETE", "/api/libros/1", "", http.StatusNoContent, ""},
+		{"eliminar inexistente", "DELETE", "/api/libros/1", "", http.StatusNotFound, `"detail":"No se pudo eliminar el libro: no se encontró ningún libro con ID 1 para eliminar"`},
+	}
+
+	repos, h := nuevoServidorPrueba(t)
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			rec := ejecutar(h, c.metodo, c.ruta, tipoJSON, c.cuerpo)
+			if rec.Code != c.estado {
+				t.Fatalf("%s %s: estado %d, se esperaba %d (%s)", c.metodo, c.ruta, rec.Code, c.estado, rec.Body.String())
+			}
+			if c.contiene != "" && !strings.Contains(rec.Body.String(), c.contiene) {
+				t.Errorf("%s %s: la respuesta %q no contiene %q", c.metodo, c.ruta, rec.Body.String(), c.contiene)
+			}
+		})
+	}
+
+	libros, _ := repos.libros.GetAllLibros()
+	// El primer ejemplar de un libro nuevo siempre queda disponible.
+	if len(libros) != 2 || libros[0].Titulo != "Ficciones" || libros[1].Prestado {
+		t.Errorf("estado final inesperado del repositorio: %+v", libros)
+	}
+}
+
+// TestApiCrearDevuelveUbicacion verifica que cada POST de creación de la API responda 201 con el recurso
+// guardado, incluido su ID, y la URL del recurso en la cabecera Location.
+func TestApiCrearDevuelveUbicacion(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		ruta      string
+		cuerpo    string
+		ubicacion string
+		contiene  string
+	}{
+		{"libro", "/api/libros", `{"Titulo":"Ficciones","Autor":"Borges","AnioPublicacion":1944,"Editorial":"Sur"}`, "/api/libros/2", `"Id":2,`},
+		{"socio", "/api/socios", `{"Nombre":"Eva"}`, "/api/socios/3", `"Id":3,`},
+		{"ejemplar", "/api/libros/1/ejemplares", `{"CodigoBarras":"XYZ"}`, "/api/ejemplares/3", `"Id":3,`},
+		{"préstamo", "/api/prestamos", `{"LibroId":2,"SocioId":1}`, "/api/prestamos/1", `"Id":1,`},
+		{"reserva", "/api/reservas", `{"LibroId":2,"SocioId":2}`, "/api/reservas/1", `"Id":1,`},
+	}
+
+	_, h := nuevoServidorPrueba(t)
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			rec := ejecutar(h, "POST", c.ruta, "application/json", c.cuerpo)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("POST %s: estado %d (%s)", c.ruta, rec.Code, rec.Body.String())
+			}
+			if ubicacion := rec.Header().Get("Location"); ubicacion != c.ubicacion {
+				t.Errorf("POST %s: Location %q, se esperaba %q", c.ruta, ubicacion, c.ubicacion)
+			}
+			if !strings.Contains(rec.Body.String(), c.contiene) {
+				t.Errorf("POST %s: la respuesta %q no contiene %q", c.ruta, rec.Body.String(), c.contiene)
+			}
+			// La URL de Location lleva al mismo recurso.
+			if rec := ejecutar(h, "GET", c.ubicacion, "", ""); rec.Code != http.StatusOK {
+				t.Errorf("GET %s: estado %d", c.ubicacion, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRutasPrestamos(t *testing.T) {
+	const tipoFormulario = "application/x-www-form-urlencoded"
+	casos := []struct {
+		nombre   string
+		metodo   string
+		ruta     string
+		tipo     string
+		cuerpo   string
+		estado   int
+		contiene string
+	}{
+		{"listar vacío", "GET", "/prestamos", "", "", http.StatusOK, "No hay préstamos"},
+		{"formulario", "GET", "/prestamos/crear?LibroId=1", "", "", http.StatusOK, "Rayuela"},
+		{"formulario muestra socios", "GET", "/prestamos/crear", "", "", http.StatusOK, "Luis Gómez"},
+		{"prestar", "POST", "/prestamos/crear", tipoFormulario, "LibroId=1&SocioId=1&FechaVencimiento=2030-01-15", http.StatusSeeOther, ""},
+		{"prestar no disponible", "POST", "/prestamos/crear", tipoFormulario, "LibroId=1&SocioId=2", http.StatusConflict, ""},
+		{"listar", "GET", "/prestamos", "", "", http.StatusOK, "15/01/2030"},
+		{"historial del socio", "GET", "/socios/1/prestamos", "", "", http.StatusOK, "Rayuela"},
+		{"editar muestra el préstamo", "GET", "/libros/editar/1", "", "", http.StatusOK, "0 de 1 ejemplares disponibles"},
+		{"api listar", "GET", "/api/prestamos", "application/json", "", http.StatusOK, `"Socio":"Ana Torres"`},
+		{"api obtener", "GET", "/api/prestamos/1", "application/json", "", http.StatusOK, `"FechaDevolucion":null`},
+		{"devolver", "POST", "/prestamos/devolver/1", "", "", http.StatusSeeOther, ""},
+		{"devolver dos veces", "POST", "/prestamos/devolver/1", "", "", http.StatusConflict, ""},
+		{"api prestar", "POST", "/api/prestamos", "application/json", `{"LibroId":1,"SocioId":2}`, http.StatusCreated, `"Titulo":"Rayuela"`},
+		{"api prestar no disponible", "POST", "/api/prestamos", "application/json", `{"LibroId":1,"SocioId":1}`, http.StatusConflict, ""},
+		{"api prestar sin socio", "POST", "/api/prestamos", "application/json", `{"LibroId":1}`, http.StatusBadRequest, ""},
+		{"api devolver", "POST", "/api/prestamos/2/devolucion", "application/json", "", http.StatusOK, `"LibroId":1`},
+		{"api devolver dos veces", "POST", "/api/prestamos/2/devolucion", "application/json", "", http.StatusConflict, ""},
+		{"api historial del socio", "GET", "/api/socios/2/prestamos", "application/json", "", http.StatusOK, `"Socio":"Luis Gómez"`},
+	}
+
+	repos, h := nuevoServidorPrueba(t)
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			rec := ejecutar(h, c.metodo, c.ruta, c.tipo, c.cuerpo)
+			if rec.Code != c.estado {
+				t.Fatalf("%s %s: estado %d, se esperaba %d (%s)", c.metodo, c.ruta, rec.Code, c.estado, rec.Body.String())
+			}
+			if c.contiene != "" && !strings.Contains(rec.Body.String(), c.contiene) {
+				t.Errorf("%s %s: la respuesta no contiene %q", c.metodo, c.ruta, c.contiene)
+			}
+		})
+	}
+
+	if libro, _ := repos.libros.GetLibroByID(1); libro.Prestado {
+		t.Error("el libro sigue prestado después de la devolución")
+	}
+}
+
+func TestRutasSocios(t *testing.T) {
+	const tipoFormulario = "application/x-www-form-urlencoded"
+	casos := []struct {
+		nombre   string
+		metodo   string
+		ruta     string
+		tipo     string
+		cuerpo   string
+		estado   int
+		contiene string
+	}{
+		{"listar", "GET", "/socios", "", "", http.StatusOK, "Ana Torres"},
+		{"formulario crear", "GET", "/socios/crear", "", "", http.StatusOK, "Suspendido"},
+		{"crear", "POST", "/socios/crear", tipoFormulario, "Nombre=Marta+Ruiz&Email=marta%40example.com&Estado=Suspendido", http.StatusSeeOther, ""},
+		{"crear sin nombre", "POST", "/socios/crear", tipoFormulario, "Email=x%40example.com", http.StatusBadRequest, ""},
+		{"crear estado inválido", "POST", "/socios/crear", tipoFormulario, "Nombre=X&Estado=Moroso", http.StatusBadRequest, ""},
+		{"formulario editar", "GET", "/socios/editar/3", "", "", http.StatusOK, "marta@example.com"},
+		{"formulario editar inexistente", "GET", "/socios/editar/99", "", "", http.StatusNotFound, ""},
+		{"prestar a socio suspendido", "POST", "/prestamos/crear", tipoFormulario, "LibroId=1&SocioId=3", http.StatusConflict, ""},
+		{"editar", "POST", "/socios/editar/3", tipoFormulario, "Nombre=Marta+Ruiz&Estado=Activo", http.StatusSeeOther, ""},
+		{"prestar a socio reactivado", "POST", "/prestamos/crear", tipoFormulario, "LibroId=1&SocioId=3", http.StatusSeeOther, ""},
+		{"historial", "GET", "/socios/3/prestamos", "", "", http.StatusOK, "Rayuela"},
+		{"historial inexistente", "GET", "/socios/99/prestamos", "", "", http.StatusNotFound, ""},
+		{"confirmar eliminación con préstamos", "GET", "/socios/3/eliminar", "", "", http.StatusOK, "No se puede eliminar"},
+		{"eliminar con préstamos", "POST", "/socios/eliminar/3", tipoFormulario, "_method=DELETE", http.StatusConflict, ""},
+		{"eliminar por GET", "GET", "/socios/eliminar/2", "", "", http.StatusMethodNotAllowed, ""},
+		{"confirmar eliminación", "GET", "/socios/2/eliminar", "", "", http.StatusOK, "Luis Gómez"},
+		{"eliminar", "DELETE", "/socios/eliminar/2", "", "", http.StatusSeeOther, ""},
+		{"api listar", "GET", "/api/socios", "application/json", "", http.StatusOK, `"nombre":"Marta Ruiz"`},
+		{"api obtener", "GET", "/api/socios/1", "application/json", "", http.StatusOK, `"Nombre":"Ana Torres"`},
+		{"api crear", "POST", "/api/socios", "application/json", `{"Nombre":"Eva","Telefono":"555"}`, http.StatusCreated, `"Estado":"Activo"`},
+		{"api crear sin nombre", "POST", "/api/socios", "application/json", `{"Email":"eva@example.com"}`, http.StatusBadRequest, ""},
+		{"api actualizar", "PUT", "/api/socios/1", "application/json", `{"Nombre":"Ana Torres","Estado":"Baja"}`, http.StatusOK, `"Estado":"Baja"`},
+		{"api eliminar con préstamos", "DELETE", "/api/socios/3", "application/json", "", http.StatusConflict, ""},
+		{"api eliminar", "DELETE", "/api/socios/1", "application/json", "", http.StatusNoContent, ""},
+	}
+
+	_, h := nuevoServidorPrueba(t)
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			rec := ejecutar(h, c.metodo, c.ruta, c.tipo, c.cuerpo)
+			if rec.Code != c.estado {
+				t.Fatalf("%s %s: estado %d, se esperaba %d (%s)", c.metodo, c.ruta, rec.Code, c.estado, rec.Body.String())
+			}
+			if c.contiene != "" && !strings.Contains(rec.Body.String(), c.contiene) {
+				t.Errorf("%s %s: la respuesta no contiene %q", c.metodo, c.ruta, c.contiene)
+			}
+		})
+	}
+}
+
+func TestRutasEjemplares(t *testing.T) {
+	const tipoFormulario = "application/x-www-form-urlencoded"
+	casos := []struct {
+		nombre   string
+		metodo   string
+		ruta     string
+		tipo     string
+		cuerpo   string
+		estado   int
+		contiene string
+	}{
+		{"listar", "GET", "/libros/1/ejemplares", "", "", http.StatusOK, "L000001-1"},
+		{"listar libro inexistente", "GET", "/libros/99/ejemplares", "", "", http.StatusNotFound, ""},
+		{"agregar", "POST", "/libros/1/ejemplares", tipoFormulario, "CodigoBarras=L000001-2&Condicion=Nuevo&Ubicacion=Estante+A", http.StatusSeeOther, ""},
+		{"agregar duplicado", "POST", "/libros/1/ejemplares", tipoFormulario, "CodigoBarras=L000001-2", http.StatusConflict, ""},
+		{"agregar condición inválida", "POST", "/libros/1/ejemplares", tipoFormulario, "CodigoBarras=X&Condicion=Roto", http.StatusBadRequest, ""},
+		{"libros muestra las copias", "GET", "/libros", "", "", http.StatusOK, "2 de 2 disponibles"},
+		{"formulario editar", "GET", "/ejemplares/editar/2", "", "", http.StatusOK, "Estante A"},
+		{"editar", "POST", "/ejemplares/editar/2", tipoFormulario, "CodigoBarras=ABC&Condicion=Desgastado&FechaAdquisicion=2020-05-01", http.StatusSeeOther, ""},
+		{"prestar", "POST", "/prestamos/crear", tipoFormulario, "LibroId=1&SocioId=1", http.StatusSeeOther, ""},
+		{"préstamos muestra la copia", "GET", "/prestamos", "", "", http.StatusOK, "L000001-1"},
+		{"confirmar eliminación prestado", "GET", "/ejemplares/1/eliminar", "", "", http.StatusOK, "está prestado"},
+		{"eliminar prestado", "POST", "/ejemplares/eliminar/1", tipoFormulario, "_method=DELETE", http.StatusConflict, ""},
+		{"api listar", "GET", "/api/libros/1/ejemplares", "application/json", "", http.StatusOK, `"CodigoBarras":"ABC"`},
+		{"api listar libro inexistente", "GET", "/api/libros/99/ejemplares", "application/json", "", http.StatusNotFound, ""},
+		{"api crear", "POST", "/api/libros/1/ejemplares", "application/json", `{"CodigoBarras":"XYZ"}`, http.StatusCreated, `"Condicion":"Bueno"`},
+		{"api crear fecha inválida", "POST", "/api/libros/1/ejemplares", "application/json", `{"CodigoBarras":"XYZ-2","FechaAdquisicion":"ayer"}`, http.StatusBadRequest, ""},
+		{"api actualizar duplicado", "PUT", "/api/ejemplares/3", "application/json", `{"CodigoBarras":"ABC"}`, http.StatusConflict, ""},
+		{"api actualizar", "PUT", "/api/ejemplares/3", "application/json", `{"CodigoBarras":"XYZ","Ubicacion":"Depósito"}`, http.StatusOK, `"Titulo":"Rayuela"`},
+		{"api eliminar", "DELETE", "/api/ejemplares/3", "application/json", "", http.StatusNoContent, ""},
+		{"api eliminar prestado", "DELETE", "/api/ejemplares/1", "application/json", "", http.StatusConflict, ""},
+		{"eliminar por GET", "GET", "/ejemplares/eliminar/2", "", "", http.StatusMethodNotAllowed, ""},
+		{"eliminar", "POST", "/ejemplares/eliminar/2", tipoFormulario, "_method=DELETE", http.StatusSeeOther, ""},
+		{"api libro sin copias libres", "GET", "/api/libros/1", "application/json", "", http.StatusOK, `"Prestado":true`},
+	}
+
+	_, h := nuevoServidorPrueba(t)
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			rec := ejecutar(h, c.metodo, c.ruta, c.tipo, c.cuerpo)
+			if rec.Code != c.estado {
+				t.Fatalf("%s %s: estado %d, se esperaba %d (%s)", c.metodo, c.ruta, rec.Code, c.estado, rec.Body.String())
+			}
+			if c.contiene != "" && !strings.Contains(rec.Body.String(), c.contiene) {
+				t.Errorf("%s %s: la respuesta no contiene %q", c.metodo, c.ruta, c.contiene)
+			}
+		})
+	}
+}
+
+func TestRutasMultas(t *testing.T) {
+	repos, h := nuevoServidorPrueba(t)
+	if rec := ejecutar(h, "GET", "/api/multas", "application/json", ""); rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Fatalf("GET /api/multas sin multas: %d %s", rec.Code, rec.Body.String())
+	}
+
+	// Un préstamo vencido hace cuatro días: con la configuración por defecto se cobran dos.
+	if _, err := repos.prestamos.PrestarLibro(1, 1, time.Now().Add(-(3*24*time.Hour + time.Hour))); err != nil {
+		t.Fatalf("PrestarLibro: %v", err)
+	}
+	if _, err := repos.multas.ActualizarAtrasos(time.Now(), models.ConfigMultasPorDefecto()); err != nil {
+		t.Fatalf("ActualizarAtrasos: %v", err)
+	}
+
+	casos := []struct {
+		nombre   string
+		ruta     string
+		contiene string
+	}{
+		{"api multas", "/api/multas", `"DiasAtraso":4,"Monto":1,`},
+		{"dashboard", "/", `<span class="card-title">Atrasados</span>
+            <span class="card-value">1</span>`},
+		{"préstamos", "/prestamos", "Atrasado"},
+		{"historial del socio", "/socios/1/prestamos", "Atrasado"},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			rec := ejecutar(h, "GET", c.ruta, "", "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET %s: estado %d (%s)", c.ruta, rec.Code, rec.Body.String())
+			}
+			// Las plantillas usan finales de línea CRLF, se ignoran para comparar el HTML.
+			if !strings.Contains(strings.ReplaceAll(rec.Body.String(), "\r", ""), c.contiene) {
+				t.Errorf("GET %s: la respuesta no contiene %q", c.ruta, c.contiene)
+			}
+		})
+	}
+}
+
+func TestRutasReservas(t *testing.T) {
+	const tipoFormulario = "application/x-www-form-urlencoded"
+	casos := []struct {
+		nombre   string
+		metodo   string
+		ruta     string
+		tipo     string
+		cuerpo   string
+		estado   int
+		contiene string
+	}{
+		{"listar vacío", "GET", "/reservas", "", "", http.StatusOK, "No hay reservas pendientes"},
+		{"api reservar disponible", "POST", "/api/reservas", "application/json", `{"LibroId":1,"SocioId":2}`, http.StatusConflict, ""},
+		{"prestar", "POST", "/prestamos/crear", tipoFormulario, "LibroId=1&SocioId=1", http.StatusSeeOther, ""},
+		{"libros ofrece reservar", "GET", "/libros", "", "", http.StatusOK, "/reservas/crear?LibroId=1"},
+		{"formulario", "GET", "/reservas/crear?LibroId=1", "", "", http.StatusOK, "Rayuela"},
+		{"reservar", "POST", "/reservas/crear", tipoFormulario, "LibroId=1&SocioId=2", http.StatusSeeOther, ""},
+		{"reservar dos veces", "POST", "/reservas/crear", tipoFormulario, "LibroId=1&SocioId=2", http.StatusConflict, ""},
+		{"reservar sin socio", "POST", "/reservas/crear", tipoFormulario, "LibroId=1", http.StatusBadRequest, ""},
+		{"listar", "GET", "/reservas", "", "", http.StatusOK, "Luis Gómez"},
+		{"api cola del libro", "GET", "/api/libros/1/reservas", "application/json", "", http.StatusOK, `"Estado":"Pendiente"`},
+		{"api cola de libro inexistente", "GET", "/api/libros/99/reservas", "application/json", "", http.StatusNotFound, ""},
+		{"devolver asigna la copia", "POST", "/prestamos/devolver/1", "", "", http.StatusSeeOther, ""},
+		{"api obtener asignada", "GET", "/api/reservas/1", "application/json", "", http.StatusOK, `"Estado":"Asignada"`},
+		{"ejemplares muestra apartado", "GET", "/libros/1/ejemplares", "", "", http.StatusOK, "Apartado"},
+		{"prestar a otro socio", "POST", "/prestamos/crear", tipoFormulario, "LibroId=1&SocioId=1", http.StatusConflict, ""},
+		{"prestar al socio de la reserva", "POST", "/prestamos/crear", tipoFormulario, "LibroId=1&SocioId=2", http.StatusSeeOther, ""},
+		{"api reservas atendidas", "GET", "/api/reservas", "application/json", "", http.StatusOK, "[]"},
+		{"api reservar", "POST", "/api/reservas", "application/json", `{"LibroId":1,"SocioId":1}`, http.StatusCreated, `"Posicion":1`},
+		{"api reservar sin libro", "POST", "/api/reservas", "application/json", `{"SocioId":1}`, http.StatusBadRequest, ""},
+		{"api cancelar", "POST", "/api/reservas/2/cancelacion", "application/json", "", http.StatusOK, `"Estado":"Cancelada"`},
+		{"api cancelar dos veces", "POST", "/api/reservas/2/cancelacion", "application/json", "", http.StatusConflict, ""},
+		{"cancelar inexistente", "POST", "/reservas/cancelar/99", "", "", http.StatusNotFound, ""},
+	}
+
+	_, h := nuevoServidorPrueba(t)
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			rec := ejecutar(h, c.metodo, c.ruta, c.tipo, c.cuerpo)
+			if rec.Code != c.estado {
+				t.Fatalf("%s %s: estado %d, se esperaba %d (%s)", c.metodo, c.ruta, rec.Code, c.estado, rec.Body.String())
+			}
+			if c.contiene != "" && !strings.Contains(rec.Body.String(), c.contiene) {
+				t.Errorf("%s %s: la respuesta no contiene %q", c.metodo, c.ruta, c.contiene)
+			}
+		})
+	}
+}
+
+// Expresiones para leer los formularios de las páginas que genera la aplicación.
+var (
+	reFormulario = regexp.MustCompile(`<form action="([^"]+)" method="POST">`)
+	reInput      = regexp.MustCompile(`<input [^>]*name="([^"]+)"[^>]*value="([^"]*)"`)
+	reSelect     = regexp.MustCompile(`(?s)<select [^>]*name="([^"]+)"[^>]*>(.*?)</select>`)
+	reOpcion     = regexp.MustCompile(`<option value="([^"]*)"\s*(selected)?`)
+)
+
+// formularioRenderizado extrae de una página la acción del formulario y los valores que enviaría el navegador
+// sin que el usuario cambie nada: el value de cada input y la opción seleccionada (o la primera) de cada select.
+// Solo se mira el contenido principal, no el formulario para cerrar la sesión de la barra lateral.
+func formularioRenderizado(t *testing.T, pagina string) (string, url.Values) {
+	t.Helper()
+	if inicio := strings.Index(pagina, `<main class="content-area">`); inicio >= 0 {
+		pagina = pagina[inicio:]
+	}
+	accion := reFormulario.FindStringSubmatch(pagina)
+	if accion == nil {
+		t.Fatalf("la página no tiene un formulario POST:\n%s", pagina)
+	}
+	valores := url.Values{}
+	for _, input := range reInput.FindAllStringSubmatch(pagina, -1) {
+		valores.Set(input[1], html.UnescapeString(input[2]))
+	}
+	for _, sel := range reSelect.FindAllStringSubmatch(pagina, -1) {
+		opciones := reOpcion.FindAllStringSubmatch(sel[2], -1)
+		for i, opcion := range opciones {
+			if i == 0 || opcion[2] != "" {
+				valores.Set(sel[1], html.UnescapeString(opcion[1]))
+			}
+		}
+	}
+	return html.UnescapeString(accion[1]), valores
+}
+
+// enviarFormulario completa los campos indicados del formulario de la página y lo envía como lo haría el navegador.
+// Falla si la página no tiene alguno de los campos, para detectar nombres que no coinciden con los del manejador.
+func enviarFormulario(t *testing.T, h http.Handler, ruta string, cambios map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	pagina := ejecutar(h, "GET", ruta, "", "")
+	if pagina.Code != http.StatusOK {
+		t.Fatalf("GET %s: estado %d", ruta, pagina.Code)
+	}
+	accion, valores := formularioRenderizado(t, pagina.Body.String())
+	for campo, valor := range cambios {
+		if _, ok := valores[campo]; !ok {
+			t.Fatalf("el formulario de %s no tiene el campo %q (tiene %v)", ruta, campo, valores)
+		}
+		valores.Set(campo, valor)
+	}
+	// El token CSRF viaja solo en el campo oculto de la página, igual que desde el navegador.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, nuevaSolicitud("POST", accion, "application/x-www-form-urlencoded", valores.Encode()))
+	return rec
+}
+
+func TestFormulariosLibro(t *testing.T) {
+	repos, h := nuevoServidorPrueba(t)
+
+	// Editar sin cambiar nada reenvía los mismos datos y debe guardarse sin errores.
+	if rec := enviarFormulario(t, h, "/libros/editar/1", nil); rec.Code != http.StatusSeeOther {
+		t.Fatalf("editar sin cambios: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	rec := enviarFormulario(t, h, "/libros/editar/1", map[string]string{
+		models.CampoTitulo: "Rayuela (edición crítica)", models.CampoEditorial: "Cátedra", models.CampoAnioPublicacion: "1963",
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("editar: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if libro, _ := repos.libros.GetLibroByID(1); libro.Titulo != "Rayuela (edición crítica)" || libro.Editorial != "Cátedra" || libro.Autor != "Julio Cortázar" {
+		t.Errorf("después de editar = %+v", libro)
+	}
+
+	rec = enviarFormulario(t, h, "/libros/crear", map[string]string{
+		models.CampoTitulo: "Ficciones", models.CampoAutor: "Jorge Luis Borges", models.CampoAnioPublicacion: "1944",
+		models.CampoEditorial: "Sur",
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("crear: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if libro, err := repos.libros.GetLibroByID(2); err != nil || libro.Titulo != "Ficciones" || libro.Prestado || libro.Disponibles != 1 {
+		t.Errorf("después de crear = %+v, %v", libro, err)
+	}
+
+	// Un formulario rechazado se vuelve a mostrar con lo escrito; corregir solo el campo marcado alcanza para guardarlo.
+	rechazado := ejecutar(h, "POST", "/libros/editar/1", "application/x-www-form-urlencoded", url.Values{
+		models.CampoTitulo: {"Rayuela"}, models.CampoAutor: {"Julio Cortázar"}, models.CampoAnioPublicacion: {"3000"}, models.CampoEditorial: {"Cátedra"},
+	}.Encode())
+	if rechazado.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("editar con año inválido: estado %d", rechazado.Code)
+	}
+	accion, valores := formularioRenderizado(t, rechazado.Body.String())
+	if valores.Get(models.CampoAnioPublicacion) != "3000" || valores.Get(models.CampoAutor) != "Julio Cortázar" {
+		t.Fatalf("el formulario rechazado no conserva lo escrito: %v", valores)
+	}
+	valores.Set(models.CampoAnioPublicacion, "1963")
+	if rec := ejecutar(h, "POST", accion, "application/x-www-form-urlencoded", valores.Encode()); rec.Code != http.StatusSeeOther {
+		t.Fatalf("reenviar el formulario corregido: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if libro, _ := repos.libros.GetLibroByID(1); libro.Titulo != "Rayuela" {
+		t.Errorf("después de corregir = %+v", libro)
+	}
+
+	// La página de confirmación envía la eliminación con el método DELETE.
+	if rec := enviarFormulario(t, h, "/libros/1/eliminar", nil); rec.Code != http.StatusSeeOther {
+		t.Fatalf("confirmar la eliminación: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if _, err := repos.libros.GetLibroByID(1); err == nil {
+		t.Error("el libro sigue existiendo después de confirmar la eliminación")
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	const tipoFormulario = "application/x-www-form-urlencoded"
+	formulario := url.Values{
+		"Titulo": {"Ficciones"}, "Autor": {"Borges"}, "AnioPublicacion": {"1944"}, "Editorial": {"Sur"},
+	}.Encode()
+	repos, _ := nuevoServidorPrueba(t)
+	h := nuevoRouter(repos) // Sin la sesión que agrega nuevoServidorPrueba.
+
+	// enviar hace una solicitud con la cookie de sesión, la cookie CSRF y el campo csrf_token indicados, si no están vacíos.
+	enviar := func(metodo, ruta, tipo, cuerpo, sesion, cookieCSRF, campo string) *httptest.ResponseRecorder {
+		if campo != "" {
+			cuerpo += "&" + url.Values{handlers.CampoCSRF: {campo}}.Encode()
+		}
+		req := httptest.NewRequest(metodo, ruta, strings.NewReader(cuerpo))
+		req.Header.Set("Content-Type", tipo)
+		if sesion != "" {
+			req.AddCookie(&http.Cookie{Name: handlers.CookieSesion, Value: sesion})
+		}
+		if cookieCSRF != "" {
+			req.AddCookie(&http.Cookie{Name: handlers.CookieCSRF, Value: cookieCSRF})
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	cookieDe := func(rec *httptest.ResponseRecorder, nombre string) *http.Cookie {
+		for _, c := range rec.Result().Cookies() {
+			if c.Name == nombre {
+				return c
+			}
+		}
+		return nil
+	}
+
+	// Antes de iniciar sesión, la primera visita entrega un token en una cookie HttpOnly para el formulario de /login.
+	rec := enviar("GET", "/login", "", "", "", "", "")
+	previo := cookieDe(rec, handlers.CookieCSRF)
+	if previo == nil || previo.Value == "" || !previo.HttpOnly {
+		t.Fatalf("la primera visita no entregó la cookie CSRF: %v", rec.Result().Cookies())
+	}
+	if !strings.Contains(rec.Body.String(), `name="csrf_token" value="`+previo.Value+`"`) {
+		t.Fatal("el formulario de /login no incluye el token de la cookie")
+	}
+	login := url.Values{"Usuario": {"bibliotecario"}, "Contrasena": {contrasenaPrueba}}.Encode()
+	if rec := enviar("POST", "/login", tipoFormulario, login, "", previo.Value, "otro-token"); rec.Code != http.StatusForbidden {
+		t.Errorf("login con un token distinto: estado %d", rec.Code)
+	}
+
+	// Un token plantado antes del login (por ejemplo, escrito por otro sitio en la cookie) no sirve después:
+	// el login entrega otra cookie CSRF y, con la sesión, el token se deriva de ella.
+	const plantado = "token-plantado"
+	rec = enviar("POST", "/login", tipoFormulario, login, "", plantado, plantado)
+	sesion := cookieDe(rec, handlers.CookieSesion)
+	if rec.Code != http.StatusSeeOther || sesion == nil {
+		t.Fatalf("login: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if renovada := cookieDe(rec, handlers.CookieCSRF); renovada == nil || renovada.Value == plantado {
+		t.Errorf("el login no renovó la cookie CSRF: %v", rec.Result().Cookies())
+	}
+	token := handlers.TokenCSRFDeSesion(sesion.Value)
+	if token == handlers.TokenCSRFDeSesion("otra-sesion") || strings.Contains(token, sesion.Value) {
+		t.Fatalf("el token CSRF no depende de la sesión o la revela: %q", token)
+	}
+
+	pagina := enviar("GET", "/libros/crear", "", "", sesion.Value, plantado, "")
+	if !strings.Contains(pagina.Body.String(), `name="csrf_token" value="`+token+`"`) || cookieDe(pagina, handlers.CookieCSRF) != nil {
+		t.Fatalf("el formulario no incluye el token de la sesión (cookies %v)", pagina.Result().Cookies())
+	}
+
+	rechazos := []struct {
+		nombre                string
+		sesion, cookie, campo string
+	}{
+		{"sin token", sesion.Value, "", ""},
+		{"con un token distinto", sesion.Value, "", "otro-token"},
+		{"con el token plantado", sesion.Value, plantado, plantado},
+		{"con el token pero sin la sesión", "", "", token},
+	}
+	for _, c := range rechazos {
+		if rec := enviar("POST", "/libros/crear", tipoFormulario, formulario, c.sesion, c.cookie, c.campo); rec.Code != http.StatusForbidden {
+			t.Errorf("POST %s: estado %d", c.nombre, rec.Code)
+		}
+	}
+	if libros, _ := repos.libros.GetAllLibros(); len(libros) != 1 {
+		t.Fatalf("una solicitud rechazada creó un libro: %+v", libros)
+	}
+	if rec := enviar("POST", "/libros/crear", tipoFormulario, formulario, sesion.Value, "", token); rec.Code != http.StatusSeeOther {
+		t.Errorf("POST con el token de la sesión: estado %d", rec.Code)
+	}
+
+	// La API usada con la sesión también pide el token, pero solo en la cabecera; tampoco acepta _method desde un formulario.
+	libroJSON := `{"Titulo":"El túnel","Autor":"Sabato","AnioPublicacion":1948,"Editorial":"Sur"}`
+	if rec := enviar("POST", "/api/libros", "application/json", libroJSON, sesion.Value, "", ""); rec.Code != http.StatusForbidden || rec.Header().Get("Content-Type") != handlers.TipoProblemaJSON {
+		t.Errorf("POST /api/libros con la sesión y sin token: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	req := httptest.NewRequest("POST", "/api/libros", strings.NewReader(libroJSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.CabeceraCSRF, token)
+	req.AddCookie(&http.Cookie{Name: handlers.CookieSesion, Value: sesion.Value})
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("POST /api/libros con la sesión y el token: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	rec = enviar("POST", "/api/libros/1", tipoFormulario, "_method=DELETE", sesion.Value, "", token)
+	if _, err := repos.libros.GetLibroByID(1); err != nil || rec.Code == http.StatusNoContent {
+		t.Errorf("un formulario con _method=DELETE hacia la API: estado %d, %v", rec.Code, err)
+	}
+
+	// Cerrar la sesión también entrega una cookie CSRF nueva para el próximo login.
+	req = httptest.NewRequest("POST", "/logout", nil)
+	req.Header.Set(handlers.CabeceraCSRF, token)
+	req.AddCookie(&http.Cookie{Name: handlers.CookieSesion, Value: sesion.Value})
+	req.AddCookie(&http.Cookie{Name: handlers.CookieCSRF, Value: previo.Value})
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if renovada := cookieDe(rec, handlers.CookieCSRF); rec.Code != http.StatusSeeOther || renovada == nil || renovada.Value == previo.Value {
+		t.Errorf("logout: estado %d, cookies %v", rec.Code, rec.Result().Cookies())
+	}
+}
+
+func TestSesion(t *testing.T) {
+	const tipoFormulario = "application/x-www-form-urlencoded"
+	repos, _ := nuevoServidorPrueba(t)
+	h := nuevoRouter(repos) // Sin la sesión que agrega nuevoServidorPrueba.
+
+	// enviar hace una solicitud que pasa la verificación CSRF, con la cookie de sesión indicada si no está vacía.
+	enviar := func(metodo, ruta, cuerpo, sesion string) *httptest.ResponseRecorder {
+		req := nuevaSolicitud(metodo, ruta, tipoFormulario, cuerpo)
+		req.Header.Set(handlers.CabeceraCSRF, tokenPrueba)
+		if sesion != "" {
+			req.AddCookie(&http.Cookie{Name: handlers.CookieSesion, Value: sesion})
+			req.Header.Set(handlers.CabeceraCSRF, handlers.TokenCSRFDeSesion(sesion))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	login := func(usuario, contrasena, siguiente string) *httptest.ResponseRecorder {
+		return enviar("POST", "/login", url.Values{"Usuario": {usuario}, "Contrasena": {contrasena}, "siguiente": {siguiente}}.Encode(), "")
+	}
+
+	// Sin sesión, la interfaz web envía a /login recordando la página y la API responde 401.
+	if rec := enviar("GET", "/libros?pagina=2", "", ""); rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login?siguiente=%2Flibros%3Fpagina%3D2" {
+		t.Errorf("GET /libros sin sesión: estado %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := enviar("POST", "/libros/eliminar/1", "_method=DELETE", ""); rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login" {
+		t.Errorf("eliminar sin sesión: estado %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := enviar("GET", "/api/libros", "", ""); rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), `"detail":"Debe iniciar sesión`) {
+		t.Errorf("GET /api/libros sin sesión: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if rec := enviar("GET", "/", "", "token-inventado"); rec.Code != http.StatusSeeOther {
+		t.Errorf("GET / con una sesión inventada: estado %d", rec.Code)
+	}
+	if rec := enviar("GET", "/static/style.css", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET /static/style.css sin sesión: estado %d", rec.Code)
+	}
+	if _, err := repos.libros.GetLibroByID(1); err != nil {
+		t.Fatalf("una solicitud sin sesión eliminó el libro: %v", err)
+	}
+
+	// La página de inicio de sesión usa base.html sin las opciones del menú.
+	pagina := enviar("GET", "/login?siguiente=/socios", "", "")
+	if pagina.Code != http.StatusOK || !strings.Contains(pagina.Body.String(), `name="siguiente" value="/socios"`) || strings.Contains(pagina.Body.String(), "Listar Libros") {
+		t.Fatalf("GET /login: estado %d (%s)", pagina.Code, pagina.Body.String())
+	}
+
+	// Las credenciales incorrectas vuelven a mostrar el formulario con el usuario escrito.
+	for _, contrasena := range []string{"incorrecta", ""} {
+		rec := login("bibliotecario", contrasena, "/")
+		if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "Usuario o contraseña incorrectos") || !strings.Contains(rec.Body.String(), `value="bibliotecario"`) {
+			t.Errorf("login con contraseña %q: estado %d", contrasena, rec.Code)
+		}
+	}
+
+	// Un login correcto entrega la cookie de sesión y vuelve a la página pedida, si es local.
+	rec := login("bibliotecario", contrasenaPrueba, "/socios")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/socios" {
+		t.Fatalf("login: estado %d, Location %q (%s)", rec.Code, rec.Header().Get("Location"), rec.Body.String())
+	}
+	var sesion *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == handlers.CookieSesion {
+			sesion = c
+		}
+	}
+	if sesion == nil || sesion.Value == "" || !sesion.HttpOnly || sesion.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("el login no entregó una cookie de sesión segura: %v", rec.Result().Cookies())
+	}
+	if rec := login("bibliotecario", contrasenaPrueba, "//otro-sitio.example/"); rec.Header().Get("Location") != "/" {
+		t.Errorf("login con un destino externo: Location %q", rec.Header().Get("Location"))
+	}
+
+	// Con la sesión se accede a las páginas y a la API, y la barra lateral muestra al usuario.
+	pagina = enviar("GET", "/libros", "", sesion.Value)
+	if pagina.Code != http.StatusOK || !strings.Contains(pagina.Body.String(), "Bibliotecaria de Prueba") || !strings.Contains(pagina.Body.String(), `action="/logout"`) {
+		t.Fatalf("GET /libros con sesión: estado %d", pagina.Code)
+	}
+	if rec := enviar("GET", "/api/libros", "", sesion.Value); rec.Code != http.StatusOK {
+		t.Errorf("GET /api/libros con sesión: estado %d", rec.Code)
+	}
+	if rec := enviar("GET", "/login", "", sesion.Value); rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/" {
+		t.Errorf("GET /login con sesión: estado %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// Cerrar la sesión la invalida en el servidor, no solo en el navegador.
+	if rec := enviar("POST", "/logout", "", sesion.Value); rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login" {
+		t.Fatalf("POST /logout: estado %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := enviar("GET", "/libros", "", sesion.Value); rec.Code != http.StatusSeeOther {
+		t.Errorf("GET /libros con la sesión cerrada: estado %d", rec.Code)
+	}
+}
+
+func TestCrearUsuarioInicial(t *testing.T) {
+	repos := nuevosRepositoriosMemoria()
+
+	// Sin ADMIN_CONTRASENA solo se avisa; no se crea una cuenta con una contraseña conocida.
+	t.Setenv("ADMIN_CONTRASENA", "")
+	if err := crearUsuarioInicial(repos.usuarios); err != nil {
+		t.Fatalf("crearUsuarioInicial sin contraseña: %v", err)
+	}
+	if cantidad, _ := repos.usuarios.ContarUsuarios(); cantidad != 0 {
+		t.Fatalf("se creó un usuario sin ADMIN_CONTRASENA")
+	}
+
+	t.Setenv("ADMIN_CONTRASENA", "clave-inicial")
+	if err := crearUsuarioInicial(repos.usuarios); err != nil {
+		t.Fatalf("crearUsuarioInicial: %v", err)
+	}
+	if admin, err := repos.usuarios.Autenticar(usuarioAdminPorDefecto, "clave-inicial"); err != nil || admin.Rol != models.RolAdmin {
+		t.Errorf("el usuario inicial no puede iniciar sesión como admin: %+v, %v", admin, err)
+	}
+	// Con usuarios registrados no se vuelve a crear ni a cambiar la cuenta.
+	t.Setenv("ADMIN_CONTRASENA", "otra-clave")
+	if err := crearUsuarioInicial(repos.usuarios); err != nil {
+		t.Fatalf("crearUsuarioInicial con usuarios: %v", err)
+	}
+	if cantidad, _ := repos.usuarios.ContarUsuarios(); cantidad != 1 {
+		t.Errorf("ContarUsuarios = %d, se esperaba 1", cantidad)
+	}
+}
+
+// sesionConRol crea un usuario con el rol indicado, le inicia una sesión y devuelve el enrutador con esa sesión.
+func sesionConRol(t *testing.T, repos repositorios, nombre, rol string) (models.Usuario, http.Handler) {
+	t.Helper()
+	usuario, err := repos.usuarios.CrearUsuario(models.Usuario{Usuario: nombre, Nombre: "Usuario " + nombre, Rol: rol}, contrasenaPrueba)
+	if err != nil {
+		t.Fatalf("CrearUsuario(%q): %v", nombre, err)
+	}
+	token, err := repos.sesiones.CrearSesion(usuario.Id)
+	if err != nil {
+		t.Fatalf("CrearSesion: %v", err)
+	}
+	return usuario, conSesion(nuevoRouter(repos), token)
+}
+
+func TestRoles(t *testing.T) {
+	const tipoFormulario = "application/x-www-form-urlencoded"
+	repos, admin := nuevoServidorPrueba(t)
+	_, lector := sesionConRol(t, repos, "lector", models.RolLector)
+	_, bibliotecario := sesionConRol(t, repos, "biblio", models.RolBibliotecario)
+	socio := url.Values{"Nombre": {"Carla Ruiz"}, "Estado": {models.EstadoSocioActivo}}.Encode()
+	prestamo := url.Values{"LibroId": {"1"}, "SocioId": {"1"}}.Encode()
+
+	casos := []struct {
+		nombre string
+		h      http.Handler
+		metodo string
+		ruta   string
+		tipo   string
+		cuerpo string
+		estado int
+	}{
+		{"lector lista libros", lector, "GET", "/libros", "", "", http.StatusOK},
+		{"lector usa la API de lectura", lector, "GET", "/api/libros", "", "", http.StatusOK},
+		{"lector no abre el formulario de libros", lector, "GET", "/libros/crear", "", "", http.StatusForbidden},
+		{"lector no inscribe socios", lector, "POST", "/socios/crear", tipoFormulario, socio, http.StatusForbidden},
+		{"lector no presta", lector, "POST", "/prestamos/crear", tipoFormulario, prestamo, http.StatusForbidden},
+		{"lector no crea por la API", lector, "POST", "/api/libros", "application/json", `{"Titulo":"X"}`, http.StatusForbidden},
+		{"lector no elimina", lector, "POST", "/libros/eliminar/1", tipoFormulario, "_method=DELETE", http.StatusForbidden},
+		{"bibliotecario inscribe socios", bibliotecario, "POST", "/socios/crear", tipoFormulario, socio, http.StatusSeeOther},
+		{"bibliotecario presta", bibliotecario, "POST", "/prestamos/crear", tipoFormulario, prestamo, http.StatusSeeOther},
+		{"bibliotecario no confirma eliminaciones", bibliotecario, "GET", "/socios/3/eliminar", "", "", http.StatusForbidden},
+		{"bibliotecario no elimina", bibliotecario, "POST", "/socios/eliminar/3", tipoFormulario, "_method=DELETE", http.StatusForbidden},
+		{"bibliotecario no elimina por la API", bibliotecario, "DELETE", "/api/socios/3", "", "", http.StatusForbidden},
+		{"bibliotecario no administra usuarios", bibliotecario, "GET", "/usuarios", "", "", http.StatusForbidden},
+		{"admin elimina", admin, "POST", "/socios/eliminar/3", tipoFormulario, "_method=DELETE", http.StatusSeeOther},
+		{"admin administra usuarios", admin, "GET", "/usuarios", "", "", http.StatusOK},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			rec := ejecutar(c.h, c.metodo, c.ruta, c.tipo, c.cuerpo)
+			if rec.Code != c.estado {
+				t.Fatalf("%s %s: estado %d, se esperaba %d (%s)", c.metodo, c.ruta, rec.Code, c.estado, rec.Body.String())
+			}
+		})
+	}
+	if _, err := repos.libros.GetLibroByID(1); err != nil {
+		t.Fatalf("un usuario sin permiso eliminó el libro: %v", err)
+	}
+
+	// La API responde 403 en problem+json.
+	rec := ejecutar(lector, "POST", "/api/libros", "application/json", `{"Titulo":"X"}`)
+	if rec.Header().Get("Content-Type") != handlers.TipoProblemaJSON || !strings.Contains(rec.Body.String(), `"detail":"Su rol no tiene permiso`) {
+		t.Errorf("POST /api/libros como lector: %q (%s)", rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+
+	// El menú y las listas solo muestran las acciones permitidas.
+	enlaces := []struct {
+		h          http.Handler
+		ruta       string
+		visibles   []string
+		invisibles []string
+	}{
+		{lector, "/libros", []string{`href="/libros"`}, []string{`href="/libros/crear"`, `href="/libros/editar/1"`, `href="/libros/1/eliminar"`, `href="/usuarios"`}},
+		{bibliotecario, "/libros", []string{`href="/libros/crear"`, `href="/libros/editar/1"`}, []string{`href="/libros/1/eliminar"`, `href="/usuarios"`}},
+		{admin, "/libros", []string{`href="/libros/crear"`, `href="/libros/1/eliminar"`, `href="/usuarios"`}, nil},
+		{lector, "/prestamos", nil, []string{`href="/prestamos/crear"`, `action="/prestamos/devolver/`}},
+		{bibliotecario, "/prestamos", []string{`href="/prestamos/crear"`, `action="/prestamos/devolver/1"`}, nil},
+	}
+	for _, e := range enlaces {
+		cuerpo := ejecutar(e.h, "GET", e.ruta, "", "").Body.String()
+		for _, v := range e.visibles {
+			if !strings.Contains(cuerpo, v) {
+				t.Errorf("GET %s no muestra %s", e.ruta, v)
+			}
+		}
+		for _, v := range e.invisibles {
+			if strings.Contains(cuerpo, v) {
+				t.Errorf("GET %s muestra %s a un rol sin permiso", e.ruta, v)
+			}
+		}
+	}
+}
+
+func TestRutasUsuarios(t *testing.T) {
+	const tipoFormulario = "application/x-www-form-urlencoded"
+	repos, h := nuevoServidorPrueba(t)
+	alta := func(usuario, rol, contrasena, confirmacion string) string {
+		return url.Values{"Usuario": {usuario}, "Nombre": {"Lucía Vera"}, "Rol": {rol}, "Contrasena": {contrasena}, "ConfirmarContrasena": {confirmacion}}.Encode()
+	}
+
+	casos := []struct {
+		nombre   string
+		metodo   string
+		ruta     string
+		cuerpo   string
+		estado   int
+		contiene string
+	}{
+		{"listar", "GET", "/usuarios", "", http.StatusOK, "bibliotecario"},
+		{"formulario crear", "GET", "/usuarios/crear", "", http.StatusOK, `name="ConfirmarContrasena"`},
+		{"crear", "POST", "/usuarios/crear", alta("lvera", models.RolLector, "secreta123", "secreta123"), http.StatusSeeOther, ""},
+		{"crear duplicado", "POST", "/usuarios/crear", alta("LVera", models.RolLector, "secreta123", "secreta123"), http.StatusUnprocessableEntity, "Ya existe un usuario con ese nombre"},
+		{"crear contraseña corta", "POST", "/usuarios/crear", alta("otro", models.RolLector, "corta", "corta"), http.StatusUnprocessableEntity, "Debe tener al menos 8 caracteres"},
+		{"crear sin confirmar", "POST", "/usuarios/crear", alta("otro", models.RolLector, "secreta123", "secreta124"), http.StatusUnprocessableEntity, "No coincide con la contraseña"},
+		{"crear rol inválido", "POST", "/usuarios/crear", alta("otro", "superusuario", "secreta123", "secreta123"), http.StatusUnprocessableEntity, "Debe ser admin, bibliotecario o lector"},
+		{"cambiar rol", "POST", "/usuarios/2/rol", "Rol=" + models.RolBibliotecario, http.StatusSeeOther, ""},
+		{"cambiar a un rol inválido", "POST", "/usuarios/2/rol", "Rol=superusuario", http.StatusBadRequest, ""},
+		{"cambiar rol inexistente", "POST", "/usuarios/99/rol", "Rol=" + models.RolLector, http.StatusNotFound, ""},
+		{"cambiar el rol propio", "POST", "/usuarios/1/rol", "Rol=" + models.RolLector, http.StatusConflict, ""},
+		{"confirmar eliminación propia", "GET", "/usuarios/1/eliminar", "", http.StatusOK, "No puede eliminar su propia cuenta"},
+		{"eliminar la cuenta propia", "POST", "/usuarios/eliminar/1", "_method=DELETE", http.StatusConflict, ""},
+		{"confirmar eliminación", "GET", "/usuarios/2/eliminar", "", http.StatusOK, "lvera"},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			rec := ejecutar(h, c.metodo, c.ruta, tipoFormulario, c.cuerpo)
+			if rec.Code != c.estado {
+				t.Fatalf("%s %s: estado %d, se esperaba %d (%s)", c.metodo, c.ruta, rec.Code, c.estado, rec.Body.String())
+			}
+			if c.contiene != "" && !strings.Contains(rec.Body.String(), c.contiene) {
+				t.Errorf("%s %s: la respuesta no contiene %q", c.metodo, c.ruta, c.contiene)
+			}
+		})
+	}
+	if usuario, err := repos.usuarios.GetUsuarioByID(2); err != nil || usuario.Rol != models.RolBibliotecario {
+		t.Fatalf("usuario 2 = %+v, %v", usuario, err)
+	}
+	if admin, _ := repos.usuarios.GetUsuarioByID(1); admin.Rol != models.RolAdmin {
+		t.Errorf("el administrador cambió su propio rol a %q", admin.Rol)
+	}
+
+	// El cambio de rol se aplica a la sesión ya iniciada y eliminar la cuenta la cierra.
+	token, _ := repos.sesiones.CrearSesion(2)
+	lvera := conSesion(nuevoRouter(repos), token)
+	if rec := ejecutar(lvera, "GET", "/libros/crear", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET /libros/crear como bibliotecario: estado %d", rec.Code)
+	}
+	ejecutar(h, "POST", "/usuarios/2/rol", tipoFormulario, "Rol="+models.RolLector)
+	if rec := ejecutar(lvera, "GET", "/libros/crear", "", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("GET /libros/crear después de pasar a lector: estado %d", rec.Code)
+	}
+	if rec := ejecutar(h, "POST", "/usuarios/eliminar/2", tipoFormulario, "_method=DELETE"); rec.Code != http.StatusSeeOther {
+		t.Fatalf("eliminar usuario: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if rec := ejecutar(lvera, "GET", "/libros", "", ""); rec.Code != http.StatusSeeOther {
+		t.Errorf("GET /libros con la sesión de un usuario eliminado: estado %d", rec.Code)
+	}
+}
+
+func TestClavesAPI(t *testing.T) {
+	const tipoFormulario = "application/x-www-form-urlencoded"
+	repos, admin := nuevoServidorPrueba(t)
+	h := nuevoRouter(repos) // Sin sesión: los clientes de la API solo envían su credencial.
+
+	// conCredencial hace una solicitud a la API con la cabecera indicada, sin cookies.
+	conCredencial := func(metodo, ruta, cuerpo, cabecera, valor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(metodo, ruta, strings.NewReader(cuerpo))
+		req.Header.Set("Content-Type", "application/json")
+		if cabecera != "" {
+			req.Header.Set(cabecera, valor)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	bearer := func(metodo, ruta, cuerpo, credencial string) *httptest.ResponseRecorder {
+		return conCredencial(metodo, ruta, cuerpo, "Authorization", "Bearer "+credencial)
+	}
+
+	// Solo un administrador crea claves; la clave completa aparece una vez en la respuesta.
+	_, lector := sesionConRol(t, repos, "lector", models.RolLector)
+	if rec := ejecutar(lector, "GET", "/claves-api", "", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("GET /claves-api como lector: estado %d", rec.Code)
+	}
+	if rec := ejecutar(admin, "POST", "/claves-api/crear", tipoFormulario, "Nombre=Sin+alcances"); rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "Elija al menos un alcance") || !strings.Contains(rec.Body.String(), `value="Sin alcances"`) {
+		t.Errorf("crear una clave sin alcances: estado %d", rec.Code)
+	}
+	rec := ejecutar(admin, "POST", "/claves-api/crear", tipoFormulario, url.Values{"Nombre": {"Catálogo"}, "Alcances": {"libros:read", "prestamos:read"}}.Encode())
+	clave := regexp.MustCompile(models.PrefijoClaveAPI + `[A-Za-z0-9_-]+`).FindString(rec.Body.String())
+	if rec.Code != http.StatusCreated || clave == "" || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("crear una clave: estado %d, clave %q", rec.Code, clave)
+	}
+	if pagina := ejecutar(admin, "GET", "/claves-api", "", "").Body.String(); strings.Contains(pagina, clave) || !strings.Contains(pagina, clave[:len(models.PrefijoClaveAPI)+models.LongitudPrefijoVisible]) {
+		t.Error("la lista de claves muestra la clave completa o no muestra su prefijo")
+	}
+
+	// Sin credencial, o con una inválida, la API responde 401 y lo indica en WWW-Authenticate.
+	if rec := bearer("GET", "/api/libros", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Bearer vacío: estado %d", rec.Code)
+	}
+	for nombre, rec := range map[string]*httptest.ResponseRecorder{
+		"sin credencial":      conCredencial("GET", "/api/libros", "", "", ""),
+		"clave inventada":     bearer("GET", "/api/libros", "", models.PrefijoClaveAPI+"inventada"),
+		"token inventado":     bearer("GET", "/api/libros", "", "a.b.c"),
+		"esquema Basic":       conCredencial("GET", "/api/libros", "", "Authorization", "Basic "+clave),
+		"X-API-Key inventada": conCredencial("GET", "/api/libros", "", handlers.CabeceraClaveAPI, "inventada"),
+	} {
+		if rec.Code != http.StatusUnauthorized || !strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), "Bearer") || rec.Header().Get("Content-Type") != handlers.TipoProblemaJSON {
+			t.Errorf("%s: estado %d, WWW-Authenticate %q", nombre, rec.Code, rec.Header().Get("WWW-Authenticate"))
+		}
+	}
+
+	// La clave vale en Authorization: Bearer y en X-API-Key, solo dentro de sus alcances.
+	if rec := bearer("GET", "/api/libros", "", clave); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Rayuela") {
+		t.Errorf("GET /api/libros con la clave: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if rec := conCredencial("GET", "/api/prestamos", "", handlers.CabeceraClaveAPI, clave); rec.Code != http.StatusOK {
+		t.Errorf("GET /api/prestamos con X-API-Key: estado %d", rec.Code)
+	}
+	if rec := bearer("POST", "/api/libros", `{"Titulo":"Ficciones","Autor":"Borges","AnioPublicacion":1944,"Editorial":"Sur"}`, clave); rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "libros:write") {
+		t.Errorf("POST /api/libros con una clave de lectura: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if rec := bearer("DELETE", "/api/libros/1", "", clave); rec.Code != http.StatusForbidden {
+		t.Errorf("DELETE /api/libros/1 con una clave de lectura: estado %d", rec.Code)
+	}
+	if rec := bearer("GET", "/api/socios", "", clave); rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "socios:read") {
+		t.Errorf("GET /api/socios sin el alcance socios:read: estado %d", rec.Code)
+	}
+	// La clave no abre la interfaz web, que sigue pidiendo una sesión.
+	if rec := bearer("GET", "/libros", "", clave); rec.Code != http.StatusSeeOther {
+		t.Errorf("GET /libros con la clave: estado %d", rec.Code)
+	}
+
+	// Una clave con libros:write puede crear y eliminar libros sin sesión ni token CSRF.
+	rec = ejecutar(admin, "POST", "/claves-api/crear", tipoFormulario, "Nombre=Inventario&Alcances=libros:write")
+	escritura := regexp.MustCompile(models.PrefijoClaveAPI + `[A-Za-z0-9_-]+`).FindString(rec.Body.String())
+	if rec := bearer("POST", "/api/libros", `{"Titulo":"Ficciones","Autor":"Borges","AnioPublicacion":1944,"Editorial":"Sur"}`, escritura); rec.Code != http.StatusCreated {
+		t.Errorf("POST /api/libros con libros:write: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if rec := bearer("GET", "/api/libros", "", escritura); rec.Code != http.StatusForbidden {
+		t.Errorf("libros:write no incluye libros:read: estado %d", rec.Code)
+	}
+
+	// La clave se cambia por un token firmado, con todos sus alcances o solo algunos.
+	rec = bearer("POST", "/api/tokens", "", clave)
+	var respuesta handlers.RespuestaToken
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &respuesta) != nil || respuesta.Tipo != "Bearer" || len(respuesta.Alcances) != 2 || !respuesta.Expira.After(time.Now()) {
+		t.Fatalf("POST /api/tokens: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	token := respuesta.Token
+	if rec := bearer("GET", "/api/libros/1", "", token); rec.Code != http.StatusOK {
+		t.Errorf("GET /api/libros/1 con el token: estado %d", rec.Code)
+	}
+	rec = bearer("POST", "/api/tokens", `{"Alcances":["prestamos:read"]}`, clave)
+	if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/tokens con un alcance: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if rec := bearer("GET", "/api/libros", "", respuesta.Token); rec.Code != http.StatusForbidden {
+		t.Errorf("un token de prestamos:read consultó libros: estado %d", rec.Code)
+	}
+	if rec := bearer("POST", "/api/tokens", `{"Alcances":["libros:write"]}`, clave); rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "libros:write") {
+		t.Errorf("pedir un alcance que la clave no tiene: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if rec := bearer("POST", "/api/tokens", "", token); rec.Code != http.StatusForbidden {
+		t.Errorf("un token pidió otro token: estado %d", rec.Code)
+	}
+	if rec := ejecutar(admin, "POST", "/api/tokens", "", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("una sesión pidió un token: estado %d", rec.Code)
+	}
+	partes := strings.Split(token, ".")
+	if rec := bearer("GET", "/api/libros", "", partes[0]+"."+partes[1]+".firma-cambiada"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("token con la firma cambiada: estado %d", rec.Code)
+	}
+
+	// Revocar la clave invalida también sus tokens.
+	if rec := ejecutar(admin, "POST", "/claves-api/revocar/1", tipoFormulario, ""); rec.Code != http.StatusSeeOther {
+		t.Fatalf("revocar la clave: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if rec := ejecutar(admin, "POST", "/claves-api/revocar/99", tipoFormulario, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("revocar una clave inexistente: estado %d", rec.Code)
+	}
+	if rec := bearer("GET", "/api/libros", "", clave); rec.Code != http.StatusUnauthorized {
+		t.Errorf("la clave revocada sigue valiendo: estado %d", rec.Code)
+	}
+	if rec := bearer("GET", "/api/libros", "", token); rec.Code != http.StatusUnauthorized {
+		t.Errorf("el token de una clave revocada sigue valiendo: estado %d", rec.Code)
+	}
+	if pagina := ejecutar(admin, "GET", "/claves-api", "", "").Body.String(); !strings.Contains(pagina, "Revocada el") {
+		t.Error("la lista de claves no muestra la clave revocada")
+	}
+}
+
+// TestProblemasAPI verifica que los errores de la API se informen como problem+json con el tipo de su estado.
+func TestProblemasAPI(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		metodo  string
+		ruta    string
+		cuerpo  string
+		estado  int
+		tipo    string
+		detalle string
+	}{
+		{"id inválido", "GET", "/api/libros/abc", "", http.StatusBadRequest, "/problemas/solicitud-invalida", "El ID de la ruta"},
+		{"json inválido", "POST", "/api/socios", `{`, http.StatusBadRequest, "/problemas/solicitud-invalida", "El cuerpo no es un JSON válido para el socio"},
+		{"datos inválidos", "POST", "/api/libros", `{"Titulo":" "}`, http.StatusUnprocessableEntity, "/problemas/datos-invalidos", "Revise los campos"},
+		{"libro inexistente", "GET", "/api/libros/99/ejemplares", "", http.StatusNotFound, "/problemas/no-encontrado", "libro con ID 99 no encontrado"},
+		{"prestar", "POST", "/api/prestamos", `{"LibroId":1,"SocioId":1}`, http.StatusCreated, "", ""},
+		{"prestar sin copias", "POST", "/api/prestamos", `{"LibroId":1,"SocioId":2}`, http.StatusConflict, "/problemas/conflicto", "No se pudo prestar el libro: "},
+		{"ruta inexistente", "GET", "/api/autores", "", http.StatusNotFound, "/problemas/no-encontrado", "La API no atiende GET /api/autores"},
+	}
+
+	_, h := nuevoServidorPrueba(t)
+	for _, c := range casos {
+		rec := ejecutar(h, c.metodo, c.ruta, "application/json", c.cuerpo)
+		if rec.Code != c.estado {
+			t.Fatalf("%s: estado %d, se esperaba %d (%s)", c.nombre, rec.Code, c.estado, rec.Body.String())
+		}
+		if c.tipo == "" {
+			continue
+		}
+		var problema handlers.Problema
+		if err := json.Unmarshal(rec.Body.Bytes(), &problema); err != nil || rec.Header().Get("Content-Type") != handlers.TipoProblemaJSON {
+			t.Errorf("%s: %q %q: %v", c.nombre, rec.Header().Get("Content-Type"), rec.Body.String(), err)
+			continue
+		}
+		if problema.Type != c.tipo || problema.Status != c.estado || problema.Title == "" || problema.Instance != c.ruta || !strings.Contains(problema.Detail, c.detalle) {
+			t.Errorf("%s: problema %+v", c.nombre, problema)
+		}
+		if c.estado == http.StatusUnprocessableEntity && problema.Errores["Titulo"] != "Es obligatorio" {
+			t.Errorf("%s: errores por campo %v", c.nombre, problema.Errores)
+		}
+	}
+}
+
+// Un error de la base de datos responde 500 sin mostrar el mensaje del controlador al cliente.
+func TestProblemasAPIErrorInterno(t *testing.T) {
+	conexion, err := db.OpenSQLite(filepath.Join(t.TempDir(), "biblioteca.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	defer conexion.Close()
+	if _, err := migraciones.Up(conexion, db.DriverSQLite); err != nil {
+		t.Fatalf("migraciones.Up: %v", err)
+	}
+	repos := nuevosRepositoriosSQL(conexion, db.DriverSQLite)
+	usuario, err := repos.usuarios.CrearUsuario(models.Usuario{Usuario: "bibliotecario", Nombre: "Bibliotecaria de Prueba", Rol: models.RolAdmin}, contrasenaPrueba)
+	if err != nil {
+		t.Fatalf("CrearUsuario: %v", err)
+	}
+	token, _ := repos.sesiones.CrearSesion(usuario.Id)
+	h := conSesion(nuevoRouter(repos), token)
+	if _, err := conexion.Exec("DROP TABLE prestamos"); err != nil {
+		t.Fatalf("DROP TABLE: %v", err)
+	}
+
+	rec := ejecutar(h, "GET", "/api/prestamos", "", "")
+	var problema handlers.Problema
+	if rec.Code != http.StatusInternalServerError || json.Unmarshal(rec.Body.Bytes(), &problema) != nil || problema.Type != "/problemas/error-interno" {
+		t.Fatalf("GET /api/prestamos sin la tabla: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "prestamos:") || strings.Contains(strings.ToLower(rec.Body.String()), "no such table") {
+		t.Errorf("la respuesta muestra el error de la base de datos: %s", rec.Body.String())
+	}
+}
+
+// TestNoEncontrado verifica que un registro o una ruta inexistentes respondan 404: en problem+json en la API
+// y con la página noEncontrado.html en la interfaz web.
+func TestNoEncontrado(t *testing.T) {
+	const tipoFormulario = "application/x-www-form-urlencoded"
+	casos := []struct {
+		nombre   string
+		metodo   string
+		ruta     string
+		tipo     string
+		cuerpo   string
+		contiene string
+	}{
+		{"api libro", "GET", "/api/libros/99", "", "", `"detail":"No se pudo recuperar el libro: libro con ID 99 no encontrado"`},
+		{"api actualizar socio", "PUT", "/api/socios/99", "application/json", `{"Nombre":"Nadie","Estado":"activo"}`, `"type":"/problemas/no-encontrado"`},
+		{"api devolver préstamo", "POST", "/api/prestamos/99/devolucion", "", "", `"type":"/problemas/no-encontrado"`},
+		{"web libro", "GET", "/libros/editar/99", "", "", "libro con ID 99 no encontrado"},
+		{"web socio", "GET", "/socios/99/prestamos", "", "", "socio con ID 99 no encontrado"},
+		{"web devolver", "POST", "/prestamos/devolver/99", tipoFormulario, "", "No se encontró lo que buscaba"},
+		{"web ruta", "GET", "/autores", "", "", "la página /autores no existe"},
+	}
+
+	_, h := nuevoServidorPrueba(t)
+	for _, c := range casos {
+		rec := ejecutar(h, c.metodo, c.ruta, c.tipo, c.cuerpo)
+		if rec.Code != http.StatusNotFound || !strings.Contains(html.UnescapeString(rec.Body.String()), c.contiene) {
+			t.Errorf("%s: estado %d, se esperaba 404 con %q (%s)", c.nombre, rec.Code, c.contiene, rec.Body.String())
+			continue
+		}
+		web := !strings.HasPrefix(c.ruta, "/api/")
+		if web && !strings.Contains(rec.Body.String(), "<h2>No encontrado</h2>") {
+			t.Errorf("%s: no se mostró la página de no encontrado: %s", c.nombre, rec.Body.String())
+		}
+		if !web && rec.Header().Get("Content-Type") != handlers.TipoProblemaJSON {
+			t.Errorf("%s: Content-Type %q", c.nombre, rec.Header().Get("Content-Type"))
+		}
+	}
+
+	// Una ruta inexistente también exige la sesión: no revela qué páginas hay a quien no la inició.
+	repos, _ := nuevoServidorPrueba(t)
+	if rec := ejecutar(nuevoRouter(repos), "GET", "/autores", "", ""); rec.Code != http.StatusSeeOther || !strings.HasPrefix(rec.Header().Get("Location"), handlers.RutaLogin) {
+		t.Errorf("ruta inexistente sin sesión: estado %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+// TestPaginacionAPI verifica los filtros, el orden y los enlaces de paginación de GET /api/libros.
+func TestPaginacionAPI(t *testing.T) {
+	repos, h := nuevoServidorPrueba(t)                                           // Libro 1: Rayuela, de Julio Cortázar (1963).
+	repos.libros.CreateLibro("Jorge Luis Borges", "Ficciones", 1944, "Sur")      // ID 2
+	repos.libros.CreateLibro("Jorge Luis Borges", "El Aleph", 1949, "Losada")    // ID 3
+	repos.libros.CreateLibro("Ernesto Sabato", "El túnel", 1948, "Sur")          // ID 4
+	repos.prestamos.PrestarLibro(4, 1, time.Now().AddDate(0, 0, 7))              // El túnel queda sin copias disponibles.
+	repos.libros.CreateLibro("Adolfo Bioy Casares", "Plan de evasión", 1945, "") // ID 5
+
+	// listar devuelve los IDs de la página y los enlaces de la cabecera Link por su rel.
+	reEnlace := regexp.MustCompile(`<([^>]*)>; rel="(\w+)"`)
+	listar := func(ruta string) (string, string, map[string]string) {
+		t.Helper()
+		rec := ejecutar(h, "GET", ruta, "", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: estado %d (%s)", ruta, rec.Code, rec.Body.String())
+		}
+		var libros []handlers.LibroSimple
+		if err := json.Unmarshal(rec.Body.Bytes(), &libros); err != nil {
+			t.Fatalf("GET %s: %v", ruta, err)
+		}
+		var ids []string
+		for _, libro := range libros {
+			ids = append(ids, strconv.Itoa(libro.Id))
+		}
+		enlaces := map[string]string{}
+		for _, m := range reEnlace.FindAllStringSubmatch(rec.Header().Get("Link"), -1) {
+			enlaces[m[2]] = m[1]
+		}
+		return strings.Join(ids, ","), rec.Header().Get(handlers.CabeceraTotal), enlaces
+	}
+
+	casos := []struct {
+		ruta  string
+		ids   string
+		total string
+	}{
+		{"/api/libros", "1,2,3,4,5", "5"},
+		{"/api/libros?autor=BORGES&orden=AnioPublicacion&direccion=desc", "3,2", "2"},
+		{"/api/libros?editorial=sur&anioDesde=1945", "4", "1"},
+		{"/api/libros?anioDesde=1945&anioHasta=1950&orden=Titulo", "3,4,5", "3"},
+		{"/api/libros?prestado=si", "4", "1"},
+		{"/api/libros?prestado=No&orden=Autor&limite=2", "5,2", "4"},
+	}
+	for _, c := range casos {
+		if ids, total, _ := listar(c.ruta); ids != c.ids || total != c.total {
+			t.Errorf("GET %s: libros %s, total %s; se esperaba %s, total %s", c.ruta, ids, total, c.ids, c.total)
+		}
+	}
+
+	// Sin desplazamiento, los enlaces recorren la lista por cursor y conservan los filtros y el orden.
+	var paginas []string
+	ruta := "/api/libros?orden=Autor&limite=2"
+	for i := 0; ruta != "" && i < 5; i++ {
+		ids, _, enlaces := listar(ruta)
+		paginas = append(paginas, ids)
+		if enlaces["first"] != "/api/libros?limite=2&orden=Autor" {
+			t.Errorf("GET %s: enlace first %q", ruta, enlaces["first"])
+		}
+		ruta = enlaces["next"]
+	}
+	if got := strings.Join(paginas, " | "); got != "5,4 | 2,3 | 1" {
+		t.Errorf("páginas por cursor = %s", got)
+	}
+
+	// Con desplazamiento, los enlaces son first, prev, next y last.
+	_, _, enlaces := listar("/api/libros?limite=2&desplazamiento=1")
+	esperados := map[string]string{
+		"first": "/api/libros?desplazamiento=0&limite=2",
+		"prev":  "/api/libros?desplazamiento=0&limite=2",
+		"next":  "/api/libros?desplazamiento=3&limite=2",
+		"last":  "/api/libros?desplazamiento=4&limite=2",
+	}
+	for rel, enlace := range esperados {
+		if enlaces[rel] != enlace {
+			t.Errorf("enlace %s = %q, se esperaba %q", rel, enlaces[rel], enlace)
+		}
+	}
+	if ids, _, enlaces := listar(enlaces["last"]); ids != "5" || enlaces["next"] != "" {
+		t.Errorf("última página: libros %s, enlaces %v", ids, enlaces)
+	}
+
+	// Una página sin libros es una lista vacía, no null.
+	for _, ruta := range []string{"/api/libros?autor=nadie", "/api/libros?desplazamiento=10"} {
+		if rec := ejecutar(h, "GET", ruta, "", ""); rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+			t.Errorf("GET %s: estado %d, cuerpo %q", ruta, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Los parámetros inválidos se informan uno por uno con 400 Bad Request.
+	rec := ejecutar(h, "GET", "/api/libros?limite=abc&orden=Precio&direccion=arriba&cursor=x", "", "")
+	var problema handlers.Problema
+	if rec.Code != http.StatusBadRequest || json.Unmarshal(rec.Body.Bytes(), &problema) != nil {
+		t.Fatalf("parámetros inválidos: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	for _, parametro := range []string{models.ParametroLimite, models.ParametroOrden, models.ParametroDireccion, models.ParametroCursor} {
+		if problema.Errores[parametro] == "" {
+			t.Errorf("parámetros inválidos: falta el error de %s en %v", parametro, problema.Errores)
+		}
+	}
+}
+
+// TestListaLibrosWeb verifica la paginación, el orden y los filtros de la lista de libros de la interfaz web.
+func TestListaLibrosWeb(t *testing.T) {
+	repos, h := nuevoServidorPrueba(t)                                        // Libro 1: Rayuela, de Julio Cortázar (1963).
+	repos.libros.CreateLibro("Jorge Luis Borges", "Ficciones", 1944, "Sur")   // ID 2
+	repos.libros.CreateLibro("Jorge Luis Borges", "El Aleph", 1949, "Losada") // ID 3
+	repos.libros.CreateLibro("Ernesto Sabato", "El túnel", 1948, "Sur")       // ID 4
+	repos.prestamos.PrestarLibro(4, 1, time.Now().AddDate(0, 0, 7))           // El túnel queda sin copias disponibles.
+
+	casos := []struct {
+		nombre     string
+		ruta       string
+		estado     int
+		contiene   []string
+		noContiene []string
+	}{
+		{"primera página", "/libros?limite=2", http.StatusOK,
+			[]string{"Rayuela", "Ficciones", "Página 1 de 2 (4 libros)", `href="/libros?desplazamiento=2&limite=2"`, `<option value="25" >25</option>`}, []string{"El Aleph", "« Anterior"}},
+		{"última página", "/libros?limite=2&desplazamiento=2", http.StatusOK,
+			[]string{"El Aleph", "El túnel", "Página 2 de 2", `href="/libros?desplazamiento=0&limite=2"`}, []string{"Rayuela", "Siguiente »"}},
+		{"filtros y orden", "/libros?autor=borges&orden=AnioPublicacion&direccion=desc", http.StatusOK,
+			[]string{`value="borges"`, "Año Publicación ▼", `href="/libros?autor=borges&orden=AnioPublicacion"`, `href="/libros?autor=borges&orden=Titulo"`}, []string{"Rayuela"}},
+		{"disponibilidad", "/libros?prestado=Si", http.StatusOK,
+			[]string{"El túnel", `<option value="Si" selected>`}, []string{"Ficciones"}},
+		{"sin resultados", "/libros?editorial=Planeta", http.StatusOK,
+			[]string{"Ningún libro cumple los filtros"}, nil},
+		{"parámetro inválido", "/libros?anioDesde=mil", http.StatusBadRequest,
+			[]string{`value="mil"`, `<span class="error-campo">Debe ser un número entero</span>`}, []string{"Rayuela"}},
+	}
+	for _, c := range casos {
+		rec := ejecutar(h, "GET", c.ruta, "", "")
+		cuerpo := html.UnescapeString(rec.Body.String())
+		if rec.Code != c.estado {
+			t.Errorf("%s: estado %d, se esperaba %d", c.nombre, rec.Code, c.estado)
+		}
+		for _, texto := range c.contiene {
+			if !strings.Contains(cuerpo, texto) {
+				t.Errorf("%s: la página no contiene %q", c.nombre, texto)
+			}
+		}
+		for _, texto := range c.noContiene {
+			if strings.Contains(cuerpo, texto) {
+				t.Errorf("%s: la página contiene %q", c.nombre, texto)
+			}
+		}
+	}
+	if pagina := html.UnescapeString(ejecutar(h, "GET", "/libros?autor=borges&orden=AnioPublicacion&direccion=desc", "", "").Body.String()); strings.Index(pagina, "El Aleph") > strings.Index(pagina, "Ficciones") {
+		t.Error("la lista no está ordenada por año descendente")
+	}
+}
+
+// TestBusquedaLibros verifica la búsqueda de libros de la API y de la interfaz web.
+func TestBusquedaLibros(t *testing.T) {
+	repos, h := nuevoServidorPrueba(t)                                                                         // Libro 1: Rayuela, de Julio Cortázar (1963).
+	repos.libros.CreateLibro("Gabriel García Márquez", "Cien años de soledad", 1967, "Sudamericana")           // ID 2
+	repos.libros.CreateLibro("Gabriel García Márquez", "Crónica de una muerte anunciada", 1981, "Oveja Negra") // ID 3
+	repos.libros.CreateLibro("Mario Goloboff", "Sobre Cortázar", 1998, "Seix Barral")                          // ID 4
+
+	casos := []struct {
+		ruta string
+		ids  string
+	}{
+		{"/api/libros/search?q=garcia+marquez", "2,3"},
+		{"/api/libros/search?q=GARCÍA&limite=1", "2"},
+		{"/api/libros/search?q=cortazar", "4,1"},
+		{"/api/libros/search?q=sudam", "2,1"},
+		{"/api/libros/search?q=borges", ""},
+	}
+	for _, c := range casos {
+		rec := ejecutar(h, "GET", c.ruta, "", "")
+		var encontrados []handlers.LibroEncontrado
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &encontrados) != nil {
+			t.Fatalf("GET %s: estado %d (%s)", c.ruta, rec.Code, rec.Body.String())
+		}
+		var ids []string
+		for _, encontrado := range encontrados {
+			ids = append(ids, strconv.Itoa(encontrado.Id))
+		}
+		if got := strings.Join(ids, ","); got != c.ids {
+			t.Errorf("GET %s: libros %s, se esperaba %s", c.ruta, got, c.ids)
+		}
+	}
+
+	// Sin resultados se responde una lista vacía, y cada resultado trae su relevancia.
+	if cuerpo := ejecutar(h, "GET", "/api/libros/search?q=borges", "", "").Body.String(); strings.TrimSpace(cuerpo) != "[]" {
+		t.Errorf("búsqueda sin resultados = %s", cuerpo)
+	}
+	if cuerpo := ejecutar(h, "GET", "/api/libros/search?q=rayuela", "", "").Body.String(); !strings.Contains(cuerpo, `"titulo":"Rayuela"`) || !strings.Contains(cuerpo, `"relevancia":`) {
+		t.Errorf("búsqueda de Rayuela = %s", cuerpo)
+	}
+
+	// Sin texto, o con un límite inválido, se responde 400 con el error de cada parámetro.
+	for ruta, parametro := range map[string]string{
+		"/api/libros/search":               models.ParametroBusqueda,
+		"/api/libros/search?q=+":           models.ParametroBusqueda,
+		"/api/libros/search?q=a&limite=0":  models.ParametroLimite,
+		"/api/libros/search?q=a&limite=no": models.ParametroLimite,
+	} {
+		rec := ejecutar(h, "GET", ruta, "", "")
+		var problema handlers.Problema
+		if rec.Code != http.StatusBadRequest || json.Unmarshal(rec.Body.Bytes(), &problema) != nil || problema.Errores[parametro] == "" {
+			t.Errorf("GET %s: estado %d (%s), se esperaba un error en %s", ruta, rec.Code, rec.Body.String(), parametro)
+		}
+	}
+
+	// La página web muestra los resultados en orden de relevancia, y el encabezado tiene el cuadro de búsqueda.
+	rec := ejecutar(h, "GET", "/libros/buscar?q=cortazar", "", "")
+	cuerpo := html.UnescapeString(rec.Body.String())
+	if rec.Code != http.StatusOK || !strings.Contains(cuerpo, `value="cortazar"`) || !strings.Contains(cuerpo, "2 resultados") {
+		t.Fatalf("GET /libros/buscar: estado %d\n%s", rec.Code, cuerpo)
+	}
+	if strings.Index(cuerpo, "Sobre Cortázar") > strings.Index(cuerpo, "Rayuela") {
+		t.Error("la página de búsqueda no está ordenada por relevancia")
+	}
+	if !strings.Contains(cuerpo, `action="/libros/buscar"`) || !strings.Contains(cuerpo, `name="q"`) {
+		t.Error("el encabezado no tiene el cuadro de búsqueda")
+	}
+	for ruta, texto := range map[string]string{
+		"/libros/buscar":              "Escriba parte del título",
+		"/libros/buscar?q=borges":     "No hay libros que coincidan con «borges»",
+		"/libros/buscar?q=x&limite=0": "Debe ser un número entero entre 1 y",
+	} {
+		if cuerpo := html.UnescapeString(ejecutar(h, "GET", ruta, "", "").Body.String()); !strings.Contains(cuerpo, texto) {
+			t.Errorf("GET %s: la página no contiene %q", ruta, texto)
+		}
+	}
+}
+
+// TestSugerenciasLibros verifica las sugerencias de la API y los campos que las piden en los formularios.
+func TestSugerenciasLibros(t *testing.T) {
+	repos, h := nuevoServidorPrueba(t)                                                               // Libro 1: Rayuela, de Julio Cortázar (1963, Sudamericana).
+	repos.libros.CreateLibro("Gabriel García Márquez", "Cien años de soledad", 1967, "Sudamericana") // ID 2
+
+	rec := ejecutar(h, "GET", "/api/libros/suggest?q=sud", "", "")
+	var sugerencias []handlers.SugerenciaLibro
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &sugerencias) != nil {
+		t.Fatalf("GET /api/libros/suggest: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	if len(sugerencias) != 1 || sugerencias[0] != (handlers.SugerenciaLibro{Texto: "Sudamericana", Campo: models.CampoEditorial, Libros: 2}) {
+		t.Errorf("sugerencias de sud = %+v", sugerencias)
+	}
+	if cuerpo := ejecutar(h, "GET", "/api/libros/suggest?q=garcia+m&campo=Autor", "", "").Body.String(); !strings.Contains(cuerpo, `"texto":"Gabriel García Márquez"`) {
+		t.Errorf("sugerencias de garcia m = %s", cuerpo)
+	}
+	if cuerpo := ejecutar(h, "GET", "/api/libros/suggest?q=garcia&campo=Titulo", "", "").Body.String(); strings.TrimSpace(cuerpo) != "[]" {
+		t.Errorf("sugerencias sin coincidencias = %s", cuerpo)
+	}
+
+	// Los parámetros inválidos se informan con 400 y el error de cada uno.
+	rec = ejecutar(h, "GET", "/api/libros/suggest?campo=Anio&limite=500", "", "")
+	var problema handlers.Problema
+	if rec.Code != http.StatusBadRequest || json.Unmarshal(rec.Body.Bytes(), &problema) != nil {
+		t.Fatalf("parámetros inválidos: estado %d (%s)", rec.Code, rec.Body.String())
+	}
+	for _, parametro := range []string{models.ParametroBusqueda, models.ParametroCampo, models.ParametroLimite} {
+		if problema.Errores[parametro] == "" {
+			t.Errorf("parámetros inválidos: falta el error de %s en %v", parametro, problema.Errores)
+		}
+	}
+
+	// El formulario de libro y el cuadro de búsqueda del encabezado piden sugerencias.
+	cuerpo := ejecutar(h, "GET", "/libros/crear", "", "").Body.String()
+	for _, texto := range []string{`data-sugerencias="Autor"`, `<datalist id="sugerencias-Editorial">`, `data-sugerencias=""`, `src="/static/sugerencias.js"`} {
+		if !strings.Contains(cuerpo, texto) {
+			t.Errorf("el formulario de libro no contiene %q", texto)
+		}
+	}
+	if strings.Contains(cuerpo, `data-sugerencias="Titulo"`) {
+		t.Error("el título no debería pedir sugerencias")
+	}
+}
+
+// TestPlantillasConCSRF verifica que todos los formularios POST de las plantillas incluyan el token CSRF.
+func TestPlantillasConCSRF(t *testing.T) {
+	archivos, err := filepath.Glob("templates/*.html")
+	if err != nil || len(archivos) == 0 {
+		t.Fatalf("no se encontraron plantillas: %v", err)
+	}
+	reFormularioPOST := regexp.MustCompile(`(?s)<form [^>]*method="POST"[^>]*>(.*?)</form>`)
+	for _, archivo := range archivos {
+		contenido, err := os.ReadFile(archivo)
+		if err != nil {
+			t.Fatalf("ReadFile(%s): %v", archivo, err)
+		}
+		for _, form := range reFormularioPOST.FindAllStringSubmatch(string(contenido), -1) {
+			if !strings.Contains(form[1], "{{ campoCSRF }}") {
+				t.Errorf("%s tiene un formulario POST sin {{ campoCSRF }}:\n%s", archivo, form[0])
+			}
+		}
+	}
+}
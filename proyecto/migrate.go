@@ -0,0 +1,59 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Subcomando "migrate" para aplicar, revertir y consultar las migraciones del esquema.
+*/
+
+package main
+
+import (
+	"database/sql"            // Paquete para trabajar con bases de datos SQL.
+	"fmt"                     // Paquete para formatear cadenas.
+	"os"                      // Paquete para escribir en la salida estándar.
+	"proyecto/db/migraciones" // Migraciones versionadas del esquema.
+	"text/tabwriter"          // Paquete para mostrar el estado en columnas alineadas.
+)
+
+// comandoMigrate ejecuta `proyecto migrate up|down|status` sobre la base de datos recibida.
+//   - up: aplica todas las migraciones pendientes.
+//   - down: revierte la última migración aplicada.
+//   - status: muestra cada migración y si está aplicada.
+func comandoMigrate(database *sql.DB, driver string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("uso: proyecto migrate up|down|status")
+	}
+
+	switch args[0] {
+	case "up":
+		aplicadas, err := migraciones.Up(database, driver)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migraciones aplicadas.\n", aplicadas)
+	case "down":
+		revertida, err := migraciones.Down(database, driver)
+		if err != nil {
+			return err
+		}
+		if !revertida {
+			fmt.Println("No hay migraciones aplicadas para revertir.")
+		}
+	case "status":
+		estados, err := migraciones.Status(database, driver)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNOMBRE\tESTADO")
+		for _, e := range estados {
+			estado := "pendiente"
+			if e.Aplicada {
+				estado = "aplicada el " + e.AplicadaEn.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", e.Version, e.Nombre, estado)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("subcomando desconocido %q, uso: proyecto migrate up|down|status", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo con la búsqueda de libros por texto: normalización sin tildes y el índice invertido en memoria.
+*/
+
+package models
+
+import (
+	"sort"    // Paquete para ordenar los resultados y los términos del índice.
+	"strings" // Paquete para normalizar los textos.
+	"sync"    // Paquete para proteger el índice del acceso concurrente.
+	"unicode" // Paquete para separar los textos en palabras.
+)
+
+// Cantidad de resultados de una búsqueda.
+const (
+	LimiteBusquedaPorDefecto = 20  // Resultados si la búsqueda no indica un límite.
+	LimiteBusquedaMaximo     = 100 // Máximo de resultados que se puede pedir.
+)
+
+// ParametroBusqueda es el parámetro de la URL con el texto que se busca (GET /api/libros/search?q=).
+const ParametroBusqueda = "q"
+
+// ResultadoBusqueda es un libro encontrado por BuscarLibros junto con su relevancia.
+// La escala de la relevancia depende de la implementación: solo sirve para comparar los resultados de una misma búsqueda.
+type ResultadoBusqueda struct {
+	Libro      Libro   // Libro encontrado.
+	Relevancia float64 // Cuanto mayor, mejor coincide el libro con el texto buscado.
+}
+
+// sinTildes reemplaza las letras acentuadas de los idiomas de la colección por su letra base.
+var sinTildes = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ç", "c", "ý", "y", "ÿ", "y",
+)
+
+// NormalizarBusqueda pasa el texto a minúsculas y le quita las tildes, para que "García Márquez",
+// "garcia marquez" y "GARCÍA MÁRQUEZ" se comparen igual.
+func NormalizarBusqueda(texto string) string {
+	return sinTildes.Replace(strings.ToLower(texto))
+}
+
+// palabrasBusqueda separa el texto normalizado en palabras; los signos de puntuación y los espacios
+// son separadores.
+func palabrasBusqueda(texto string) []string {
+	return strings.FieldsFunc(NormalizarBusqueda(texto), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Campos de un libro que indexa la búsqueda, con su peso en la relevancia: una coincidencia en el
+// título vale más que una en el autor, y esta más que una en la editorial.
+const (
+	campoIndiceTitulo = iota
+	campoIndiceAutor
+	campoIndiceEditorial
+	cantidadCamposIndice
+)
+
+// pesosCamposIndice es el peso de cada campo indexado.
+var pesosCamposIndice = [cantidadCamposIndice]float64{3, 2, 1}
+
+// documentoIndice son los textos normalizados de los campos indexados de un libro.
+type documentoIndice [cantidadCamposIndice]string
+
+// IndiceLibros es un índice invertido de los títulos, autores y editoriales de los libros, sin
+// distinguir mayúsculas ni tildes. Lo usan los repositorios que no tienen un índice de texto en la
+// base de datos (en memoria y SQLite). Es seguro para uso concurrente.
+type IndiceLibros struct {
+	mu         sync.Mutex
+	documentos map[int]documentoIndice  // Textos normalizados de cada libro, por su ID.
+	terminos   map[string]map[int]uint8 // Libros en que aparece cada palabra, con los campos como bits (1 << campoIndiceTitulo...).
+	ordenados  []string                 // Palabras del índice en orden, para buscar por prefijo; nil si hay que reconstruirlas.
+}
+
+// NewIndiceLibros crea un índice vacío.
+func NewIndiceLibros() *IndiceLibros {
+	return &IndiceLibros{documentos: make(map[int]documentoIndice), terminos: make(map[string]map[int]uint8)}
+}
+
+// Indexar agrega el libro al índice, o reemplaza sus textos si ya estaba.
+func (idx *IndiceLibros) Indexar(libro Libro) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.quitar(libro.Id)
+	documento := documentoIndice{}
+	for campo, texto := range [cantidadCamposIndice]string{libro.Titulo, libro.Autor, libro.Editorial} {
+		palabras := palabrasBusqueda(texto)
+		documento[campo] = strings.Join(palabras, " ")
+		for _, palabra := range palabras {
+			if idx.terminos[palabra] == nil {
+				idx.terminos[palabra] = make(map[int]uint8)
+				idx.ordenados = nil
+			}
+			idx.terminos[palabra][libro.Id] |= 1 << campo
+		}
+	}
+	idx.documentos[libro.Id] = documento
+}
+
+// Quitar elimina el libro del índice. No hace nada si el libro no estaba indexado.
+func (idx *IndiceLibros) Quitar(Id int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.quitar(Id)
+}
+
+// quitar elimina el libro del índice. Debe llamarse con el mutex tomado.
+func (idx *IndiceLibros) quitar(Id int) {
+	documento, ok := idx.documentos[Id]
+	if !ok {
+		return
+	}
+	for _, texto := range documento {
+		for _, palabra := range strings.Fields(texto) {
+			delete(idx.terminos[palabra], Id)
+			if len(idx.terminos[palabra]) == 0 {
+				delete(idx.terminos, palabra)
+				idx.ordenados = nil
+			}
+		}
+	}
+	delete(idx.documentos, Id)
+}
+
+// resultadoIndice es un libro encontrado en el índice, antes de leer sus datos completos.
+type resultadoIndice struct {
+	Id         int
+	Relevancia float64
+}
+
+// Buscar devuelve hasta limite libros que contienen todas las palabras del texto, de mayor a menor relevancia.
+// Cada palabra puede coincidir completa o como comienzo de una palabra del libro ("garc" encuentra "García"),
+// aunque la coincidencia completa vale el doble. La relevancia suma, para cada palabra buscada, el peso de
+// los campos en que aparece, y un bono si el texto completo aparece tal cual en un campo.
+// A igual relevancia los libros se ordenan por título y luego por ID.
+func (idx *IndiceLibros) Buscar(texto string, limite int) []resultadoIndice {
+	palabras := palabrasBusqueda(texto)
+	if len(palabras) == 0 {
+		return nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	var puntajes map[int]float64
+	for i, palabra := range palabras {
+		parcial := make(map[int]float64)
+		for _, termino := range idx.conPrefijo(palabra) {
+			factor := 0.5
+			if termino == palabra {
+				factor = 1
+			}
+			for id, campos := range idx.terminos[termino] {
+				parcial[id] = max(parcial[id], factor*pesoCampos(campos))
+			}
+		}
+		if i == 0 {
+			puntajes = parcial
+			continue
+		}
+		// Solo siguen los libros que también contienen esta palabra.
+		for id := range puntajes {
+			if puntaje, ok := parcial[id]; ok {
+				puntajes[id] += puntaje
+			} else {
+				delete(puntajes, id)
+			}
+		}
+	}
+
+	frase := strings.Join(palabras, " ")
+	resultados := make([]resultadoIndice, 0, len(puntajes))
+	for id, puntaje := range puntajes {
+		for campo, texto := range idx.documentos[id] {
+			if strings.Contains(texto, frase) {
+				puntaje += pesosCamposIndice[campo]
+			}
+		}
+		resultados = append(resultados, resultadoIndice{Id: id, Relevancia: puntaje})
+	}
+	sort.Slice(resultados, func(i, j int) bool {
+		a, b := resultados[i], resultados[j]
+		if a.Relevancia != b.Relevancia {
+			return a.Relevancia > b.Relevancia
+		}
+		if tituloA, tituloB := idx.documentos[a.Id][campoIndiceTitulo], idx.documentos[b.Id][campoIndiceTitulo]; tituloA != tituloB {
+			return tituloA < tituloB
+		}
+		return a.Id < b.Id
+	})
+	if len(resultados) > limite {
+		resultados = resultados[:limite]
+	}
+	return resultados
+}
+
+// conPrefijo devuelve las palabras del índice que empiezan con el prefijo, incluida la palabra misma.
+// Debe llamarse con el mutex tomado.
+func (idx *IndiceLibros) conPrefijo(prefijo string) []string {
+	if idx.ordenados == nil {
+		idx.ordenados = make([]string, 0, len(idx.terminos))
+		for termino := range idx.terminos {
+			idx.ordenados = append(idx.ordenados, termino)
+		}
+		sort.Strings(idx.ordenados)
+	}
+	var terminos []string
+	for i := sort.SearchStrings(idx.ordenados, prefijo); i < len(idx.ordenados) && strings.HasPrefix(idx.ordenados[i], prefijo); i++ {
+		terminos = append(terminos, idx.ordenados[i])
+	}
+	return terminos
+}
+
+// pesoCampos suma el peso de los campos indicados por los bits.
+func pesoCampos(campos uint8) float64 {
+	peso := 0.0
+	for campo := range cantidadCamposIndice {
+		if campos&(1<<campo) != 0 {
+			peso += pesosCamposIndice[campo]
+		}
+	}
+	return peso
+}
+
+// limiteBusqueda devuelve el límite de resultados que se usa: LimiteBusquedaPorDefecto si es 0 y nunca
+// más que LimiteBusquedaMaximo.
+func limiteBusqueda(limite int) int {
+	if limite <= 0 {
+		return LimiteBusquedaPorDefecto
+	}
+	return min(limite, LimiteBusquedaMaximo)
+}
@@ -0,0 +1,154 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define las claves de API, sus alcances y el repositorio que las guarda.
+*/
+
+package models
+
+import (
+	"errors"  // Paquete para definir errores que los manejadores pueden reconocer.
+	"strings" // Paquete para guardar los alcances como texto.
+	"time"    // Paquete para la fecha de creación y de revocación.
+)
+
+const (
+	// PrefijoClaveAPI es el comienzo de todas las claves de API. Permite distinguirlas de los tokens firmados
+	// y reconocerlas si aparecen por error en un registro o en un repositorio de código.
+	PrefijoClaveAPI = "bib_"
+	// LongitudPrefijoVisible es la cantidad de caracteres de la clave, después de PrefijoClaveAPI,
+	// que se guardan en claro para reconocerla en la lista de claves.
+	LongitudPrefijoVisible = 6
+	// LongitudMaximaNombreClave es la cantidad máxima de caracteres del nombre de una clave, como la columna de MySQL.
+	LongitudMaximaNombreClave = 100
+)
+
+// Nombres de los campos de una clave de API, tal como se envían en el formulario.
+const (
+	CampoNombreClave = "Nombre"
+	CampoAlcances    = "Alcances"
+)
+
+// Alcance limita lo que puede hacer en la API un cliente autenticado con una clave o un token.
+type Alcance string
+
+// Alcances que se verifican en cada ruta de la API. Los de escritura no incluyen los de lectura.
+const (
+	AlcanceLibrosLeer        Alcance = "libros:read"     // Consultar libros y ejemplares.
+	AlcanceLibrosEscribir    Alcance = "libros:write"    // Crear, editar y eliminar libros y ejemplares.
+	AlcanceSociosLeer        Alcance = "socios:read"     // Consultar socios.
+	AlcanceSociosEscribir    Alcance = "socios:write"    // Inscribir, editar y eliminar socios.
+	AlcancePrestamosLeer     Alcance = "prestamos:read"  // Consultar préstamos, multas y reservas.
+	AlcancePrestamosEscribir Alcance = "prestamos:write" // Registrar préstamos, devoluciones y reservas.
+)
+
+// Alcances enumera los alcances válidos, en el orden en que se muestran en el formulario.
+var Alcances = []Alcance{
+	AlcanceLibrosLeer, AlcanceLibrosEscribir,
+	AlcanceSociosLeer, AlcanceSociosEscribir,
+	AlcancePrestamosLeer, AlcancePrestamosEscribir,
+}
+
+// AlcanceValido indica si el alcance recibido es uno de Alcances.
+func AlcanceValido(alcance Alcance) bool {
+	for _, a := range Alcances {
+		if a == alcance {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrCredencialInvalida indica que la clave de API o el token no existen, están mal formados, vencieron
+// o pertenecen a una clave revocada. No se distingue el motivo para no dar pistas a quien prueba claves.
+var ErrCredencialInvalida = errors.New("la clave de API o el token no son válidos")
+
+// ClaveAPI representa una clave con la que un cliente de la API se autentica sin iniciar sesión.
+type ClaveAPI struct {
+	Id              int        // ID único de la clave (clave primaria).
+	Nombre          string     // Descripción del cliente que la usa, por ejemplo "Catálogo público".
+	Prefijo         string     // Comienzo de la clave, para reconocerla; el resto no se guarda.
+	HashClave       string     `json:"-"` // Hash SHA-256 de la clave completa.
+	Alcances        []Alcance  // Lo que puede hacer la clave en la API.
+	FechaCreacion   time.Time  // Fecha de creación, la asigna el repositorio.
+	FechaRevocacion *time.Time // Fecha en que se revocó, nil mientras siga activa.
+}
+
+// Validar revisa los datos de una clave nueva y devuelve un ErroresValidacion con un mensaje por campo inválido.
+func (c ClaveAPI) Validar() error {
+	errores := ErroresValidacion{}
+	validarTexto(errores, CampoNombreClave, c.Nombre, LongitudMaximaNombreClave)
+	if len(c.Alcances) == 0 {
+		errores.Agregar(CampoAlcances, "Elija al menos un alcance")
+	}
+	for _, alcance := range c.Alcances {
+		if !AlcanceValido(alcance) {
+			errores.Agregar(CampoAlcances, "El alcance "+string(alcance)+" no existe")
+		}
+	}
+	return errores.Err()
+}
+
+// Activa indica si la clave no fue revocada.
+func (c ClaveAPI) Activa() bool {
+	return c.FechaRevocacion == nil
+}
+
+// Tiene indica si la clave incluye el alcance recibido.
+func (c ClaveAPI) Tiene(alcance Alcance) bool {
+	return contieneAlcance(c.Alcances, alcance)
+}
+
+// contieneAlcance indica si la lista incluye el alcance recibido.
+func contieneAlcance(alcances []Alcance, alcance Alcance) bool {
+	for _, a := range alcances {
+		if a == alcance {
+			return true
+		}
+	}
+	return false
+}
+
+// nuevaClaveAPI genera una clave aleatoria con PrefijoClaveAPI, su prefijo visible y el hash con que se guarda.
+// Usa el mismo generador que los tokens de sesión: 32 bytes aleatorios en base64.
+func nuevaClaveAPI() (clave string, prefijo string, hash string, err error) {
+	token, _, err := nuevoTokenSesion()
+	if err != nil {
+		return "", "", "", err
+	}
+	clave = PrefijoClaveAPI + token
+	return clave, clave[:len(PrefijoClaveAPI)+LongitudPrefijoVisible], hashToken(clave), nil
+}
+
+// unirAlcances guarda los alcances en una sola columna, separados por espacios.
+func unirAlcances(alcances []Alcance) string {
+	textos := make([]string, len(alcances))
+	for i, alcance := range alcances {
+		textos[i] = string(alcance)
+	}
+	return strings.Join(textos, " ")
+}
+
+// separarAlcances lee los alcances guardados por unirAlcances.
+func separarAlcances(texto string) []Alcance {
+	var alcances []Alcance
+	for _, campo := range strings.Fields(texto) {
+		alcances = append(alcances, Alcance(campo))
+	}
+	return alcances
+}
+
+// ClaveAPIRepository define las operaciones de persistencia disponibles para la entidad ClaveAPI.
+// Las claves no se guardan: el almacenamiento solo conoce su hash y su prefijo.
+type ClaveAPIRepository interface {
+	// GetAllClaves devuelve todas las claves, activas y revocadas, de la más nueva a la más antigua.
+	GetAllClaves() ([]ClaveAPI, error)
+	// CrearClave valida y guarda una clave nueva. Devuelve la clave guardada con su ID y la clave completa,
+	// que solo se conoce en este momento.
+	CrearClave(clave ClaveAPI) (ClaveAPI, string, error)
+	// GetClaveByID devuelve una clave específica por su ID.
+	GetClaveByID(Id int) (ClaveAPI, error)
+	// RevocarClave marca la clave como revocada. No es un error revocar una clave ya revocada.
+	RevocarClave(Id int) error
+	// ClaveActiva devuelve la clave activa que corresponde al texto recibido, o ErrCredencialInvalida.
+	ClaveActiva(clave string) (ClaveAPI, error)
+}
@@ -0,0 +1,103 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación en memoria del repositorio de claves de API, usada en las pruebas y en el modo demo.
+*/
+
+package models
+
+import (
+	"fmt"  // Paquete para formatear cadenas.
+	"sort" // Paquete para ordenar las claves de la más nueva a la más antigua.
+	"time" // Paquete para la fecha de creación y de revocación.
+)
+
+// MemoryClaveAPIRepository implementa ClaveAPIRepository en memoria.
+type MemoryClaveAPIRepository struct {
+	db *MemoriaDB // Almacenamiento en memoria compartido con los demás repositorios.
+}
+
+// NewMemoryClaveAPIRepository crea un repositorio de claves de API sobre el almacenamiento en memoria recibido.
+func NewMemoryClaveAPIRepository(db *MemoriaDB) *MemoryClaveAPIRepository {
+	return &MemoryClaveAPIRepository{db: db}
+}
+
+// GetAllClaves devuelve todas las claves, de la más nueva a la más antigua.
+func (repo *MemoryClaveAPIRepository) GetAllClaves() ([]ClaveAPI, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	var claves []ClaveAPI
+	for _, clave := range repo.db.claves {
+		claves = append(claves, clave)
+	}
+	sort.Slice(claves, func(i, j int) bool { return claves[i].Id > claves[j].Id })
+	return claves, nil
+}
+
+// CrearClave valida la clave, genera su texto y la agrega con el siguiente ID y la fecha actual.
+func (repo *MemoryClaveAPIRepository) CrearClave(clave ClaveAPI) (ClaveAPI, string, error) {
+	if err := clave.Validar(); err != nil {
+		return ClaveAPI{}, "", err
+	}
+	texto, prefijo, hash, err := nuevaClaveAPI()
+	if err != nil {
+		return ClaveAPI{}, "", fmt.Errorf("error al generar la clave de API: %w", err)
+	}
+
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	repo.db.nextClaveId++
+	clave.Id = repo.db.nextClaveId
+	clave.Prefijo = prefijo
+	clave.HashClave = hash
+	// Copia los alcances para que quien llamó no pueda cambiar la clave guardada.
+	clave.Alcances = append([]Alcance(nil), clave.Alcances...)
+	clave.FechaCreacion = time.Now().Truncate(time.Second)
+	clave.FechaRevocacion = nil
+	repo.db.claves[clave.Id] = clave
+	return clave, texto, nil
+}
+
+// GetClaveByID devuelve una clave específica por su ID.
+func (repo *MemoryClaveAPIRepository) GetClaveByID(Id int) (ClaveAPI, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	clave, ok := repo.db.claves[Id]
+	if !ok {
+		return clave, noEncontrado("clave de API con ID %d no encontrada", Id)
+	}
+	return clave, nil
+}
+
+// RevocarClave marca la clave como revocada, conservando la fecha si ya lo estaba.
+func (repo *MemoryClaveAPIRepository) RevocarClave(Id int) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	clave, ok := repo.db.claves[Id]
+	if !ok {
+		return noEncontrado("no se encontró ninguna clave de API con ID %d", Id)
+	}
+	if clave.Activa() {
+		ahora := time.Now().Truncate(time.Second)
+		clave.FechaRevocacion = &ahora
+		repo.db.claves[Id] = clave
+	}
+	return nil
+}
+
+// ClaveActiva busca la clave por el hash del texto recibido y verifica que no esté revocada.
+func (repo *MemoryClaveAPIRepository) ClaveActiva(texto string) (ClaveAPI, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	hash := hashToken(texto)
+	for _, clave := range repo.db.claves {
+		if clave.HashClave == hash && clave.Activa() {
+			return clave, nil
+		}
+	}
+	return ClaveAPI{}, ErrCredencialInvalida
+}
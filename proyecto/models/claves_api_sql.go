@@ -0,0 +1,135 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación del repositorio de claves de API sobre una base de datos SQL (MySQL o SQLite).
+*/
+
+package models
+
+import (
+	"database/sql" // Paquete para interactuar con bases de datos SQL.
+	"fmt"          // Paquete para formatear cadenas.
+	"log"          // Paquete para logging de errores y mensajes.
+	"time"         // Paquete para la fecha de creación y de revocación.
+)
+
+// SQLClaveAPIRepository implementa ClaveAPIRepository usando un pool de conexiones compartido.
+type SQLClaveAPIRepository struct {
+	db *sql.DB // Pool de conexiones a la base de datos.
+}
+
+// NewSQLClaveAPIRepository crea un repositorio de claves de API que usa la conexión recibida.
+func NewSQLClaveAPIRepository(db *sql.DB) *SQLClaveAPIRepository {
+	return &SQLClaveAPIRepository{db: db}
+}
+
+// consultaClaves es el SELECT común de las consultas de claves; se completa con WHERE y ORDER BY.
+const consultaClaves = "SELECT Id, Nombre, Prefijo, HashClave, Alcances, FechaCreacion, FechaRevocacion FROM claves_api"
+
+// escanearClave lee una fila de consultaClaves en una estructura ClaveAPI.
+func escanearClave(fila interface{ Scan(...any) error }) (ClaveAPI, error) {
+	var clave ClaveAPI
+	var alcances string
+	var revocacion sql.NullTime // NULL mientras la clave siga activa.
+	err := fila.Scan(&clave.Id, &clave.Nombre, &clave.Prefijo, &clave.HashClave, &alcances, &clave.FechaCreacion, &revocacion)
+	clave.Alcances = separarAlcances(alcances)
+	if revocacion.Valid {
+		clave.FechaRevocacion = &revocacion.Time
+	}
+	return clave, err
+}
+
+// GetAllClaves consulta la base de datos y devuelve todas las claves, de la más nueva a la más antigua.
+func (repo *SQLClaveAPIRepository) GetAllClaves() ([]ClaveAPI, error) {
+	rows, err := repo.db.Query(consultaClaves + " ORDER BY Id DESC")
+	if err != nil {
+		log.Printf("Error al ejecutar la consulta en GetAllClaves: %v", err)
+		return nil, fmt.Errorf("error al ejecutar la consulta: %w", err)
+	}
+	defer rows.Close()
+
+	var claves []ClaveAPI
+	for rows.Next() {
+		clave, err := escanearClave(rows)
+		if err != nil {
+			log.Printf("Error al escanear los resultados en GetAllClaves: %v", err)
+			return nil, fmt.Errorf("error al escanear los resultados: %w", err)
+		}
+		claves = append(claves, clave)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("Error al procesar los resultados de la base de datos en GetAllClaves: %v", err)
+		return nil, fmt.Errorf("error al procesar los resultados: %w", err)
+	}
+	return claves, nil
+}
+
+// CrearClave valida la clave, genera su texto e inserta su hash con la fecha actual.
+func (repo *SQLClaveAPIRepository) CrearClave(clave ClaveAPI) (ClaveAPI, string, error) {
+	if err := clave.Validar(); err != nil {
+		return ClaveAPI{}, "", err
+	}
+	texto, prefijo, hash, err := nuevaClaveAPI()
+	if err != nil {
+		return ClaveAPI{}, "", fmt.Errorf("error al generar la clave de API: %w", err)
+	}
+
+	clave.Prefijo = prefijo
+	clave.HashClave = hash
+	clave.FechaCreacion = time.Now().Truncate(time.Second)
+	clave.FechaRevocacion = nil
+	resultado, err := repo.db.Exec("INSERT INTO claves_api (Nombre, Prefijo, HashClave, Alcances, FechaCreacion) VALUES (?, ?, ?, ?, ?)",
+		clave.Nombre, clave.Prefijo, clave.HashClave, unirAlcances(clave.Alcances), clave.FechaCreacion)
+	if err != nil {
+		log.Printf("Error al ejecutar la inserción de la clave de API: %v", err)
+		return ClaveAPI{}, "", fmt.Errorf("error al insertar la clave de API: %w", err)
+	}
+	lastInsertId, err := resultado.LastInsertId()
+	if err != nil {
+		log.Printf("Error al obtener el ID de la última clave insertada en CrearClave: %v", err)
+		return ClaveAPI{}, "", fmt.Errorf("error al obtener el ID de la última clave insertada: %w", err)
+	}
+	clave.Id = int(lastInsertId)
+	log.Printf("Clave de API insertada con éxito. ID: %d", lastInsertId)
+	return clave, texto, nil
+}
+
+// GetClaveByID consulta la base de datos y devuelve una clave específica por su ID.
+func (repo *SQLClaveAPIRepository) GetClaveByID(Id int) (ClaveAPI, error) {
+	clave, err := escanearClave(repo.db.QueryRow(consultaClaves+" WHERE Id = ?", Id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return clave, noEncontrado("clave de API con ID %d no encontrada", Id)
+		}
+		log.Printf("Error al escanear la clave de API con ID %d: %v", Id, err)
+		return clave, fmt.Errorf("error al obtener la clave de API: %w", err)
+	}
+	return clave, nil
+}
+
+// RevocarClave marca la clave como revocada, conservando la fecha si ya lo estaba.
+func (repo *SQLClaveAPIRepository) RevocarClave(Id int) error {
+	if _, err := repo.GetClaveByID(Id); err != nil {
+		return noEncontrado("no se encontró ninguna clave de API con ID %d", Id)
+	}
+	_, err := repo.db.Exec("UPDATE claves_api SET FechaRevocacion = ? WHERE Id = ? AND FechaRevocacion IS NULL",
+		time.Now().Truncate(time.Second), Id)
+	if err != nil {
+		log.Printf("Error al revocar la clave de API con ID %d: %v", Id, err)
+		return fmt.Errorf("error al revocar la clave de API: %w", err)
+	}
+	log.Printf("Clave de API con ID %d revocada.", Id)
+	return nil
+}
+
+// ClaveActiva busca la clave por el hash del texto recibido y verifica que no esté revocada.
+func (repo *SQLClaveAPIRepository) ClaveActiva(texto string) (ClaveAPI, error) {
+	clave, err := escanearClave(repo.db.QueryRow(consultaClaves+" WHERE HashClave = ? AND FechaRevocacion IS NULL", hashToken(texto)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ClaveAPI{}, ErrCredencialInvalida
+		}
+		log.Printf("Error al consultar la clave de API: %v", err)
+		return ClaveAPI{}, fmt.Errorf("error al consultar la clave de API: %w", err)
+	}
+	return clave, nil
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// probarClavesAPI verifica el contrato común de ClaveAPIRepository sobre un almacenamiento vacío.
+func probarClavesAPI(t *testing.T, repo ClaveAPIRepository) {
+	t.Helper()
+	var errores ErroresValidacion
+	if _, _, err := repo.CrearClave(ClaveAPI{Nombre: "Sin alcances"}); !errors.As(err, &errores) || errores[CampoAlcances] == "" {
+		t.Errorf("CrearClave sin alcances: %v", err)
+	}
+	if _, _, err := repo.CrearClave(ClaveAPI{Alcances: []Alcance{"libros:admin"}}); !errors.As(err, &errores) || errores[CampoNombreClave] == "" || errores[CampoAlcances] == "" {
+		t.Errorf("CrearClave sin nombre y con un alcance inválido: %v", err)
+	}
+
+	clave, texto, err := repo.CrearClave(ClaveAPI{Nombre: "Catálogo público", Alcances: []Alcance{AlcanceLibrosLeer, AlcanceSociosLeer}})
+	if err != nil || clave.Id != 1 || !clave.Activa() || clave.FechaCreacion.IsZero() {
+		t.Fatalf("CrearClave = %+v, %v", clave, err)
+	}
+	// Solo se guardan el prefijo y el hash; la clave completa se conoce una sola vez.
+	if !strings.HasPrefix(texto, PrefijoClaveAPI) || !strings.HasPrefix(texto, clave.Prefijo) || clave.HashClave == "" || strings.Contains(clave.HashClave, texto) {
+		t.Errorf("CrearClave devolvió la clave %q con prefijo %q y hash %q", texto, clave.Prefijo, clave.HashClave)
+	}
+	otra, textoOtra, _ := repo.CrearClave(ClaveAPI{Nombre: "Mostrador", Alcances: []Alcance{AlcancePrestamosEscribir}})
+	if textoOtra == texto {
+		t.Error("dos claves recibieron el mismo texto")
+	}
+
+	if guardada, err := repo.GetClaveByID(1); err != nil || guardada.Nombre != "Catálogo público" || !guardada.Tiene(AlcanceSociosLeer) || guardada.Tiene(AlcanceLibrosEscribir) {
+		t.Errorf("GetClaveByID(1) = %+v, %v", guardada, err)
+	}
+	if _, err := repo.GetClaveByID(99); err == nil {
+		t.Error("GetClaveByID de una clave inexistente no devolvió error")
+	}
+	if lista, err := repo.GetAllClaves(); err != nil || len(lista) != 2 || lista[0].Id != otra.Id {
+		t.Errorf("GetAllClaves = %+v, %v", lista, err)
+	}
+
+	if activa, err := repo.ClaveActiva(texto); err != nil || activa.Id != 1 {
+		t.Errorf("ClaveActiva = %+v, %v", activa, err)
+	}
+	for _, invalida := range []string{"", PrefijoClaveAPI + "inventada", clave.Prefijo, clave.HashClave} {
+		if _, err := repo.ClaveActiva(invalida); !errors.Is(err, ErrCredencialInvalida) {
+			t.Errorf("ClaveActiva(%q): %v, se esperaba ErrCredencialInvalida", invalida, err)
+		}
+	}
+
+	if err := repo.RevocarClave(1); err != nil {
+		t.Fatalf("RevocarClave: %v", err)
+	}
+	revocada, _ := repo.GetClaveByID(1)
+	if revocada.Activa() {
+		t.Fatal("la clave revocada sigue activa")
+	}
+	if _, err := repo.ClaveActiva(texto); !errors.Is(err, ErrCredencialInvalida) {
+		t.Errorf("ClaveActiva de una clave revocada: %v", err)
+	}
+	if err := repo.RevocarClave(1); err != nil {
+		t.Errorf("RevocarClave de una clave ya revocada: %v", err)
+	}
+	if err := repo.RevocarClave(99); err == nil {
+		t.Error("RevocarClave de una clave inexistente no devolvió error")
+	}
+	if activa, err := repo.ClaveActiva(textoOtra); err != nil || activa.Id != otra.Id {
+		t.Errorf("revocar una clave afectó a otra: %+v, %v", activa, err)
+	}
+}
+
+func TestMemoryClaveAPIRepository(t *testing.T) {
+	probarClavesAPI(t, NewMemoryClaveAPIRepository(NewMemoriaDB()))
+}
+
+func TestSQLClaveAPIRepositorySQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	probarClavesAPI(t, NewSQLClaveAPIRepository(conexion))
+}
+
+func TestFirmadorTokens(t *testing.T) {
+	firmador := NewFirmadorTokens([]byte("secreto-de-prueba"), DuracionTokenAPI)
+	texto, emitido, err := firmador.Emitir(7, []Alcance{AlcanceLibrosLeer, AlcanceLibrosEscribir})
+	if err != nil || strings.Count(texto, ".") != 2 {
+		t.Fatalf("Emitir = %q, %v", texto, err)
+	}
+	token, err := firmador.Verificar(texto)
+	if err != nil || token.ClaveId != 7 || len(token.Alcances) != 2 || token.Alcances[1] != AlcanceLibrosEscribir || !token.Expira.Equal(emitido.Expira) {
+		t.Fatalf("Verificar = %+v, %v", token, err)
+	}
+
+	partes := strings.Split(texto, ".")
+	ajeno, _, _ := NewFirmadorTokens([]byte("otro-secreto"), DuracionTokenAPI).Emitir(7, []Alcance{AlcanceLibrosLeer})
+	vencido, _, _ := NewFirmadorTokens([]byte("secreto-de-prueba"), -time.Minute).Emitir(7, []Alcance{AlcanceLibrosLeer})
+	otroContenido, _, _ := firmador.Emitir(8, []Alcance{AlcanceSociosEscribir})
+	sinFirma := `eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.` + partes[1] + "." // {"alg":"none","typ":"JWT"}
+	casos := map[string]string{
+		"vacío":               "",
+		"mal formado":         "no.es.un.token",
+		"otro secreto":        ajeno,
+		"vencido":             vencido,
+		"contenido cambiado":  partes[0] + "." + strings.Split(otroContenido, ".")[1] + "." + partes[2],
+		"algoritmo none":      sinFirma,
+		"firma de otro token": partes[0] + "." + partes[1] + "." + strings.Split(otroContenido, ".")[2],
+	}
+	for nombre, invalido := range casos {
+		if _, err := firmador.Verificar(invalido); !errors.Is(err, ErrCredencialInvalida) {
+			t.Errorf("Verificar de un token %s: %v, se esperaba ErrCredencialInvalida", nombre, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define la entidad Ejemplar (copia física de un libro) y su repositorio.
+*/
+
+package models
+
+import (
+	"fmt"     // Paquete para formatear cadenas.
+	"strings" // Paquete para normalizar los valores de texto.
+	"time"    // Paquete para la fecha de adquisición.
+)
+
+// Condiciones físicas posibles de un ejemplar.
+const (
+	CondicionNuevo      = "Nuevo"
+	CondicionBueno      = "Bueno"
+	CondicionDesgastado = "Desgastado"
+	CondicionDanado     = "Dañado"
+)
+
+// CondicionesEjemplar enumera las condiciones válidas, en el orden en que se muestran en los formularios.
+var CondicionesEjemplar = []string{CondicionNuevo, CondicionBueno, CondicionDesgastado, CondicionDanado}
+
+// Errores que pueden devolver las operaciones sobre ejemplares.
+var (
+	ErrEjemplarPrestado      = nuevoError(ErrConflict, "el ejemplar está prestado, registre la devolución antes de eliminarlo")
+	ErrCodigoBarrasDuplicado = nuevoError(ErrConflict, "ya existe un ejemplar con ese código de barras")
+	ErrEjemplarReservado     = nuevoError(ErrConflict, "el ejemplar está apartado para una reserva, cancélela antes de eliminarlo")
+)
+
+// Ejemplar representa una copia física de un libro. El libro es la obra (título, autor, editorial)
+// y cada ejemplar es un objeto que se presta, con su propio código de barras y ubicación.
+type Ejemplar struct {
+	Id               int       // ID único del ejemplar (clave primaria).
+	LibroId          int       // ID del libro al que pertenece la copia.
+	Titulo           string    // Título del libro (solo lectura, se obtiene de la tabla libros).
+	CodigoBarras     string    // Código de barras pegado en la copia, único en toda la biblioteca.
+	Ubicacion        string    // Estantería o ubicación donde se guarda la copia.
+	Condicion        string    // Estado físico de la copia (ver CondicionesEjemplar).
+	FechaAdquisicion time.Time // Fecha en que la biblioteca adquirió la copia.
+	Prestado         bool      // Indica si la copia está prestada (solo lectura, lo administran los préstamos).
+	Reservado        bool      // Indica si la copia está apartada para una reserva asignada (solo lectura).
+}
+
+// CondicionEjemplarValida indica si la condición recibida es una de CondicionesEjemplar.
+func CondicionEjemplarValida(condicion string) bool {
+	for _, c := range CondicionesEjemplar {
+		if c == condicion {
+			return true
+		}
+	}
+	return false
+}
+
+// CodigoBarrasSugerido genera el código de barras por defecto del ejemplar número n de un libro,
+// por ejemplo "L000012-1" para la primera copia del libro 12.
+func CodigoBarrasSugerido(LibroId, n int) string {
+	return fmt.Sprintf("L%06d-%d", LibroId, n)
+}
+
+// ParseFechaAdquisicion convierte la fecha "2006-01-02" recibida de un formulario o de la API.
+// Si el valor está vacío, se usa la fecha de hoy.
+func ParseFechaAdquisicion(valor string) (time.Time, error) {
+	valor = strings.TrimSpace(valor)
+	if valor == "" {
+		anio, mes, dia := time.Now().Date()
+		return time.Date(anio, mes, dia, 0, 0, 0, 0, time.Local), nil
+	}
+	fecha, err := time.ParseInLocation("2006-01-02", valor, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fecha de adquisición inválida: %q (use el formato AAAA-MM-DD)", valor)
+	}
+	return fecha, nil
+}
+
+// EjemplarRepository define las operaciones de persistencia para los ejemplares de un libro.
+// El campo Prestado no se modifica aquí: lo actualizan PrestarLibro y DevolverLibro.
+type EjemplarRepository interface {
+	// GetEjemplaresByLibro devuelve las copias de un libro ordenadas por ID.
+	GetEjemplaresByLibro(LibroId int) ([]Ejemplar, error)
+	// GetEjemplarByID devuelve un ejemplar específico por su ID.
+	GetEjemplarByID(Id int) (Ejemplar, error)
+	// CreateEjemplar agrega una copia a un libro existente y la devuelve con el ID asignado y el título del libro.
+	// Si el libro tiene reservas pendientes, la copia queda apartada para la más antigua (Reservado).
+	// Devuelve ErrCodigoBarrasDuplicado si el código ya está en uso.
+	CreateEjemplar(ejemplar Ejemplar) (Ejemplar, error)
+	// UpdateEjemplar actualiza el código de barras, la ubicación, la condición y la fecha de adquisición.
+	UpdateEjemplar(ejemplar Ejemplar) error
+	// DeleteEjemplar elimina una copia que no está prestada, junto con su historial de préstamos.
+	// Devuelve ErrEjemplarPrestado si la copia está prestada y ErrEjemplarReservado si está apartada para una reserva.
+	DeleteEjemplar(Id int) error
+}
@@ -0,0 +1,136 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación en memoria del repositorio de ejemplares, usada en las pruebas y en el modo demo.
+*/
+
+package models
+
+import (
+	"sort" // Paquete para ordenar los ejemplares por su ID.
+	"time" // Paquete para el plazo de la reserva que recibe una copia nueva.
+)
+
+// MemoryEjemplarRepository implementa EjemplarRepository en memoria.
+type MemoryEjemplarRepository struct {
+	db *MemoriaDB // Almacenamiento en memoria compartido con los demás repositorios.
+}
+
+// NewMemoryEjemplarRepository crea un repositorio de ejemplares sobre el almacenamiento en memoria recibido.
+func NewMemoryEjemplarRepository(db *MemoriaDB) *MemoryEjemplarRepository {
+	return &MemoryEjemplarRepository{db: db}
+}
+
+// conTitulo completa el título del libro del ejemplar y si está apartado para una reserva, como la versión SQL.
+// Debe llamarse con el mutex de MemoriaDB tomado.
+func (repo *MemoryEjemplarRepository) conTitulo(ejemplar Ejemplar) Ejemplar {
+	ejemplar.Titulo = repo.db.libros[ejemplar.LibroId].Titulo
+	ejemplar.Reservado = repo.db.ejemplarApartado(ejemplar.Id)
+	return ejemplar
+}
+
+// codigoEnUso indica si otro ejemplar distinto de excluirId ya usa el código de barras.
+// Debe llamarse con el mutex de MemoriaDB tomado.
+func (repo *MemoryEjemplarRepository) codigoEnUso(CodigoBarras string, excluirId int) bool {
+	for _, ejemplar := range repo.db.ejemplares {
+		if ejemplar.CodigoBarras == CodigoBarras && ejemplar.Id != excluirId {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEjemplaresByLibro devuelve las copias de un libro ordenadas por ID.
+func (repo *MemoryEjemplarRepository) GetEjemplaresByLibro(LibroId int) ([]Ejemplar, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	var ejemplares []Ejemplar
+	for _, ejemplar := range repo.db.ejemplares {
+		if ejemplar.LibroId == LibroId {
+			ejemplares = append(ejemplares, repo.conTitulo(ejemplar))
+		}
+	}
+	sort.Slice(ejemplares, func(i, j int) bool { return ejemplares[i].Id < ejemplares[j].Id })
+	return ejemplares, nil
+}
+
+// GetEjemplarByID devuelve un ejemplar específico por su ID.
+func (repo *MemoryEjemplarRepository) GetEjemplarByID(Id int) (Ejemplar, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	ejemplar, ok := repo.db.ejemplares[Id]
+	if !ok {
+		return ejemplar, noEncontrado("ejemplar con ID %d no encontrado", Id)
+	}
+	return repo.conTitulo(ejemplar), nil
+}
+
+// CreateEjemplar agrega una copia a un libro existente asignándole el siguiente ID.
+func (repo *MemoryEjemplarRepository) CreateEjemplar(ejemplar Ejemplar) (Ejemplar, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if _, ok := repo.db.libros[ejemplar.LibroId]; !ok {
+		return Ejemplar{}, noEncontrado("libro con ID %d no encontrado", ejemplar.LibroId)
+	}
+	if repo.codigoEnUso(ejemplar.CodigoBarras, 0) {
+		return Ejemplar{}, ErrCodigoBarrasDuplicado
+	}
+
+	repo.db.nextEjemplarId++
+	ejemplar.Id = repo.db.nextEjemplarId
+	ejemplar.Titulo = ""
+	ejemplar.Prestado = false // Una copia nueva nunca empieza prestada.
+	repo.db.ejemplares[ejemplar.Id] = ejemplar
+	// Si el libro tiene reservas pendientes, la copia queda apartada para la más antigua, como en la versión SQL.
+	repo.db.asignarSiguienteReserva(ejemplar.LibroId, ejemplar.Id, time.Now())
+	return repo.conTitulo(ejemplar), nil
+}
+
+// UpdateEjemplar actualiza los datos de una copia conservando su libro y su estado de préstamo.
+// Igual que el UPDATE de SQL, si el ejemplar no existe no se modifica nada y no se devuelve error.
+func (repo *MemoryEjemplarRepository) UpdateEjemplar(ejemplar Ejemplar) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if repo.codigoEnUso(ejemplar.CodigoBarras, ejemplar.Id) {
+		return ErrCodigoBarrasDuplicado
+	}
+	if actual, ok := repo.db.ejemplares[ejemplar.Id]; ok {
+		actual.CodigoBarras = ejemplar.CodigoBarras
+		actual.Ubicacion = ejemplar.Ubicacion
+		actual.Condicion = ejemplar.Condicion
+		actual.FechaAdquisicion = ejemplar.FechaAdquisicion
+		repo.db.ejemplares[ejemplar.Id] = actual
+	}
+	return nil
+}
+
+// DeleteEjemplar elimina una copia que no está prestada, junto con su historial de préstamos.
+func (repo *MemoryEjemplarRepository) DeleteEjemplar(Id int) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	ejemplar, ok := repo.db.ejemplares[Id]
+	if !ok {
+		return noEncontrado("no se encontró ningún ejemplar con ID %d para eliminar", Id)
+	}
+	if ejemplar.Prestado {
+		return ErrEjemplarPrestado
+	}
+	if repo.db.ejemplarApartado(Id) {
+		return ErrEjemplarReservado
+	}
+	delete(repo.db.ejemplares, Id)
+	repo.db.eliminarPrestamosSi(func(p Prestamo) bool { return p.EjemplarId == Id })
+
+	// Emula el ON DELETE SET NULL de las reservas cerradas que apuntaban a la copia.
+	for id, reserva := range repo.db.reservas {
+		if reserva.EjemplarId == Id {
+			reserva.EjemplarId = 0
+			repo.db.reservas[id] = reserva
+		}
+	}
+	return nil
+}
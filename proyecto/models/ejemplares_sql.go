@@ -0,0 +1,216 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación del repositorio de ejemplares sobre una base de datos SQL (MySQL o SQLite).
+*/
+
+package models
+
+import (
+	"database/sql" // Paquete para interactuar con bases de datos SQL.
+	"errors"       // Paquete para reconocer los errores de los drivers.
+	"fmt"          // Paquete para formatear cadenas.
+	"log"          // Paquete para logging de errores y mensajes.
+	"time"         // Paquete para el plazo de la reserva que recibe una copia nueva.
+
+	"github.com/go-sql-driver/mysql" // Driver de MySQL, para reconocer sus códigos de error.
+	"modernc.org/sqlite"             // Driver de SQLite, para reconocer sus códigos de error.
+	sqlite3 "modernc.org/sqlite/lib" // Constantes de los códigos de error de SQLite.
+)
+
+// codigoMySQLDuplicado es el código de error de MySQL para una fila que repite una clave UNIQUE (ER_DUP_ENTRY).
+const codigoMySQLDuplicado = 1062
+
+// consultaEjemplares selecciona los ejemplares junto con el título de su libro y si están apartados para una reserva.
+const consultaEjemplares = `SELECT e.Id, e.LibroId, l.Titulo, e.CodigoBarras, e.Ubicacion, e.Condicion, e.FechaAdquisicion, e.Prestado,
+	` + ejemplarApartado + `
+	FROM ejemplares e JOIN libros l ON l.Id = e.LibroId`
+
+// SQLEjemplarRepository implementa EjemplarRepository usando un pool de conexiones compartido.
+type SQLEjemplarRepository struct {
+	db *sql.DB // Pool de conexiones a la base de datos.
+}
+
+// NewSQLEjemplarRepository crea un repositorio de ejemplares que usa la conexión recibida.
+func NewSQLEjemplarRepository(db *sql.DB) *SQLEjemplarRepository {
+	return &SQLEjemplarRepository{db: db}
+}
+
+// escanearEjemplar lee una fila de consultaEjemplares en una estructura Ejemplar.
+func escanearEjemplar(fila interface{ Scan(...any) error }) (Ejemplar, error) {
+	var ejemplar Ejemplar
+	err := fila.Scan(&ejemplar.Id, &ejemplar.LibroId, &ejemplar.Titulo, &ejemplar.CodigoBarras,
+		&ejemplar.Ubicacion, &ejemplar.Condicion, &ejemplar.FechaAdquisicion, &ejemplar.Prestado, &ejemplar.Reservado)
+	return ejemplar, err
+}
+
+// GetEjemplaresByLibro consulta la base de datos y devuelve las copias de un libro ordenadas por ID.
+func (repo *SQLEjemplarRepository) GetEjemplaresByLibro(LibroId int) ([]Ejemplar, error) {
+	rows, err := repo.db.Query(consultaEjemplares+" WHERE e.LibroId = ? ORDER BY e.Id", LibroId)
+	if err != nil {
+		log.Printf("Error al ejecutar la consulta en GetEjemplaresByLibro: %v", err)
+		return nil, fmt.Errorf("error al ejecutar la consulta: %w", err)
+	}
+	defer rows.Close()
+
+	var ejemplares []Ejemplar
+	for rows.Next() {
+		ejemplar, err := escanearEjemplar(rows)
+		if err != nil {
+			log.Printf("Error al escanear los resultados en GetEjemplaresByLibro: %v", err)
+			return nil, fmt.Errorf("error al escanear los resultados: %w", err)
+		}
+		ejemplares = append(ejemplares, ejemplar)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("Error al procesar los resultados de la base de datos en GetEjemplaresByLibro: %v", err)
+		return nil, fmt.Errorf("error al procesar los resultados: %w", err)
+	}
+	return ejemplares, nil
+}
+
+// GetEjemplarByID consulta la base de datos y devuelve un ejemplar específico por su ID.
+func (repo *SQLEjemplarRepository) GetEjemplarByID(Id int) (Ejemplar, error) {
+	ejemplar, err := escanearEjemplar(repo.db.QueryRow(consultaEjemplares+" WHERE e.Id = ?", Id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ejemplar, noEncontrado("ejemplar con ID %d no encontrado", Id)
+		}
+		log.Printf("Error al escanear el ejemplar con ID %d: %v", Id, err)
+		return ejemplar, fmt.Errorf("error al obtener el ejemplar: %w", err)
+	}
+	return ejemplar, nil
+}
+
+// codigoEnUso indica si otro ejemplar distinto de excluirId ya usa el código de barras. consulta es la conexión
+// del repositorio o la transacción en curso.
+// La restricción UNIQUE de la tabla sigue siendo la garantía final: si otra solicitud guarda el mismo código
+// entre esta consulta y el INSERT o el UPDATE, el error de la restricción también se informa como
+// ErrCodigoBarrasDuplicado (ver violaRestriccionUnica).
+func codigoEnUso(consulta interface {
+	QueryRow(query string, args ...any) *sql.Row
+}, CodigoBarras string, excluirId int) (bool, error) {
+	var cantidad int
+	err := consulta.QueryRow("SELECT COUNT(*) FROM ejemplares WHERE CodigoBarras = ? AND Id <> ?", CodigoBarras, excluirId).Scan(&cantidad)
+	if err != nil {
+		return false, fmt.Errorf("error al consultar el código de barras: %w", err)
+	}
+	return cantidad > 0, nil
+}
+
+// CreateEjemplar inserta una nueva copia de un libro existente dentro de una transacción con el libro bloqueado.
+// Si el libro tiene reservas pendientes, la copia nueva queda apartada para la más antigua en lugar de ir al
+// estante, donde la tomaría el próximo préstamo sin respetar la cola.
+func (repo *SQLEjemplarRepository) CreateEjemplar(ejemplar Ejemplar) (Ejemplar, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return Ejemplar{}, fmt.Errorf("error al iniciar la transacción: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya se confirmó.
+
+	if err := bloquearLibro(tx, ejemplar.LibroId); err != nil {
+		return Ejemplar{}, err
+	}
+	// El título del libro también se devuelve en el ejemplar creado, como en GetEjemplarByID.
+	if err := tx.QueryRow("SELECT Titulo FROM libros WHERE Id = ?", ejemplar.LibroId).Scan(&ejemplar.Titulo); err != nil {
+		return Ejemplar{}, fmt.Errorf("error al consultar el libro: %w", err)
+	}
+	if enUso, err := codigoEnUso(tx, ejemplar.CodigoBarras, 0); err != nil {
+		return Ejemplar{}, err
+	} else if enUso {
+		return Ejemplar{}, ErrCodigoBarrasDuplicado
+	}
+
+	resultado, err := tx.Exec("INSERT INTO ejemplares (LibroId, CodigoBarras, Ubicacion, Condicion, FechaAdquisicion, Prestado) VALUES (?, ?, ?, ?, ?, FALSE)",
+		ejemplar.LibroId, ejemplar.CodigoBarras, ejemplar.Ubicacion, ejemplar.Condicion, ejemplar.FechaAdquisicion)
+	if violaRestriccionUnica(err) {
+		return Ejemplar{}, ErrCodigoBarrasDuplicado
+	}
+	if err != nil {
+		log.Printf("Error al ejecutar la inserción del ejemplar: %v", err)
+		return Ejemplar{}, fmt.Errorf("error al insertar el ejemplar: %w", err)
+	}
+	lastInsertId, err := resultado.LastInsertId()
+	if err != nil {
+		log.Printf("Error al obtener el ID del último ejemplar insertado en CreateEjemplar: %v", err)
+		return Ejemplar{}, fmt.Errorf("error al obtener el ID del último ejemplar insertado: %w", err)
+	}
+	ejemplar.Id = int(lastInsertId)
+
+	if err := asignarSiguienteReserva(tx, ejemplar.LibroId, ejemplar.Id, time.Now()); err != nil {
+		return Ejemplar{}, err
+	}
+	ejemplar.Prestado = false // Una copia nueva nunca empieza prestada.
+	if err := tx.QueryRow("SELECT "+ejemplarApartado+" FROM ejemplares e WHERE e.Id = ?", ejemplar.Id).Scan(&ejemplar.Reservado); err != nil {
+		return Ejemplar{}, fmt.Errorf("error al consultar la reserva del ejemplar: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return Ejemplar{}, fmt.Errorf("error al confirmar la inserción del ejemplar: %w", err)
+	}
+	log.Printf("Ejemplar insertado con éxito. ID: %d", lastInsertId)
+	return ejemplar, nil
+}
+
+// UpdateEjemplar actualiza los datos de una copia. El estado de préstamo no se modifica.
+func (repo *SQLEjemplarRepository) UpdateEjemplar(ejemplar Ejemplar) error {
+	if enUso, err := codigoEnUso(repo.db, ejemplar.CodigoBarras, ejemplar.Id); err != nil {
+		return err
+	} else if enUso {
+		return ErrCodigoBarrasDuplicado
+	}
+
+	_, err := repo.db.Exec("UPDATE ejemplares SET CodigoBarras = ?, Ubicacion = ?, Condicion = ?, FechaAdquisicion = ? WHERE Id = ?",
+		ejemplar.CodigoBarras, ejemplar.Ubicacion, ejemplar.Condicion, ejemplar.FechaAdquisicion, ejemplar.Id)
+	if violaRestriccionUnica(err) {
+		return ErrCodigoBarrasDuplicado
+	}
+	if err != nil {
+		log.Printf("Error al ejecutar la actualización del ejemplar con ID %d: %v", ejemplar.Id, err)
+		return fmt.Errorf("error al actualizar el ejemplar: %w", err)
+	}
+	log.Printf("Ejemplar con ID %d actualizado con éxito.", ejemplar.Id)
+	return nil
+}
+
+// DeleteEjemplar elimina una copia que no está prestada. Sus préstamos se eliminan por el ON DELETE CASCADE.
+func (repo *SQLEjemplarRepository) DeleteEjemplar(Id int) error {
+	// Las condiciones evitan eliminar una copia que alguien tiene en préstamo o que está apartada para una reserva.
+	resultado, err := repo.db.Exec(`DELETE FROM ejemplares WHERE Id = ? AND Prestado = FALSE
+		AND NOT EXISTS (SELECT 1 FROM reservas WHERE EjemplarId = ? AND Estado = ?)`, Id, Id, EstadoReservaAsignada)
+	if err != nil {
+		log.Printf("Error al ejecutar la eliminación del ejemplar con ID %d: %v", Id, err)
+		return fmt.Errorf("error al eliminar el ejemplar: %w", err)
+	}
+
+	filasAfectadas, err := resultado.RowsAffected()
+	if err != nil {
+		log.Printf("Error al obtener las filas afectadas en DeleteEjemplar: %v", err)
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	if filasAfectadas == 0 {
+		// Distingue entre un ejemplar inexistente, uno prestado y uno apartado.
+		ejemplar, err := repo.GetEjemplarByID(Id)
+		if err != nil {
+			return noEncontrado("no se encontró ningún ejemplar con ID %d para eliminar", Id)
+		}
+		if ejemplar.Reservado {
+			return ErrEjemplarReservado
+		}
+		return ErrEjemplarPrestado
+	}
+	log.Printf("Ejemplar con ID %d eliminado con éxito.", Id)
+	return nil
+}
+
+// violaRestriccionUnica indica si el error es de una fila que repite una columna UNIQUE, en MySQL o en SQLite.
+// La única columna UNIQUE de ejemplares es CodigoBarras.
+func violaRestriccionUnica(err error) bool {
+	var errMySQL *mysql.MySQLError
+	if errors.As(err, &errMySQL) {
+		return errMySQL.Number == codigoMySQLDuplicado
+	}
+	var errSQLite *sqlite.Error
+	if errors.As(err, &errSQLite) {
+		return errSQLite.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package models
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// probarEjemplarRepository verifica el contrato común de EjemplarRepository y su relación con libros y préstamos.
+func probarEjemplarRepository(t *testing.T, libros LibroRepository, ejemplares EjemplarRepository, socios SocioRepository, prestamos PrestamoRepository) {
+	t.Helper()
+	libros.CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana")
+	socios.CreateSocio(Socio{Nombre: "Ana", Estado: EstadoSocioActivo})
+	adquisicion := time.Date(2020, 3, 1, 0, 0, 0, 0, time.Local)
+
+	// El primer ejemplar se crea junto con el libro.
+	lista, err := ejemplares.GetEjemplaresByLibro(1)
+	if err != nil || len(lista) != 1 || lista[0].CodigoBarras != "L000001-1" || lista[0].Titulo != "Rayuela" {
+		t.Fatalf("GetEjemplaresByLibro(1) = %+v, %v", lista, err)
+	}
+
+	segundo := Ejemplar{LibroId: 1, CodigoBarras: "L000001-2", Ubicacion: "Estante A3", Condicion: CondicionNuevo, FechaAdquisicion: adquisicion}
+	// CreateEjemplar devuelve el ejemplar con su ID y el título del libro.
+	if creado, err := ejemplares.CreateEjemplar(segundo); err != nil || creado.Id != 2 || creado.Titulo != "Rayuela" || creado.Prestado {
+		t.Fatalf("CreateEjemplar = %+v, %v", creado, err)
+	}
+	if _, err := ejemplares.CreateEjemplar(Ejemplar{LibroId: 1, CodigoBarras: "L000001-2", Condicion: CondicionBueno}); !errors.Is(err, ErrCodigoBarrasDuplicado) {
+		t.Errorf("CreateEjemplar con un código repetido devolvió %v", err)
+	}
+	if _, err := ejemplares.CreateEjemplar(Ejemplar{LibroId: 99, CodigoBarras: "X-1", Condicion: CondicionBueno}); err == nil {
+		t.Error("CreateEjemplar de un libro inexistente no devolvió error")
+	}
+
+	ejemplar, err := ejemplares.GetEjemplarByID(2)
+	if err != nil || ejemplar.Ubicacion != "Estante A3" || !ejemplar.FechaAdquisicion.Equal(adquisicion) || ejemplar.Prestado {
+		t.Fatalf("GetEjemplarByID(2) = %+v, %v", ejemplar, err)
+	}
+	ejemplar.Condicion = CondicionDesgastado
+	ejemplar.CodigoBarras = "L000001-1"
+	if err := ejemplares.UpdateEjemplar(ejemplar); !errors.Is(err, ErrCodigoBarrasDuplicado) {
+		t.Errorf("UpdateEjemplar con el código de otra copia devolvió %v", err)
+	}
+	ejemplar.CodigoBarras = "L000001-2"
+	if err := ejemplares.UpdateEjemplar(ejemplar); err != nil {
+		t.Fatalf("UpdateEjemplar: %v", err)
+	}
+	if e, _ := ejemplares.GetEjemplarByID(2); e.Condicion != CondicionDesgastado {
+		t.Errorf("la condición no se actualizó: %+v", e)
+	}
+
+	// Con dos copias se pueden hacer dos préstamos del mismo libro, pero no un tercero.
+	vence := time.Now().AddDate(0, 0, DiasPrestamoPorDefecto)
+	primero, err := prestamos.PrestarLibro(1, 1, vence)
+	if err != nil || primero.EjemplarId != 1 || primero.CodigoBarras != "L000001-1" {
+		t.Fatalf("primer PrestarLibro = %+v, %v", primero, err)
+	}
+	if libro, _ := libros.GetLibroByID(1); libro.Prestado || libro.Disponibles != 1 || libro.Ejemplares != 2 {
+		t.Errorf("con una copia libre el libro figura como %+v", libro)
+	}
+	if segundoPrestamo, err := prestamos.PrestarLibro(1, 1, vence); err != nil || segundoPrestamo.EjemplarId != 2 {
+		t.Fatalf("segundo PrestarLibro = %+v, %v", segundoPrestamo, err)
+	}
+	if _, err := prestamos.PrestarLibro(1, 1, vence); !errors.Is(err, ErrLibroNoDisponible) {
+		t.Errorf("PrestarLibro sin copias libres devolvió %v", err)
+	}
+	if libro, _ := libros.GetLibroByID(1); !libro.Prestado {
+		t.Errorf("sin copias libres el libro figura como %+v", libro)
+	}
+	if resumen, _ := libros.ContarLibros(); resumen != (ResumenLibros{Titulos: 1, Total: 2, Disponibles: 0, Prestados: 2}) {
+		t.Errorf("ContarLibros = %+v", resumen)
+	}
+
+	// Una copia prestada no se puede eliminar; una devuelta sí, junto con sus préstamos.
+	if err := ejemplares.DeleteEjemplar(1); !errors.Is(err, ErrEjemplarPrestado) {
+		t.Errorf("DeleteEjemplar de una copia prestada devolvió %v", err)
+	}
+	if _, err := prestamos.DevolverLibro(primero.Id); err != nil {
+		t.Fatalf("DevolverLibro: %v", err)
+	}
+	if err := ejemplares.DeleteEjemplar(1); err != nil {
+		t.Fatalf("DeleteEjemplar: %v", err)
+	}
+	if _, err := prestamos.GetPrestamoByID(primero.Id); err == nil {
+		t.Error("el préstamo de la copia eliminada sigue existiendo")
+	}
+	if err := ejemplares.DeleteEjemplar(1); err == nil || errors.Is(err, ErrEjemplarPrestado) {
+		t.Errorf("DeleteEjemplar de una copia inexistente devolvió %v", err)
+	}
+
+	// Al eliminar el libro desaparecen sus copias.
+	libros.DeleteLibro(1)
+	if lista, _ := ejemplares.GetEjemplaresByLibro(1); len(lista) != 0 {
+		t.Errorf("quedaron ejemplares de un libro eliminado: %+v", lista)
+	}
+}
+
+// probarCodigosConcurrentes crea a la vez varias copias con el mismo código de barras: solo una debe
+// guardarse y las demás deben recibir ErrCodigoBarrasDuplicado, aunque choquen con la restricción UNIQUE.
+func probarCodigosConcurrentes(t *testing.T, libros LibroRepository, ejemplares EjemplarRepository) {
+	t.Helper()
+	libro, _ := libros.CreateLibro("Borges", "Ficciones", 1944, "Sur")
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	exitos := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ejemplares.CreateEjemplar(Ejemplar{LibroId: libro.Id, CodigoBarras: "REPETIDO", Condicion: CondicionBueno})
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				exitos++
+			} else if !errors.Is(err, ErrCodigoBarrasDuplicado) {
+				t.Errorf("CreateEjemplar simultáneo devolvió %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if exitos != 1 {
+		t.Errorf("se guardaron %d copias simultáneas con el mismo código", exitos)
+	}
+}
+
+// probarCopiaNuevaConCola agrega una copia a un libro con reservas pendientes: debe quedar apartada para la
+// más antigua y no puede prestarse a otro socio.
+func probarCopiaNuevaConCola(t *testing.T, libros LibroRepository, ejemplares EjemplarRepository, socios SocioRepository,
+	prestamos PrestamoRepository, reservas ReservaRepository) {
+	t.Helper()
+	libros.CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana")
+	for _, nombre := range []string{"Ana", "Luis", "Marta", "Pedro"} {
+		socios.CreateSocio(Socio{Nombre: nombre, Estado: EstadoSocioActivo})
+	}
+	vence := time.Now().AddDate(0, 0, DiasPrestamoPorDefecto)
+	prestamos.PrestarLibro(1, 1, vence)
+	luis, _ := reservas.CrearReserva(1, 2)
+	marta, _ := reservas.CrearReserva(1, 3)
+
+	nueva, err := ejemplares.CreateEjemplar(Ejemplar{LibroId: 1, CodigoBarras: "L000001-2", Condicion: CondicionBueno})
+	if err != nil || !nueva.Reservado {
+		t.Fatalf("CreateEjemplar con reservas pendientes = %+v, %v", nueva, err)
+	}
+	if luis, _ = reservas.GetReservaByID(luis.Id); luis.Estado != EstadoReservaAsignada || luis.EjemplarId != nueva.Id {
+		t.Errorf("la reserva más antigua no recibió la copia nueva: %+v", luis)
+	}
+	if marta, _ = reservas.GetReservaByID(marta.Id); marta.Estado != EstadoReservaPendiente {
+		t.Errorf("la segunda reserva cambió con la copia nueva: %+v", marta)
+	}
+	if _, err := prestamos.PrestarLibro(1, 4, vence); !errors.Is(err, ErrLibroNoDisponible) {
+		t.Errorf("PrestarLibro de la copia apartada a otro socio = %v", err)
+	}
+	if p, err := prestamos.PrestarLibro(1, 2, vence); err != nil || p.EjemplarId != nueva.Id {
+		t.Errorf("PrestarLibro al socio de la reserva = %+v, %v", p, err)
+	}
+}
+
+func TestMemoryEjemplarRepository(t *testing.T) {
+	mdb := NewMemoriaDB()
+	probarEjemplarRepository(t, NewMemoryLibroRepository(mdb), NewMemoryEjemplarRepository(mdb),
+		NewMemorySocioRepository(mdb), NewMemoryPrestamoRepository(mdb))
+
+	mdb = NewMemoriaDB()
+	probarCodigosConcurrentes(t, NewMemoryLibroRepository(mdb), NewMemoryEjemplarRepository(mdb))
+
+	mdb = NewMemoriaDB()
+	probarCopiaNuevaConCola(t, NewMemoryLibroRepository(mdb), NewMemoryEjemplarRepository(mdb),
+		NewMemorySocioRepository(mdb), NewMemoryPrestamoRepository(mdb), NewMemoryReservaRepository(mdb))
+}
+
+func TestSQLEjemplarRepositorySQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	probarEjemplarRepository(t, NewSQLLibroRepository(conexion), NewSQLEjemplarRepository(conexion),
+		NewSQLSocioRepository(conexion), NewSQLPrestamoRepository(conexion))
+
+	conexion = nuevaDBPrueba(t)
+	probarCodigosConcurrentes(t, NewSQLLibroRepository(conexion), NewSQLEjemplarRepository(conexion))
+
+	conexion = nuevaDBPrueba(t)
+	probarCopiaNuevaConCola(t, NewSQLLibroRepository(conexion), NewSQLEjemplarRepository(conexion),
+		NewSQLSocioRepository(conexion), NewSQLPrestamoRepository(conexion), NewSQLReservaRepository(conexion))
+}
+
+// El error de la restricción UNIQUE se reconoce tanto en un INSERT como en un UPDATE, que es lo que reciben
+// CreateEjemplar y UpdateEjemplar cuando otra solicitud guarda el mismo código después de su verificación.
+func TestViolaRestriccionUnica(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	libros := NewSQLLibroRepository(conexion)
+	libros.CreateLibro("Borges", "Ficciones", 1944, "Sur")          // Ejemplar L000001-1.
+	libros.CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana") // Ejemplar L000002-1.
+
+	_, err := conexion.Exec("INSERT INTO ejemplares (LibroId, CodigoBarras, Condicion, FechaAdquisicion) VALUES (1, 'L000001-1', 'Bueno', CURRENT_TIMESTAMP)")
+	if !violaRestriccionUnica(err) {
+		t.Errorf("INSERT con un código repetido: %v", err)
+	}
+	_, err = conexion.Exec("UPDATE ejemplares SET CodigoBarras = 'L000001-1' WHERE LibroId = 2")
+	if !violaRestriccionUnica(err) {
+		t.Errorf("UPDATE con un código repetido: %v", err)
+	}
+	_, err = conexion.Exec("INSERT INTO ejemplares (LibroId, CodigoBarras, Condicion, FechaAdquisicion) VALUES (99, 'X-1', 'Bueno', CURRENT_TIMESTAMP)")
+	if err == nil || violaRestriccionUnica(err) {
+		t.Errorf("un error de clave foránea se reconoció como código repetido: %v", err)
+	}
+}
+
+func TestParseFechaAdquisicion(t *testing.T) {
+	fecha, err := ParseFechaAdquisicion("2020-03-01")
+	if err != nil || fecha.Format("2006-01-02") != "2020-03-01" {
+		t.Errorf("ParseFechaAdquisicion(fecha) = %v, %v", fecha, err)
+	}
+	if fecha, err := ParseFechaAdquisicion(""); err != nil || fecha.Format("2006-01-02") != time.Now().Format("2006-01-02") {
+		t.Errorf("ParseFechaAdquisicion(\"\") = %v, %v", fecha, err)
+	}
+	if _, err := ParseFechaAdquisicion("01/03/2020"); err == nil {
+		t.Error("ParseFechaAdquisicion de un formato inválido no devolvió error")
+	}
+}
@@ -0,0 +1,51 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define las clases de error del dominio que los manejadores reconocen con errors.Is.
+*/
+
+package models
+
+import (
+	"errors" // Paquete para definir las clases de error.
+	"fmt"    // Paquete para formatear los mensajes de error.
+)
+
+// Clases de error del dominio. Los errores que devuelven los repositorios pertenecen a una de ellas,
+// así que los manejadores eligen el estado HTTP con errors.Is sin depender del texto del mensaje:
+//
+//	errors.Is(err, ErrNotFound)   // el registro pedido no existe: 404 Not Found.
+//	errors.Is(err, ErrConflict)   // la operación no es posible en el estado actual de los datos: 409 Conflict.
+//	errors.Is(err, ErrValidation) // los datos recibidos no cumplen las reglas: 422 (API) o 400 (formularios).
+//
+// Cualquier otro error es una falla del servidor (por ejemplo, de la base de datos).
+var (
+	ErrNotFound   = errors.New("no encontrado")
+	ErrConflict   = errors.New("conflicto con el estado de los datos")
+	ErrValidation = errors.New("datos inválidos")
+)
+
+// errorDominio es un error con su propio mensaje que pertenece a una de las clases de error del dominio.
+type errorDominio struct {
+	mensaje string // Mensaje completo, que se puede mostrar al usuario.
+	clase   error  // ErrNotFound, ErrConflict o ErrValidation.
+}
+
+// Error devuelve el mensaje del error, sin el de su clase.
+func (e *errorDominio) Error() string {
+	return e.mensaje
+}
+
+// Unwrap devuelve la clase del error, para que errors.Is la reconozca.
+func (e *errorDominio) Unwrap() error {
+	return e.clase
+}
+
+// nuevoError crea un error de la clase indicada con el mensaje recibido.
+func nuevoError(clase error, mensaje string) error {
+	return &errorDominio{mensaje: mensaje, clase: clase}
+}
+
+// noEncontrado crea un error de la clase ErrNotFound con el mensaje formateado.
+func noEncontrado(formato string, args ...any) error {
+	return nuevoError(ErrNotFound, fmt.Sprintf(formato, args...))
+}
@@ -0,0 +1,21 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo con la conversión de las fechas que los repositorios SQL guardan y comparan en la base de datos.
+*/
+
+package models
+
+import "time" // Paquete para convertir las fechas a UTC y a la hora local.
+
+// fechaSQL pasa a UTC una fecha antes de guardarla o compararla en SQL. El driver de SQLite guarda las fechas
+// como texto con la zona horaria que traen, y ese texto solo se ordena como las fechas si todas están en la
+// misma zona; el de MySQL ya las convierte a UTC.
+func fechaSQL(fecha time.Time) time.Time {
+	return fecha.UTC()
+}
+
+// fechaLocal pasa a la hora local una fecha leída de la base de datos, que fechaSQL guardó en UTC, para que
+// las páginas muestren el día del calendario de la biblioteca y no el de UTC.
+func fechaLocal(fecha time.Time) time.Time {
+	return fecha.Local()
+}
@@ -1,199 +1,144 @@
-/*
-@Autor: Kevin Pérez
-@Descripcion: Módulo que maneja las operaciones CRUD para la entidad Libro en la base de datos.
-*/
-
-package models
-
-import (
-	"database/sql" // Paquete para interactuar con bases de datos SQL.
-	"fmt"          // Paquete para formatear cadenas.
-	"log"          // Paquete para logging de errores y mensajes.
-	"proyecto/db"  // Importa el paquete db para obtener la conexión a la base de datos.
-)
-
-// Libro representa la estructura de un libro en la base de datos.
-// Los nombres de los campos deben coincidir con los nombres de las columnas de la tabla.
-type Libro struct {
-	Id              int    // ID único del libro (clave primaria).
-	Titulo          string // Título del libro.
-	Autor           string // Autor del libro.
-	AnioPublicacion int    // Año de publicación del libro.
-	Editorial       string // Editorial del libro.
-	Prestado        string // Estado de préstamo del libro (ej. "Si", "No").
-}
-
-// GetAllLibros consulta la base de datos y devuelve una lista de todos los libros.
-func GetAllLibros() ([]Libro, error) {
-	var libros []Libro // Declara una slice para almacenar los libros.
-	// Establece una conexión a la base de datos.
-	DB, err := db.Connect()
-	if err != nil {
-		log.Printf("Error al conectar a la base de datos en GetAllLibros: %v", err)
-		return nil, fmt.Errorf("error al conectar a la base de datos: %w", err)
-	}
-
-	defer DB.Close() // Asegura que la conexión a la base de datos se cierre al finalizar la función.
-	// Ejecuta la consulta SQL para seleccionar todos los campos de todos los libros.
-	rows, err := DB.Query("SELECT Id, Titulo, Autor, AnioPublicacion, Editorial, Prestado FROM libros")
-	if err != nil {
-		log.Printf("Error al ejecutar la consulta en GetAllLibros: %v", err)
-		return nil, fmt.Errorf("error al ejecutar la consulta: %w", err)
-	}
-
-	defer rows.Close() // Asegura que las filas de resultados se cierren al finalizar la función.
-	// Itera sobre cada fila de resultados.
-	for rows.Next() {
-		var libro Libro // Declara una variable Libro para almacenar los datos de la fila actual.
-		// Escanea los valores de la fila en los campos de la estructura Libro.
-		err := rows.Scan(&libro.Id, &libro.Titulo, &libro.Autor, &libro.AnioPublicacion, &libro.Editorial, &libro.Prestado)
-		if err != nil {
-			log.Printf("Error al escanear los resultados en GetAllLibros: %v", err)
-			return nil, fmt.Errorf("error al escanear los resultados: %w", err)
-		}
-		libros = append(libros, libro) // Agrega el libro a la slice de libros.
-	}
-
-	// Verifica si hubo algún error durante la iteración de las filas.
-	if err = rows.Err(); err != nil {
-		log.Printf("Error al procesar los resultados de la base de datos en GetAllLibros: %v", err)
-		return nil, fmt.Errorf("error al procesar los resultados: %w", err)
-	}
-
-	return libros, nil // Devuelve la lista de libros y nil si no hay errores.
-}
-
-// CreateLibro inserta un nuevo libro en la base de datos.
-func CreateLibro(Autor string, Titulo string, AnioPublicacion int, Editorial string, Prestado string) error {
-	// Establece una conexión a la base de datos.
-	DB, err := db.Connect()
-	if err != nil {
-		log.Printf("Error al conectar a la base de datos en CreateLibro: %v", err)
-		return fmt.Errorf("error al conectar a la base de datos: %w", err)
-	}
-	defer DB.Close() // Asegura que la conexión se cierre.
-
-	// Prepara la sentencia SQL para insertar un nuevo libro.
-	// Esto ayuda a prevenir inyecciones SQL y mejora el rendimiento.
-	stmt, err := DB.Prepare("INSERT INTO libros (Autor, Titulo, AnioPublicacion, Editorial, Prestado) VALUES (?, ?, ?, ?, ?)")
-	if err != nil {
-		log.Printf("Error al preparar la sentencia INSERT en CreateLibro: %v", err)
-		return fmt.Errorf("error al preparar la sentencia: %w", err)
-	}
-	defer stmt.Close() // Asegura que la sentencia preparada se cierre.
-
-	// Ejecuta la sentencia preparada con los valores proporcionados.
-	resultado, err := stmt.Exec(Autor, Titulo, AnioPublicacion, Editorial, Prestado)
-	if err != nil {
-		log.Printf("Error al ejecutar la inserción del libro: %v", err)
-		return fmt.Errorf("error al insertar el libro: %w", err)
-	}
-
-	// Obtiene el ID del último libro insertado.
-	lastInsertId, err := resultado.LastInsertId()
-	if err != nil {
-		log.Printf("Error al obtener el ID del último libro insertado en CreateLibro: %v", err)
-		return fmt.Errorf("error al obtener el ID del último libro insertado: %w", err)
-	}
-	log.Printf("Libro insertado con éxito. ID: %d", lastInsertId)
-
-	return nil // Devuelve nil si la inserción fue exitosa.
-}
-
-// GetLibroByID consulta la base de datos y devuelve un libro específico por su ID.
-func GetLibroByID(Id int) (Libro, error) {
-	var libro Libro // Declara una variable Libro para almacenar el resultado.
-	// Establece una conexión a la base de datos.
-	DB, err := db.Connect()
-	if err != nil {
-		log.Printf("Error al conectar a la base de datos en GetLibroByID: %v", err)
-		return libro, fmt.Errorf("error al conectar a la base de datos: %w", err)
-	}
-	defer DB.Close() // Asegura que la conexión se cierre.
-
-	// Prepara la sentencia SQL para seleccionar un libro por su ID.
-	stmt, err := DB.Prepare("SELECT Id, Titulo, Autor, AnioPublicacion, Editorial, Prestado FROM libros WHERE Id = ?")
-	if err != nil {
-		log.Printf("Error al preparar la consulta en GetLibroByID: %v", err)
-		return libro, fmt.Errorf("error al preparar la consulta: %w", err)
-	}
-	defer stmt.Close() // Asegura que la sentencia preparada se cierre.
-
-	// Ejecuta la consulta y escanea el resultado en la estructura Libro.
-	fila := stmt.QueryRow(Id)
-	err = fila.Scan(&libro.Id, &libro.Titulo, &libro.Autor, &libro.AnioPublicacion, &libro.Editorial, &libro.Prestado)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			// Si no se encuentra ninguna fila, devuelve un error específico.
-			return libro, fmt.Errorf("libro con ID %d no encontrado", Id)
-		}
-		log.Printf("Error al escanear el libro con ID %d: %v", Id, err)
-		return libro, fmt.Errorf("error al obtener el libro: %w", err)
-	}
-	log.Printf("Libro obtenido con éxito: %+v", libro)
-	return libro, nil // Devuelve el libro y nil si no hay errores.
-}
-
-// UpdateLibro actualiza un libro existente en la base de datos.
-func UpdateLibro(libro Libro) error {
-	DB, err := db.Connect()
-	if err != nil {
-		log.Printf("Error al conectar a la base de datos en UpdateLibro: %v", err)
-		return fmt.Errorf("error al conectar a la base de datos: %w", err)
-	}
-	defer DB.Close()
-
-	// Prepara la sentencia SQL para actualizar un libro.
-	stmt, err := DB.Prepare("UPDATE libros SET Titulo = ?, Autor = ?, AnioPublicacion = ?, Editorial = ?, Prestado = ? WHERE Id = ?")
-	if err != nil {
-		log.Printf("Error al preparar la sentencia UPDATE en UpdateLibro: %v", err)
-		return fmt.Errorf("error al preparar la sentencia: %w", err)
-	}
-	defer stmt.Close()
-
-	// Ejecuta la sentencia preparada con los datos actualizados del libro.
-	_, err = stmt.Exec(libro.Titulo, libro.Autor, libro.AnioPublicacion, libro.Editorial, libro.Prestado, libro.Id)
-	if err != nil {
-		log.Printf("Error al ejecutar la actualización del libro con ID %d: %v", libro.Id, err)
-		return fmt.Errorf("error al actualizar el libro: %w", err)
-	}
-	log.Printf("Libro con ID %d actualizado con éxito.", libro.Id)
-	return nil
-}
-
-// DeleteLibro elimina un libro de la base de datos por su ID.
-func DeleteLibro(Id int) error {
-	DB, err := db.Connect()
-	if err != nil {
-		log.Printf("Error al conectar a la base de datos en DeleteLibro: %v", err)
-		return fmt.Errorf("error al conectar a la base de datos: %w", err)
-	}
-	defer DB.Close()
-
-	// Prepara la sentencia SQL para eliminar un libro.
-	stmt, err := DB.Prepare("DELETE FROM libros WHERE Id = ?")
-	if err != nil {
-		log.Printf("Error al preparar la sentencia DELETE en DeleteLibro: %v", err)
-		return fmt.Errorf("error al preparar la sentencia: %w", err)
-	}
-	defer stmt.Close()
-
-	// Ejecuta la sentencia preparada con el ID del libro a eliminar.
-	resultado, err := stmt.Exec(Id)
-	if err != nil {
-		log.Printf("Error al ejecutar la eliminación del libro con ID %d: %v", Id, err)
-		return fmt.Errorf("error al eliminar el libro: %w", err)
-	}
-
-	filasAfectadas, err := resultado.RowsAffected()
-	if err != nil {
-		log.Printf("Error al obtener las filas afectadas en DeleteLibro: %v", err)
-		return fmt.Errorf("error al obtener filas afectadas: %w", err)
-	}
-
-	if filasAfectadas == 0 {
-		return fmt.Errorf("no se encontró ningún libro con ID %d para eliminar", Id)
-	}
-	log.Printf("Libro con ID %d eliminado con éxito.", Id)
-	return nil
-}
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define la entidad Libro y el repositorio para sus operaciones CRUD.
+*/
+
+package models
+
+import (
+	"fmt"          // Paquete para formatear cadenas.
+	"strings"      // Paquete para normalizar los valores de texto.
+	"time"         // Paquete para obtener el año actual.
+	"unicode/utf8" // Paquete para medir los textos en caracteres y no en bytes.
+)
+
+// Límites de los datos de un libro. Las longitudes coinciden con las columnas VARCHAR(255) de la tabla libros
+// y el año mínimo con el atributo min del formulario.
+const (
+	LongitudMaximaTitulo    = 255  // Cantidad máxima de caracteres del título.
+	LongitudMaximaAutor     = 255  // Cantidad máxima de caracteres del autor.
+	LongitudMaximaEditorial = 255  // Cantidad máxima de caracteres de la editorial.
+	AnioPublicacionMinimo   = 1500 // Año de publicación más antiguo aceptado.
+)
+
+// Nombres de los campos de un libro. Son los nombres de los controles de los formularios web, las claves del
+// JSON de la API y las claves de ErroresValidacion, de modo que un mismo nombre identifica el campo en todas partes.
+const (
+	CampoTitulo          = "Titulo"
+	CampoAutor           = "Autor"
+	CampoAnioPublicacion = "AnioPublicacion"
+	CampoEditorial       = "Editorial"
+	CampoPrestado        = "Prestado"
+)
+
+// AnioPublicacionMaximo devuelve el año de publicación más reciente aceptado, que es el año en curso.
+func AnioPublicacionMaximo() int {
+	return time.Now().Year()
+}
+
+// Libro representa la estructura de un libro en la base de datos.
+// Los nombres de los campos deben coincidir con los nombres de las columnas de la tabla.
+// Un libro es la obra; las copias físicas que se prestan son sus ejemplares.
+type Libro struct {
+	Id              int    // ID único del libro (clave primaria).
+	Titulo          string // Título del libro.
+	Autor           string // Autor del libro.
+	AnioPublicacion int    // Año de publicación del libro.
+	Editorial       string // Editorial del libro.
+	Prestado        bool   // Indica si no queda ningún ejemplar disponible (solo lectura, se calcula de los ejemplares).
+	Ejemplares      int    // Cantidad de ejemplares del libro (solo lectura).
+	Disponibles     int    // Cantidad de ejemplares que no están prestados ni apartados para una reserva (solo lectura).
+}
+
+// conDisponibilidad completa los contadores de ejemplares del libro y calcula Prestado a partir de ellos.
+func (l Libro) conDisponibilidad(ejemplares, disponibles int) Libro {
+	l.Ejemplares = ejemplares
+	l.Disponibles = disponibles
+	l.Prestado = ejemplares > 0 && disponibles == 0
+	return l
+}
+
+// Normalizar quita los espacios sobrantes al principio y al final de los textos del libro.
+func (l Libro) Normalizar() Libro {
+	l.Titulo = strings.TrimSpace(l.Titulo)
+	l.Autor = strings.TrimSpace(l.Autor)
+	l.Editorial = strings.TrimSpace(l.Editorial)
+	return l
+}
+
+// Validar verifica los datos bibliográficos del libro: los textos son obligatorios y no superan su longitud
+// máxima, y el año de publicación está entre AnioPublicacionMinimo y el año en curso.
+// Devuelve ErroresValidacion con un mensaje por campo, o nil si el libro es válido.
+// Los textos se validan tal como están; los manejadores los normalizan antes con Normalizar.
+func (l Libro) Validar() error {
+	errores := ErroresValidacion{}
+	validarTexto(errores, CampoTitulo, l.Titulo, LongitudMaximaTitulo)
+	validarTexto(errores, CampoAutor, l.Autor, LongitudMaximaAutor)
+	validarTexto(errores, CampoEditorial, l.Editorial, LongitudMaximaEditorial)
+	if maximo := AnioPublicacionMaximo(); l.AnioPublicacion < AnioPublicacionMinimo || l.AnioPublicacion > maximo {
+		errores.Agregar(CampoAnioPublicacion, fmt.Sprintf("Debe estar entre %d y %d", AnioPublicacionMinimo, maximo))
+	}
+	return errores.Err()
+}
+
+// validarTexto registra un error si el texto está vacío o tiene más de maximo caracteres.
+func validarTexto(errores ErroresValidacion, campo, valor string, maximo int) {
+	if strings.TrimSpace(valor) == "" {
+		errores.Agregar(campo, "Es obligatorio")
+	} else if utf8.RuneCountInString(valor) > maximo {
+		errores.Agregar(campo, fmt.Sprintf("No puede tener más de %d caracteres", maximo))
+	}
+}
+
+// ResumenLibros agrupa los contadores que se muestran en el dashboard.
+// La disponibilidad se cuenta por ejemplar, no por título.
+type ResumenLibros struct {
+	Titulos     int // Cantidad de libros (obras) registrados.
+	Total       int // Cantidad total de ejemplares.
+	Disponibles int // Cantidad de ejemplares que no están prestados ni apartados para una reserva.
+	Prestados   int // Cantidad de ejemplares prestados.
+	Reservados  int // Cantidad de ejemplares apartados para una reserva, esperando que el socio los retire.
+}
+
+// LibroRepository define las operaciones de persistencia disponibles para la entidad Libro.
+// Los manejadores dependen de esta interfaz y no de una base de datos concreta,
+// lo que permite intercambiar la implementación (por ejemplo, en las pruebas).
+type LibroRepository interface {
+	// GetAllLibros devuelve una lista de todos los libros.
+	GetAllLibros() ([]Libro, error)
+	// ListarLibros devuelve la página de libros que pide la consulta, junto con la cantidad total de libros
+	// que cumplen sus filtros. Una consulta inválida devuelve ErroresValidacion (ver ConsultaLibros.Validar).
+	ListarLibros(consulta ConsultaLibros) (PaginaLibros, error)
+	// BuscarLibros devuelve hasta limite libros cuyo título, autor o editorial contienen las palabras del texto,
+	// sin distinguir mayúsculas ni tildes, de mayor a menor relevancia. Un límite de 0 usa LimiteBusquedaPorDefecto.
+	BuscarLibros(texto string, limite int) ([]ResultadoBusqueda, error)
+	// SugerirLibros devuelve hasta limite títulos, autores o editoriales con alguna palabra que empieza con el texto,
+	// para completar lo que se está escribiendo. campo restringe las sugerencias a uno de CamposSugerencias; vacío
+	// sugiere de todos. Un límite de 0 usa LimiteSugerenciasPorDefecto.
+	SugerirLibros(texto, campo string, limite int) ([]Sugerencia, error)
+	// CreateLibro inserta un nuevo libro junto con su primer ejemplar y devuelve el libro guardado, con el ID
+	// asignado. La primera copia queda disponible: solo un préstamo la marca como prestada.
+	CreateLibro(Autor string, Titulo string, AnioPublicacion int, Editorial string) (Libro, error)
+	// GetLibroByID devuelve un libro específico por su ID.
+	GetLibroByID(Id int) (Libro, error)
+	// UpdateLibro actualiza los datos bibliográficos de un libro existente.
+	// Prestado se ignora: la disponibilidad depende de los ejemplares.
+	UpdateLibro(libro Libro) error
+	// DeleteLibro elimina un libro por su ID, junto con sus ejemplares y préstamos.
+	DeleteLibro(Id int) error
+	// ContarLibros devuelve la cantidad de títulos y los contadores de ejemplares totales, disponibles y prestados.
+	ContarLibros() (ResumenLibros, error)
+}
+
+// ParsePrestado convierte un valor de texto en el estado de préstamo de un libro.
+// Acepta los valores históricos "Si"/"No" (con o sin tilde), "true"/"false", "1"/"0"
+// y "on", que es lo que envía un checkbox marcado.
+func ParsePrestado(valor string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(valor)) {
+	case "si", "sí", "true", "1", "on":
+		return true, nil
+	case "no", "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("valor de prestado inválido: %q (use Si o No)", valor)
+}
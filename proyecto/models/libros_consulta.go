@@ -0,0 +1,215 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define la consulta paginada de libros: filtros, orden, página y cursor.
+*/
+
+package models
+
+import (
+	"encoding/base64" // Paquete para representar el cursor como texto seguro en una URL.
+	"errors"          // Paquete para crear los mensajes de error del cursor.
+	"fmt"             // Paquete para formatear los mensajes de error.
+	"strings"         // Paquete para comparar los textos sin distinguir mayúsculas.
+
+	"github.com/goccy/go-json" // Paquete para codificar el cursor en JSON.
+)
+
+// Tamaño de las páginas de libros.
+const (
+	LimiteLibrosPorDefecto = 50  // Libros por página si la consulta no indica un límite.
+	LimiteLibrosMaximo     = 200 // Máximo de libros por página que se puede pedir.
+)
+
+// Nombres de los parámetros de la consulta de libros. Son los parámetros de la URL de GET /api/libros
+// y las claves de ErroresValidacion cuando un valor no es válido.
+const (
+	ParametroAutor          = "autor"
+	ParametroEditorial      = "editorial"
+	ParametroAnioDesde      = "anioDesde"
+	ParametroAnioHasta      = "anioHasta"
+	ParametroPrestado       = "prestado"
+	ParametroOrden          = "orden"
+	ParametroDireccion      = "direccion"
+	ParametroLimite         = "limite"
+	ParametroDesplazamiento = "desplazamiento"
+	ParametroCursor         = "cursor"
+)
+
+// CamposOrdenLibros son los campos de Libro por los que se puede ordenar una consulta, con el orden por
+// defecto primero. A igualdad de valor los libros se ordenan por Id, así que el orden es siempre total.
+var CamposOrdenLibros = []string{"Id", CampoTitulo, CampoAutor, CampoAnioPublicacion, CampoEditorial, CampoPrestado, "Ejemplares", "Disponibles"}
+
+// ConsultaLibros describe una página de libros: los filtros que deben cumplir, el orden y qué parte
+// del resultado se devuelve. El valor cero pide la primera página de todos los libros ordenados por Id.
+// La página se elige con Desplazamiento (cantidad de libros que se saltean) o con Cursor (el libro
+// después del cual empieza la página), pero no con ambos.
+type ConsultaLibros struct {
+	Autor          string // Texto que debe contener el autor, sin distinguir mayúsculas; vacío no filtra.
+	Editorial      string // Texto que debe contener la editorial, sin distinguir mayúsculas; vacío no filtra.
+	AnioDesde      int    // Año de publicación mínimo; 0 no filtra.
+	AnioHasta      int    // Año de publicación máximo; 0 no filtra.
+	Prestado       *bool  // Si no es nil, solo los libros con ese valor de Prestado.
+	Orden          string // Campo de CamposOrdenLibros por el que se ordena; vacío ordena por Id.
+	Descendente    bool   // Ordena de mayor a menor.
+	Limite         int    // Cantidad máxima de libros de la página; 0 usa LimiteLibrosPorDefecto.
+	Desplazamiento int    // Cantidad de libros que se saltean antes de la página.
+	Cursor         string // Cursor PaginaLibros.Siguiente de la página anterior; vacío empieza desde el principio.
+}
+
+// PaginaLibros es el resultado de una ConsultaLibros.
+type PaginaLibros struct {
+	Libros    []Libro // Libros de la página, en el orden pedido.
+	Total     int     // Cantidad de libros que cumplen los filtros, contando todas las páginas.
+	Siguiente string  // Cursor para pedir la página siguiente; vacío si es la última.
+}
+
+// cursorLibros es la posición del último libro de una página: su valor en el campo de orden y su Id.
+// El orden y la dirección se guardan para rechazar un cursor usado con otra consulta.
+type cursorLibros struct {
+	Orden       string `json:"o"`
+	Descendente bool   `json:"d,omitempty"`
+	Texto       string `json:"t,omitempty"` // Valor del campo de orden, si es de texto.
+	Numero      int    `json:"n,omitempty"` // Valor del campo de orden, si es numérico (Prestado vale 0 o 1).
+	Id          int    `json:"i"`
+}
+
+// Validar verifica los parámetros de la consulta. Devuelve ErroresValidacion con un mensaje por parámetro
+// (ver ParametroAutor), o nil si la consulta es válida.
+func (c ConsultaLibros) Validar() error {
+	errores := ErroresValidacion{}
+	if c.Orden != "" && !campoOrdenLibros(c.Orden) {
+		errores.Agregar(ParametroOrden, "Debe ser uno de "+strings.Join(CamposOrdenLibros, ", "))
+	}
+	if c.Limite < 0 || c.Limite > LimiteLibrosMaximo {
+		errores.Agregar(ParametroLimite, fmt.Sprintf("Debe estar entre 1 y %d", LimiteLibrosMaximo))
+	}
+	if c.Desplazamiento < 0 {
+		errores.Agregar(ParametroDesplazamiento, "No puede ser negativo")
+	}
+	if c.AnioDesde != 0 && c.AnioHasta != 0 && c.AnioDesde > c.AnioHasta {
+		errores.Agregar(ParametroAnioHasta, "No puede ser anterior a "+ParametroAnioDesde)
+	}
+	if c.Cursor != "" {
+		if c.Desplazamiento != 0 {
+			errores.Agregar(ParametroCursor, "No se puede usar junto con "+ParametroDesplazamiento)
+		} else if _, err := c.cursor(); err != nil {
+			errores.Agregar(ParametroCursor, err.Error())
+		}
+	}
+	return errores.Err()
+}
+
+// preparar valida la consulta, completa los valores por defecto y decodifica su cursor, que es nil
+// si la consulta no tiene uno. Las implementaciones de LibroRepository la usan al empezar ListarLibros.
+func (c ConsultaLibros) preparar() (ConsultaLibros, *cursorLibros, error) {
+	if err := c.Validar(); err != nil {
+		return c, nil, err
+	}
+	if c.Orden == "" {
+		c.Orden = CamposOrdenLibros[0]
+	}
+	if c.Limite == 0 {
+		c.Limite = LimiteLibrosPorDefecto
+	}
+	if c.Cursor == "" {
+		return c, nil, nil
+	}
+	cursor, err := c.cursor()
+	return c, &cursor, err
+}
+
+// cursor decodifica el cursor de la consulta y verifica que se haya creado con el mismo orden y dirección.
+func (c ConsultaLibros) cursor() (cursorLibros, error) {
+	var cursor cursorLibros
+	datos, err := base64.RawURLEncoding.DecodeString(c.Cursor)
+	if err != nil || json.Unmarshal(datos, &cursor) != nil || !campoOrdenLibros(cursor.Orden) {
+		return cursor, errors.New("No es un cursor válido")
+	}
+	orden := c.Orden
+	if orden == "" {
+		orden = CamposOrdenLibros[0]
+	}
+	if cursor.Orden != orden || cursor.Descendente != c.Descendente {
+		return cursor, fmt.Errorf("Pertenece a otro orden; use el mismo %s y %s de la página anterior", ParametroOrden, ParametroDireccion)
+	}
+	return cursor, nil
+}
+
+// posicion devuelve la posición del libro en el orden de la consulta, en la forma en que se guarda en un cursor.
+func (c ConsultaLibros) posicion(libro Libro) cursorLibros {
+	cursor := cursorLibros{Orden: c.Orden, Descendente: c.Descendente, Id: libro.Id}
+	switch c.Orden {
+	case CampoTitulo:
+		cursor.Texto = libro.Titulo
+	case CampoAutor:
+		cursor.Texto = libro.Autor
+	case CampoEditorial:
+		cursor.Texto = libro.Editorial
+	case CampoAnioPublicacion:
+		cursor.Numero = libro.AnioPublicacion
+	case CampoPrestado:
+		if libro.Prestado {
+			cursor.Numero = 1
+		}
+	case "Ejemplares":
+		cursor.Numero = libro.Ejemplares
+	case "Disponibles":
+		cursor.Numero = libro.Disponibles
+	}
+	return cursor
+}
+
+// siguiente devuelve el cursor que apunta después del libro, para pedir la página que le sigue.
+func (c ConsultaLibros) siguiente(libro Libro) string {
+	datos, _ := json.Marshal(c.posicion(libro)) // Una estructura de textos y números siempre se puede codificar.
+	return base64.RawURLEncoding.EncodeToString(datos)
+}
+
+// comparar compara dos posiciones en el orden de la consulta: negativo si a va antes que b, positivo
+// si va después y 0 si son el mismo libro. Los textos se comparan byte a byte, como la intercalación
+// BINARY de SQLite.
+func (c ConsultaLibros) comparar(a, b cursorLibros) int {
+	resultado := strings.Compare(a.Texto, b.Texto)
+	if resultado == 0 {
+		resultado = a.Numero - b.Numero
+	}
+	if resultado == 0 {
+		resultado = a.Id - b.Id
+	}
+	if c.Descendente {
+		return -resultado
+	}
+	return resultado
+}
+
+// cumple indica si el libro cumple los filtros de la consulta.
+func (c ConsultaLibros) cumple(libro Libro) bool {
+	if c.Autor != "" && !strings.Contains(strings.ToLower(libro.Autor), strings.ToLower(c.Autor)) {
+		return false
+	}
+	if c.Editorial != "" && !strings.Contains(strings.ToLower(libro.Editorial), strings.ToLower(c.Editorial)) {
+		return false
+	}
+	if c.AnioDesde != 0 && libro.AnioPublicacion < c.AnioDesde {
+		return false
+	}
+	if c.AnioHasta != 0 && libro.AnioPublicacion > c.AnioHasta {
+		return false
+	}
+	return c.Prestado == nil || *c.Prestado == libro.Prestado
+}
+
+// esCampoTextoLibro indica si el campo de orden es de texto; los demás son numéricos.
+func esCampoTextoLibro(campo string) bool {
+	return campo == CampoTitulo || campo == CampoAutor || campo == CampoEditorial
+}
+
+// campoOrdenLibros indica si se puede ordenar por el campo.
+func campoOrdenLibros(campo string) bool {
+	for _, valido := range CamposOrdenLibros {
+		if campo == valido {
+			return true
+		}
+	}
+	return false
+}
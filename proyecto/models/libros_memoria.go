@@ -0,0 +1,199 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación en memoria del repositorio de libros, usada en las pruebas y en el modo demo.
+*/
+
+package models
+
+import (
+	"sort" // Paquete para ordenar los libros y buscar el comienzo de una página.
+	"time" // Paquete para la fecha de adquisición del primer ejemplar.
+)
+
+// MemoryLibroRepository implementa LibroRepository guardando los libros en memoria.
+// Es seguro para uso concurrente y no necesita una base de datos.
+type MemoryLibroRepository struct {
+	db *MemoriaDB // Almacenamiento en memoria compartido con los demás repositorios.
+}
+
+// NewMemoryLibroRepository crea un repositorio de libros sobre el almacenamiento en memoria recibido.
+func NewMemoryLibroRepository(db *MemoriaDB) *MemoryLibroRepository {
+	return &MemoryLibroRepository{db: db}
+}
+
+// GetAllLibros devuelve una lista de todos los libros ordenados por ID.
+func (repo *MemoryLibroRepository) GetAllLibros() ([]Libro, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	var libros []Libro // Igual que en MySQL, la slice queda en nil si no hay libros.
+	for _, libro := range repo.db.libros {
+		libros = append(libros, repo.db.conDisponibilidad(libro))
+	}
+	// Los mapas no tienen orden, se ordena por ID como lo haría la clave primaria.
+	sort.Slice(libros, func(i, j int) bool { return libros[i].Id < libros[j].Id })
+	return libros, nil
+}
+
+// ListarLibros filtra, ordena y recorta los libros en memoria con las mismas reglas que la consulta SQL.
+func (repo *MemoryLibroRepository) ListarLibros(consulta ConsultaLibros) (PaginaLibros, error) {
+	consulta, cursor, err := consulta.preparar()
+	if err != nil {
+		return PaginaLibros{}, err
+	}
+
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	var libros []Libro
+	for _, libro := range repo.db.libros {
+		if libro = repo.db.conDisponibilidad(libro); consulta.cumple(libro) {
+			libros = append(libros, libro)
+		}
+	}
+	sort.Slice(libros, func(i, j int) bool {
+		return consulta.comparar(consulta.posicion(libros[i]), consulta.posicion(libros[j])) < 0
+	})
+
+	pagina := PaginaLibros{Total: len(libros)}
+	inicio := consulta.Desplazamiento
+	if cursor != nil {
+		// La página empieza en el primer libro que va después del cursor.
+		inicio = sort.Search(len(libros), func(i int) bool { return consulta.comparar(consulta.posicion(libros[i]), *cursor) > 0 })
+	}
+	if inicio >= len(libros) {
+		return pagina, nil
+	}
+	fin := min(inicio+consulta.Limite, len(libros))
+	pagina.Libros = libros[inicio:fin]
+	if fin < len(libros) {
+		pagina.Siguiente = consulta.siguiente(libros[fin-1])
+	}
+	return pagina, nil
+}
+
+// CreateLibro agrega un nuevo libro asignándole el siguiente ID de la secuencia, junto con su primer ejemplar.
+func (repo *MemoryLibroRepository) CreateLibro(Autor string, Titulo string, AnioPublicacion int, Editorial string) (Libro, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	repo.db.nextLibroId++
+	repo.db.libros[repo.db.nextLibroId] = Libro{
+		Id:              repo.db.nextLibroId,
+		Titulo:          Titulo,
+		Autor:           Autor,
+		AnioPublicacion: AnioPublicacion,
+		Editorial:       Editorial,
+	}
+	repo.db.indiceLibros.Indexar(repo.db.libros[repo.db.nextLibroId])
+	repo.db.sugerenciasLibros.Indexar(repo.db.libros[repo.db.nextLibroId])
+
+	repo.db.nextEjemplarId++
+	repo.db.ejemplares[repo.db.nextEjemplarId] = Ejemplar{
+		Id:               repo.db.nextEjemplarId,
+		LibroId:          repo.db.nextLibroId,
+		CodigoBarras:     CodigoBarrasSugerido(repo.db.nextLibroId, 1),
+		Condicion:        CondicionBueno,
+		FechaAdquisicion: time.Now().Truncate(time.Second),
+	}
+	return repo.db.conDisponibilidad(repo.db.libros[repo.db.nextLibroId]), nil
+}
+
+// GetLibroByID devuelve un libro específico por su ID.
+func (repo *MemoryLibroRepository) GetLibroByID(Id int) (Libro, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	libro, ok := repo.db.libros[Id]
+	if !ok {
+		return libro, noEncontrado("libro con ID %d no encontrado", Id)
+	}
+	return repo.db.conDisponibilidad(libro), nil
+}
+
+// UpdateLibro actualiza los datos bibliográficos de un libro existente.
+// Igual que el UPDATE de SQL, si el libro no existe no se modifica nada y no se devuelve error.
+func (repo *MemoryLibroRepository) UpdateLibro(libro Libro) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if _, ok := repo.db.libros[libro.Id]; ok {
+		// Solo se guardan las columnas de la tabla; la disponibilidad se calcula de los ejemplares.
+		repo.db.libros[libro.Id] = Libro{
+			Id:              libro.Id,
+			Titulo:          libro.Titulo,
+			Autor:           libro.Autor,
+			AnioPublicacion: libro.AnioPublicacion,
+			Editorial:       libro.Editorial,
+		}
+		repo.db.indiceLibros.Indexar(libro)
+		repo.db.sugerenciasLibros.Indexar(libro)
+	}
+	return nil
+}
+
+// DeleteLibro elimina un libro por su ID.
+func (repo *MemoryLibroRepository) DeleteLibro(Id int) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if _, ok := repo.db.libros[Id]; !ok {
+		// Mismo error que devuelve MySQL cuando no hay filas afectadas.
+		return noEncontrado("no se encontró ningún libro con ID %d para eliminar", Id)
+	}
+	delete(repo.db.libros, Id)
+	repo.db.indiceLibros.Quitar(Id)
+	repo.db.sugerenciasLibros.Quitar(Id)
+
+	// Emula el ON DELETE CASCADE de las tablas ejemplares, prestamos y reservas.
+	for id, ejemplar := range repo.db.ejemplares {
+		if ejemplar.LibroId == Id {
+			delete(repo.db.ejemplares, id)
+		}
+	}
+	repo.db.eliminarPrestamosSi(func(p Prestamo) bool { return p.LibroId == Id })
+	for id, reserva := range repo.db.reservas {
+		if reserva.LibroId == Id {
+			delete(repo.db.reservas, id)
+		}
+	}
+	return nil
+}
+
+// BuscarLibros busca el texto en el índice de la MemoriaDB, que se actualiza al crear, editar y eliminar libros.
+func (repo *MemoryLibroRepository) BuscarLibros(texto string, limite int) ([]ResultadoBusqueda, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	var resultados []ResultadoBusqueda
+	for _, encontrado := range repo.db.indiceLibros.Buscar(texto, limiteBusqueda(limite)) {
+		if libro, ok := repo.db.libros[encontrado.Id]; ok {
+			resultados = append(resultados, ResultadoBusqueda{Libro: repo.db.conDisponibilidad(libro), Relevancia: encontrado.Relevancia})
+		}
+	}
+	return resultados, nil
+}
+
+// SugerirLibros busca el texto en el trie de sugerencias de la MemoriaDB.
+func (repo *MemoryLibroRepository) SugerirLibros(texto, campo string, limite int) ([]Sugerencia, error) {
+	return repo.db.sugerenciasLibros.Sugerir(texto, campo, limiteSugerencias(limite)), nil
+}
+
+// ContarLibros devuelve la cantidad de títulos y los contadores de ejemplares.
+func (repo *MemoryLibroRepository) ContarLibros() (ResumenLibros, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	resumen := ResumenLibros{Titulos: len(repo.db.libros), Total: len(repo.db.ejemplares)}
+	for _, ejemplar := range repo.db.ejemplares {
+		switch {
+		case ejemplar.Prestado:
+			resumen.Prestados++
+		case repo.db.ejemplarApartado(ejemplar.Id):
+			resumen.Reservados++
+		default:
+			resumen.Disponibles++
+		}
+	}
+	return resumen, nil
+}
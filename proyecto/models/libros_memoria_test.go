@@ -0,0 +1,43 @@
+package models
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMemoryLibroRepository(t *testing.T) {
+	mdb := NewMemoriaDB()
+	probarLibroRepository(t, NewMemoryLibroRepository(mdb), NewMemorySocioRepository(mdb), NewMemoryPrestamoRepository(mdb))
+}
+
+func TestMemoryListarLibros(t *testing.T) {
+	mdb := NewMemoriaDB()
+	probarListarLibros(t, NewMemoryLibroRepository(mdb), NewMemorySocioRepository(mdb), NewMemoryPrestamoRepository(mdb))
+}
+
+func TestMemoryBuscarLibros(t *testing.T) {
+	probarBuscarLibros(t, NewMemoryLibroRepository(NewMemoriaDB()))
+}
+
+func TestMemorySugerirLibros(t *testing.T) {
+	probarSugerirLibros(t, NewMemoryLibroRepository(NewMemoriaDB()))
+}
+
+func TestMemoryLibroRepositoryConcurrente(t *testing.T) {
+	repo := NewMemoryLibroRepository(NewMemoriaDB())
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repo.CreateLibro("Autor", "Título", 2000, "Editorial")
+			repo.GetAllLibros()
+		}()
+	}
+	wg.Wait()
+
+	resumen, _ := repo.ContarLibros()
+	if resumen.Total != 50 {
+		t.Errorf("se esperaban 50 libros, hay %d", resumen.Total)
+	}
+}
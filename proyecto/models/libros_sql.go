@@ -0,0 +1,575 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación del repositorio de libros sobre una base de datos SQL (MySQL o SQLite).
+*/
+
+package models
+
+import (
+	"database/sql" // Paquete para interactuar con bases de datos SQL.
+	"fmt"          // Paquete para formatear cadenas.
+	"log"          // Paquete para logging de errores y mensajes.
+	"strings"      // Paquete para armar las condiciones de la consulta de libros.
+	"sync"         // Paquete para cargar el índice de búsqueda una sola vez.
+	"time"         // Paquete para la fecha de adquisición del primer ejemplar.
+	"unicode/utf8" // Paquete para medir las palabras que busca el índice FULLTEXT.
+)
+
+// consultaLibros selecciona los libros junto con la cantidad de ejemplares totales y disponibles.
+// Las copias apartadas para una reserva no cuentan como disponibles.
+const consultaLibros = `SELECT l.Id, l.Titulo, l.Autor, l.AnioPublicacion, l.Editorial,
+	(SELECT COUNT(*) FROM ejemplares e WHERE e.LibroId = l.Id) AS Ejemplares,
+	(SELECT COUNT(*) FROM ejemplares e WHERE e.LibroId = l.Id AND e.Prestado = FALSE AND NOT ` + ejemplarApartado + `) AS Disponibles
+	FROM libros l`
+
+// consultaLibrosListado envuelve consultaLibros en una tabla derivada para que los filtros y el orden
+// de ListarLibros puedan usar los contadores de ejemplares como si fueran columnas.
+const consultaLibrosListado = `SELECT Id, Titulo, Autor, AnioPublicacion, Editorial, Ejemplares, Disponibles
+	FROM (` + consultaLibros + `) c`
+
+// columnasOrdenLibros asocia cada campo de CamposOrdenLibros con su expresión en consultaLibrosListado.
+var columnasOrdenLibros = map[string]string{
+	"Id":                 "Id",
+	CampoTitulo:          "Titulo",
+	CampoAutor:           "Autor",
+	CampoAnioPublicacion: "AnioPublicacion",
+	CampoEditorial:       "Editorial",
+	CampoPrestado:        "(" + libroPrestadoSQL + ")",
+	"Ejemplares":         "Ejemplares",
+	"Disponibles":        "Disponibles",
+}
+
+// libroPrestadoSQL es la condición de Libro.conDisponibilidad: el libro tiene copias y ninguna está disponible.
+const libroPrestadoSQL = "Ejemplares > 0 AND Disponibles = 0"
+
+// SQLLibroRepository implementa LibroRepository usando un pool de conexiones compartido.
+// Las consultas usan solo SQL común a MySQL y SQLite (marcadores "?", TRUE/FALSE, LastInsertId),
+// por lo que la misma implementación sirve para ambos drivers.
+type SQLLibroRepository struct {
+	db *sql.DB // Pool de conexiones a la base de datos, abierto una sola vez al iniciar la aplicación.
+
+	textoCompleto bool               // BuscarLibros usa el índice FULLTEXT de MySQL en lugar de indice.
+	muIndices     sync.Mutex         // Protege la carga de los índices en memoria.
+	cargados      bool               // Indica si los índices en memoria ya se cargaron.
+	indice        *IndiceLibros      // Índice de búsqueda en memoria; nil si se usa el índice FULLTEXT.
+	sugerencias   *IndiceSugerencias // Trie de sugerencias.
+}
+
+// indiceEnMemoria es un índice de los libros que se mantiene al día con los cambios que hace el repositorio.
+type indiceEnMemoria interface {
+	Indexar(libro Libro)
+	Quitar(Id int)
+}
+
+// NewSQLLibroRepository crea un repositorio de libros que usa la conexión recibida.
+// La conexión no se cierra aquí: su ciclo de vida lo controla quien la abrió (inicio.go).
+// BuscarLibros y SugerirLibros usan índices en memoria que se cargan en la primera consulta y se actualizan
+// con cada alta, edición y eliminación hecha por este repositorio, así que sirven con cualquier driver.
+func NewSQLLibroRepository(db *sql.DB) *SQLLibroRepository {
+	return &SQLLibroRepository{db: db}
+}
+
+// NewSQLLibroRepositoryTextoCompleto crea un repositorio de libros que busca con el índice FULLTEXT
+// idx_libros_busqueda de MySQL (migración 0012) en lugar de mantener un índice en memoria. Las sugerencias
+// siguen usando el trie en memoria, que responde sin consultar la base de datos.
+// La intercalación de las columnas ya compara sin distinguir mayúsculas ni tildes.
+func NewSQLLibroRepositoryTextoCompleto(db *sql.DB) *SQLLibroRepository {
+	return &SQLLibroRepository{db: db, textoCompleto: true}
+}
+
+// GetAllLibros consulta la base de datos y devuelve una lista de todos los libros.
+func (repo *SQLLibroRepository) GetAllLibros() ([]Libro, error) {
+	var libros []Libro // Declara una slice para almacenar los libros.
+	// Ejecuta la consulta SQL para seleccionar todos los campos de todos los libros.
+	rows, err := repo.db.Query(consultaLibros + " ORDER BY l.Id")
+	if err != nil {
+		log.Printf("Error al ejecutar la consulta en GetAllLibros: %v", err)
+		return nil, fmt.Errorf("error al ejecutar la consulta: %w", err)
+	}
+
+	defer rows.Close() // Asegura que las filas de resultados se cierren al finalizar la función.
+	// Itera sobre cada fila de resultados.
+	for rows.Next() {
+		var libro Libro                 // Declara una variable Libro para almacenar los datos de la fila actual.
+		var ejemplares, disponibles int // Contadores de ejemplares del libro.
+		// Escanea los valores de la fila en los campos de la estructura Libro.
+		err := rows.Scan(&libro.Id, &libro.Titulo, &libro.Autor, &libro.AnioPublicacion, &libro.Editorial, &ejemplares, &disponibles)
+		if err != nil {
+			log.Printf("Error al escanear los resultados en GetAllLibros: %v", err)
+			return nil, fmt.Errorf("error al escanear los resultados: %w", err)
+		}
+		libros = append(libros, libro.conDisponibilidad(ejemplares, disponibles)) // Agrega el libro a la slice de libros.
+	}
+
+	// Verifica si hubo algún error durante la iteración de las filas.
+	if err = rows.Err(); err != nil {
+		log.Printf("Error al procesar los resultados de la base de datos en GetAllLibros: %v", err)
+		return nil, fmt.Errorf("error al procesar los resultados: %w", err)
+	}
+
+	return libros, nil // Devuelve la lista de libros y nil si no hay errores.
+}
+
+// ListarLibros devuelve una página de libros. Los filtros, el orden, el recorte de la página y el total
+// se resuelven en la base de datos, así que solo se leen las filas de la página.
+func (repo *SQLLibroRepository) ListarLibros(consulta ConsultaLibros) (PaginaLibros, error) {
+	consulta, cursor, err := consulta.preparar()
+	if err != nil {
+		return PaginaLibros{}, err
+	}
+	var pagina PaginaLibros
+	condiciones, args := filtrosLibrosSQL(consulta)
+
+	// El total cuenta los libros que cumplen los filtros, sin tener en cuenta la página.
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM ("+consultaLibrosListado+donde(condiciones)+") t", args...).Scan(&pagina.Total); err != nil {
+		log.Printf("Error al contar los libros en ListarLibros: %v", err)
+		return pagina, fmt.Errorf("error al contar los libros: %w", err)
+	}
+
+	columna, direccion, comparacion := columnasOrdenLibros[consulta.Orden], "ASC", ">"
+	if consulta.Descendente {
+		direccion, comparacion = "DESC", "<"
+	}
+	if cursor != nil {
+		// Paginación por cursor: la página empieza en el primer libro que va después del último de la
+		// página anterior, comparando el valor del campo de orden y, a igualdad de valor, el Id.
+		var valor any = cursor.Numero
+		if esCampoTextoLibro(consulta.Orden) {
+			valor = cursor.Texto
+		}
+		if consulta.Orden == "Id" {
+			condiciones = append(condiciones, "Id "+comparacion+" ?")
+			args = append(args, cursor.Id)
+		} else {
+			condiciones = append(condiciones, "("+columna+" "+comparacion+" ? OR ("+columna+" = ? AND Id "+comparacion+" ?))")
+			args = append(args, valor, valor, cursor.Id)
+		}
+	}
+	orden := " ORDER BY " + columna + " " + direccion
+	if consulta.Orden != "Id" {
+		orden += ", Id " + direccion
+	}
+	// Se lee un libro más que el límite para saber si hay una página siguiente.
+	args = append(args, consulta.Limite+1, consulta.Desplazamiento)
+	rows, err := repo.db.Query(consultaLibrosListado+donde(condiciones)+orden+" LIMIT ? OFFSET ?", args...)
+	if err != nil {
+		log.Printf("Error al ejecutar la consulta en ListarLibros: %v", err)
+		return pagina, fmt.Errorf("error al ejecutar la consulta: %w", err)
+	}
+	defer rows.Close()
+
+	libros, err := escanearLibros(rows)
+	if err != nil {
+		log.Printf("Error al leer los resultados en ListarLibros: %v", err)
+		return pagina, err
+	}
+	if len(libros) > consulta.Limite {
+		libros = libros[:consulta.Limite]
+		pagina.Siguiente = consulta.siguiente(libros[len(libros)-1])
+	}
+	pagina.Libros = libros
+	return pagina, nil
+}
+
+// filtrosLibrosSQL traduce los filtros de la consulta en condiciones sobre consultaLibrosListado y sus argumentos.
+// Los textos se buscan con LIKE, que no distingue mayúsculas en MySQL ni, para las letras ASCII, en SQLite.
+func filtrosLibrosSQL(consulta ConsultaLibros) ([]string, []any) {
+	var condiciones []string
+	var args []any
+	if consulta.Autor != "" {
+		condiciones = append(condiciones, "Autor LIKE ? ESCAPE '!'")
+		args = append(args, "%"+escaparLike(consulta.Autor)+"%")
+	}
+	if consulta.Editorial != "" {
+		condiciones = append(condiciones, "Editorial LIKE ? ESCAPE '!'")
+		args = append(args, "%"+escaparLike(consulta.Editorial)+"%")
+	}
+	if consulta.AnioDesde != 0 {
+		condiciones = append(condiciones, "AnioPublicacion >= ?")
+		args = append(args, consulta.AnioDesde)
+	}
+	if consulta.AnioHasta != 0 {
+		condiciones = append(condiciones, "AnioPublicacion <= ?")
+		args = append(args, consulta.AnioHasta)
+	}
+	if consulta.Prestado != nil {
+		if *consulta.Prestado {
+			condiciones = append(condiciones, "("+libroPrestadoSQL+")")
+		} else {
+			condiciones = append(condiciones, "NOT ("+libroPrestadoSQL+")")
+		}
+	}
+	return condiciones, args
+}
+
+// donde une las condiciones en una cláusula WHERE, o devuelve un texto vacío si no hay condiciones.
+func donde(condiciones []string) string {
+	if len(condiciones) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(condiciones, " AND ")
+}
+
+// escaparLike escapa los comodines de LIKE para que el texto se busque tal cual. Se usa "!" como carácter
+// de escape porque la barra invertida tiene otro significado en las cadenas de MySQL.
+func escaparLike(texto string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(texto)
+}
+
+// escanearLibros lee las filas de consultaLibros, completando la disponibilidad de cada libro.
+func escanearLibros(rows *sql.Rows) ([]Libro, error) {
+	var libros []Libro
+	for rows.Next() {
+		var libro Libro
+		var ejemplares, disponibles int
+		if err := rows.Scan(&libro.Id, &libro.Titulo, &libro.Autor, &libro.AnioPublicacion, &libro.Editorial, &ejemplares, &disponibles); err != nil {
+			return nil, fmt.Errorf("error al escanear los resultados: %w", err)
+		}
+		libros = append(libros, libro.conDisponibilidad(ejemplares, disponibles))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al procesar los resultados: %w", err)
+	}
+	return libros, nil
+}
+
+// CreateLibro inserta un nuevo libro en la base de datos junto con su primer ejemplar.
+// Ambas inserciones se hacen en una transacción para que no quede un libro sin su copia inicial.
+func (repo *SQLLibroRepository) CreateLibro(Autor string, Titulo string, AnioPublicacion int, Editorial string) (Libro, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return Libro{}, fmt.Errorf("error al iniciar la transacción: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya se confirmó.
+
+	// Ejecuta la sentencia con marcadores "?", lo que previene inyecciones SQL.
+	resultado, err := tx.Exec("INSERT INTO libros (Autor, Titulo, AnioPublicacion, Editorial) VALUES (?, ?, ?, ?)",
+		Autor, Titulo, AnioPublicacion, Editorial)
+	if err != nil {
+		log.Printf("Error al ejecutar la inserción del libro: %v", err)
+		return Libro{}, fmt.Errorf("error al insertar el libro: %w", err)
+	}
+
+	// Obtiene el ID del último libro insertado.
+	lastInsertId, err := resultado.LastInsertId()
+	if err != nil {
+		log.Printf("Error al obtener el ID del último libro insertado en CreateLibro: %v", err)
+		return Libro{}, fmt.Errorf("error al obtener el ID del último libro insertado: %w", err)
+	}
+
+	// Registra la primera copia del libro con un código de barras generado.
+	_, err = tx.Exec("INSERT INTO ejemplares (LibroId, CodigoBarras, Ubicacion, Condicion, FechaAdquisicion, Prestado) VALUES (?, ?, ?, ?, ?, FALSE)",
+		lastInsertId, CodigoBarrasSugerido(int(lastInsertId), 1), "", CondicionBueno, time.Now().Truncate(time.Second))
+	if err != nil {
+		log.Printf("Error al insertar el primer ejemplar del libro %d: %v", lastInsertId, err)
+		return Libro{}, fmt.Errorf("error al insertar el ejemplar: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Libro{}, fmt.Errorf("error al confirmar la inserción del libro: %w", err)
+	}
+	log.Printf("Libro insertado con éxito. ID: %d", lastInsertId)
+	libro := Libro{Id: int(lastInsertId), Titulo: Titulo, Autor: Autor, AnioPublicacion: AnioPublicacion, Editorial: Editorial}
+	repo.actualizarIndices(func(indice indiceEnMemoria) { indice.Indexar(libro) })
+
+	// El libro tiene una sola copia, disponible.
+	return libro.conDisponibilidad(1, 1), nil
+}
+
+// GetLibroByID consulta la base de datos y devuelve un libro específico por su ID.
+func (repo *SQLLibroRepository) GetLibroByID(Id int) (Libro, error) {
+	var libro Libro // Declara una variable Libro para almacenar el resultado.
+	// Prepara la sentencia SQL para seleccionar un libro por su ID.
+	stmt, err := repo.db.Prepare(consultaLibros + " WHERE l.Id = ?")
+	if err != nil {
+		log.Printf("Error al preparar la consulta en GetLibroByID: %v", err)
+		return libro, fmt.Errorf("error al preparar la consulta: %w", err)
+	}
+	defer stmt.Close() // Asegura que la sentencia preparada se cierre.
+
+	// Ejecuta la consulta y escanea el resultado en la estructura Libro.
+	var ejemplares, disponibles int
+	fila := stmt.QueryRow(Id)
+	err = fila.Scan(&libro.Id, &libro.Titulo, &libro.Autor, &libro.AnioPublicacion, &libro.Editorial, &ejemplares, &disponibles)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Si no se encuentra ninguna fila, devuelve un error específico.
+			return libro, noEncontrado("libro con ID %d no encontrado", Id)
+		}
+		log.Printf("Error al escanear el libro con ID %d: %v", Id, err)
+		return libro, fmt.Errorf("error al obtener el libro: %w", err)
+	}
+	libro = libro.conDisponibilidad(ejemplares, disponibles)
+	log.Printf("Libro obtenido con éxito: %+v", libro)
+	return libro, nil // Devuelve el libro y nil si no hay errores.
+}
+
+// UpdateLibro actualiza los datos bibliográficos de un libro existente en la base de datos.
+func (repo *SQLLibroRepository) UpdateLibro(libro Libro) error {
+	// Prepara la sentencia SQL para actualizar un libro.
+	// Prestado no se guarda: se calcula a partir de los ejemplares.
+	stmt, err := repo.db.Prepare("UPDATE libros SET Titulo = ?, Autor = ?, AnioPublicacion = ?, Editorial = ? WHERE Id = ?")
+	if err != nil {
+		log.Printf("Error al preparar la sentencia UPDATE en UpdateLibro: %v", err)
+		return fmt.Errorf("error al preparar la sentencia: %w", err)
+	}
+	defer stmt.Close()
+
+	// Ejecuta la sentencia preparada con los datos actualizados del libro.
+	resultado, err := stmt.Exec(libro.Titulo, libro.Autor, libro.AnioPublicacion, libro.Editorial, libro.Id)
+	if err != nil {
+		log.Printf("Error al ejecutar la actualización del libro con ID %d: %v", libro.Id, err)
+		return fmt.Errorf("error al actualizar el libro: %w", err)
+	}
+	// MySQL no cuenta las filas que no cambiaron, pero en ese caso el índice ya tiene los textos del libro.
+	if filasAfectadas, err := resultado.RowsAffected(); err == nil && filasAfectadas > 0 {
+		repo.actualizarIndices(func(indice indiceEnMemoria) { indice.Indexar(libro) })
+	}
+	log.Printf("Libro con ID %d actualizado con éxito.", libro.Id)
+	return nil
+}
+
+// DeleteLibro elimina un libro de la base de datos por su ID.
+func (repo *SQLLibroRepository) DeleteLibro(Id int) error {
+	// Prepara la sentencia SQL para eliminar un libro.
+	// Sus ejemplares y préstamos se eliminan por el ON DELETE CASCADE de las claves foráneas.
+	stmt, err := repo.db.Prepare("DELETE FROM libros WHERE Id = ?")
+	if err != nil {
+		log.Printf("Error al preparar la sentencia DELETE en DeleteLibro: %v", err)
+		return fmt.Errorf("error al preparar la sentencia: %w", err)
+	}
+	defer stmt.Close()
+
+	// Ejecuta la sentencia preparada con el ID del libro a eliminar.
+	resultado, err := stmt.Exec(Id)
+	if err != nil {
+		log.Printf("Error al ejecutar la eliminación del libro con ID %d: %v", Id, err)
+		return fmt.Errorf("error al eliminar el libro: %w", err)
+	}
+
+	filasAfectadas, err := resultado.RowsAffected()
+	if err != nil {
+		log.Printf("Error al obtener las filas afectadas en DeleteLibro: %v", err)
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+
+	if filasAfectadas == 0 {
+		return noEncontrado("no se encontró ningún libro con ID %d para eliminar", Id)
+	}
+	repo.actualizarIndices(func(indice indiceEnMemoria) { indice.Quitar(Id) })
+	log.Printf("Libro con ID %d eliminado con éxito.", Id)
+	return nil
+}
+
+// BuscarLibros busca el texto con el índice FULLTEXT de MySQL o con el índice en memoria, según cómo
+// se creó el repositorio, y después lee los datos completos de los libros encontrados.
+func (repo *SQLLibroRepository) BuscarLibros(texto string, limite int) ([]ResultadoBusqueda, error) {
+	limite = limiteBusqueda(limite)
+	var encontrados []resultadoIndice
+	if repo.textoCompleto {
+		var err error
+		if encontrados, err = repo.buscarTextoCompleto(texto, limite); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := repo.cargarIndices(); err != nil {
+			return nil, err
+		}
+		encontrados = repo.indice.Buscar(texto, limite)
+	}
+	if len(encontrados) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]any, len(encontrados))
+	for i, encontrado := range encontrados {
+		ids[i] = encontrado.Id
+	}
+	rows, err := repo.db.Query(consultaLibros+" WHERE l.Id IN (?"+strings.Repeat(", ?", len(ids)-1)+")", ids...)
+	if err != nil {
+		log.Printf("Error al leer los libros encontrados: %v", err)
+		return nil, fmt.Errorf("error al leer los libros encontrados: %w", err)
+	}
+	defer rows.Close()
+	libros, err := escanearLibros(rows)
+	if err != nil {
+		return nil, err
+	}
+	porId := make(map[int]Libro, len(libros))
+	for _, libro := range libros {
+		porId[libro.Id] = libro
+	}
+
+	// Los libros se devuelven en el orden de relevancia; se saltea el que se haya eliminado entretanto.
+	var resultados []ResultadoBusqueda
+	for _, encontrado := range encontrados {
+		if libro, ok := porId[encontrado.Id]; ok {
+			resultados = append(resultados, ResultadoBusqueda{Libro: libro, Relevancia: encontrado.Relevancia})
+		}
+	}
+	return resultados, nil
+}
+
+// Reglas del índice FULLTEXT de InnoDB: en modo booleano descarta las palabras más cortas que
+// innodb_ft_min_token_size y las de su lista de palabras vacías, aunque lleven "+".
+const longitudMinimaTextoCompleto = 3
+
+// palabrasVaciasTextoCompleto es la lista de palabras vacías por defecto de InnoDB
+// (INFORMATION_SCHEMA.INNODB_FT_DEFAULT_STOPWORD).
+var palabrasVaciasTextoCompleto = map[string]bool{
+	"a": true, "about": true, "an": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"com": true, "de": true, "en": true, "for": true, "from": true, "how": true, "i": true, "in": true,
+	"is": true, "it": true, "la": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "was": true, "what": true, "when": true, "where": true, "who": true,
+	"will": true, "with": true, "und": true, "www": true,
+}
+
+// separarPalabrasTextoCompleto separa las palabras que el índice FULLTEXT puede buscar de las que
+// ignoraría, que se buscan con LIKE para que todas sigan siendo obligatorias, igual que en IndiceLibros.
+func separarPalabrasTextoCompleto(palabras []string) (indexadas, sinIndice []string) {
+	for _, palabra := range palabras {
+		if utf8.RuneCountInString(palabra) < longitudMinimaTextoCompleto || palabrasVaciasTextoCompleto[palabra] {
+			sinIndice = append(sinIndice, palabra)
+		} else {
+			indexadas = append(indexadas, palabra)
+		}
+	}
+	return indexadas, sinIndice
+}
+
+// buscarTextoCompleto busca con MATCH ... AGAINST en modo booleano: cada palabra es obligatoria y
+// también coincide como comienzo de una palabra ("garc" encuentra "García"), igual que en IndiceLibros.
+// Las palabras que el índice ignora se exigen con LIKE al comienzo de alguna palabra de los campos; si
+// solo hay palabras de ese tipo, todos los resultados tienen la misma relevancia.
+func (repo *SQLLibroRepository) buscarTextoCompleto(texto string, limite int) ([]resultadoIndice, error) {
+	palabras := palabrasBusqueda(texto)
+	if len(palabras) == 0 {
+		return nil, nil
+	}
+	indexadas, sinIndice := separarPalabrasTextoCompleto(palabras)
+
+	relevancia := "1"
+	var condiciones []string
+	var args []any
+	if len(indexadas) > 0 {
+		consulta := "+" + strings.Join(indexadas, "* +") + "*"
+		relevancia = "MATCH(Titulo, Autor, Editorial) AGAINST (? IN BOOLEAN MODE)"
+		condiciones = append(condiciones, relevancia)
+		args = append(args, consulta, consulta)
+	}
+	for _, palabra := range sinIndice {
+		// Las palabras solo tienen letras y números, así que no hace falta escapar "%" ni "_".
+		condiciones = append(condiciones, "CONCAT(' ', Titulo, ' ', Autor, ' ', Editorial) LIKE ?")
+		args = append(args, "% "+palabra+"%")
+	}
+	args = append(args, limite)
+	rows, err := repo.db.Query(`SELECT Id, `+relevancia+` AS Relevancia
+		FROM libros WHERE `+strings.Join(condiciones, " AND ")+`
+		ORDER BY Relevancia DESC, Titulo, Id LIMIT ?`, args...)
+	if err != nil {
+		log.Printf("Error al buscar libros con el índice FULLTEXT: %v", err)
+		return nil, fmt.Errorf("error al buscar libros: %w", err)
+	}
+	defer rows.Close()
+
+	var encontrados []resultadoIndice
+	for rows.Next() {
+		var encontrado resultadoIndice
+		if err := rows.Scan(&encontrado.Id, &encontrado.Relevancia); err != nil {
+			return nil, fmt.Errorf("error al escanear los resultados: %w", err)
+		}
+		encontrados = append(encontrados, encontrado)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al procesar los resultados: %w", err)
+	}
+	return encontrados, nil
+}
+
+// SugerirLibros busca el texto en el trie de sugerencias, que se carga con todos los libros la primera vez.
+func (repo *SQLLibroRepository) SugerirLibros(texto, campo string, limite int) ([]Sugerencia, error) {
+	if err := repo.cargarIndices(); err != nil {
+		return nil, err
+	}
+	return repo.sugerencias.Sugerir(texto, campo, limiteSugerencias(limite)), nil
+}
+
+// cargarIndices carga los índices en memoria con todos los libros la primera vez que se llama.
+// Si la carga falla, la próxima consulta vuelve a intentarlo.
+func (repo *SQLLibroRepository) cargarIndices() error {
+	repo.muIndices.Lock()
+	defer repo.muIndices.Unlock()
+	if repo.cargados {
+		return nil
+	}
+
+	libros, err := repo.GetAllLibros()
+	if err != nil {
+		return err
+	}
+	if !repo.textoCompleto {
+		repo.indice = NewIndiceLibros()
+	}
+	repo.sugerencias = NewIndiceSugerencias()
+	repo.cargados = true
+	for _, indice := range repo.indicesEnMemoria() {
+		for _, libro := range libros {
+			indice.Indexar(libro)
+		}
+	}
+	log.Printf("Índices de búsqueda cargados con %d libros.", len(libros))
+	return nil
+}
+
+// actualizarIndices aplica un cambio ya confirmado en la base de datos a los índices en memoria, si están
+// cargados. Si todavía no se cargaron no hace nada: la carga leerá el cambio de la base de datos.
+func (repo *SQLLibroRepository) actualizarIndices(cambio func(indice indiceEnMemoria)) {
+	repo.muIndices.Lock()
+	defer repo.muIndices.Unlock()
+	for _, indice := range repo.indicesEnMemoria() {
+		cambio(indice)
+	}
+}
+
+// indicesEnMemoria devuelve los índices cargados. Debe llamarse con el mutex tomado.
+func (repo *SQLLibroRepository) indicesEnMemoria() []indiceEnMemoria {
+	if !repo.cargados {
+		return nil
+	}
+	indices := []indiceEnMemoria{repo.sugerencias}
+	if repo.indice != nil {
+		indices = append(indices, repo.indice)
+	}
+	return indices
+}
+
+// ContarLibros devuelve la cantidad de títulos y los contadores de ejemplares para el dashboard.
+func (repo *SQLLibroRepository) ContarLibros() (ResumenLibros, error) {
+	var resumen ResumenLibros
+	// Contar los títulos registrados
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM libros").Scan(&resumen.Titulos); err != nil {
+		log.Printf("Error al contar los títulos: %v", err)
+		return resumen, fmt.Errorf("error al contar los títulos: %w", err)
+	}
+	// Contar el total de ejemplares
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM ejemplares").Scan(&resumen.Total); err != nil {
+		log.Printf("Error al contar ejemplares totales: %v", err)
+		return resumen, fmt.Errorf("error al contar ejemplares totales: %w", err)
+	}
+	// Contar ejemplares no prestados ni apartados para una reserva (disponibles)
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM ejemplares e WHERE e.Prestado = FALSE AND NOT " + ejemplarApartado).Scan(&resumen.Disponibles); err != nil {
+		log.Printf("Error al contar ejemplares disponibles: %v", err)
+		return resumen, fmt.Errorf("error al contar ejemplares disponibles: %w", err)
+	}
+	// Contar ejemplares prestados
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM ejemplares WHERE Prestado = TRUE").Scan(&resumen.Prestados); err != nil {
+		log.Printf("Error al contar ejemplares prestados: %v", err)
+		return resumen, fmt.Errorf("error al contar ejemplares prestados: %w", err)
+	}
+	// Contar ejemplares apartados para una reserva
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM ejemplares e WHERE e.Prestado = FALSE AND " + ejemplarApartado).Scan(&resumen.Reservados); err != nil {
+		log.Printf("Error al contar ejemplares reservados: %v", err)
+		return resumen, fmt.Errorf("error al contar ejemplares reservados: %w", err)
+	}
+	return resumen, nil
+}
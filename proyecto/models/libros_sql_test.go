@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSQLLibroRepositorySQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	probarLibroRepository(t, NewSQLLibroRepository(conexion), NewSQLSocioRepository(conexion), NewSQLPrestamoRepository(conexion))
+}
+
+func TestSQLListarLibrosSQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	probarListarLibros(t, NewSQLLibroRepository(conexion), NewSQLSocioRepository(conexion), NewSQLPrestamoRepository(conexion))
+}
+
+func TestSQLBuscarLibrosSQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	// Los libros se crean antes de la primera búsqueda, así que se prueba tanto la carga del índice
+	// como su actualización con los cambios posteriores.
+	probarBuscarLibros(t, NewSQLLibroRepository(conexion))
+}
+
+func TestSepararPalabrasTextoCompleto(t *testing.T) {
+	casos := []struct {
+		texto     string
+		indexadas string
+		sinIndice string
+	}{
+		{"garcia marquez", "garcia marquez", ""},
+		{"el amor", "amor", "el"},
+		{"go", "", "go"},
+		{"la casa de los espiritus", "casa los espiritus", "la de"},
+		{"the who", "", "the who"},
+	}
+	for _, c := range casos {
+		indexadas, sinIndice := separarPalabrasTextoCompleto(palabrasBusqueda(c.texto))
+		if strings.Join(indexadas, " ") != c.indexadas || strings.Join(sinIndice, " ") != c.sinIndice {
+			t.Errorf("separarPalabrasTextoCompleto(%q) = %q, %q; se esperaba %q, %q", c.texto, indexadas, sinIndice, c.indexadas, c.sinIndice)
+		}
+	}
+}
+
+func TestSQLSugerirLibrosSQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	probarSugerirLibros(t, NewSQLLibroRepository(conexion))
+	// El repositorio de MySQL busca con FULLTEXT, pero las sugerencias salen del mismo trie en memoria.
+	if sugerencias, err := NewSQLLibroRepositoryTextoCompleto(conexion).SugerirLibros("borg", "", 0); err != nil || len(sugerencias) != 1 {
+		t.Errorf("SugerirLibros con texto completo = %+v, %v", sugerencias, err)
+	}
+}
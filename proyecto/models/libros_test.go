@@ -0,0 +1,381 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// probarLibroRepository verifica el comportamiento común que debe cumplir cualquier LibroRepository.
+// Las implementaciones en memoria y SQL se prueban con el mismo contrato para que no diverjan.
+// socios y prestamos deben compartir la base de datos con repo; se usan para prestar un ejemplar.
+func probarLibroRepository(t *testing.T, repo LibroRepository, socios SocioRepository, prestamos PrestamoRepository) {
+	t.Helper()
+	if creado, err := repo.CreateLibro("Borges", "Ficciones", 1944, "Sur"); err != nil || creado.Id != 1 || creado.Ejemplares != 1 || creado.Disponibles != 1 {
+		t.Fatalf("CreateLibro = %+v, %v", creado, err)
+	}
+	// CreateLibro devuelve el libro tal como quedó guardado, con su ID y su disponibilidad.
+	if creado, err := repo.CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana"); err != nil || creado.Id != 2 || creado.Titulo != "Rayuela" || creado.Disponibles != 1 || creado.Prestado {
+		t.Fatalf("CreateLibro = %+v, %v", creado, err)
+	}
+
+	// Cada libro nuevo tiene un ejemplar disponible; el de Rayuela solo deja de estarlo con un préstamo.
+	prestarLibroPrueba(t, socios, prestamos, 2)
+	libro, err := repo.GetLibroByID(2)
+	if err != nil || libro.Titulo != "Rayuela" || libro.Ejemplares != 1 || libro.Disponibles != 0 || !libro.Prestado {
+		t.Fatalf("GetLibroByID(2) = %+v, %v", libro, err)
+	}
+
+	// UpdateLibro no cambia la disponibilidad, que depende de los ejemplares.
+	libro.Editorial = "Alfaguara"
+	libro.Prestado = false
+	if err := repo.UpdateLibro(libro); err != nil {
+		t.Fatalf("UpdateLibro: %v", err)
+	}
+	if libro, _ := repo.GetLibroByID(2); libro.Editorial != "Alfaguara" || !libro.Prestado {
+		t.Errorf("después de UpdateLibro = %+v", libro)
+	}
+
+	resumen, err := repo.ContarLibros()
+	if err != nil || resumen != (ResumenLibros{Titulos: 2, Total: 2, Disponibles: 1, Prestados: 1}) {
+		t.Errorf("ContarLibros = %+v, %v", resumen, err)
+	}
+
+	if err := repo.DeleteLibro(1); err != nil {
+		t.Fatalf("DeleteLibro: %v", err)
+	}
+	if err := repo.DeleteLibro(1); !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "no se encontró ningún libro con ID 1") {
+		t.Errorf("DeleteLibro de un libro inexistente devolvió %v", err)
+	}
+	if _, err := repo.GetLibroByID(1); !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "libro con ID 1 no encontrado") {
+		t.Errorf("GetLibroByID de un libro eliminado devolvió %v", err)
+	}
+
+	// Los IDs no se reutilizan después de eliminar, igual que AUTO_INCREMENT.
+	repo.CreateLibro("Sabato", "El túnel", 1948, "Sur")
+	libros, _ := repo.GetAllLibros()
+	if len(libros) != 2 || libros[0].Id != 2 || libros[1].Id != 3 {
+		t.Errorf("GetAllLibros = %+v", libros)
+	}
+}
+
+// prestarLibroPrueba presta el único ejemplar del libro a un socio nuevo, para las pruebas que necesitan
+// un libro sin copias disponibles.
+func prestarLibroPrueba(t *testing.T, socios SocioRepository, prestamos PrestamoRepository, LibroId int) {
+	t.Helper()
+	socio, err := socios.CreateSocio(Socio{Nombre: "Socio de prueba", Estado: EstadoSocioActivo})
+	if err != nil {
+		t.Fatalf("CreateSocio: %v", err)
+	}
+	if _, err := prestamos.PrestarLibro(LibroId, socio.Id, time.Now().AddDate(0, 0, 7)); err != nil {
+		t.Fatalf("PrestarLibro(%d): %v", LibroId, err)
+	}
+}
+
+// probarListarLibros verifica los filtros, el orden y la paginación de ListarLibros sobre un repositorio vacío.
+// socios y prestamos deben compartir la base de datos con repo; se usan para prestar el ejemplar de Rayuela.
+func probarListarLibros(t *testing.T, repo LibroRepository, socios SocioRepository, prestamos PrestamoRepository) {
+	t.Helper()
+	repo.CreateLibro("Jorge Luis Borges", "Ficciones", 1944, "Sur")                       // ID 1
+	repo.CreateLibro("Julio Cortázar", "Rayuela", 1963, "Sudamericana")                   // ID 2
+	repo.CreateLibro("Jorge Luis Borges", "El Aleph", 1949, "Losada")                     // ID 3
+	repo.CreateLibro("Ernesto Sabato", "El túnel", 1948, "Sur")                           // ID 4
+	repo.CreateLibro("Adolfo Bioy Casares", "La invención de Morel", 1940, "Losada_100%") // ID 5
+	prestarLibroPrueba(t, socios, prestamos, 2)
+
+	ids := func(pagina PaginaLibros) string {
+		var ids []string
+		for _, libro := range pagina.Libros {
+			ids = append(ids, strconv.Itoa(libro.Id))
+		}
+		return strings.Join(ids, ",")
+	}
+	si, no := true, false
+	casos := []struct {
+		nombre   string
+		consulta ConsultaLibros
+		ids      string
+		total    int
+	}{
+		{"todos", ConsultaLibros{}, "1,2,3,4,5", 5},
+		{"autor", ConsultaLibros{Autor: "borges"}, "1,3", 2},
+		{"editorial y año", ConsultaLibros{Editorial: "SUR", AnioDesde: 1945}, "4", 1},
+		{"rango de años", ConsultaLibros{AnioDesde: 1944, AnioHasta: 1948, Orden: CampoAnioPublicacion}, "1,4", 2},
+		{"prestados", ConsultaLibros{Prestado: &si}, "2", 1},
+		{"disponibles", ConsultaLibros{Prestado: &no, Orden: CampoPrestado, Descendente: true}, "5,4,3,1", 4},
+		{"comodines literales", ConsultaLibros{Editorial: "_100%"}, "5", 1},
+		{"comodín sin coincidencias", ConsultaLibros{Autor: "%"}, "", 0},
+		{"por título", ConsultaLibros{Orden: CampoTitulo}, "3,4,1,5,2", 5},
+		{"desplazamiento", ConsultaLibros{Orden: CampoTitulo, Desplazamiento: 3, Limite: 10}, "5,2", 5},
+		{"desplazamiento fuera de rango", ConsultaLibros{Desplazamiento: 10}, "", 5},
+	}
+	for _, c := range casos {
+		pagina, err := repo.ListarLibros(c.consulta)
+		if err != nil || ids(pagina) != c.ids || pagina.Total != c.total || pagina.Siguiente != "" {
+			t.Errorf("%s: ListarLibros = [%s] total %d siguiente %q, %v; se esperaba [%s] total %d", c.nombre, ids(pagina), pagina.Total, pagina.Siguiente, err, c.ids, c.total)
+		}
+	}
+
+	// Recorre las páginas con el cursor; los dos libros de Borges quedan en páginas distintas,
+	// así que el cursor tiene que desempatar por Id.
+	recorrer := func(consulta ConsultaLibros) string {
+		var paginas []string
+		for i := 0; i < 5; i++ {
+			pagina, err := repo.ListarLibros(consulta)
+			if err != nil || pagina.Total != 5 {
+				t.Fatalf("ListarLibros(%+v) = %+v, %v", consulta, pagina, err)
+			}
+			paginas = append(paginas, ids(pagina))
+			if pagina.Siguiente == "" {
+				break
+			}
+			consulta.Cursor = pagina.Siguiente
+		}
+		return strings.Join(paginas, " | ")
+	}
+	if got := recorrer(ConsultaLibros{Orden: CampoAutor, Limite: 3}); got != "5,4,1 | 3,2" {
+		t.Errorf("páginas por autor = %s", got)
+	}
+	if got := recorrer(ConsultaLibros{Orden: CampoAnioPublicacion, Descendente: true, Limite: 2}); got != "2,3 | 4,1 | 5" {
+		t.Errorf("páginas por año descendente = %s", got)
+	}
+	if got := recorrer(ConsultaLibros{Limite: 3}); got != "1,2,3 | 4,5" {
+		t.Errorf("páginas por Id = %s", got)
+	}
+
+	// Las consultas inválidas se rechazan con un error por parámetro.
+	primera, _ := repo.ListarLibros(ConsultaLibros{Orden: CampoTitulo, Limite: 1})
+	invalidas := []struct {
+		consulta  ConsultaLibros
+		parametro string
+	}{
+		{ConsultaLibros{Orden: "Precio"}, ParametroOrden},
+		{ConsultaLibros{Limite: LimiteLibrosMaximo + 1}, ParametroLimite},
+		{ConsultaLibros{Desplazamiento: -1}, ParametroDesplazamiento},
+		{ConsultaLibros{AnioDesde: 1960, AnioHasta: 1950}, ParametroAnioHasta},
+		{ConsultaLibros{Cursor: "no-es-un-cursor"}, ParametroCursor},
+		{ConsultaLibros{Orden: CampoAutor, Cursor: primera.Siguiente}, ParametroCursor},
+		{ConsultaLibros{Orden: CampoTitulo, Cursor: primera.Siguiente, Desplazamiento: 1}, ParametroCursor},
+	}
+	for _, c := range invalidas {
+		var errores ErroresValidacion
+		if _, err := repo.ListarLibros(c.consulta); !errors.As(err, &errores) || errores[c.parametro] == "" {
+			t.Errorf("ListarLibros(%+v) devolvió %v, se esperaba un error en %s", c.consulta, err, c.parametro)
+		}
+	}
+}
+
+// probarBuscarLibros verifica la búsqueda por texto de BuscarLibros sobre un repositorio vacío: sin distinguir
+// mayúsculas ni tildes, con todas las palabras obligatorias y los resultados de mayor a menor relevancia.
+func probarBuscarLibros(t *testing.T, repo LibroRepository) {
+	t.Helper()
+	repo.CreateLibro("Gabriel García Márquez", "Cien años de soledad", 1967, "Sudamericana")             // ID 1
+	repo.CreateLibro("Gabriel García Márquez", "El amor en los tiempos del cólera", 1985, "Oveja Negra") // ID 2
+	repo.CreateLibro("Julio Cortázar", "Rayuela", 1963, "Sudamericana")                                  // ID 3
+	repo.CreateLibro("Federico García Lorca", "Romancero gitano", 1928, "Revista de Occidente")          // ID 4
+	repo.CreateLibro("Mario Goloboff", "Sobre Cortázar", 1998, "Seix Barral")                            // ID 5
+
+	buscar := func(texto string, limite int) string {
+		resultados, err := repo.BuscarLibros(texto, limite)
+		if err != nil {
+			t.Fatalf("BuscarLibros(%q): %v", texto, err)
+		}
+		var ids []string
+		for i, resultado := range resultados {
+			if resultado.Relevancia <= 0 || (i > 0 && resultado.Relevancia > resultados[i-1].Relevancia) {
+				t.Errorf("BuscarLibros(%q): relevancias fuera de orden: %+v", texto, resultados)
+			}
+			ids = append(ids, strconv.Itoa(resultado.Libro.Id))
+		}
+		return strings.Join(ids, ",")
+	}
+	casos := []struct {
+		nombre string
+		texto  string
+		limite int
+		ids    string
+	}{
+		{"sin tildes", "garcia marquez", 0, "1,2"},
+		{"mayúsculas y tildes", "GARCÍA MÁRQUEZ", 0, "1,2"},
+		{"a igual relevancia por título", "garcía", 0, "1,2,4"},
+		{"el título pesa más que el autor", "cortazar", 0, "5,3"},
+		{"editorial", "sudamericana", 0, "1,3"},
+		{"comienzo de palabra", "garc marq", 0, "1,2"},
+		{"todas las palabras", "garcia rayuela", 0, ""},
+		{"palabra corta", "el amor", 0, "2"},
+		{"solo palabras cortas", "el", 0, "2"},
+		{"sin coincidencias", "borges", 0, ""},
+		{"solo puntuación", " ¡! ", 0, ""},
+		{"límite", "garcia", 2, "1,2"},
+	}
+	for _, c := range casos {
+		if got := buscar(c.texto, c.limite); got != c.ids {
+			t.Errorf("%s: BuscarLibros(%q) = [%s], se esperaba [%s]", c.nombre, c.texto, got, c.ids)
+		}
+	}
+
+	// La búsqueda sigue los cambios de los libros.
+	libro, _ := repo.GetLibroByID(3)
+	libro.Titulo = "Rayuela (edición crítica)"
+	repo.UpdateLibro(libro)
+	if got := buscar("critica", 0); got != "3" {
+		t.Errorf("después de UpdateLibro: BuscarLibros(critica) = [%s]", got)
+	}
+	repo.DeleteLibro(1)
+	if got := buscar("garcia marquez", 0); got != "2" {
+		t.Errorf("después de DeleteLibro: BuscarLibros(garcia marquez) = [%s]", got)
+	}
+	repo.CreateLibro("Gabriel García Márquez", "Crónica de una muerte anunciada", 1981, "Oveja Negra") // ID 6
+	if got := buscar("cronica", 0); got != "6" {
+		t.Errorf("después de CreateLibro: BuscarLibros(cronica) = [%s]", got)
+	}
+
+	// Los resultados traen los datos completos del libro, incluida la disponibilidad.
+	resultados, _ := repo.BuscarLibros("rayuela", 0)
+	if len(resultados) != 1 || resultados[0].Libro.Autor != "Julio Cortázar" || resultados[0].Libro.Ejemplares != 1 {
+		t.Errorf("BuscarLibros(rayuela) = %+v", resultados)
+	}
+}
+
+// probarSugerirLibros verifica las sugerencias de SugerirLibros sobre un repositorio vacío: los valores con una
+// palabra que empieza con el texto, primero los que empiezan con él y después los de más libros.
+func probarSugerirLibros(t *testing.T, repo LibroRepository) {
+	t.Helper()
+	repo.CreateLibro("Gabriel García Márquez", "Cien años de soledad", 1967, "Sudamericana")           // ID 1
+	repo.CreateLibro("Gabriel García Márquez", "Crónica de una muerte anunciada", 1981, "Oveja Negra") // ID 2
+	repo.CreateLibro("Federico García Lorca", "Romancero gitano", 1928, "Sur")                         // ID 3
+	repo.CreateLibro("Julio Cortázar", "Rayuela", 1963, "Sudamericana")                                // ID 4
+	repo.CreateLibro("Ana María Garcés", "Sudamérica en cuentos", 2001, "Sur")                         // ID 5
+
+	sugerir := func(texto, campo string, limite int) string {
+		sugerencias, err := repo.SugerirLibros(texto, campo, limite)
+		if err != nil {
+			t.Fatalf("SugerirLibros(%q): %v", texto, err)
+		}
+		var textos []string
+		for _, sugerencia := range sugerencias {
+			textos = append(textos, sugerencia.Campo+":"+sugerencia.Texto+":"+strconv.Itoa(sugerencia.Libros))
+		}
+		return strings.Join(textos, " | ")
+	}
+	casos := []struct {
+		nombre string
+		texto  string
+		campo  string
+		limite int
+		quiere string
+	}{
+		{"palabra intermedia", "garc", CampoAutor, 0, "Autor:Gabriel García Márquez:2 | Autor:Ana María Garcés:1 | Autor:Federico García Lorca:1"},
+		{"sin tildes", "MARQ", "", 0, "Autor:Gabriel García Márquez:2"},
+		{"primero los que empiezan con el texto", "sud", "", 0, "Editorial:Sudamericana:2 | Titulo:Sudamérica en cuentos:1"},
+		{"varias palabras", "garcia m", "", 0, "Autor:Gabriel García Márquez:2"},
+		{"campo", "sur", CampoEditorial, 0, "Editorial:Sur:2"},
+		{"límite", "garc", "", 1, "Autor:Gabriel García Márquez:2"},
+		{"sin coincidencias", "borges", "", 0, ""},
+		{"vacío", "  ", "", 0, ""},
+	}
+	for _, c := range casos {
+		if got := sugerir(c.texto, c.campo, c.limite); got != c.quiere {
+			t.Errorf("%s: SugerirLibros(%q, %q) = %s\nse esperaba %s", c.nombre, c.texto, c.campo, got, c.quiere)
+		}
+	}
+
+	// Las sugerencias siguen los cambios de los libros: el valor desaparece cuando ningún libro lo tiene.
+	repo.DeleteLibro(2)
+	if got := sugerir("marq", "", 0); got != "Autor:Gabriel García Márquez:1" {
+		t.Errorf("después de DeleteLibro: %s", got)
+	}
+	libro, _ := repo.GetLibroByID(1)
+	libro.Autor = "Gabo"
+	repo.UpdateLibro(libro)
+	if got := sugerir("marq", "", 0); got != "" {
+		t.Errorf("después de UpdateLibro: %s", got)
+	}
+	if got := sugerir("gab", CampoAutor, 0); got != "Autor:Gabo:1" {
+		t.Errorf("después de UpdateLibro: %s", got)
+	}
+	repo.CreateLibro("Jorge Luis Borges", "Ficciones", 1944, "Sur") // ID 6
+	if got := sugerir("borg", "", 0); got != "Autor:Jorge Luis Borges:1" {
+		t.Errorf("después de CreateLibro: %s", got)
+	}
+}
+
+func TestNormalizarBusqueda(t *testing.T) {
+	casos := map[string]string{
+		"García Márquez": "garcia marquez",
+		"ÁRBOL Ñandú":    "arbol nandu",
+		"Müller Çelik":   "muller celik",
+		"sin cambios 42": "sin cambios 42",
+	}
+	for texto, esperado := range casos {
+		if got := NormalizarBusqueda(texto); got != esperado {
+			t.Errorf("NormalizarBusqueda(%q) = %q, se esperaba %q", texto, got, esperado)
+		}
+	}
+}
+
+func TestParsePrestado(t *testing.T) {
+	casos := map[string]bool{"Si": true, "sí": true, "true": true, "on": true, "1": true, "No": false, "false": false, "0": false}
+	for valor, esperado := range casos {
+		if got, err := ParsePrestado(valor); err != nil || got != esperado {
+			t.Errorf("ParsePrestado(%q) = %v, %v", valor, got, err)
+		}
+	}
+	if _, err := ParsePrestado("quizás"); err == nil {
+		t.Error("ParsePrestado de un valor desconocido no devolvió error")
+	}
+}
+
+func TestLibroValidar(t *testing.T) {
+	valido := Libro{Titulo: "Rayuela", Autor: "Julio Cortázar", AnioPublicacion: 1963, Editorial: "Sudamericana"}
+	if err := valido.Validar(); err != nil {
+		t.Fatalf("Validar de un libro válido devolvió %v", err)
+	}
+
+	casos := []struct {
+		nombre  string
+		cambiar func(l *Libro)
+		campo   string
+		mensaje string
+	}{
+		{"título vacío", func(l *Libro) { l.Titulo = "   " }, "Titulo", "Es obligatorio"},
+		{"autor demasiado largo", func(l *Libro) { l.Autor = strings.Repeat("ñ", LongitudMaximaAutor+1) }, "Autor", "No puede tener más de 255 caracteres"},
+		{"editorial vacía", func(l *Libro) { l.Editorial = "" }, "Editorial", "Es obligatorio"},
+		{"año negativo", func(l *Libro) { l.AnioPublicacion = -5 }, "AnioPublicacion", "Debe estar entre 1500"},
+		{"año futuro", func(l *Libro) { l.AnioPublicacion = time.Now().Year() + 1 }, "AnioPublicacion", "Debe estar entre 1500"},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			libro := valido
+			c.cambiar(&libro)
+			var errores ErroresValidacion
+			if err := libro.Validar(); !errors.As(err, &errores) {
+				t.Fatalf("Validar devolvió %v, se esperaba ErroresValidacion", err)
+			}
+			if len(errores) != 1 || !strings.HasPrefix(errores[c.campo], c.mensaje) {
+				t.Errorf("Validar = %v, se esperaba %s: %s", errores, c.campo, c.mensaje)
+			}
+		})
+	}
+
+	// La longitud se cuenta en caracteres: 255 letras con tilde son válidas aunque ocupen más bytes.
+	libro := valido
+	libro.Titulo = strings.Repeat("á", LongitudMaximaTitulo)
+	if err := libro.Validar(); err != nil {
+		t.Errorf("Validar de un título de 255 caracteres devolvió %v", err)
+	}
+
+	// Todos los errores se informan a la vez, uno por campo.
+	var errores ErroresValidacion
+	if !errors.As(Libro{}.Validar(), &errores) || len(errores) != 4 {
+		t.Errorf("Validar de un libro vacío = %v", errores)
+	}
+	if got := errores.Error(); !strings.HasPrefix(got, "datos inválidos: AnioPublicacion: ") {
+		t.Errorf("ErroresValidacion.Error() = %q", got)
+	}
+	if !errors.Is(Libro{}.Validar(), ErrValidation) {
+		t.Error("ErroresValidacion no pertenece a la clase ErrValidation")
+	}
+}
@@ -0,0 +1,144 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Almacenamiento en memoria compartido por los repositorios en memoria (pruebas y modo demo).
+*/
+
+package models
+
+import (
+	"sync" // Paquete para proteger el acceso concurrente a los datos.
+	"time" // Paquete para el plazo de las reservas asignadas y de las sesiones.
+)
+
+// MemoriaDB hace el papel de la base de datos para los repositorios en memoria.
+// Todas las "tablas" comparten un único mutex, de modo que las operaciones que modifican
+// varias entidades a la vez (por ejemplo, prestar un libro) son atómicas, como una transacción.
+type MemoriaDB struct {
+	mu sync.RWMutex // Protege todos los mapas y secuencias de IDs.
+
+	libros      map[int]Libro // Libros almacenados, indexados por su ID.
+	nextLibroId int           // Último ID de libro asignado, emula el AUTO_INCREMENT de la tabla.
+
+	ejemplares     map[int]Ejemplar // Ejemplares almacenados, indexados por su ID.
+	nextEjemplarId int              // Último ID de ejemplar asignado.
+
+	prestamos      map[int]Prestamo // Préstamos almacenados, indexados por su ID.
+	nextPrestamoId int              // Último ID de préstamo asignado.
+
+	socios      map[int]Socio // Socios almacenados, indexados por su ID.
+	nextSocioId int           // Último ID de socio asignado.
+
+	multas      map[int]Multa // Multas almacenadas, indexadas por su ID.
+	nextMultaId int           // Último ID de multa asignado.
+
+	reservas      map[int]Reserva // Reservas almacenadas, indexadas por su ID.
+	nextReservaId int             // Último ID de reserva asignado.
+
+	usuarios      map[int]Usuario   // Usuarios almacenados, indexados por su ID.
+	nextUsuarioId int               // Último ID de usuario asignado.
+	sesiones      map[string]sesion // Sesiones iniciadas, indexadas por el hash de su token.
+
+	claves      map[int]ClaveAPI // Claves de API, indexadas por su ID.
+	nextClaveId int              // Último ID de clave de API asignado.
+
+	indiceLibros      *IndiceLibros      // Índice de búsqueda de los libros, que se actualiza junto con el mapa libros.
+	sugerenciasLibros *IndiceSugerencias // Trie de sugerencias de los libros, que se actualiza junto con el mapa libros.
+}
+
+// sesion es una fila de la "tabla" de sesiones en memoria.
+type sesion struct {
+	UsuarioId       int       // Usuario que inició la sesión.
+	FechaCreacion   time.Time // Momento del inicio de sesión.
+	FechaExpiracion time.Time // Momento a partir del cual la sesión deja de ser válida.
+}
+
+// NewMemoriaDB crea un almacenamiento en memoria vacío.
+// Los repositorios que reciben la misma MemoriaDB ven los mismos datos, igual que con un *sql.DB compartido.
+func NewMemoriaDB() *MemoriaDB {
+	return &MemoriaDB{
+		libros:     make(map[int]Libro),
+		ejemplares: make(map[int]Ejemplar),
+		prestamos:  make(map[int]Prestamo),
+		socios:     make(map[int]Socio),
+		multas:     make(map[int]Multa),
+		reservas:   make(map[int]Reserva),
+		usuarios:   make(map[int]Usuario),
+		sesiones:   make(map[string]sesion),
+		claves:     make(map[int]ClaveAPI),
+
+		indiceLibros:      NewIndiceLibros(),
+		sugerenciasLibros: NewIndiceSugerencias(),
+	}
+}
+
+// conDisponibilidad completa los contadores de ejemplares del libro, como las subconsultas de la versión SQL.
+// Debe llamarse con el mutex tomado.
+func (db *MemoriaDB) conDisponibilidad(libro Libro) Libro {
+	ejemplares, disponibles := 0, 0
+	for _, ejemplar := range db.ejemplares {
+		if ejemplar.LibroId == libro.Id {
+			ejemplares++
+			if !ejemplar.Prestado && !db.ejemplarApartado(ejemplar.Id) {
+				disponibles++
+			}
+		}
+	}
+	return libro.conDisponibilidad(ejemplares, disponibles)
+}
+
+// eliminarPrestamosSi borra los préstamos que cumplen la condición junto con sus multas, emulando un ON DELETE CASCADE.
+// Debe llamarse con el mutex tomado para escritura.
+func (db *MemoriaDB) eliminarPrestamosSi(condicion func(Prestamo) bool) {
+	for id, prestamo := range db.prestamos {
+		if condicion(prestamo) {
+			delete(db.prestamos, id)
+		}
+	}
+	for id, multa := range db.multas {
+		if _, ok := db.prestamos[multa.PrestamoId]; !ok {
+			delete(db.multas, id)
+		}
+	}
+}
+
+// ejemplarApartado indica si la copia está apartada para una reserva asignada.
+// Debe llamarse con el mutex tomado.
+func (db *MemoriaDB) ejemplarApartado(EjemplarId int) bool {
+	for _, reserva := range db.reservas {
+		if reserva.EjemplarId == EjemplarId && reserva.Estado == EstadoReservaAsignada {
+			return true
+		}
+	}
+	return false
+}
+
+// asignarSiguienteReserva aparta la copia recibida para la reserva pendiente más antigua del libro,
+// con un plazo de DiasRetencionReserva días para retirarla. No hace nada si la cola está vacía.
+// Si EjemplarId es 0 (la copia se eliminó) aparta otra copia libre del libro, igual que la versión SQL.
+// Debe llamarse con el mutex tomado para escritura.
+func (db *MemoriaDB) asignarSiguienteReserva(LibroId, EjemplarId int, ahora time.Time) {
+	var siguiente Reserva
+	for _, reserva := range db.reservas {
+		if reserva.LibroId == LibroId && reserva.Estado == EstadoReservaPendiente && (siguiente.Id == 0 || reserva.Id < siguiente.Id) {
+			siguiente = reserva
+		}
+	}
+	if siguiente.Id == 0 {
+		return
+	}
+	if EjemplarId == 0 {
+		for _, e := range db.ejemplares {
+			if e.LibroId == LibroId && !e.Prestado && !db.ejemplarApartado(e.Id) && (EjemplarId == 0 || e.Id < EjemplarId) {
+				EjemplarId = e.Id
+			}
+		}
+		if EjemplarId == 0 {
+			return
+		}
+	}
+	expiracion := finDelDia(ahora.AddDate(0, 0, DiasRetencionReserva))
+	siguiente.Estado = EstadoReservaAsignada
+	siguiente.EjemplarId = EjemplarId
+	siguiente.FechaExpiracion = &expiracion
+	db.reservas[siguiente.Id] = siguiente
+}
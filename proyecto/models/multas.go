@@ -0,0 +1,78 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define las multas por atraso y el repositorio que detecta los préstamos vencidos.
+*/
+
+package models
+
+import (
+	"math" // Paquete para redondear los días y los montos.
+	"time" // Paquete para las fechas de cálculo.
+)
+
+// Valores por defecto del cálculo de multas, usados cuando no se configuran con variables de entorno.
+const (
+	TarifaDiariaPorDefecto = 0.50 // Monto que se cobra por cada día de atraso.
+	DiasGraciaPorDefecto   = 2    // Días de atraso que no se cobran.
+)
+
+// ConfigMultas define cómo se calcula el monto de una multa.
+type ConfigMultas struct {
+	TarifaDiaria float64 // Monto que se cobra por cada día de atraso después del período de gracia.
+	DiasGracia   int     // Días de atraso que no se cobran.
+}
+
+// ConfigMultasPorDefecto devuelve la configuración con la tarifa y el período de gracia por defecto.
+func ConfigMultasPorDefecto() ConfigMultas {
+	return ConfigMultas{TarifaDiaria: TarifaDiariaPorDefecto, DiasGracia: DiasGraciaPorDefecto}
+}
+
+// Monto calcula la multa para los días de atraso recibidos, redondeada a centavos.
+// Los días dentro del período de gracia no se cobran.
+func (c ConfigMultas) Monto(DiasAtraso int) float64 {
+	cobrables := DiasAtraso - c.DiasGracia
+	if cobrables <= 0 {
+		return 0
+	}
+	return math.Round(float64(cobrables)*c.TarifaDiaria*100) / 100
+}
+
+// DiasAtraso devuelve los días (o fracciones de día) que pasaron entre el vencimiento del préstamo
+// y su devolución, o hasta ahora si todavía no se devolvió. Devuelve 0 si el préstamo no está vencido.
+func DiasAtraso(prestamo Prestamo, ahora time.Time) int {
+	fin := ahora
+	if prestamo.FechaDevolucion != nil {
+		fin = *prestamo.FechaDevolucion
+	}
+	if !fin.After(prestamo.FechaVencimiento) {
+		return 0
+	}
+	// El vencimiento es el último segundo del día, así que cualquier fracción cuenta como un día más.
+	return int(math.Ceil(fin.Sub(prestamo.FechaVencimiento).Hours() / 24))
+}
+
+// Multa representa el cargo por la devolución tardía de un préstamo.
+// Mientras el libro no se devuelve el monto crece en cada revisión; al devolverlo queda definitivo.
+type Multa struct {
+	Id           int       // ID único de la multa (clave primaria).
+	PrestamoId   int       // ID del préstamo atrasado. Cada préstamo tiene como máximo una multa.
+	Titulo       string    // Título del libro (solo lectura, se obtiene de la tabla libros).
+	SocioId      int       // ID del socio (solo lectura, se obtiene del préstamo).
+	Socio        string    // Nombre del socio (solo lectura, se obtiene de la tabla socios).
+	DiasAtraso   int       // Días de atraso a la fecha del cálculo.
+	Monto        float64   // Monto de la multa, descontados los días de gracia.
+	Definitiva   bool      // Indica que el libro ya se devolvió y el monto no cambiará.
+	FechaCalculo time.Time // Fecha de la última revisión que actualizó la multa.
+}
+
+// MultaRepository define las operaciones de persistencia para los atrasos y las multas.
+type MultaRepository interface {
+	// GetAllMultas devuelve todas las multas, de la más reciente a la más antigua.
+	GetAllMultas() ([]Multa, error)
+	// ContarAtrasados devuelve cuántos préstamos activos están marcados como atrasados.
+	ContarAtrasados() (int, error)
+	// ActualizarAtrasos revisa los préstamos vencidos a la fecha recibida: los marca como atrasados
+	// y crea o actualiza su multa con la configuración indicada. Los préstamos devueltos con atraso
+	// reciben su monto final y no vuelven a revisarse. Devuelve cuántos préstamos se actualizaron.
+	ActualizarAtrasos(ahora time.Time, config ConfigMultas) (int, error)
+}
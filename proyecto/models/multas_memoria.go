@@ -0,0 +1,93 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación en memoria del repositorio de multas, usada en las pruebas y en el modo demo.
+*/
+
+package models
+
+import (
+	"sort" // Paquete para ordenar las multas.
+	"time" // Paquete para la fecha de cálculo.
+)
+
+// MemoryMultaRepository implementa MultaRepository en memoria.
+type MemoryMultaRepository struct {
+	db *MemoriaDB // Almacenamiento en memoria compartido con los demás repositorios.
+}
+
+// NewMemoryMultaRepository crea un repositorio de multas sobre el almacenamiento en memoria recibido.
+func NewMemoryMultaRepository(db *MemoriaDB) *MemoryMultaRepository {
+	return &MemoryMultaRepository{db: db}
+}
+
+// GetAllMultas devuelve todas las multas, de la más reciente a la más antigua.
+func (repo *MemoryMultaRepository) GetAllMultas() ([]Multa, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	var multas []Multa
+	for _, multa := range repo.db.multas {
+		// Completa los datos del préstamo, como el JOIN de la versión SQL.
+		prestamo := repo.db.prestamos[multa.PrestamoId]
+		multa.Titulo = repo.db.libros[prestamo.LibroId].Titulo
+		multa.SocioId = prestamo.SocioId
+		multa.Socio = repo.db.socios[prestamo.SocioId].Nombre
+		multas = append(multas, multa)
+	}
+	sort.Slice(multas, func(i, j int) bool { return multas[i].Id > multas[j].Id })
+	return multas, nil
+}
+
+// ContarAtrasados devuelve cuántos préstamos sin devolver están marcados como atrasados.
+func (repo *MemoryMultaRepository) ContarAtrasados() (int, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	atrasados := 0
+	for _, prestamo := range repo.db.prestamos {
+		if prestamo.Atrasado && prestamo.Activo() {
+			atrasados++
+		}
+	}
+	return atrasados, nil
+}
+
+// ActualizarAtrasos marca los préstamos vencidos y crea o actualiza sus multas.
+func (repo *MemoryMultaRepository) ActualizarAtrasos(ahora time.Time, config ConfigMultas) (int, error) {
+	ahora = ahora.Truncate(time.Second)
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	// Indexa las multas por préstamo, como la restricción UNIQUE de la tabla.
+	multasPorPrestamo := make(map[int]Multa, len(repo.db.multas))
+	for _, multa := range repo.db.multas {
+		multasPorPrestamo[multa.PrestamoId] = multa
+	}
+
+	actualizados := 0
+	for id, prestamo := range repo.db.prestamos {
+		multa, existe := multasPorPrestamo[id]
+		if existe && multa.Definitiva {
+			continue // Las multas definitivas no se vuelven a tocar.
+		}
+		dias := DiasAtraso(prestamo, ahora)
+		if dias == 0 {
+			continue
+		}
+
+		prestamo.Atrasado = true
+		repo.db.prestamos[id] = prestamo
+
+		if !existe {
+			repo.db.nextMultaId++
+			multa = Multa{Id: repo.db.nextMultaId, PrestamoId: id}
+		}
+		multa.DiasAtraso = dias
+		multa.Monto = config.Monto(dias)
+		multa.Definitiva = !prestamo.Activo()
+		multa.FechaCalculo = ahora
+		repo.db.multas[multa.Id] = multa
+		actualizados++
+	}
+	return actualizados, nil
+}
@@ -0,0 +1,141 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación del repositorio de multas sobre una base de datos SQL (MySQL o SQLite).
+*/
+
+package models
+
+import (
+	"database/sql" // Paquete para interactuar con bases de datos SQL.
+	"fmt"          // Paquete para formatear cadenas.
+	"log"          // Paquete para logging de errores y mensajes.
+	"time"         // Paquete para la fecha de cálculo.
+)
+
+// SQLMultaRepository implementa MultaRepository usando un pool de conexiones compartido.
+type SQLMultaRepository struct {
+	db *sql.DB // Pool de conexiones a la base de datos.
+}
+
+// NewSQLMultaRepository crea un repositorio de multas que usa la conexión recibida.
+func NewSQLMultaRepository(db *sql.DB) *SQLMultaRepository {
+	return &SQLMultaRepository{db: db}
+}
+
+// GetAllMultas consulta la base de datos y devuelve todas las multas con el título del libro y el nombre del socio.
+func (repo *SQLMultaRepository) GetAllMultas() ([]Multa, error) {
+	rows, err := repo.db.Query(`SELECT m.Id, m.PrestamoId, l.Titulo, p.SocioId, s.Nombre, m.DiasAtraso, m.Monto, m.Definitiva, m.FechaCalculo
+		FROM multas m JOIN prestamos p ON p.Id = m.PrestamoId JOIN libros l ON l.Id = p.LibroId JOIN socios s ON s.Id = p.SocioId
+		ORDER BY m.Id DESC`)
+	if err != nil {
+		log.Printf("Error al ejecutar la consulta en GetAllMultas: %v", err)
+		return nil, fmt.Errorf("error al ejecutar la consulta: %w", err)
+	}
+	defer rows.Close()
+
+	var multas []Multa
+	for rows.Next() {
+		var multa Multa
+		err := rows.Scan(&multa.Id, &multa.PrestamoId, &multa.Titulo, &multa.SocioId, &multa.Socio,
+			&multa.DiasAtraso, &multa.Monto, &multa.Definitiva, &multa.FechaCalculo)
+		if err != nil {
+			log.Printf("Error al escanear los resultados en GetAllMultas: %v", err)
+			return nil, fmt.Errorf("error al escanear los resultados: %w", err)
+		}
+		multa.FechaCalculo = fechaLocal(multa.FechaCalculo)
+		multas = append(multas, multa)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("Error al procesar los resultados de la base de datos en GetAllMultas: %v", err)
+		return nil, fmt.Errorf("error al procesar los resultados: %w", err)
+	}
+	return multas, nil
+}
+
+// ContarAtrasados devuelve cuántos préstamos sin devolver están marcados como atrasados.
+func (repo *SQLMultaRepository) ContarAtrasados() (int, error) {
+	var atrasados int
+	err := repo.db.QueryRow("SELECT COUNT(*) FROM prestamos WHERE Atrasado = TRUE AND FechaDevolucion IS NULL").Scan(&atrasados)
+	if err != nil {
+		log.Printf("Error al contar los préstamos atrasados: %v", err)
+		return 0, fmt.Errorf("error al contar los préstamos atrasados: %w", err)
+	}
+	return atrasados, nil
+}
+
+// vencido es un préstamo candidato a multa leído por ActualizarAtrasos.
+type vencido struct {
+	prestamo Prestamo      // Solo se leen el ID y las fechas del préstamo.
+	MultaId  sql.NullInt64 // ID de la multa existente, NULL si el préstamo todavía no tiene multa.
+}
+
+// ActualizarAtrasos marca los préstamos vencidos y crea o actualiza sus multas dentro de una transacción.
+func (repo *SQLMultaRepository) ActualizarAtrasos(ahora time.Time, config ConfigMultas) (int, error) {
+	ahora = ahora.Truncate(time.Second)
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error al iniciar la transacción: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya se confirmó.
+
+	// Candidatos: préstamos sin devolver que ya vencieron y préstamos devueltos tarde cuya multa
+	// todavía no es definitiva. Las multas definitivas no se vuelven a tocar.
+	rows, err := tx.Query(`SELECT p.Id, p.FechaVencimiento, p.FechaDevolucion, m.Id
+		FROM prestamos p LEFT JOIN multas m ON m.PrestamoId = p.Id
+		WHERE (m.Id IS NULL OR m.Definitiva = FALSE)
+		AND ((p.FechaDevolucion IS NULL AND p.FechaVencimiento < ?) OR p.FechaDevolucion > p.FechaVencimiento)`, fechaSQL(ahora))
+	if err != nil {
+		log.Printf("Error al consultar los préstamos vencidos: %v", err)
+		return 0, fmt.Errorf("error al consultar los préstamos vencidos: %w", err)
+	}
+	// Se leen todas las filas antes de modificar nada, porque MySQL no permite ejecutar
+	// otras sentencias en la transacción mientras el cursor sigue abierto.
+	var vencidos []vencido
+	for rows.Next() {
+		var v vencido
+		var devolucion sql.NullTime
+		if err := rows.Scan(&v.prestamo.Id, &v.prestamo.FechaVencimiento, &devolucion, &v.MultaId); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error al escanear los préstamos vencidos: %w", err)
+		}
+		if devolucion.Valid {
+			v.prestamo.FechaDevolucion = &devolucion.Time
+		}
+		vencidos = append(vencidos, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error al procesar los préstamos vencidos: %w", err)
+	}
+
+	actualizados := 0
+	for _, v := range vencidos {
+		dias := DiasAtraso(v.prestamo, ahora)
+		if dias == 0 {
+			continue
+		}
+		monto, definitiva := config.Monto(dias), !v.prestamo.Activo()
+
+		if _, err := tx.Exec("UPDATE prestamos SET Atrasado = TRUE WHERE Id = ?", v.prestamo.Id); err != nil {
+			log.Printf("Error al marcar el préstamo %d como atrasado: %v", v.prestamo.Id, err)
+			return 0, fmt.Errorf("error al actualizar el préstamo: %w", err)
+		}
+		if v.MultaId.Valid {
+			_, err = tx.Exec("UPDATE multas SET DiasAtraso = ?, Monto = ?, Definitiva = ?, FechaCalculo = ? WHERE Id = ?",
+				dias, monto, definitiva, fechaSQL(ahora), v.MultaId.Int64)
+		} else {
+			_, err = tx.Exec("INSERT INTO multas (PrestamoId, DiasAtraso, Monto, Definitiva, FechaCalculo) VALUES (?, ?, ?, ?, ?)",
+				v.prestamo.Id, dias, monto, definitiva, fechaSQL(ahora))
+		}
+		if err != nil {
+			log.Printf("Error al guardar la multa del préstamo %d: %v", v.prestamo.Id, err)
+			return 0, fmt.Errorf("error al guardar la multa: %w", err)
+		}
+		actualizados++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error al confirmar la revisión de atrasos: %w", err)
+	}
+	return actualizados, nil
+}
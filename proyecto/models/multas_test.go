@@ -0,0 +1,149 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// probarMultaRepository verifica el contrato común de MultaRepository sobre repositorios vacíos.
+func probarMultaRepository(t *testing.T, libros LibroRepository, socios SocioRepository, prestamos PrestamoRepository, multas MultaRepository) {
+	t.Helper()
+	libros.CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana")
+	libros.CreateLibro("Borges", "Ficciones", 1944, "Sur")
+	libros.CreateLibro("Borges", "El Aleph", 1949, "Losada")
+	socios.CreateSocio(Socio{Nombre: "Ana", Estado: EstadoSocioActivo})
+	config := ConfigMultas{TarifaDiaria: 1, DiasGracia: 2}
+
+	ahora := time.Now().Truncate(time.Second)
+	atrasado, _ := prestamos.PrestarLibro(1, 1, ahora.Add(-(4*24*time.Hour + time.Hour))) // 5 días de atraso.
+	alDia, _ := prestamos.PrestarLibro(2, 1, ahora.AddDate(0, 0, 10))
+	devueltoTarde, _ := prestamos.PrestarLibro(3, 1, ahora.Add(-time.Hour))
+	if _, err := prestamos.DevolverLibro(devueltoTarde.Id); err != nil {
+		t.Fatalf("DevolverLibro: %v", err)
+	}
+
+	if n, err := multas.ActualizarAtrasos(ahora, config); err != nil || n != 2 {
+		t.Fatalf("ActualizarAtrasos = %d, %v; se esperaban 2", n, err)
+	}
+	if n, _ := multas.ContarAtrasados(); n != 1 {
+		t.Errorf("ContarAtrasados = %d, se esperaba 1", n)
+	}
+	if p, _ := prestamos.GetPrestamoByID(atrasado.Id); !p.Atrasado {
+		t.Error("el préstamo vencido no quedó marcado como atrasado")
+	}
+	if p, _ := prestamos.GetPrestamoByID(alDia.Id); p.Atrasado {
+		t.Error("un préstamo al día quedó marcado como atrasado")
+	}
+
+	lista, err := multas.GetAllMultas()
+	if err != nil || len(lista) != 2 {
+		t.Fatalf("GetAllMultas = %+v, %v", lista, err)
+	}
+	porPrestamo := map[int]Multa{}
+	for _, m := range lista {
+		porPrestamo[m.PrestamoId] = m
+	}
+	if m := porPrestamo[atrasado.Id]; m.DiasAtraso != 5 || m.Monto != 3 || m.Definitiva || m.Titulo != "Rayuela" || m.Socio != "Ana" {
+		t.Errorf("multa del préstamo atrasado = %+v", m)
+	}
+	// Devuelto dentro del período de gracia: queda registrado el atraso, sin monto, y la multa es definitiva.
+	if m := porPrestamo[devueltoTarde.Id]; m.DiasAtraso != 1 || m.Monto != 0 || !m.Definitiva {
+		t.Errorf("multa del préstamo devuelto tarde = %+v", m)
+	}
+
+	// Dos días después la multa activa crece y la definitiva no se vuelve a revisar.
+	despues := ahora.AddDate(0, 0, 2)
+	if n, err := multas.ActualizarAtrasos(despues, config); err != nil || n != 1 {
+		t.Fatalf("segundo ActualizarAtrasos = %d, %v; se esperaba 1", n, err)
+	}
+	lista, _ = multas.GetAllMultas()
+	for _, m := range lista {
+		if m.PrestamoId == atrasado.Id && (m.DiasAtraso != 7 || m.Monto != 5) {
+			t.Errorf("multa después de dos días = %+v", m)
+		}
+	}
+
+	// Al devolver el libro el monto se calcula hasta la fecha de devolución y queda definitivo.
+	prestamos.DevolverLibro(atrasado.Id)
+	if n, err := multas.ActualizarAtrasos(despues, config); err != nil || n != 1 {
+		t.Fatalf("ActualizarAtrasos después de la devolución = %d, %v", n, err)
+	}
+	if n, _ := multas.ActualizarAtrasos(despues, config); n != 0 {
+		t.Errorf("ActualizarAtrasos volvió a revisar %d multas definitivas", n)
+	}
+	if n, _ := multas.ContarAtrasados(); n != 0 {
+		t.Errorf("ContarAtrasados después de las devoluciones = %d", n)
+	}
+	lista, _ = multas.GetAllMultas()
+	for _, m := range lista {
+		if m.PrestamoId == atrasado.Id && (m.DiasAtraso != 5 || m.Monto != 3 || !m.Definitiva) {
+			t.Errorf("multa definitiva = %+v", m)
+		}
+	}
+}
+
+// probarAtrasosEnOtraZona verifica que ActualizarAtrasos compara los instantes y no el texto de las fechas:
+// un vencimiento guardado en otra zona horaria se escribe "después" de ahora aunque ya haya pasado.
+func probarAtrasosEnOtraZona(t *testing.T, libros LibroRepository, socios SocioRepository, prestamos PrestamoRepository, multas MultaRepository) {
+	t.Helper()
+	libros.CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana")
+	socios.CreateSocio(Socio{Nombre: "Ana", Estado: EstadoSocioActivo})
+
+	ahora := time.Date(2024, time.March, 10, 4, 0, 0, 0, time.UTC)
+	vencimiento := time.Date(2024, time.March, 10, 5, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60)) // 02:00 UTC.
+	if _, err := prestamos.PrestarLibro(1, 1, vencimiento); err != nil {
+		t.Fatalf("PrestarLibro: %v", err)
+	}
+	if n, err := multas.ActualizarAtrasos(ahora, ConfigMultas{TarifaDiaria: 1}); err != nil || n != 1 {
+		t.Fatalf("ActualizarAtrasos = %d, %v; se esperaba 1", n, err)
+	}
+	if lista, _ := multas.GetAllMultas(); len(lista) != 1 || lista[0].DiasAtraso != 1 {
+		t.Errorf("GetAllMultas = %+v", lista)
+	}
+	// Antes del vencimiento no hay atraso, aunque la fecha de ahora se escriba después.
+	if n, _ := multas.ActualizarAtrasos(time.Date(2024, time.March, 10, 1, 0, 0, 0, time.UTC), ConfigMultas{TarifaDiaria: 1}); n != 0 {
+		t.Errorf("ActualizarAtrasos antes del vencimiento = %d", n)
+	}
+}
+
+func TestMemoryMultaRepository(t *testing.T) {
+	mdb := NewMemoriaDB()
+	probarMultaRepository(t, NewMemoryLibroRepository(mdb), NewMemorySocioRepository(mdb),
+		NewMemoryPrestamoRepository(mdb), NewMemoryMultaRepository(mdb))
+	mdb = NewMemoriaDB()
+	probarAtrasosEnOtraZona(t, NewMemoryLibroRepository(mdb), NewMemorySocioRepository(mdb),
+		NewMemoryPrestamoRepository(mdb), NewMemoryMultaRepository(mdb))
+}
+
+func TestSQLMultaRepositorySQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	probarMultaRepository(t, NewSQLLibroRepository(conexion), NewSQLSocioRepository(conexion),
+		NewSQLPrestamoRepository(conexion), NewSQLMultaRepository(conexion))
+	conexion = nuevaDBPrueba(t)
+	probarAtrasosEnOtraZona(t, NewSQLLibroRepository(conexion), NewSQLSocioRepository(conexion),
+		NewSQLPrestamoRepository(conexion), NewSQLMultaRepository(conexion))
+}
+
+func TestCalculoMulta(t *testing.T) {
+	config := ConfigMultas{TarifaDiaria: 0.75, DiasGracia: 2}
+	casos := map[int]float64{0: 0, 1: 0, 2: 0, 3: 0.75, 10: 6}
+	for dias, esperado := range casos {
+		if monto := config.Monto(dias); monto != esperado {
+			t.Errorf("Monto(%d) = %v, se esperaba %v", dias, monto, esperado)
+		}
+	}
+
+	vence := time.Date(2030, 1, 15, 23, 59, 59, 0, time.Local)
+	prestamo := Prestamo{FechaVencimiento: vence}
+	if dias := DiasAtraso(prestamo, vence); dias != 0 {
+		t.Errorf("DiasAtraso el día del vencimiento = %d", dias)
+	}
+	if dias := DiasAtraso(prestamo, vence.Add(time.Second)); dias != 1 {
+		t.Errorf("DiasAtraso un segundo después = %d", dias)
+	}
+	devolucion := vence.AddDate(0, 0, 3)
+	prestamo.FechaDevolucion = &devolucion
+	if dias := DiasAtraso(prestamo, vence.AddDate(0, 0, 30)); dias != 3 {
+		t.Errorf("DiasAtraso de un préstamo devuelto = %d, se esperaba 3", dias)
+	}
+}
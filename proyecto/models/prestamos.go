@@ -0,0 +1,83 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define la entidad Prestamo y el repositorio para prestar y devolver libros.
+*/
+
+package models
+
+import (
+	"fmt"     // Paquete para formatear cadenas.
+	"strings" // Paquete para normalizar los valores de texto.
+	"time"    // Paquete para las fechas del préstamo.
+)
+
+// DiasPrestamoPorDefecto es la duración de un préstamo cuando no se indica fecha de vencimiento.
+const DiasPrestamoPorDefecto = 14
+
+// Errores que pueden devolver las operaciones de préstamo. Los manejadores los reconocen con errors.Is
+// para responder 409 (Conflict) en lugar de 500.
+var (
+	ErrLibroNoDisponible = nuevoError(ErrConflict, "no quedan ejemplares disponibles del libro")
+	ErrPrestamoDevuelto  = nuevoError(ErrConflict, "el préstamo ya fue devuelto")
+)
+
+// Prestamo representa el préstamo de un libro a un socio.
+type Prestamo struct {
+	Id               int        // ID único del préstamo (clave primaria).
+	LibroId          int        // ID del libro prestado.
+	EjemplarId       int        // ID de la copia física que se llevó el socio.
+	Titulo           string     // Título del libro prestado (solo lectura, se obtiene de la tabla libros).
+	CodigoBarras     string     // Código de barras de la copia prestada (solo lectura, se obtiene de la tabla ejemplares).
+	SocioId          int        // ID del socio que se lleva el libro.
+	Socio            string     // Nombre del socio (solo lectura, se obtiene de la tabla socios).
+	FechaPrestamo    time.Time  // Fecha en que se prestó el libro.
+	FechaVencimiento time.Time  // Fecha límite para devolver el libro.
+	FechaDevolucion  *time.Time // Fecha en que se devolvió el libro, nil mientras siga prestado.
+	Atrasado         bool       // Lo marca la revisión de atrasos cuando el préstamo pasa su vencimiento sin devolverse.
+}
+
+// Activo indica si el libro todavía no se ha devuelto.
+func (p Prestamo) Activo() bool {
+	return p.FechaDevolucion == nil
+}
+
+// PrestamoRepository define las operaciones de persistencia para los préstamos.
+// PrestarLibro y DevolverLibro actualizan también el campo Prestado del ejemplar de forma atómica.
+type PrestamoRepository interface {
+	// GetAllPrestamos devuelve todos los préstamos, del más reciente al más antiguo.
+	GetAllPrestamos() ([]Prestamo, error)
+	// GetPrestamoByID devuelve un préstamo específico por su ID.
+	GetPrestamoByID(Id int) (Prestamo, error)
+	// GetPrestamosBySocio devuelve el historial de préstamos de un socio, del más reciente al más antiguo.
+	GetPrestamosBySocio(SocioId int) ([]Prestamo, error)
+	// PrestarLibro registra el préstamo de un ejemplar disponible del libro a un socio activo y marca la copia como prestada.
+	// Devuelve ErrLibroNoDisponible si no queda ninguna copia libre
+	// y ErrSocioNoActivo si la membresía del socio está suspendida o dada de baja.
+	PrestarLibro(LibroId int, SocioId int, FechaVencimiento time.Time) (Prestamo, error)
+	// DevolverLibro registra la devolución de un préstamo activo y marca el ejemplar como disponible.
+	DevolverLibro(Id int) (Prestamo, error)
+}
+
+// ParseFechaVencimiento convierte la fecha recibida de un formulario o de la API en la fecha límite del préstamo.
+// Acepta una fecha "2006-01-02", que vence al final de ese día, o una fecha y hora RFC 3339.
+// Si el valor está vacío, el préstamo vence en DiasPrestamoPorDefecto días.
+func ParseFechaVencimiento(valor string) (time.Time, error) {
+	valor = strings.TrimSpace(valor)
+	if valor == "" {
+		return finDelDia(time.Now().AddDate(0, 0, DiasPrestamoPorDefecto)), nil
+	}
+	if fecha, err := time.ParseInLocation("2006-01-02", valor, time.Local); err == nil {
+		return finDelDia(fecha), nil
+	}
+	fecha, err := time.Parse(time.RFC3339, valor)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fecha de vencimiento inválida: %q (use el formato AAAA-MM-DD)", valor)
+	}
+	return fecha, nil
+}
+
+// finDelDia devuelve el último segundo del día de la fecha recibida.
+func finDelDia(fecha time.Time) time.Time {
+	anio, mes, dia := fecha.Date()
+	return time.Date(anio, mes, dia, 23, 59, 59, 0, fecha.Location())
+}
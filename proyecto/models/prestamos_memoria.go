@@ -0,0 +1,175 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación en memoria del repositorio de préstamos, usada en las pruebas y en el modo demo.
+*/
+
+package models
+
+import (
+	"sort" // Paquete para ordenar los préstamos.
+	"time" // Paquete para las fechas del préstamo.
+)
+
+// MemoryPrestamoRepository implementa PrestamoRepository en memoria.
+// Como todas las tablas de MemoriaDB comparten un mutex, prestar y devolver
+// actualizan el préstamo y el ejemplar en un solo paso atómico.
+type MemoryPrestamoRepository struct {
+	db *MemoriaDB // Almacenamiento en memoria compartido con los demás repositorios.
+}
+
+// NewMemoryPrestamoRepository crea un repositorio de préstamos sobre el almacenamiento en memoria recibido.
+func NewMemoryPrestamoRepository(db *MemoriaDB) *MemoryPrestamoRepository {
+	return &MemoryPrestamoRepository{db: db}
+}
+
+// conTitulo completa el título del libro, el código de la copia y el nombre del socio del préstamo,
+// como el JOIN de la versión SQL. Devuelve false si el libro ya no existe.
+// Debe llamarse con el mutex de MemoriaDB tomado.
+func (repo *MemoryPrestamoRepository) conTitulo(prestamo Prestamo) (Prestamo, bool) {
+	libro, ok := repo.db.libros[prestamo.LibroId]
+	prestamo.Titulo = libro.Titulo
+	prestamo.CodigoBarras = repo.db.ejemplares[prestamo.EjemplarId].CodigoBarras
+	prestamo.Socio = repo.db.socios[prestamo.SocioId].Nombre
+	return prestamo, ok
+}
+
+// GetAllPrestamos devuelve todos los préstamos, del más reciente al más antiguo.
+func (repo *MemoryPrestamoRepository) GetAllPrestamos() ([]Prestamo, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	var prestamos []Prestamo
+	for _, prestamo := range repo.db.prestamos {
+		if prestamo, ok := repo.conTitulo(prestamo); ok {
+			prestamos = append(prestamos, prestamo)
+		}
+	}
+	sort.Slice(prestamos, func(i, j int) bool { return prestamos[i].Id > prestamos[j].Id })
+	return prestamos, nil
+}
+
+// GetPrestamosBySocio devuelve el historial de préstamos de un socio, del más reciente al más antiguo.
+func (repo *MemoryPrestamoRepository) GetPrestamosBySocio(SocioId int) ([]Prestamo, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	var prestamos []Prestamo
+	for _, prestamo := range repo.db.prestamos {
+		if prestamo.SocioId != SocioId {
+			continue
+		}
+		if prestamo, ok := repo.conTitulo(prestamo); ok {
+			prestamos = append(prestamos, prestamo)
+		}
+	}
+	sort.Slice(prestamos, func(i, j int) bool { return prestamos[i].Id > prestamos[j].Id })
+	return prestamos, nil
+}
+
+// GetPrestamoByID devuelve un préstamo específico por su ID.
+func (repo *MemoryPrestamoRepository) GetPrestamoByID(Id int) (Prestamo, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	prestamo, ok := repo.db.prestamos[Id]
+	if ok {
+		prestamo, ok = repo.conTitulo(prestamo)
+	}
+	if !ok {
+		return Prestamo{}, noEncontrado("préstamo con ID %d no encontrado", Id)
+	}
+	return prestamo, nil
+}
+
+// PrestarLibro registra el préstamo de la primera copia disponible del libro a un socio activo y la marca como prestada.
+func (repo *MemoryPrestamoRepository) PrestarLibro(LibroId int, SocioId int, FechaVencimiento time.Time) (Prestamo, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	libro, ok := repo.db.libros[LibroId]
+	if !ok {
+		return Prestamo{}, noEncontrado("libro con ID %d no encontrado", LibroId)
+	}
+
+	socio, ok := repo.db.socios[SocioId]
+	if !ok {
+		return Prestamo{}, noEncontrado("socio con ID %d no encontrado", SocioId)
+	}
+	if socio.Estado != EstadoSocioActivo {
+		return Prestamo{}, ErrSocioNoActivo
+	}
+
+	// Si el socio tiene una copia apartada por una reserva, se lleva esa copia. Si no, se elige la copia
+	// disponible más antigua que no esté apartada para otro socio, igual que el ORDER BY Id de la versión SQL.
+	var ejemplar Ejemplar
+	for _, reserva := range repo.db.reservas {
+		if reserva.LibroId == LibroId && reserva.SocioId == SocioId && reserva.Estado == EstadoReservaAsignada {
+			ejemplar = repo.db.ejemplares[reserva.EjemplarId]
+		}
+	}
+	if ejemplar.Id == 0 {
+		for _, e := range repo.db.ejemplares {
+			if e.LibroId == LibroId && !e.Prestado && !repo.db.ejemplarApartado(e.Id) && (ejemplar.Id == 0 || e.Id < ejemplar.Id) {
+				ejemplar = e
+			}
+		}
+	}
+	if ejemplar.Id == 0 {
+		return Prestamo{}, ErrLibroNoDisponible
+	}
+
+	ejemplar.Prestado = true
+	repo.db.ejemplares[ejemplar.Id] = ejemplar
+
+	repo.db.nextPrestamoId++
+	prestamo := Prestamo{
+		Id:               repo.db.nextPrestamoId,
+		LibroId:          LibroId,
+		EjemplarId:       ejemplar.Id,
+		Titulo:           libro.Titulo,
+		CodigoBarras:     ejemplar.CodigoBarras,
+		SocioId:          SocioId,
+		Socio:            socio.Nombre,
+		FechaPrestamo:    time.Now().Truncate(time.Second),
+		FechaVencimiento: FechaVencimiento,
+	}
+	repo.db.prestamos[prestamo.Id] = prestamo
+
+	// La reserva abierta del socio para este libro, si tenía una, queda atendida con este préstamo.
+	for id, reserva := range repo.db.reservas {
+		if reserva.LibroId == LibroId && reserva.SocioId == SocioId && reserva.Abierta() {
+			reserva.Estado = EstadoReservaCompletada
+			repo.db.reservas[id] = reserva
+		}
+	}
+	return prestamo, nil
+}
+
+// DevolverLibro registra la devolución de un préstamo activo y marca el ejemplar como disponible.
+func (repo *MemoryPrestamoRepository) DevolverLibro(Id int) (Prestamo, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	prestamo, ok := repo.db.prestamos[Id]
+	if ok {
+		prestamo, ok = repo.conTitulo(prestamo)
+	}
+	if !ok {
+		return Prestamo{}, noEncontrado("préstamo con ID %d no encontrado", Id)
+	}
+	if !prestamo.Activo() {
+		return Prestamo{}, ErrPrestamoDevuelto
+	}
+
+	ahora := time.Now().Truncate(time.Second)
+	prestamo.FechaDevolucion = &ahora
+	repo.db.prestamos[Id] = prestamo
+
+	ejemplar := repo.db.ejemplares[prestamo.EjemplarId]
+	ejemplar.Prestado = false
+	repo.db.ejemplares[prestamo.EjemplarId] = ejemplar
+
+	// Si alguien espera el libro, la copia devuelta queda apartada para la reserva más antigua.
+	repo.db.asignarSiguienteReserva(prestamo.LibroId, prestamo.EjemplarId, ahora)
+	return prestamo, nil
+}
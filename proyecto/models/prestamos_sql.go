@@ -0,0 +1,269 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación del repositorio de préstamos sobre una base de datos SQL (MySQL o SQLite).
+*/
+
+package models
+
+import (
+	"database/sql" // Paquete para interactuar con bases de datos SQL.
+	"fmt"          // Paquete para formatear cadenas.
+	"log"          // Paquete para logging de errores y mensajes.
+	"time"         // Paquete para las fechas del préstamo.
+)
+
+// consultaPrestamos selecciona los préstamos junto con el título del libro, el código de la copia y el nombre del socio.
+const consultaPrestamos = `SELECT p.Id, p.LibroId, p.EjemplarId, l.Titulo, e.CodigoBarras, p.SocioId, s.Nombre,
+	p.FechaPrestamo, p.FechaVencimiento, p.FechaDevolucion, p.Atrasado
+	FROM prestamos p JOIN libros l ON l.Id = p.LibroId JOIN ejemplares e ON e.Id = p.EjemplarId JOIN socios s ON s.Id = p.SocioId`
+
+// SQLPrestamoRepository implementa PrestamoRepository usando un pool de conexiones compartido.
+type SQLPrestamoRepository struct {
+	db *sql.DB // Pool de conexiones a la base de datos.
+}
+
+// NewSQLPrestamoRepository crea un repositorio de préstamos que usa la conexión recibida.
+func NewSQLPrestamoRepository(db *sql.DB) *SQLPrestamoRepository {
+	return &SQLPrestamoRepository{db: db}
+}
+
+// escanearPrestamo lee una fila de consultaPrestamos en una estructura Prestamo.
+func escanearPrestamo(fila interface{ Scan(...any) error }) (Prestamo, error) {
+	var prestamo Prestamo
+	var devolucion sql.NullTime // La fecha de devolución es NULL mientras el libro siga prestado.
+	err := fila.Scan(&prestamo.Id, &prestamo.LibroId, &prestamo.EjemplarId, &prestamo.Titulo, &prestamo.CodigoBarras, &prestamo.SocioId, &prestamo.Socio,
+		&prestamo.FechaPrestamo, &prestamo.FechaVencimiento, &devolucion, &prestamo.Atrasado)
+	prestamo.FechaPrestamo, prestamo.FechaVencimiento = fechaLocal(prestamo.FechaPrestamo), fechaLocal(prestamo.FechaVencimiento)
+	if devolucion.Valid {
+		devuelto := fechaLocal(devolucion.Time)
+		prestamo.FechaDevolucion = &devuelto
+	}
+	return prestamo, err
+}
+
+// GetAllPrestamos consulta la base de datos y devuelve todos los préstamos, del más reciente al más antiguo.
+func (repo *SQLPrestamoRepository) GetAllPrestamos() ([]Prestamo, error) {
+	return repo.listarPrestamos(consultaPrestamos + " ORDER BY p.Id DESC")
+}
+
+// GetPrestamosBySocio devuelve el historial de préstamos de un socio, del más reciente al más antiguo.
+func (repo *SQLPrestamoRepository) GetPrestamosBySocio(SocioId int) ([]Prestamo, error) {
+	return repo.listarPrestamos(consultaPrestamos+" WHERE p.SocioId = ? ORDER BY p.Id DESC", SocioId)
+}
+
+// listarPrestamos ejecuta una variante de consultaPrestamos y escanea todas sus filas.
+func (repo *SQLPrestamoRepository) listarPrestamos(consulta string, args ...any) ([]Prestamo, error) {
+	rows, err := repo.db.Query(consulta, args...)
+	if err != nil {
+		log.Printf("Error al ejecutar la consulta de préstamos: %v", err)
+		return nil, fmt.Errorf("error al ejecutar la consulta: %w", err)
+	}
+	defer rows.Close()
+
+	var prestamos []Prestamo
+	for rows.Next() {
+		prestamo, err := escanearPrestamo(rows)
+		if err != nil {
+			log.Printf("Error al escanear los resultados de préstamos: %v", err)
+			return nil, fmt.Errorf("error al escanear los resultados: %w", err)
+		}
+		prestamos = append(prestamos, prestamo)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("Error al procesar los resultados de préstamos: %v", err)
+		return nil, fmt.Errorf("error al procesar los resultados: %w", err)
+	}
+	return prestamos, nil
+}
+
+// GetPrestamoByID consulta la base de datos y devuelve un préstamo específico por su ID.
+func (repo *SQLPrestamoRepository) GetPrestamoByID(Id int) (Prestamo, error) {
+	return buscarPrestamo(repo.db, Id)
+}
+
+// buscarPrestamo obtiene un préstamo por su ID usando la conexión o la transacción recibida.
+func buscarPrestamo(q interface {
+	QueryRow(query string, args ...any) *sql.Row
+}, Id int) (Prestamo, error) {
+	prestamo, err := escanearPrestamo(q.QueryRow(consultaPrestamos+" WHERE p.Id = ?", Id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return prestamo, noEncontrado("préstamo con ID %d no encontrado", Id)
+		}
+		log.Printf("Error al escanear el préstamo con ID %d: %v", Id, err)
+		return prestamo, fmt.Errorf("error al obtener el préstamo: %w", err)
+	}
+	return prestamo, nil
+}
+
+// PrestarLibro registra el préstamo de la primera copia disponible del libro dentro de una transacción.
+func (repo *SQLPrestamoRepository) PrestarLibro(LibroId int, SocioId int, FechaVencimiento time.Time) (Prestamo, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return Prestamo{}, fmt.Errorf("error al iniciar la transacción: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya se confirmó.
+
+	if err := bloquearLibro(tx, LibroId); err != nil {
+		return Prestamo{}, err
+	}
+	// Solo los socios con la membresía activa pueden llevarse libros.
+	if err := verificarSocioActivo(tx, SocioId); err != nil {
+		return Prestamo{}, err
+	}
+
+	// Si el socio tiene una copia apartada por una reserva, se lleva esa copia.
+	// Si no, se elige la copia disponible más antigua que no esté apartada para otro socio.
+	var EjemplarId int
+	var apartado sql.NullInt64 // NULL si la copia apartada se eliminó (ON DELETE SET NULL).
+	err = tx.QueryRow("SELECT EjemplarId FROM reservas WHERE LibroId = ? AND SocioId = ? AND Estado = ? ORDER BY Id LIMIT 1",
+		LibroId, SocioId, EstadoReservaAsignada).Scan(&apartado)
+	if err == nil && apartado.Valid {
+		EjemplarId = int(apartado.Int64)
+	} else if err == nil || err == sql.ErrNoRows {
+		err = tx.QueryRow("SELECT e.Id FROM ejemplares e WHERE e.LibroId = ? AND e.Prestado = FALSE AND NOT "+ejemplarApartado+" ORDER BY e.Id LIMIT 1",
+			LibroId).Scan(&EjemplarId)
+	}
+	if err == sql.ErrNoRows {
+		return Prestamo{}, ErrLibroNoDisponible
+	}
+	if err != nil {
+		return Prestamo{}, fmt.Errorf("error al consultar los ejemplares: %w", err)
+	}
+
+	// La condición Prestado = FALSE es una segunda protección contra prestar dos veces la misma copia.
+	resultado, err := tx.Exec("UPDATE ejemplares SET Prestado = TRUE WHERE Id = ? AND Prestado = FALSE", EjemplarId)
+	if err != nil {
+		log.Printf("Error al marcar el ejemplar %d como prestado: %v", EjemplarId, err)
+		return Prestamo{}, fmt.Errorf("error al actualizar el ejemplar: %w", err)
+	}
+	if filas, err := resultado.RowsAffected(); err != nil {
+		return Prestamo{}, fmt.Errorf("error al obtener filas afectadas: %w", err)
+	} else if filas == 0 {
+		return Prestamo{}, ErrLibroNoDisponible
+	}
+
+	ahora := time.Now().Truncate(time.Second)
+	resultado, err = tx.Exec("INSERT INTO prestamos (LibroId, EjemplarId, SocioId, FechaPrestamo, FechaVencimiento) VALUES (?, ?, ?, ?, ?)",
+		LibroId, EjemplarId, SocioId, fechaSQL(ahora), fechaSQL(FechaVencimiento))
+	if err != nil {
+		log.Printf("Error al insertar el préstamo del libro %d: %v", LibroId, err)
+		return Prestamo{}, fmt.Errorf("error al insertar el préstamo: %w", err)
+	}
+	id, err := resultado.LastInsertId()
+	if err != nil {
+		return Prestamo{}, fmt.Errorf("error al obtener el ID del préstamo: %w", err)
+	}
+
+	// La reserva abierta del socio para este libro, si tenía una, queda atendida con este préstamo.
+	_, err = tx.Exec("UPDATE reservas SET Estado = ? WHERE LibroId = ? AND SocioId = ? AND Estado IN (?, ?)",
+		EstadoReservaCompletada, LibroId, SocioId, EstadoReservaPendiente, EstadoReservaAsignada)
+	if err != nil {
+		log.Printf("Error al completar la reserva del socio %d para el libro %d: %v", SocioId, LibroId, err)
+		return Prestamo{}, fmt.Errorf("error al actualizar la reserva: %w", err)
+	}
+
+	prestamo, err := buscarPrestamo(tx, int(id))
+	if err != nil {
+		return Prestamo{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Prestamo{}, fmt.Errorf("error al confirmar el préstamo: %w", err)
+	}
+	log.Printf("Ejemplar %d del libro %d prestado al socio %d. Préstamo ID: %d", EjemplarId, LibroId, SocioId, id)
+	return prestamo, nil
+}
+
+// DevolverLibro registra la devolución y marca el ejemplar como disponible dentro de una transacción.
+func (repo *SQLPrestamoRepository) DevolverLibro(Id int) (Prestamo, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return Prestamo{}, fmt.Errorf("error al iniciar la transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Se bloquea el libro igual que al prestarlo, antes de leer el préstamo, para que la copia devuelta no se
+	// preste a otro socio mientras se aparta para la primera reserva de la cola.
+	if err := bloquearLibroDe(tx, "prestamos", Id); err != nil {
+		return Prestamo{}, err
+	}
+	prestamo, err := buscarPrestamo(tx, Id)
+	if err != nil {
+		return Prestamo{}, err
+	}
+
+	// La condición FechaDevolucion IS NULL evita registrar dos veces la misma devolución.
+	ahora := time.Now().Truncate(time.Second)
+	resultado, err := tx.Exec("UPDATE prestamos SET FechaDevolucion = ? WHERE Id = ? AND FechaDevolucion IS NULL", fechaSQL(ahora), Id)
+	if err != nil {
+		log.Printf("Error al registrar la devolución del préstamo %d: %v", Id, err)
+		return Prestamo{}, fmt.Errorf("error al actualizar el préstamo: %w", err)
+	}
+	if filas, err := resultado.RowsAffected(); err != nil {
+		return Prestamo{}, fmt.Errorf("error al obtener filas afectadas: %w", err)
+	} else if filas == 0 {
+		return Prestamo{}, ErrPrestamoDevuelto
+	}
+
+	if _, err := tx.Exec("UPDATE ejemplares SET Prestado = FALSE WHERE Id = ?", prestamo.EjemplarId); err != nil {
+		log.Printf("Error al marcar el ejemplar %d como disponible: %v", prestamo.EjemplarId, err)
+		return Prestamo{}, fmt.Errorf("error al actualizar el ejemplar: %w", err)
+	}
+	// Si alguien espera el libro, la copia devuelta queda apartada para la reserva más antigua.
+	if err := asignarSiguienteReserva(tx, prestamo.LibroId, prestamo.EjemplarId, ahora); err != nil {
+		return Prestamo{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Prestamo{}, fmt.Errorf("error al confirmar la devolución: %w", err)
+	}
+
+	prestamo.FechaDevolucion = &ahora
+	log.Printf("Préstamo %d devuelto. Ejemplar %d devuelto al estante o apartado para una reserva.", Id, prestamo.EjemplarId)
+	return prestamo, nil
+}
+
+// bloquearLibro bloquea la fila del libro dentro de la transacción y verifica que exista.
+// Se llama antes de leer nada: dos operaciones simultáneas sobre el mismo libro (prestar, reservar)
+// se atienden una detrás de la otra, y la segunda ya ve los cambios de la primera.
+// Es SQL común a MySQL (bloqueo de fila) y SQLite (bloqueo de escritura de la base).
+func bloquearLibro(tx *sql.Tx, LibroId int) error {
+	if _, err := tx.Exec("UPDATE libros SET Id = Id WHERE Id = ?", LibroId); err != nil {
+		log.Printf("Error al bloquear el libro %d: %v", LibroId, err)
+		return fmt.Errorf("error al actualizar el libro: %w", err)
+	}
+	var existe int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM libros WHERE Id = ?", LibroId).Scan(&existe); err != nil {
+		return fmt.Errorf("error al consultar el libro: %w", err)
+	}
+	if existe == 0 {
+		return noEncontrado("libro con ID %d no encontrado", LibroId)
+	}
+	return nil
+}
+
+// bloquearLibroDe bloquea el libro de un préstamo o una reserva (tabla es "prestamos" o "reservas") antes de leer
+// la fila, cuando solo se conoce su ID. En MySQL la primera lectura de la transacción fija la instantánea que ven
+// las siguientes; bloquear primero asegura que la fila y la cola de reservas se lean con los cambios que confirmó
+// la operación anterior sobre el mismo libro. Si la fila no existe no bloquea nada y la lectura informa el error.
+func bloquearLibroDe(tx *sql.Tx, tabla string, Id int) error {
+	if _, err := tx.Exec("UPDATE libros SET Id = Id WHERE Id = (SELECT LibroId FROM "+tabla+" WHERE Id = ?)", Id); err != nil {
+		log.Printf("Error al bloquear el libro de %s %d: %v", tabla, Id, err)
+		return fmt.Errorf("error al actualizar el libro: %w", err)
+	}
+	return nil
+}
+
+// verificarSocioActivo devuelve ErrSocioNoActivo si la membresía del socio no está activa.
+func verificarSocioActivo(tx *sql.Tx, SocioId int) error {
+	var estado string
+	if err := tx.QueryRow("SELECT Estado FROM socios WHERE Id = ?", SocioId).Scan(&estado); err != nil {
+		if err == sql.ErrNoRows {
+			return noEncontrado("socio con ID %d no encontrado", SocioId)
+		}
+		return fmt.Errorf("error al consultar el socio: %w", err)
+	}
+	if estado != EstadoSocioActivo {
+		return ErrSocioNoActivo
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// probarPrestamoRepository verifica el contrato común de PrestamoRepository sobre repositorios de libros y socios vacíos.
+func probarPrestamoRepository(t *testing.T, libros LibroRepository, socios SocioRepository, prestamos PrestamoRepository) {
+	t.Helper()
+	libros.CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana")
+	socios.CreateSocio(Socio{Nombre: "Ana", Estado: EstadoSocioActivo})       // ID 1
+	socios.CreateSocio(Socio{Nombre: "Luis", Estado: EstadoSocioActivo})      // ID 2
+	socios.CreateSocio(Socio{Nombre: "Marta", Estado: EstadoSocioSuspendido}) // ID 3
+	vence := time.Date(2030, 1, 15, 23, 59, 59, 0, time.Local)
+
+	prestamo, err := prestamos.PrestarLibro(1, 1, vence)
+	if err != nil {
+		t.Fatalf("PrestarLibro: %v", err)
+	}
+	if prestamo.Id != 1 || prestamo.Titulo != "Rayuela" || prestamo.SocioId != 1 || prestamo.Socio != "Ana" ||
+		!prestamo.Activo() || !prestamo.FechaVencimiento.Equal(vence) {
+		t.Errorf("PrestarLibro = %+v", prestamo)
+	}
+	if libro, _ := libros.GetLibroByID(1); !libro.Prestado {
+		t.Error("el libro no quedó marcado como prestado")
+	}
+	if _, err := prestamos.PrestarLibro(1, 2, vence); !errors.Is(err, ErrLibroNoDisponible) || !errors.Is(err, ErrConflict) {
+		t.Errorf("PrestarLibro de un libro prestado devolvió %v", err)
+	}
+	if _, err := prestamos.PrestarLibro(99, 2, vence); !errors.Is(err, ErrNotFound) {
+		t.Errorf("PrestarLibro de un libro inexistente devolvió %v", err)
+	}
+	if _, err := prestamos.PrestarLibro(1, 99, vence); !errors.Is(err, ErrNotFound) {
+		t.Errorf("PrestarLibro a un socio inexistente devolvió %v", err)
+	}
+
+	devuelto, err := prestamos.DevolverLibro(1)
+	if err != nil || devuelto.Activo() {
+		t.Fatalf("DevolverLibro = %+v, %v", devuelto, err)
+	}
+	if libro, _ := libros.GetLibroByID(1); libro.Prestado {
+		t.Error("el libro sigue prestado después de la devolución")
+	}
+	if _, err := prestamos.DevolverLibro(1); !errors.Is(err, ErrPrestamoDevuelto) {
+		t.Errorf("DevolverLibro de un préstamo devuelto devolvió %v", err)
+	}
+
+	// Un socio suspendido no puede llevarse libros, aunque el libro esté disponible.
+	if _, err := prestamos.PrestarLibro(1, 3, vence); !errors.Is(err, ErrSocioNoActivo) {
+		t.Errorf("PrestarLibro a un socio suspendido devolvió %v", err)
+	}
+
+	prestamos.PrestarLibro(1, 2, vence)
+	lista, err := prestamos.GetAllPrestamos()
+	if err != nil || len(lista) != 2 || lista[0].Socio != "Luis" || lista[1].FechaDevolucion == nil {
+		t.Errorf("GetAllPrestamos = %+v, %v", lista, err)
+	}
+	if p, err := prestamos.GetPrestamoByID(2); err != nil || p.Socio != "Luis" {
+		t.Errorf("GetPrestamoByID(2) = %+v, %v", p, err)
+	}
+	if historial, err := prestamos.GetPrestamosBySocio(1); err != nil || len(historial) != 1 || historial[0].Id != 1 {
+		t.Errorf("GetPrestamosBySocio(1) = %+v, %v", historial, err)
+	}
+
+	// Un socio con historial de préstamos no se puede eliminar.
+	if err := socios.DeleteSocio(1); !errors.Is(err, ErrSocioConPrestamos) {
+		t.Errorf("DeleteSocio de un socio con préstamos devolvió %v", err)
+	}
+
+	// Al eliminar el libro desaparece su historial de préstamos.
+	if err := libros.DeleteLibro(1); err != nil {
+		t.Fatalf("DeleteLibro: %v", err)
+	}
+	if lista, _ := prestamos.GetAllPrestamos(); len(lista) != 0 {
+		t.Errorf("quedaron préstamos de un libro eliminado: %+v", lista)
+	}
+}
+
+// probarPrestamosConcurrentes verifica que solo uno de varios préstamos simultáneos del mismo libro tenga éxito.
+func probarPrestamosConcurrentes(t *testing.T, libros LibroRepository, socios SocioRepository, prestamos PrestamoRepository) {
+	t.Helper()
+	libros.CreateLibro("Borges", "Ficciones", 1944, "Sur")
+	libro, _ := libros.GetAllLibros()
+	socios.CreateSocio(Socio{Nombre: "Socio", Estado: EstadoSocioActivo})
+	socio, _ := socios.GetAllSocios()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	exitos := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := prestamos.PrestarLibro(libro[0].Id, socio[0].Id, time.Now()); err == nil {
+				mu.Lock()
+				exitos++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if exitos != 1 {
+		t.Errorf("se registraron %d préstamos simultáneos del mismo libro", exitos)
+	}
+}
+
+func TestMemoryPrestamoRepository(t *testing.T) {
+	mdb := NewMemoriaDB()
+	probarPrestamoRepository(t, NewMemoryLibroRepository(mdb), NewMemorySocioRepository(mdb), NewMemoryPrestamoRepository(mdb))
+
+	mdb = NewMemoriaDB()
+	probarPrestamosConcurrentes(t, NewMemoryLibroRepository(mdb), NewMemorySocioRepository(mdb), NewMemoryPrestamoRepository(mdb))
+}
+
+func TestSQLPrestamoRepositorySQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	libros, socios, prestamos := NewSQLLibroRepository(conexion), NewSQLSocioRepository(conexion), NewSQLPrestamoRepository(conexion)
+	probarPrestamoRepository(t, libros, socios, prestamos)
+	probarPrestamosConcurrentes(t, libros, socios, prestamos)
+}
+
+func TestParseFechaVencimiento(t *testing.T) {
+	fecha, err := ParseFechaVencimiento("2030-01-15")
+	if err != nil || fecha.Format("2006-01-02 15:04") != "2030-01-15 23:59" {
+		t.Errorf("ParseFechaVencimiento(fecha) = %v, %v", fecha, err)
+	}
+	fecha, err = ParseFechaVencimiento("")
+	if err != nil || fecha.Sub(time.Now()) < (DiasPrestamoPorDefecto-1)*24*time.Hour {
+		t.Errorf("ParseFechaVencimiento(\"\") = %v, %v", fecha, err)
+	}
+	if _, err := ParseFechaVencimiento("15/01/2030"); err == nil {
+		t.Error("ParseFechaVencimiento de un formato inválido no devolvió error")
+	}
+}
@@ -0,0 +1,74 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define las reservas (cola de espera) de libros prestados y su repositorio.
+*/
+
+package models
+
+import (
+	"time" // Paquete para las fechas de la reserva.
+)
+
+// DiasRetencionReserva es el plazo que tiene un socio para retirar la copia que se le asignó al devolverse el libro.
+const DiasRetencionReserva = 3
+
+// Estados posibles de una reserva.
+const (
+	EstadoReservaPendiente  = "Pendiente"  // En la cola, esperando que se devuelva una copia.
+	EstadoReservaAsignada   = "Asignada"   // Tiene una copia apartada hasta FechaExpiracion.
+	EstadoReservaCompletada = "Completada" // El socio se llevó el libro en préstamo.
+	EstadoReservaCancelada  = "Cancelada"  // La canceló el socio o el personal de la biblioteca.
+	EstadoReservaVencida    = "Vencida"    // El socio no retiró la copia asignada a tiempo.
+)
+
+// Errores que pueden devolver las operaciones sobre reservas. Los manejadores los reconocen con errors.Is
+// para responder 409 (Conflict) en lugar de 500.
+var (
+	ErrLibroDisponible  = nuevoError(ErrConflict, "el libro tiene ejemplares disponibles, puede prestarse sin reservarlo")
+	ErrReservaDuplicada = nuevoError(ErrConflict, "el socio ya tiene una reserva abierta de este libro")
+	ErrReservaCerrada   = nuevoError(ErrConflict, "la reserva ya no está pendiente ni asignada")
+)
+
+// Reserva representa el lugar de un socio en la cola de espera de un libro sin copias disponibles.
+// Las reservas se atienden por orden de llegada: al devolverse una copia, se asigna a la reserva
+// pendiente más antigua, que la tiene apartada durante DiasRetencionReserva días.
+type Reserva struct {
+	Id              int        // ID único de la reserva (clave primaria). También define el orden de la cola.
+	LibroId         int        // ID del libro reservado.
+	Titulo          string     // Título del libro (solo lectura, se obtiene de la tabla libros).
+	SocioId         int        // ID del socio que espera el libro.
+	Socio           string     // Nombre del socio (solo lectura, se obtiene de la tabla socios).
+	Estado          string     // Estado de la reserva (Pendiente, Asignada, Completada, Cancelada o Vencida).
+	FechaReserva    time.Time  // Fecha en que el socio entró en la cola.
+	EjemplarId      int        // ID de la copia apartada, 0 mientras la reserva esté pendiente.
+	CodigoBarras    string     // Código de barras de la copia apartada (solo lectura).
+	FechaExpiracion *time.Time // Fecha límite para retirar la copia apartada, nil mientras esté pendiente.
+	Posicion        int        // Posición en la cola de las reservas pendientes (solo lectura), 0 en los demás estados.
+}
+
+// Abierta indica si la reserva sigue esperando el libro o tiene una copia apartada.
+func (r Reserva) Abierta() bool {
+	return r.Estado == EstadoReservaPendiente || r.Estado == EstadoReservaAsignada
+}
+
+// ReservaRepository define las operaciones de persistencia para las reservas.
+// Prestar, devolver y vencer reservas mantienen la cola de forma atómica junto con el estado de las copias:
+// una copia apartada para una reserva no cuenta como disponible y solo puede prestarse a ese socio.
+type ReservaRepository interface {
+	// GetReservasAbiertas devuelve las reservas pendientes y asignadas, agrupadas por libro en orden de llegada.
+	GetReservasAbiertas() ([]Reserva, error)
+	// GetReservasByLibro devuelve la cola de reservas abiertas de un libro, en orden de llegada.
+	GetReservasByLibro(LibroId int) ([]Reserva, error)
+	// GetReservaByID devuelve una reserva específica por su ID.
+	GetReservaByID(Id int) (Reserva, error)
+	// CrearReserva agrega a un socio activo al final de la cola de un libro.
+	// Devuelve ErrLibroDisponible si queda alguna copia libre, ErrReservaDuplicada si el socio ya
+	// está en la cola y ErrSocioNoActivo si su membresía no está activa.
+	CrearReserva(LibroId int, SocioId int) (Reserva, error)
+	// CancelarReserva cancela una reserva abierta. Si tenía una copia apartada, la copia pasa a la
+	// siguiente reserva de la cola. Devuelve ErrReservaCerrada si la reserva ya estaba cerrada.
+	CancelarReserva(Id int) (Reserva, error)
+	// VencerReservas marca como vencidas las reservas asignadas cuyo plazo terminó antes de la fecha
+	// recibida y pasa sus copias a la siguiente reserva de la cola. Devuelve cuántas reservas vencieron.
+	VencerReservas(ahora time.Time) (int, error)
+}
@@ -0,0 +1,164 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación en memoria del repositorio de reservas, usada en las pruebas y en el modo demo.
+*/
+
+package models
+
+import (
+	"sort" // Paquete para ordenar las reservas.
+	"time" // Paquete para las fechas de la reserva.
+)
+
+// MemoryReservaRepository implementa ReservaRepository en memoria.
+// Como todas las tablas de MemoriaDB comparten un mutex, la cola y las copias apartadas
+// se actualizan en un solo paso atómico, igual que en las transacciones de la versión SQL.
+type MemoryReservaRepository struct {
+	db *MemoriaDB // Almacenamiento en memoria compartido con los demás repositorios.
+}
+
+// NewMemoryReservaRepository crea un repositorio de reservas sobre el almacenamiento en memoria recibido.
+func NewMemoryReservaRepository(db *MemoriaDB) *MemoryReservaRepository {
+	return &MemoryReservaRepository{db: db}
+}
+
+// completar llena los datos de solo lectura de la reserva (título, socio, código de la copia y posición
+// en la cola), como la consulta de la versión SQL. Debe llamarse con el mutex de MemoriaDB tomado.
+func (repo *MemoryReservaRepository) completar(reserva Reserva) Reserva {
+	reserva.Titulo = repo.db.libros[reserva.LibroId].Titulo
+	reserva.Socio = repo.db.socios[reserva.SocioId].Nombre
+	reserva.CodigoBarras = repo.db.ejemplares[reserva.EjemplarId].CodigoBarras
+	reserva.Posicion = 0
+	if reserva.Estado == EstadoReservaPendiente {
+		for _, r := range repo.db.reservas {
+			if r.LibroId == reserva.LibroId && r.Estado == EstadoReservaPendiente && r.Id <= reserva.Id {
+				reserva.Posicion++
+			}
+		}
+	}
+	return reserva
+}
+
+// listar devuelve las reservas abiertas que cumplen la condición, ordenadas por libro y orden de llegada.
+func (repo *MemoryReservaRepository) listar(condicion func(Reserva) bool) []Reserva {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	var reservas []Reserva
+	for _, reserva := range repo.db.reservas {
+		if reserva.Abierta() && condicion(reserva) {
+			reservas = append(reservas, repo.completar(reserva))
+		}
+	}
+	sort.Slice(reservas, func(i, j int) bool {
+		if reservas[i].LibroId != reservas[j].LibroId {
+			return reservas[i].LibroId < reservas[j].LibroId
+		}
+		return reservas[i].Id < reservas[j].Id
+	})
+	return reservas
+}
+
+// GetReservasAbiertas devuelve las reservas pendientes y asignadas, agrupadas por libro en orden de llegada.
+func (repo *MemoryReservaRepository) GetReservasAbiertas() ([]Reserva, error) {
+	return repo.listar(func(Reserva) bool { return true }), nil
+}
+
+// GetReservasByLibro devuelve la cola de reservas abiertas de un libro, en orden de llegada.
+func (repo *MemoryReservaRepository) GetReservasByLibro(LibroId int) ([]Reserva, error) {
+	return repo.listar(func(r Reserva) bool { return r.LibroId == LibroId }), nil
+}
+
+// GetReservaByID devuelve una reserva específica por su ID.
+func (repo *MemoryReservaRepository) GetReservaByID(Id int) (Reserva, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	reserva, ok := repo.db.reservas[Id]
+	if !ok {
+		return Reserva{}, noEncontrado("reserva con ID %d no encontrada", Id)
+	}
+	return repo.completar(reserva), nil
+}
+
+// CrearReserva agrega a un socio activo al final de la cola de un libro sin copias libres.
+func (repo *MemoryReservaRepository) CrearReserva(LibroId int, SocioId int) (Reserva, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	libro, ok := repo.db.libros[LibroId]
+	if !ok {
+		return Reserva{}, noEncontrado("libro con ID %d no encontrado", LibroId)
+	}
+	socio, ok := repo.db.socios[SocioId]
+	if !ok {
+		return Reserva{}, noEncontrado("socio con ID %d no encontrado", SocioId)
+	}
+	if socio.Estado != EstadoSocioActivo {
+		return Reserva{}, ErrSocioNoActivo
+	}
+	for _, reserva := range repo.db.reservas {
+		if reserva.LibroId == LibroId && reserva.SocioId == SocioId && reserva.Abierta() {
+			return Reserva{}, ErrReservaDuplicada
+		}
+	}
+	if repo.db.conDisponibilidad(libro).Disponibles > 0 {
+		return Reserva{}, ErrLibroDisponible
+	}
+
+	repo.db.nextReservaId++
+	reserva := Reserva{
+		Id:           repo.db.nextReservaId,
+		LibroId:      LibroId,
+		SocioId:      SocioId,
+		Estado:       EstadoReservaPendiente,
+		FechaReserva: time.Now().Truncate(time.Second),
+	}
+	repo.db.reservas[reserva.Id] = reserva
+	return repo.completar(reserva), nil
+}
+
+// CancelarReserva cancela una reserva abierta y pasa su copia apartada, si tenía, a la siguiente de la cola.
+func (repo *MemoryReservaRepository) CancelarReserva(Id int) (Reserva, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	reserva, ok := repo.db.reservas[Id]
+	if !ok {
+		return Reserva{}, noEncontrado("reserva con ID %d no encontrada", Id)
+	}
+	if !reserva.Abierta() {
+		return Reserva{}, ErrReservaCerrada
+	}
+
+	asignada := reserva.Estado == EstadoReservaAsignada
+	reserva.Estado = EstadoReservaCancelada
+	repo.db.reservas[Id] = reserva
+	if asignada {
+		repo.db.asignarSiguienteReserva(reserva.LibroId, reserva.EjemplarId, time.Now())
+	}
+	return repo.completar(reserva), nil
+}
+
+// VencerReservas marca como vencidas las reservas asignadas que no se retiraron a tiempo.
+func (repo *MemoryReservaRepository) VencerReservas(ahora time.Time) (int, error) {
+	ahora = ahora.Truncate(time.Second)
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	// Se recorren en orden de ID, como el ORDER BY de la versión SQL, para que la cola avance igual.
+	var vencidas []Reserva
+	for _, reserva := range repo.db.reservas {
+		if reserva.Estado == EstadoReservaAsignada && reserva.FechaExpiracion.Before(ahora) {
+			vencidas = append(vencidas, reserva)
+		}
+	}
+	sort.Slice(vencidas, func(i, j int) bool { return vencidas[i].Id < vencidas[j].Id })
+
+	for _, reserva := range vencidas {
+		reserva.Estado = EstadoReservaVencida
+		repo.db.reservas[reserva.Id] = reserva
+		repo.db.asignarSiguienteReserva(reserva.LibroId, reserva.EjemplarId, ahora)
+	}
+	return len(vencidas), nil
+}
@@ -0,0 +1,330 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación del repositorio de reservas sobre una base de datos SQL (MySQL o SQLite).
+*/
+
+package models
+
+import (
+	"database/sql" // Paquete para interactuar con bases de datos SQL.
+	"errors"       // Paquete para reconocer el libro eliminado al vencer una reserva.
+	"fmt"          // Paquete para formatear cadenas.
+	"log"          // Paquete para logging de errores y mensajes.
+	"time"         // Paquete para las fechas de la reserva.
+)
+
+// ejemplarApartado es la condición SQL que indica si la copia "e" está apartada para una reserva asignada.
+// Usa el literal de EstadoReservaAsignada porque se inserta en otras consultas constantes.
+const ejemplarApartado = `EXISTS (SELECT 1 FROM reservas r WHERE r.EjemplarId = e.Id AND r.Estado = 'Asignada')`
+
+// consultaReservas selecciona las reservas con el título del libro, el nombre del socio, el código de la copia
+// apartada y la posición en la cola de las pendientes (el literal 'Pendiente' es EstadoReservaPendiente).
+const consultaReservas = `SELECT r.Id, r.LibroId, l.Titulo, r.SocioId, s.Nombre, r.Estado, r.FechaReserva,
+	r.EjemplarId, e.CodigoBarras, r.FechaExpiracion,
+	CASE WHEN r.Estado = 'Pendiente' THEN
+		(SELECT COUNT(*) FROM reservas c WHERE c.LibroId = r.LibroId AND c.Estado = 'Pendiente' AND c.Id <= r.Id)
+	ELSE 0 END
+	FROM reservas r JOIN libros l ON l.Id = r.LibroId JOIN socios s ON s.Id = r.SocioId
+	LEFT JOIN ejemplares e ON e.Id = r.EjemplarId`
+
+// SQLReservaRepository implementa ReservaRepository usando un pool de conexiones compartido.
+type SQLReservaRepository struct {
+	db *sql.DB // Pool de conexiones a la base de datos.
+}
+
+// NewSQLReservaRepository crea un repositorio de reservas que usa la conexión recibida.
+func NewSQLReservaRepository(db *sql.DB) *SQLReservaRepository {
+	return &SQLReservaRepository{db: db}
+}
+
+// escanearReserva lee una fila de consultaReservas en una estructura Reserva.
+func escanearReserva(fila interface{ Scan(...any) error }) (Reserva, error) {
+	var reserva Reserva
+	var EjemplarId sql.NullInt64    // NULL mientras la reserva esté pendiente.
+	var CodigoBarras sql.NullString // NULL si no hay copia apartada.
+	var expiracion sql.NullTime     // NULL mientras la reserva esté pendiente.
+	err := fila.Scan(&reserva.Id, &reserva.LibroId, &reserva.Titulo, &reserva.SocioId, &reserva.Socio, &reserva.Estado,
+		&reserva.FechaReserva, &EjemplarId, &CodigoBarras, &expiracion, &reserva.Posicion)
+	reserva.EjemplarId = int(EjemplarId.Int64)
+	reserva.CodigoBarras = CodigoBarras.String
+	reserva.FechaReserva = fechaLocal(reserva.FechaReserva)
+	if expiracion.Valid {
+		expira := fechaLocal(expiracion.Time)
+		reserva.FechaExpiracion = &expira
+	}
+	return reserva, err
+}
+
+// GetReservasAbiertas devuelve las reservas pendientes y asignadas, agrupadas por libro en orden de llegada.
+func (repo *SQLReservaRepository) GetReservasAbiertas() ([]Reserva, error) {
+	return repo.listarReservas(consultaReservas+" WHERE r.Estado IN (?, ?) ORDER BY r.LibroId, r.Id",
+		EstadoReservaPendiente, EstadoReservaAsignada)
+}
+
+// GetReservasByLibro devuelve la cola de reservas abiertas de un libro, en orden de llegada.
+func (repo *SQLReservaRepository) GetReservasByLibro(LibroId int) ([]Reserva, error) {
+	return repo.listarReservas(consultaReservas+" WHERE r.LibroId = ? AND r.Estado IN (?, ?) ORDER BY r.Id",
+		LibroId, EstadoReservaPendiente, EstadoReservaAsignada)
+}
+
+// listarReservas ejecuta una variante de consultaReservas y escanea todas sus filas.
+func (repo *SQLReservaRepository) listarReservas(consulta string, args ...any) ([]Reserva, error) {
+	rows, err := repo.db.Query(consulta, args...)
+	if err != nil {
+		log.Printf("Error al ejecutar la consulta de reservas: %v", err)
+		return nil, fmt.Errorf("error al ejecutar la consulta: %w", err)
+	}
+	defer rows.Close()
+
+	var reservas []Reserva
+	for rows.Next() {
+		reserva, err := escanearReserva(rows)
+		if err != nil {
+			log.Printf("Error al escanear los resultados de reservas: %v", err)
+			return nil, fmt.Errorf("error al escanear los resultados: %w", err)
+		}
+		reservas = append(reservas, reserva)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("Error al procesar los resultados de reservas: %v", err)
+		return nil, fmt.Errorf("error al procesar los resultados: %w", err)
+	}
+	return reservas, nil
+}
+
+// GetReservaByID consulta la base de datos y devuelve una reserva específica por su ID.
+func (repo *SQLReservaRepository) GetReservaByID(Id int) (Reserva, error) {
+	return buscarReserva(repo.db, Id)
+}
+
+// buscarReserva obtiene una reserva por su ID usando la conexión o la transacción recibida.
+func buscarReserva(q interface {
+	QueryRow(query string, args ...any) *sql.Row
+}, Id int) (Reserva, error) {
+	reserva, err := escanearReserva(q.QueryRow(consultaReservas+" WHERE r.Id = ?", Id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return reserva, noEncontrado("reserva con ID %d no encontrada", Id)
+		}
+		log.Printf("Error al escanear la reserva con ID %d: %v", Id, err)
+		return reserva, fmt.Errorf("error al obtener la reserva: %w", err)
+	}
+	return reserva, nil
+}
+
+// CrearReserva agrega al socio al final de la cola del libro dentro de una transacción.
+func (repo *SQLReservaRepository) CrearReserva(LibroId int, SocioId int) (Reserva, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return Reserva{}, fmt.Errorf("error al iniciar la transacción: %w", err)
+	}
+	defer tx.Rollback() // No tiene efecto si la transacción ya se confirmó.
+
+	// Se bloquea el libro igual que al prestarlo, para que la cola y las copias libres no cambien mientras tanto.
+	if err := bloquearLibro(tx, LibroId); err != nil {
+		return Reserva{}, err
+	}
+	if err := verificarSocioActivo(tx, SocioId); err != nil {
+		return Reserva{}, err
+	}
+
+	var abiertas int
+	err = tx.QueryRow("SELECT COUNT(*) FROM reservas WHERE LibroId = ? AND SocioId = ? AND Estado IN (?, ?)",
+		LibroId, SocioId, EstadoReservaPendiente, EstadoReservaAsignada).Scan(&abiertas)
+	if err != nil {
+		return Reserva{}, fmt.Errorf("error al consultar las reservas del socio: %w", err)
+	}
+	if abiertas > 0 {
+		return Reserva{}, ErrReservaDuplicada
+	}
+
+	// Solo se puede reservar un libro que no tiene copias libres.
+	var disponibles int
+	err = tx.QueryRow("SELECT COUNT(*) FROM ejemplares e WHERE e.LibroId = ? AND e.Prestado = FALSE AND NOT "+ejemplarApartado, LibroId).Scan(&disponibles)
+	if err != nil {
+		return Reserva{}, fmt.Errorf("error al consultar los ejemplares: %w", err)
+	}
+	if disponibles > 0 {
+		return Reserva{}, ErrLibroDisponible
+	}
+
+	resultado, err := tx.Exec("INSERT INTO reservas (LibroId, SocioId, Estado, FechaReserva) VALUES (?, ?, ?, ?)",
+		LibroId, SocioId, EstadoReservaPendiente, fechaSQL(time.Now().Truncate(time.Second)))
+	if err != nil {
+		log.Printf("Error al insertar la reserva del libro %d: %v", LibroId, err)
+		return Reserva{}, fmt.Errorf("error al insertar la reserva: %w", err)
+	}
+	id, err := resultado.LastInsertId()
+	if err != nil {
+		return Reserva{}, fmt.Errorf("error al obtener el ID de la reserva: %w", err)
+	}
+
+	reserva, err := buscarReserva(tx, int(id))
+	if err != nil {
+		return Reserva{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Reserva{}, fmt.Errorf("error al confirmar la reserva: %w", err)
+	}
+	log.Printf("Libro %d reservado por el socio %d. Reserva ID: %d, posición %d", LibroId, SocioId, id, reserva.Posicion)
+	return reserva, nil
+}
+
+// CancelarReserva cancela una reserva abierta y pasa su copia apartada, si tenía, a la siguiente de la cola.
+func (repo *SQLReservaRepository) CancelarReserva(Id int) (Reserva, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return Reserva{}, fmt.Errorf("error al iniciar la transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := bloquearLibroDe(tx, "reservas", Id); err != nil {
+		return Reserva{}, err
+	}
+	reserva, err := buscarReserva(tx, Id)
+	if err != nil {
+		return Reserva{}, err
+	}
+	if !reserva.Abierta() {
+		return Reserva{}, ErrReservaCerrada
+	}
+
+	// La condición sobre el estado evita cancelar dos veces o cancelar una reserva ya atendida.
+	resultado, err := tx.Exec("UPDATE reservas SET Estado = ? WHERE Id = ? AND Estado = ?", EstadoReservaCancelada, Id, reserva.Estado)
+	if err != nil {
+		log.Printf("Error al cancelar la reserva %d: %v", Id, err)
+		return Reserva{}, fmt.Errorf("error al actualizar la reserva: %w", err)
+	}
+	if filas, err := resultado.RowsAffected(); err != nil {
+		return Reserva{}, fmt.Errorf("error al obtener filas afectadas: %w", err)
+	} else if filas == 0 {
+		return Reserva{}, ErrReservaCerrada
+	}
+
+	if reserva.Estado == EstadoReservaAsignada {
+		if err := asignarSiguienteReserva(tx, reserva.LibroId, reserva.EjemplarId, time.Now()); err != nil {
+			return Reserva{}, err
+		}
+	}
+
+	reserva, err = buscarReserva(tx, Id)
+	if err != nil {
+		return Reserva{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Reserva{}, fmt.Errorf("error al confirmar la cancelación: %w", err)
+	}
+	log.Printf("Reserva %d cancelada.", Id)
+	return reserva, nil
+}
+
+// VencerReservas marca como vencidas las reservas asignadas que no se retiraron a tiempo.
+// Cada reserva se vence en su propia transacción, con el libro bloqueado antes de leer nada (ver vencerReserva),
+// para que un préstamo o una reserva confirmados mientras tanto no se pisen con la cola.
+func (repo *SQLReservaRepository) VencerReservas(ahora time.Time) (int, error) {
+	ahora = ahora.Truncate(time.Second)
+	rows, err := repo.db.Query("SELECT Id, LibroId, EjemplarId FROM reservas WHERE Estado = ? AND FechaExpiracion < ? ORDER BY Id",
+		EstadoReservaAsignada, fechaSQL(ahora))
+	if err != nil {
+		log.Printf("Error al consultar las reservas vencidas: %v", err)
+		return 0, fmt.Errorf("error al consultar las reservas vencidas: %w", err)
+	}
+	// Se leen todas las filas antes de modificar nada, para no mantener el cursor abierto durante las transacciones.
+	var vencidas []Reserva
+	for rows.Next() {
+		var reserva Reserva
+		var EjemplarId sql.NullInt64 // NULL si la copia apartada se eliminó (ON DELETE SET NULL).
+		if err := rows.Scan(&reserva.Id, &reserva.LibroId, &EjemplarId); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error al escanear las reservas vencidas: %w", err)
+		}
+		reserva.EjemplarId = int(EjemplarId.Int64)
+		vencidas = append(vencidas, reserva)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error al procesar las reservas vencidas: %w", err)
+	}
+
+	vencidasAhora := 0
+	for _, reserva := range vencidas {
+		vencida, err := repo.vencerReserva(reserva, ahora)
+		if err != nil {
+			return vencidasAhora, err
+		}
+		if vencida {
+			vencidasAhora++
+		}
+	}
+	return vencidasAhora, nil
+}
+
+// vencerReserva marca como vencida una reserva asignada y pasa su copia a la siguiente de la cola.
+// Devuelve false sin cambiar nada si la reserva ya no está asignada: el socio retiró el libro o la canceló
+// después de la consulta de VencerReservas, y la copia ya no le pertenece.
+func (repo *SQLReservaRepository) vencerReserva(reserva Reserva, ahora time.Time) (bool, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("error al iniciar la transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := bloquearLibro(tx, reserva.LibroId); errors.Is(err, ErrNotFound) {
+		return false, nil // El libro se eliminó junto con sus reservas.
+	} else if err != nil {
+		return false, err
+	}
+	resultado, err := tx.Exec("UPDATE reservas SET Estado = ? WHERE Id = ? AND Estado = ?", EstadoReservaVencida, reserva.Id, EstadoReservaAsignada)
+	if err != nil {
+		log.Printf("Error al vencer la reserva %d: %v", reserva.Id, err)
+		return false, fmt.Errorf("error al actualizar la reserva: %w", err)
+	}
+	if filas, err := resultado.RowsAffected(); err != nil {
+		return false, fmt.Errorf("error al obtener filas afectadas: %w", err)
+	} else if filas == 0 {
+		return false, nil
+	}
+	if err := asignarSiguienteReserva(tx, reserva.LibroId, reserva.EjemplarId, ahora); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("error al confirmar el vencimiento de la reserva: %w", err)
+	}
+	return true, nil
+}
+
+// asignarSiguienteReserva aparta la copia recibida para la reserva pendiente más antigua del libro,
+// con un plazo de DiasRetencionReserva días para retirarla. No hace nada si la cola está vacía.
+// EjemplarId es 0 si la copia que se libera ya no existe (la reserva quedó con EjemplarId NULL al
+// eliminarla); en ese caso se aparta otra copia libre del libro, o la reserva sigue esperando si no la hay.
+// Debe llamarse con el libro bloqueado (ver bloquearLibro).
+func asignarSiguienteReserva(tx *sql.Tx, LibroId, EjemplarId int, ahora time.Time) error {
+	var ReservaId int
+	err := tx.QueryRow("SELECT Id FROM reservas WHERE LibroId = ? AND Estado = ? ORDER BY Id LIMIT 1", LibroId, EstadoReservaPendiente).Scan(&ReservaId)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error al consultar la cola de reservas: %w", err)
+	}
+	if EjemplarId == 0 {
+		err = tx.QueryRow("SELECT e.Id FROM ejemplares e WHERE e.LibroId = ? AND e.Prestado = FALSE AND NOT "+ejemplarApartado+" ORDER BY e.Id LIMIT 1",
+			LibroId).Scan(&EjemplarId)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error al consultar los ejemplares: %w", err)
+		}
+	}
+
+	expiracion := finDelDia(ahora.AddDate(0, 0, DiasRetencionReserva))
+	_, err = tx.Exec("UPDATE reservas SET Estado = ?, EjemplarId = ?, FechaExpiracion = ? WHERE Id = ?",
+		EstadoReservaAsignada, EjemplarId, fechaSQL(expiracion), ReservaId)
+	if err != nil {
+		log.Printf("Error al asignar el ejemplar %d a la reserva %d: %v", EjemplarId, ReservaId, err)
+		return fmt.Errorf("error al asignar la reserva: %w", err)
+	}
+	log.Printf("Ejemplar %d apartado para la reserva %d hasta %s.", EjemplarId, ReservaId, expiracion.Format("02/01/2006"))
+	return nil
+}
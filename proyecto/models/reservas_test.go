@@ -0,0 +1,248 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// probarReservaRepository verifica el contrato común de ReservaRepository sobre repositorios vacíos.
+func probarReservaRepository(t *testing.T, libros LibroRepository, ejemplares EjemplarRepository, socios SocioRepository,
+	prestamos PrestamoRepository, reservas ReservaRepository) {
+	t.Helper()
+	libros.CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana")
+	socios.CreateSocio(Socio{Nombre: "Ana", Estado: EstadoSocioActivo})       // ID 1
+	socios.CreateSocio(Socio{Nombre: "Luis", Estado: EstadoSocioActivo})      // ID 2
+	socios.CreateSocio(Socio{Nombre: "Marta", Estado: EstadoSocioActivo})     // ID 3
+	socios.CreateSocio(Socio{Nombre: "Pedro", Estado: EstadoSocioSuspendido}) // ID 4
+	vence := time.Now().AddDate(0, 0, DiasPrestamoPorDefecto)
+
+	if _, err := reservas.CrearReserva(1, 2); !errors.Is(err, ErrLibroDisponible) {
+		t.Errorf("CrearReserva de un libro disponible devolvió %v", err)
+	}
+	prestamo, _ := prestamos.PrestarLibro(1, 1, vence)
+
+	// La cola se atiende por orden de llegada.
+	luis, err := reservas.CrearReserva(1, 2)
+	if err != nil || luis.Estado != EstadoReservaPendiente || luis.Posicion != 1 || luis.Titulo != "Rayuela" || luis.Socio != "Luis" {
+		t.Fatalf("CrearReserva = %+v, %v", luis, err)
+	}
+	marta, _ := reservas.CrearReserva(1, 3)
+	if marta.Posicion != 2 {
+		t.Errorf("posición de la segunda reserva = %d", marta.Posicion)
+	}
+	if _, err := reservas.CrearReserva(1, 2); !errors.Is(err, ErrReservaDuplicada) {
+		t.Errorf("CrearReserva duplicada devolvió %v", err)
+	}
+	if _, err := reservas.CrearReserva(1, 4); !errors.Is(err, ErrSocioNoActivo) {
+		t.Errorf("CrearReserva de un socio suspendido devolvió %v", err)
+	}
+	if _, err := reservas.CrearReserva(99, 2); err == nil {
+		t.Error("CrearReserva de un libro inexistente no devolvió error")
+	}
+
+	// Al devolverse el libro, la copia queda apartada para la primera reserva.
+	prestamos.DevolverLibro(prestamo.Id)
+	luis, _ = reservas.GetReservaByID(luis.Id)
+	if luis.Estado != EstadoReservaAsignada || luis.EjemplarId != 1 || luis.CodigoBarras != "L000001-1" || luis.FechaExpiracion == nil {
+		t.Fatalf("reserva después de la devolución = %+v", luis)
+	}
+	if cola, _ := reservas.GetReservasByLibro(1); len(cola) != 2 || cola[0].Id != luis.Id || cola[1].Posicion != 1 {
+		t.Errorf("GetReservasByLibro = %+v", cola)
+	}
+	if libro, _ := libros.GetLibroByID(1); libro.Disponibles != 0 {
+		t.Errorf("la copia apartada cuenta como disponible: %+v", libro)
+	}
+	if resumen, _ := libros.ContarLibros(); resumen.Reservados != 1 || resumen.Disponibles != 0 {
+		t.Errorf("ContarLibros = %+v", resumen)
+	}
+	if ejemplar, _ := ejemplares.GetEjemplarByID(1); !ejemplar.Reservado {
+		t.Error("el ejemplar apartado no figura como reservado")
+	}
+	if err := ejemplares.DeleteEjemplar(1); !errors.Is(err, ErrEjemplarReservado) {
+		t.Errorf("DeleteEjemplar de una copia apartada devolvió %v", err)
+	}
+	if _, err := prestamos.PrestarLibro(1, 3, vence); !errors.Is(err, ErrLibroNoDisponible) {
+		t.Errorf("PrestarLibro de una copia apartada para otro socio devolvió %v", err)
+	}
+
+	// Cancelar una reserva asignada pasa la copia a la siguiente de la cola.
+	if cancelada, err := reservas.CancelarReserva(luis.Id); err != nil || cancelada.Estado != EstadoReservaCancelada {
+		t.Fatalf("CancelarReserva = %+v, %v", cancelada, err)
+	}
+	if _, err := reservas.CancelarReserva(luis.Id); !errors.Is(err, ErrReservaCerrada) {
+		t.Errorf("CancelarReserva de una reserva cancelada devolvió %v", err)
+	}
+	if marta, _ = reservas.GetReservaByID(marta.Id); marta.Estado != EstadoReservaAsignada || marta.EjemplarId != 1 {
+		t.Fatalf("la copia no pasó a la siguiente reserva: %+v", marta)
+	}
+
+	// Si nadie retira la copia a tiempo, la reserva vence y la copia vuelve a estar disponible.
+	if n, err := reservas.VencerReservas(time.Now()); err != nil || n != 0 {
+		t.Errorf("VencerReservas antes del plazo = %d, %v", n, err)
+	}
+	if n, err := reservas.VencerReservas(time.Now().AddDate(0, 0, DiasRetencionReserva+1)); err != nil || n != 1 {
+		t.Fatalf("VencerReservas = %d, %v", n, err)
+	}
+	if marta, _ = reservas.GetReservaByID(marta.Id); marta.Estado != EstadoReservaVencida {
+		t.Errorf("reserva después del vencimiento = %+v", marta)
+	}
+	if libro, _ := libros.GetLibroByID(1); libro.Disponibles != 1 {
+		t.Errorf("la copia no volvió a estar disponible: %+v", libro)
+	}
+
+	// El socio con la copia apartada se la lleva y su reserva queda completada.
+	prestamo, _ = prestamos.PrestarLibro(1, 1, vence)
+	marta, _ = reservas.CrearReserva(1, 3)
+	prestamos.DevolverLibro(prestamo.Id)
+	if p, err := prestamos.PrestarLibro(1, 3, vence); err != nil || p.EjemplarId != 1 {
+		t.Fatalf("PrestarLibro con la copia apartada = %+v, %v", p, err)
+	}
+	if marta, _ = reservas.GetReservaByID(marta.Id); marta.Estado != EstadoReservaCompletada {
+		t.Errorf("reserva después del préstamo = %+v", marta)
+	}
+	if abiertas, _ := reservas.GetReservasAbiertas(); len(abiertas) != 0 {
+		t.Errorf("GetReservasAbiertas = %+v", abiertas)
+	}
+	if _, err := reservas.GetReservaByID(99); err == nil {
+		t.Error("GetReservaByID de una reserva inexistente no devolvió error")
+	}
+}
+
+// probarVencimientoEnOtraZona verifica que VencerReservas compara los instantes y no el texto de las fechas:
+// un momento anterior al plazo escrito en UTC+14 se escribe "después" del plazo, y uno posterior escrito en
+// UTC-12 se escribe "antes".
+func probarVencimientoEnOtraZona(t *testing.T, libros LibroRepository, socios SocioRepository, prestamos PrestamoRepository, reservas ReservaRepository) {
+	t.Helper()
+	libros.CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana")
+	socios.CreateSocio(Socio{Nombre: "Ana", Estado: EstadoSocioActivo})  // ID 1
+	socios.CreateSocio(Socio{Nombre: "Luis", Estado: EstadoSocioActivo}) // ID 2
+	prestamo, _ := prestamos.PrestarLibro(1, 1, time.Now().AddDate(0, 0, DiasPrestamoPorDefecto))
+	reserva, _ := reservas.CrearReserva(1, 2)
+	prestamos.DevolverLibro(prestamo.Id)
+	reserva, err := reservas.GetReservaByID(reserva.Id)
+	if err != nil || reserva.FechaExpiracion == nil {
+		t.Fatalf("reserva asignada = %+v, %v", reserva, err)
+	}
+
+	plazo := *reserva.FechaExpiracion
+	if n, err := reservas.VencerReservas(plazo.Add(-time.Hour).In(time.FixedZone("UTC+14", 14*60*60))); err != nil || n != 0 {
+		t.Errorf("VencerReservas antes del plazo = %d, %v", n, err)
+	}
+	if n, err := reservas.VencerReservas(plazo.Add(time.Hour).In(time.FixedZone("UTC-12", -12*60*60))); err != nil || n != 1 {
+		t.Errorf("VencerReservas después del plazo = %d, %v", n, err)
+	}
+}
+
+func TestMemoryReservaRepository(t *testing.T) {
+	mdb := NewMemoriaDB()
+	probarReservaRepository(t, NewMemoryLibroRepository(mdb), NewMemoryEjemplarRepository(mdb), NewMemorySocioRepository(mdb),
+		NewMemoryPrestamoRepository(mdb), NewMemoryReservaRepository(mdb))
+	mdb = NewMemoriaDB()
+	probarVencimientoEnOtraZona(t, NewMemoryLibroRepository(mdb), NewMemorySocioRepository(mdb),
+		NewMemoryPrestamoRepository(mdb), NewMemoryReservaRepository(mdb))
+}
+
+func TestSQLReservaRepositorySQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	probarReservaRepository(t, NewSQLLibroRepository(conexion), NewSQLEjemplarRepository(conexion), NewSQLSocioRepository(conexion),
+		NewSQLPrestamoRepository(conexion), NewSQLReservaRepository(conexion))
+	conexion = nuevaDBPrueba(t)
+	probarVencimientoEnOtraZona(t, NewSQLLibroRepository(conexion), NewSQLSocioRepository(conexion),
+		NewSQLPrestamoRepository(conexion), NewSQLReservaRepository(conexion))
+}
+
+// Si el socio retira el libro entre la consulta de VencerReservas y el vencimiento de su reserva, la reserva
+// ya no está asignada: no se marca como vencida ni se aparta para otro la copia que se llevó.
+func TestSQLVencerReservaRetirada(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	libros, socios := NewSQLLibroRepository(conexion), NewSQLSocioRepository(conexion)
+	prestamos, reservas := NewSQLPrestamoRepository(conexion), NewSQLReservaRepository(conexion)
+	libros.CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana")
+	for _, nombre := range []string{"Ana", "Luis", "Marta"} {
+		socios.CreateSocio(Socio{Nombre: nombre, Estado: EstadoSocioActivo})
+	}
+	vence := time.Now().AddDate(0, 0, DiasPrestamoPorDefecto)
+	prestamo, _ := prestamos.PrestarLibro(1, 1, vence)
+	luis, _ := reservas.CrearReserva(1, 2)
+	marta, _ := reservas.CrearReserva(1, 3)
+	prestamos.DevolverLibro(prestamo.Id) // La copia 1 queda apartada para Luis.
+	if _, err := prestamos.PrestarLibro(1, 2, vence); err != nil {
+		t.Fatalf("PrestarLibro con la copia apartada: %v", err)
+	}
+
+	// La reserva de Luis, tal como la leyó VencerReservas antes del préstamo.
+	vencida, err := reservas.vencerReserva(Reserva{Id: luis.Id, LibroId: 1, EjemplarId: 1}, time.Now().AddDate(0, 0, DiasRetencionReserva+1))
+	if err != nil || vencida {
+		t.Fatalf("vencerReserva de una reserva retirada = %v, %v", vencida, err)
+	}
+	if luis, _ = reservas.GetReservaByID(luis.Id); luis.Estado != EstadoReservaCompletada {
+		t.Errorf("reserva retirada después de vencerReserva = %+v", luis)
+	}
+	if marta, _ = reservas.GetReservaByID(marta.Id); marta.Estado != EstadoReservaPendiente {
+		t.Errorf("la siguiente reserva recibió la copia prestada: %+v", marta)
+	}
+}
+
+// Una reserva asignada cuya copia se eliminó queda con EjemplarId NULL (ON DELETE SET NULL). Vencerla,
+// cancelarla o prestar el libro no deben fallar: la siguiente de la cola recibe otra copia libre.
+func TestSQLReservaConEjemplarEliminado(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	libros, socios := NewSQLLibroRepository(conexion), NewSQLSocioRepository(conexion)
+	prestamos, reservas := NewSQLPrestamoRepository(conexion), NewSQLReservaRepository(conexion)
+	libros.CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana")
+	for _, nombre := range []string{"Ana", "Luis", "Marta", "Pedro"} {
+		socios.CreateSocio(Socio{Nombre: nombre, Estado: EstadoSocioActivo})
+	}
+	vence := time.Now().AddDate(0, 0, DiasPrestamoPorDefecto)
+	// eliminarCopia borra la copia directamente, como lo haría un DELETE hecho por fuera de DeleteEjemplar.
+	eliminarCopia := func(Id int) {
+		t.Helper()
+		if _, err := conexion.Exec("DELETE FROM ejemplares WHERE Id = ?", Id); err != nil {
+			t.Fatalf("eliminar el ejemplar %d: %v", Id, err)
+		}
+	}
+	// nuevaCopia inserta una copia libre directamente; CreateEjemplar la apartaría para la cola.
+	nuevaCopia := func(codigo string) Ejemplar {
+		t.Helper()
+		resultado, err := conexion.Exec("INSERT INTO ejemplares (LibroId, CodigoBarras, Condicion, FechaAdquisicion) VALUES (1, ?, 'Bueno', CURRENT_TIMESTAMP)", codigo)
+		if err != nil {
+			t.Fatalf("insertar el ejemplar %s: %v", codigo, err)
+		}
+		Id, _ := resultado.LastInsertId()
+		return Ejemplar{Id: int(Id), LibroId: 1, CodigoBarras: codigo}
+	}
+
+	prestamo, _ := prestamos.PrestarLibro(1, 1, vence)
+	luis, _ := reservas.CrearReserva(1, 2)
+	marta, _ := reservas.CrearReserva(1, 3)
+	prestamos.DevolverLibro(prestamo.Id) // La copia 1 queda apartada para Luis.
+	segunda := nuevaCopia("L000001-2")
+	eliminarCopia(1)
+
+	if n, err := reservas.VencerReservas(time.Now().AddDate(0, 0, DiasRetencionReserva+1)); err != nil || n != 1 {
+		t.Fatalf("VencerReservas con la copia eliminada = %d, %v", n, err)
+	}
+	if luis, _ = reservas.GetReservaByID(luis.Id); luis.Estado != EstadoReservaVencida {
+		t.Errorf("reserva con la copia eliminada después del vencimiento = %+v", luis)
+	}
+	if marta, _ = reservas.GetReservaByID(marta.Id); marta.Estado != EstadoReservaAsignada || marta.EjemplarId != segunda.Id {
+		t.Fatalf("la siguiente reserva no recibió la copia libre: %+v", marta)
+	}
+
+	pedro, _ := reservas.CrearReserva(1, 4)
+	tercera := nuevaCopia("L000001-3")
+	eliminarCopia(segunda.Id)
+	if cancelada, err := reservas.CancelarReserva(marta.Id); err != nil || cancelada.Estado != EstadoReservaCancelada {
+		t.Fatalf("CancelarReserva con la copia eliminada = %+v, %v", cancelada, err)
+	}
+	if pedro, _ = reservas.GetReservaByID(pedro.Id); pedro.Estado != EstadoReservaAsignada || pedro.EjemplarId != tercera.Id {
+		t.Fatalf("la siguiente reserva no recibió la copia libre al cancelar: %+v", pedro)
+	}
+
+	cuarta := nuevaCopia("L000001-4")
+	eliminarCopia(tercera.Id)
+	if p, err := prestamos.PrestarLibro(1, 4, vence); err != nil || p.EjemplarId != cuarta.Id {
+		t.Errorf("PrestarLibro con la copia apartada eliminada = %+v, %v", p, err)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define la entidad Socio y el repositorio para sus operaciones CRUD.
+*/
+
+package models
+
+import (
+	"time" // Paquete para la fecha de alta del socio.
+)
+
+// Estados posibles de la membresía de un socio.
+const (
+	EstadoSocioActivo     = "Activo"     // Puede pedir libros prestados.
+	EstadoSocioSuspendido = "Suspendido" // Membresía suspendida temporalmente, no puede pedir libros.
+	EstadoSocioBaja       = "Baja"       // Dado de baja, se conserva solo por su historial.
+)
+
+// EstadosSocio enumera los estados válidos, en el orden en que se muestran en los formularios.
+var EstadosSocio = []string{EstadoSocioActivo, EstadoSocioSuspendido, EstadoSocioBaja}
+
+// Errores que pueden devolver las operaciones sobre socios.
+var (
+	ErrSocioNoActivo     = nuevoError(ErrConflict, "el socio no tiene la membresía activa")
+	ErrSocioConPrestamos = nuevoError(ErrConflict, "el socio tiene préstamos registrados, márquelo como Baja en lugar de eliminarlo")
+)
+
+// Socio representa a una persona inscrita en la biblioteca que puede pedir libros prestados.
+type Socio struct {
+	Id        int       // ID único del socio (clave primaria).
+	Nombre    string    // Nombre completo del socio.
+	Email     string    // Correo electrónico de contacto.
+	Telefono  string    // Teléfono de contacto.
+	Direccion string    // Dirección postal.
+	Estado    string    // Estado de la membresía (ver EstadosSocio).
+	FechaAlta time.Time // Fecha de inscripción, la asigna el repositorio al crear el socio.
+}
+
+// EstadoSocioValido indica si el estado recibido es uno de EstadosSocio.
+func EstadoSocioValido(estado string) bool {
+	for _, e := range EstadosSocio {
+		if e == estado {
+			return true
+		}
+	}
+	return false
+}
+
+// SocioRepository define las operaciones de persistencia disponibles para la entidad Socio.
+type SocioRepository interface {
+	// GetAllSocios devuelve una lista de todos los socios ordenados por nombre.
+	GetAllSocios() ([]Socio, error)
+	// CreateSocio inserta un nuevo socio y lo devuelve con el ID y la fecha de alta, que asigna el repositorio.
+	CreateSocio(socio Socio) (Socio, error)
+	// GetSocioByID devuelve un socio específico por su ID.
+	GetSocioByID(Id int) (Socio, error)
+	// UpdateSocio actualiza los datos de contacto y el estado de un socio existente.
+	UpdateSocio(socio Socio) error
+	// DeleteSocio elimina un socio sin préstamos. Devuelve ErrSocioConPrestamos si tiene historial.
+	DeleteSocio(Id int) error
+}
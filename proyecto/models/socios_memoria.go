@@ -0,0 +1,100 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación en memoria del repositorio de socios, usada en las pruebas y en el modo demo.
+*/
+
+package models
+
+import (
+	"sort" // Paquete para ordenar los socios por nombre.
+	"time" // Paquete para la fecha de alta.
+)
+
+// MemorySocioRepository implementa SocioRepository en memoria.
+type MemorySocioRepository struct {
+	db *MemoriaDB // Almacenamiento en memoria compartido con los demás repositorios.
+}
+
+// NewMemorySocioRepository crea un repositorio de socios sobre el almacenamiento en memoria recibido.
+func NewMemorySocioRepository(db *MemoriaDB) *MemorySocioRepository {
+	return &MemorySocioRepository{db: db}
+}
+
+// GetAllSocios devuelve todos los socios ordenados por nombre.
+func (repo *MemorySocioRepository) GetAllSocios() ([]Socio, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	var socios []Socio
+	for _, socio := range repo.db.socios {
+		socios = append(socios, socio)
+	}
+	sort.Slice(socios, func(i, j int) bool {
+		if socios[i].Nombre != socios[j].Nombre {
+			return socios[i].Nombre < socios[j].Nombre
+		}
+		return socios[i].Id < socios[j].Id
+	})
+	return socios, nil
+}
+
+// CreateSocio agrega un nuevo socio asignándole el siguiente ID y la fecha de alta actual.
+func (repo *MemorySocioRepository) CreateSocio(socio Socio) (Socio, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	repo.db.nextSocioId++
+	socio.Id = repo.db.nextSocioId
+	socio.FechaAlta = time.Now().Truncate(time.Second)
+	repo.db.socios[socio.Id] = socio
+	return socio, nil
+}
+
+// GetSocioByID devuelve un socio específico por su ID.
+func (repo *MemorySocioRepository) GetSocioByID(Id int) (Socio, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	socio, ok := repo.db.socios[Id]
+	if !ok {
+		return socio, noEncontrado("socio con ID %d no encontrado", Id)
+	}
+	return socio, nil
+}
+
+// UpdateSocio actualiza un socio existente conservando su fecha de alta.
+// Igual que el UPDATE de SQL, si el socio no existe no se modifica nada y no se devuelve error.
+func (repo *MemorySocioRepository) UpdateSocio(socio Socio) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if actual, ok := repo.db.socios[socio.Id]; ok {
+		socio.FechaAlta = actual.FechaAlta
+		repo.db.socios[socio.Id] = socio
+	}
+	return nil
+}
+
+// DeleteSocio elimina un socio por su ID si no tiene préstamos registrados.
+func (repo *MemorySocioRepository) DeleteSocio(Id int) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if _, ok := repo.db.socios[Id]; !ok {
+		return noEncontrado("no se encontró ningún socio con ID %d para eliminar", Id)
+	}
+	for _, prestamo := range repo.db.prestamos {
+		if prestamo.SocioId == Id {
+			return ErrSocioConPrestamos
+		}
+	}
+	delete(repo.db.socios, Id)
+
+	// Emula el ON DELETE CASCADE de la tabla reservas.
+	for id, reserva := range repo.db.reservas {
+		if reserva.SocioId == Id {
+			delete(repo.db.reservas, id)
+		}
+	}
+	return nil
+}
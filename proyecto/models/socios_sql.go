@@ -0,0 +1,121 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación del repositorio de socios sobre una base de datos SQL (MySQL o SQLite).
+*/
+
+package models
+
+import (
+	"database/sql" // Paquete para interactuar con bases de datos SQL.
+	"fmt"          // Paquete para formatear cadenas.
+	"log"          // Paquete para logging de errores y mensajes.
+	"time"         // Paquete para la fecha de alta.
+)
+
+// SQLSocioRepository implementa SocioRepository usando un pool de conexiones compartido.
+type SQLSocioRepository struct {
+	db *sql.DB // Pool de conexiones a la base de datos.
+}
+
+// NewSQLSocioRepository crea un repositorio de socios que usa la conexión recibida.
+func NewSQLSocioRepository(db *sql.DB) *SQLSocioRepository {
+	return &SQLSocioRepository{db: db}
+}
+
+// GetAllSocios consulta la base de datos y devuelve todos los socios ordenados por nombre.
+func (repo *SQLSocioRepository) GetAllSocios() ([]Socio, error) {
+	rows, err := repo.db.Query("SELECT Id, Nombre, Email, Telefono, Direccion, Estado, FechaAlta FROM socios ORDER BY Nombre, Id")
+	if err != nil {
+		log.Printf("Error al ejecutar la consulta en GetAllSocios: %v", err)
+		return nil, fmt.Errorf("error al ejecutar la consulta: %w", err)
+	}
+	defer rows.Close()
+
+	var socios []Socio
+	for rows.Next() {
+		var socio Socio
+		err := rows.Scan(&socio.Id, &socio.Nombre, &socio.Email, &socio.Telefono, &socio.Direccion, &socio.Estado, &socio.FechaAlta)
+		if err != nil {
+			log.Printf("Error al escanear los resultados en GetAllSocios: %v", err)
+			return nil, fmt.Errorf("error al escanear los resultados: %w", err)
+		}
+		socios = append(socios, socio)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("Error al procesar los resultados de la base de datos en GetAllSocios: %v", err)
+		return nil, fmt.Errorf("error al procesar los resultados: %w", err)
+	}
+	return socios, nil
+}
+
+// CreateSocio inserta un nuevo socio con la fecha de alta actual.
+func (repo *SQLSocioRepository) CreateSocio(socio Socio) (Socio, error) {
+	socio.FechaAlta = time.Now().Truncate(time.Second)
+	resultado, err := repo.db.Exec("INSERT INTO socios (Nombre, Email, Telefono, Direccion, Estado, FechaAlta) VALUES (?, ?, ?, ?, ?, ?)",
+		socio.Nombre, socio.Email, socio.Telefono, socio.Direccion, socio.Estado, socio.FechaAlta)
+	if err != nil {
+		log.Printf("Error al ejecutar la inserción del socio: %v", err)
+		return Socio{}, fmt.Errorf("error al insertar el socio: %w", err)
+	}
+
+	lastInsertId, err := resultado.LastInsertId()
+	if err != nil {
+		log.Printf("Error al obtener el ID del último socio insertado en CreateSocio: %v", err)
+		return Socio{}, fmt.Errorf("error al obtener el ID del último socio insertado: %w", err)
+	}
+	socio.Id = int(lastInsertId)
+	log.Printf("Socio insertado con éxito. ID: %d", lastInsertId)
+	return socio, nil
+}
+
+// GetSocioByID consulta la base de datos y devuelve un socio específico por su ID.
+func (repo *SQLSocioRepository) GetSocioByID(Id int) (Socio, error) {
+	var socio Socio
+	err := repo.db.QueryRow("SELECT Id, Nombre, Email, Telefono, Direccion, Estado, FechaAlta FROM socios WHERE Id = ?", Id).
+		Scan(&socio.Id, &socio.Nombre, &socio.Email, &socio.Telefono, &socio.Direccion, &socio.Estado, &socio.FechaAlta)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return socio, noEncontrado("socio con ID %d no encontrado", Id)
+		}
+		log.Printf("Error al escanear el socio con ID %d: %v", Id, err)
+		return socio, fmt.Errorf("error al obtener el socio: %w", err)
+	}
+	return socio, nil
+}
+
+// UpdateSocio actualiza un socio existente. La fecha de alta no se modifica.
+func (repo *SQLSocioRepository) UpdateSocio(socio Socio) error {
+	_, err := repo.db.Exec("UPDATE socios SET Nombre = ?, Email = ?, Telefono = ?, Direccion = ?, Estado = ? WHERE Id = ?",
+		socio.Nombre, socio.Email, socio.Telefono, socio.Direccion, socio.Estado, socio.Id)
+	if err != nil {
+		log.Printf("Error al ejecutar la actualización del socio con ID %d: %v", socio.Id, err)
+		return fmt.Errorf("error al actualizar el socio: %w", err)
+	}
+	log.Printf("Socio con ID %d actualizado con éxito.", socio.Id)
+	return nil
+}
+
+// DeleteSocio elimina un socio por su ID si no tiene préstamos registrados.
+func (repo *SQLSocioRepository) DeleteSocio(Id int) error {
+	// La condición NOT EXISTS evita borrar el historial de préstamos del socio.
+	resultado, err := repo.db.Exec("DELETE FROM socios WHERE Id = ? AND NOT EXISTS (SELECT 1 FROM prestamos WHERE SocioId = ?)", Id, Id)
+	if err != nil {
+		log.Printf("Error al ejecutar la eliminación del socio con ID %d: %v", Id, err)
+		return fmt.Errorf("error al eliminar el socio: %w", err)
+	}
+
+	filasAfectadas, err := resultado.RowsAffected()
+	if err != nil {
+		log.Printf("Error al obtener las filas afectadas en DeleteSocio: %v", err)
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	if filasAfectadas == 0 {
+		// Distingue entre un socio inexistente y uno con historial de préstamos.
+		if _, err := repo.GetSocioByID(Id); err != nil {
+			return noEncontrado("no se encontró ningún socio con ID %d para eliminar", Id)
+		}
+		return ErrSocioConPrestamos
+	}
+	log.Printf("Socio con ID %d eliminado con éxito.", Id)
+	return nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import "testing"
+
+// probarSocioRepository verifica el contrato común de SocioRepository sobre un repositorio vacío.
+func probarSocioRepository(t *testing.T, repo SocioRepository) {
+	t.Helper()
+	// CreateSocio devuelve el socio con el ID y la fecha de alta que se le asignaron.
+	if creado, err := repo.CreateSocio(Socio{Nombre: "Luis Gómez", Email: "luis@example.com", Estado: EstadoSocioActivo}); err != nil || creado.Id != 1 || creado.FechaAlta.IsZero() {
+		t.Fatalf("CreateSocio = %+v, %v", creado, err)
+	}
+	repo.CreateSocio(Socio{Nombre: "Ana Torres", Telefono: "555-1234", Estado: EstadoSocioActivo})
+
+	socios, err := repo.GetAllSocios()
+	if err != nil || len(socios) != 2 || socios[0].Nombre != "Ana Torres" {
+		t.Fatalf("GetAllSocios = %+v, %v", socios, err)
+	}
+
+	socio, err := repo.GetSocioByID(1)
+	if err != nil || socio.Email != "luis@example.com" || socio.FechaAlta.IsZero() {
+		t.Fatalf("GetSocioByID(1) = %+v, %v", socio, err)
+	}
+	alta := socio.FechaAlta
+
+	// La actualización cambia los datos de contacto y el estado, pero no la fecha de alta.
+	socio.Estado = EstadoSocioSuspendido
+	socio.Direccion = "Calle 1"
+	socio.FechaAlta = alta.AddDate(-1, 0, 0)
+	if err := repo.UpdateSocio(socio); err != nil {
+		t.Fatalf("UpdateSocio: %v", err)
+	}
+	if socio, _ := repo.GetSocioByID(1); socio.Estado != EstadoSocioSuspendido || socio.Direccion != "Calle 1" || !socio.FechaAlta.Equal(alta) {
+		t.Errorf("después de UpdateSocio = %+v", socio)
+	}
+
+	if err := repo.DeleteSocio(2); err != nil {
+		t.Fatalf("DeleteSocio: %v", err)
+	}
+	if _, err := repo.GetSocioByID(2); err == nil {
+		t.Error("el socio eliminado sigue existiendo")
+	}
+	if err := repo.DeleteSocio(99); err == nil {
+		t.Error("DeleteSocio de un socio inexistente no devolvió error")
+	}
+}
+
+func TestMemorySocioRepository(t *testing.T) {
+	probarSocioRepository(t, NewMemorySocioRepository(NewMemoriaDB()))
+}
+
+func TestSQLSocioRepositorySQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	probarSocioRepository(t, NewSQLSocioRepository(conexion))
+}
+
+func TestEstadoSocioValido(t *testing.T) {
+	for _, estado := range EstadosSocio {
+		if !EstadoSocioValido(estado) {
+			t.Errorf("EstadoSocioValido(%q) = false", estado)
+		}
+	}
+	if EstadoSocioValido("activo") || EstadoSocioValido("") {
+		t.Error("EstadoSocioValido aceptó un estado inválido")
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"proyecto/db"
+	"proyecto/db/migraciones"
+)
+
+// nuevaDBPrueba abre una base SQLite vacía en un directorio temporal de la prueba, con todas las migraciones
+// aplicadas. La conexión se cierra al terminar la prueba.
+func nuevaDBPrueba(t *testing.T) *sql.DB {
+	t.Helper()
+	conexion, err := db.OpenSQLite(filepath.Join(t.TempDir(), "biblioteca.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { conexion.Close() })
+	if _, err := migraciones.Up(conexion, db.DriverSQLite); err != nil {
+		t.Fatalf("migraciones.Up: %v", err)
+	}
+	return conexion
+}
@@ -0,0 +1,238 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo con las sugerencias mientras se escribe: un trie de los títulos, autores y editoriales de los libros.
+*/
+
+package models
+
+import (
+	"sort"    // Paquete para ordenar las sugerencias.
+	"strings" // Paquete para normalizar los textos.
+	"sync"    // Paquete para proteger el trie del acceso concurrente.
+)
+
+// Cantidad de sugerencias de una consulta.
+const (
+	LimiteSugerenciasPorDefecto = 10 // Sugerencias si la consulta no indica un límite.
+	LimiteSugerenciasMaximo     = 50 // Máximo de sugerencias que se puede pedir.
+)
+
+// ParametroCampo es el parámetro de la URL que restringe las sugerencias a un campo (GET /api/libros/suggest?campo=Autor).
+const ParametroCampo = "campo"
+
+// CamposSugerencias son los campos de Libro que se sugieren, en el orden en que se muestran a igual coincidencia.
+var CamposSugerencias = []string{CampoTitulo, CampoAutor, CampoEditorial}
+
+// Sugerencia es un título, autor o editorial de la colección que coincide con lo que se está escribiendo.
+type Sugerencia struct {
+	Texto  string // Valor tal como está guardado en el libro, por ejemplo "Gabriel García Márquez".
+	Campo  string // Campo del valor: CampoTitulo, CampoAutor o CampoEditorial.
+	Libros int    // Cantidad de libros que tienen ese valor en el campo.
+}
+
+// claveSugerencia identifica un valor sugerido: el campo y el texto normalizado, así que "García" y "Garcia"
+// son la misma sugerencia.
+type claveSugerencia struct {
+	campo string
+	texto string
+}
+
+// valorSugerencia es un valor sugerido junto con la cantidad de libros que lo tienen.
+type valorSugerencia struct {
+	texto  string // Texto del primer libro indexado con este valor, que es el que se muestra.
+	libros int
+}
+
+// nodoSugerencias es un nodo del trie. Cada valor se guarda una vez por cada palabra, al final del camino
+// que empieza en esa palabra, así que el subárbol de un prefijo contiene los valores con alguna palabra que
+// empieza con él ("marq" llega a "Gabriel García Márquez").
+type nodoSugerencias struct {
+	hijos   map[byte]*nodoSugerencias
+	valores map[claveSugerencia]bool // Valores cuyo camino termina en este nodo.
+}
+
+// IndiceSugerencias es el trie de los títulos, autores y editoriales de los libros, sin distinguir mayúsculas
+// ni tildes. Los repositorios lo mantienen al día al crear, editar y eliminar libros, así que las sugerencias
+// no consultan la base de datos. Es seguro para uso concurrente.
+type IndiceSugerencias struct {
+	mu      sync.RWMutex
+	raiz    *nodoSugerencias
+	valores map[claveSugerencia]*valorSugerencia // Valores del trie con su cantidad de libros.
+	libros  map[int][]claveSugerencia            // Valores de cada libro, por su ID, para quitarlos al editarlo o eliminarlo.
+}
+
+// NewIndiceSugerencias crea un trie vacío.
+func NewIndiceSugerencias() *IndiceSugerencias {
+	return &IndiceSugerencias{
+		raiz:    &nodoSugerencias{},
+		valores: make(map[claveSugerencia]*valorSugerencia),
+		libros:  make(map[int][]claveSugerencia),
+	}
+}
+
+// Indexar agrega el título, el autor y la editorial del libro, o reemplaza los que tenía si ya estaba.
+func (idx *IndiceSugerencias) Indexar(libro Libro) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.quitar(libro.Id)
+	var claves []claveSugerencia
+	for i, texto := range []string{libro.Titulo, libro.Autor, libro.Editorial} {
+		clave := claveSugerencia{campo: CamposSugerencias[i], texto: strings.Join(palabrasBusqueda(texto), " ")}
+		if clave.texto == "" {
+			continue
+		}
+		claves = append(claves, clave)
+		if valor, ok := idx.valores[clave]; ok {
+			valor.libros++
+			continue
+		}
+		idx.valores[clave] = &valorSugerencia{texto: strings.TrimSpace(texto), libros: 1}
+		for _, sufijo := range sufijosPalabras(clave.texto) {
+			nodo := idx.raiz
+			for j := 0; j < len(sufijo); j++ {
+				if nodo.hijos == nil {
+					nodo.hijos = make(map[byte]*nodoSugerencias)
+				}
+				if nodo.hijos[sufijo[j]] == nil {
+					nodo.hijos[sufijo[j]] = &nodoSugerencias{}
+				}
+				nodo = nodo.hijos[sufijo[j]]
+			}
+			if nodo.valores == nil {
+				nodo.valores = make(map[claveSugerencia]bool)
+			}
+			nodo.valores[clave] = true
+		}
+	}
+	idx.libros[libro.Id] = claves
+}
+
+// Quitar elimina los valores del libro. No hace nada si el libro no estaba indexado.
+func (idx *IndiceSugerencias) Quitar(Id int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.quitar(Id)
+}
+
+// quitar descuenta los valores del libro y saca del trie los que ya no tiene ningún libro.
+// Debe llamarse con el mutex tomado.
+func (idx *IndiceSugerencias) quitar(Id int) {
+	for _, clave := range idx.libros[Id] {
+		valor := idx.valores[clave]
+		if valor.libros--; valor.libros > 0 {
+			continue
+		}
+		delete(idx.valores, clave)
+		for _, sufijo := range sufijosPalabras(clave.texto) {
+			quitarSufijo(idx.raiz, sufijo, clave)
+		}
+	}
+	delete(idx.libros, Id)
+}
+
+// quitarSufijo quita el valor del final del camino del sufijo y poda los nodos que quedan vacíos.
+// Devuelve true si el nodo quedó sin hijos ni valores.
+func quitarSufijo(nodo *nodoSugerencias, sufijo string, clave claveSugerencia) bool {
+	if sufijo == "" {
+		delete(nodo.valores, clave)
+	} else if hijo := nodo.hijos[sufijo[0]]; hijo != nil && quitarSufijo(hijo, sufijo[1:], clave) {
+		delete(nodo.hijos, sufijo[0])
+	}
+	return len(nodo.hijos) == 0 && len(nodo.valores) == 0
+}
+
+// Sugerir devuelve hasta limite valores del campo (o de todos los campos, si campo está vacío) con alguna
+// palabra que empieza con el texto escrito, sin distinguir mayúsculas ni tildes. Primero van los valores que
+// empiezan con el texto, después los que tienen más libros y, a igualdad, en orden alfabético.
+func (idx *IndiceSugerencias) Sugerir(texto, campo string, limite int) []Sugerencia {
+	prefijo := strings.Join(palabrasBusqueda(texto), " ")
+	if prefijo == "" {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	nodo := idx.raiz
+	for i := 0; i < len(prefijo) && nodo != nil; i++ {
+		nodo = nodo.hijos[prefijo[i]]
+	}
+	if nodo == nil {
+		return nil
+	}
+
+	// Recorre el subárbol; un valor puede aparecer varias veces si varias de sus palabras empiezan con el prefijo.
+	encontradas := make(map[claveSugerencia]bool)
+	pendientes := []*nodoSugerencias{nodo}
+	for len(pendientes) > 0 {
+		nodo, pendientes = pendientes[len(pendientes)-1], pendientes[:len(pendientes)-1]
+		for clave := range nodo.valores {
+			if campo == "" || clave.campo == campo {
+				encontradas[clave] = true
+			}
+		}
+		for _, hijo := range nodo.hijos {
+			pendientes = append(pendientes, hijo)
+		}
+	}
+
+	claves := make([]claveSugerencia, 0, len(encontradas))
+	for clave := range encontradas {
+		claves = append(claves, clave)
+	}
+	sort.Slice(claves, func(i, j int) bool {
+		a, b := claves[i], claves[j]
+		if inicioA, inicioB := strings.HasPrefix(a.texto, prefijo), strings.HasPrefix(b.texto, prefijo); inicioA != inicioB {
+			return inicioA
+		}
+		if librosA, librosB := idx.valores[a].libros, idx.valores[b].libros; librosA != librosB {
+			return librosA > librosB
+		}
+		if a.texto != b.texto {
+			return a.texto < b.texto
+		}
+		return posicionCampo(a.campo) < posicionCampo(b.campo)
+	})
+	if len(claves) > limite {
+		claves = claves[:limite]
+	}
+
+	sugerencias := make([]Sugerencia, len(claves))
+	for i, clave := range claves {
+		valor := idx.valores[clave]
+		sugerencias[i] = Sugerencia{Texto: valor.texto, Campo: clave.campo, Libros: valor.libros}
+	}
+	return sugerencias
+}
+
+// sufijosPalabras devuelve las partes del texto normalizado que empiezan en cada una de sus palabras:
+// "gabriel garcia marquez" da "gabriel garcia marquez", "garcia marquez" y "marquez".
+func sufijosPalabras(texto string) []string {
+	sufijos := []string{texto}
+	for i := 0; i < len(texto); i++ {
+		if texto[i] == ' ' {
+			sufijos = append(sufijos, texto[i+1:])
+		}
+	}
+	return sufijos
+}
+
+// posicionCampo devuelve la posición del campo en CamposSugerencias.
+func posicionCampo(campo string) int {
+	for i, valido := range CamposSugerencias {
+		if campo == valido {
+			return i
+		}
+	}
+	return len(CamposSugerencias)
+}
+
+// limiteSugerencias devuelve el límite de sugerencias que se usa: LimiteSugerenciasPorDefecto si es 0 y nunca
+// más que LimiteSugerenciasMaximo.
+func limiteSugerencias(limite int) int {
+	if limite <= 0 {
+		return LimiteSugerenciasPorDefecto
+	}
+	return min(limite, LimiteSugerenciasMaximo)
+}
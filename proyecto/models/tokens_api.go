@@ -0,0 +1,105 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que emite y verifica los tokens bearer firmados con que los clientes de la API se autentican.
+*/
+
+package models
+
+import (
+	"crypto/hmac"     // Paquete para firmar los tokens y comparar firmas en tiempo constante.
+	"crypto/sha256"   // Paquete con la función de hash de la firma HMAC-SHA256.
+	"encoding/base64" // Paquete para codificar las partes del token.
+	"strconv"         // Paquete para guardar el ID de la clave en el token.
+	"strings"         // Paquete para separar las partes del token.
+	"time"            // Paquete para el vencimiento de los tokens.
+
+	"github.com/goccy/go-json" // Paquete para codificar JSON de forma eficiente.
+)
+
+// DuracionTokenAPI es el tiempo que un token firmado sigue válido desde que se emite.
+const DuracionTokenAPI = time.Hour
+
+// TokenAPI es el contenido de un token firmado: la clave que lo pidió, sus alcances y su vencimiento.
+type TokenAPI struct {
+	ClaveId  int       // Clave de API con que se emitió; si se revoca, el token deja de valer.
+	Alcances []Alcance // Alcances del token, los de la clave o una parte de ellos.
+	Expira   time.Time // Momento a partir del cual el token deja de ser válido.
+}
+
+// reclamosToken son los campos del token en formato JWT (RFC 7519).
+type reclamosToken struct {
+	Sub   string `json:"sub"`   // ID de la clave de API.
+	Scope string `json:"scope"` // Alcances separados por espacios, como en OAuth 2.0.
+	Iat   int64  `json:"iat"`   // Momento de emisión, en segundos Unix.
+	Exp   int64  `json:"exp"`   // Momento de vencimiento, en segundos Unix.
+}
+
+// cabeceraToken es la cabecera JWT de todos los tokens. Se compara tal cual al verificar, así que
+// un token con otro algoritmo (por ejemplo "none") se rechaza sin interpretar su cabecera.
+var cabeceraToken = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// FirmadorTokens emite y verifica tokens JWT firmados con HMAC-SHA256.
+// Los tokens no se guardan: cualquiera con el mismo secreto puede verificarlos.
+type FirmadorTokens struct {
+	secreto  []byte        // Clave de la firma HMAC; quien la conozca puede emitir tokens.
+	duracion time.Duration // Tiempo de validez de los tokens que emite.
+}
+
+// NewFirmadorTokens crea un firmador con el secreto y la duración de los tokens recibidos.
+func NewFirmadorTokens(secreto []byte, duracion time.Duration) *FirmadorTokens {
+	return &FirmadorTokens{secreto: secreto, duracion: duracion}
+}
+
+// Emitir firma un token para la clave y los alcances recibidos, que vence después de la duración del firmador.
+func (f *FirmadorTokens) Emitir(ClaveId int, alcances []Alcance) (string, TokenAPI, error) {
+	ahora := time.Now().Truncate(time.Second)
+	token := TokenAPI{ClaveId: ClaveId, Alcances: alcances, Expira: ahora.Add(f.duracion)}
+	reclamos, err := json.Marshal(reclamosToken{
+		Sub:   strconv.Itoa(ClaveId),
+		Scope: unirAlcances(alcances),
+		Iat:   ahora.Unix(),
+		Exp:   token.Expira.Unix(),
+	})
+	if err != nil {
+		return "", TokenAPI{}, err
+	}
+	contenido := cabeceraToken + "." + base64.RawURLEncoding.EncodeToString(reclamos)
+	return contenido + "." + f.firmar(contenido), token, nil
+}
+
+// Verificar comprueba la firma y el vencimiento del token y devuelve su contenido, o ErrCredencialInvalida.
+// No consulta si la clave sigue activa; eso le corresponde a quien usa el token.
+func (f *FirmadorTokens) Verificar(texto string) (TokenAPI, error) {
+	partes := strings.Split(texto, ".")
+	if len(partes) != 3 || partes[0] != cabeceraToken {
+		return TokenAPI{}, ErrCredencialInvalida
+	}
+	if !hmac.Equal([]byte(partes[2]), []byte(f.firmar(partes[0]+"."+partes[1]))) {
+		return TokenAPI{}, ErrCredencialInvalida
+	}
+
+	datos, err := base64.RawURLEncoding.DecodeString(partes[1])
+	if err != nil {
+		return TokenAPI{}, ErrCredencialInvalida
+	}
+	var reclamos reclamosToken
+	if err := json.Unmarshal(datos, &reclamos); err != nil {
+		return TokenAPI{}, ErrCredencialInvalida
+	}
+	ClaveId, err := strconv.Atoi(reclamos.Sub)
+	if err != nil {
+		return TokenAPI{}, ErrCredencialInvalida
+	}
+	token := TokenAPI{ClaveId: ClaveId, Alcances: separarAlcances(reclamos.Scope), Expira: time.Unix(reclamos.Exp, 0)}
+	if !time.Now().Before(token.Expira) {
+		return TokenAPI{}, ErrCredencialInvalida
+	}
+	return token, nil
+}
+
+// firmar calcula la firma HMAC-SHA256 del contenido, codificada para el token.
+func (f *FirmadorTokens) firmar(contenido string) string {
+	mac := hmac.New(sha256.New, f.secreto)
+	mac.Write([]byte(contenido))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,191 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define las cuentas de usuario, sus roles y permisos, sus sesiones y los repositorios que las guardan.
+*/
+
+package models
+
+import (
+	"crypto/rand"     // Paquete para generar tokens de sesión impredecibles.
+	"crypto/sha256"   // Paquete para guardar solo el hash de los tokens.
+	"encoding/base64" // Paquete para representar el token como texto.
+	"encoding/hex"    // Paquete para representar el hash del token como texto.
+	"errors"          // Paquete para definir errores que los manejadores pueden reconocer.
+	"strings"         // Paquete para revisar el nombre de usuario.
+	"time"            // Paquete para la fecha de alta y el vencimiento de las sesiones.
+
+	"golang.org/x/crypto/bcrypt" // Hash de contraseñas resistente a ataques de fuerza bruta.
+)
+
+const (
+	// DuracionSesion es el tiempo que una sesión sigue válida desde que se inicia.
+	DuracionSesion = 12 * time.Hour
+	// LongitudMinimaContrasena es la cantidad mínima de caracteres de una contraseña nueva.
+	LongitudMinimaContrasena = 8
+	// LongitudMaximaUsuario es la cantidad máxima de caracteres del nombre de usuario, como la columna de MySQL.
+	LongitudMaximaUsuario = 50
+	// LongitudMaximaNombreUsuario es la cantidad máxima de caracteres del nombre completo.
+	LongitudMaximaNombreUsuario = 255
+)
+
+// Nombres de los campos del usuario, tal como se envían en el formulario.
+const (
+	CampoUsuario       = "Usuario"
+	CampoNombreUsuario = "Nombre"
+	CampoRol           = "Rol"
+	CampoContrasena    = "Contrasena"
+)
+
+// Roles de los usuarios. Cada rol tiene los permisos de PermisosPorRol.
+const (
+	RolAdmin         = "admin"         // Acceso completo: elimina registros y administra los usuarios.
+	RolBibliotecario = "bibliotecario" // Presta y devuelve libros, y crea o edita libros, ejemplares y socios.
+	RolLector        = "lector"        // Solo puede consultar los listados y los detalles.
+)
+
+// Roles enumera los roles válidos, en el orden en que se muestran en los formularios.
+var Roles = []string{RolAdmin, RolBibliotecario, RolLector}
+
+// RolValido indica si el rol recibido es uno de Roles.
+func RolValido(rol string) bool {
+	for _, r := range Roles {
+		if r == rol {
+			return true
+		}
+	}
+	return false
+}
+
+// Permiso es una acción de la aplicación que solo pueden hacer algunos roles.
+type Permiso string
+
+// Permisos que se verifican en las rutas y en el menú de la barra lateral.
+const (
+	PermisoVer                 Permiso = "ver"                  // Consultar listados, detalles y la API de lectura.
+	PermisoPrestar             Permiso = "prestar"              // Registrar préstamos, devoluciones y reservas.
+	PermisoEditar              Permiso = "editar"               // Crear y editar libros, ejemplares y socios.
+	PermisoEliminar            Permiso = "eliminar"             // Eliminar libros, ejemplares y socios.
+	PermisoAdministrarUsuarios Permiso = "administrar-usuarios" // Crear usuarios, cambiar su rol y eliminarlos.
+	PermisoAdministrarClaves   Permiso = "administrar-claves"   // Crear y revocar las claves de API.
+)
+
+// PermisosPorRol es la matriz de permisos: lo que puede hacer cada rol.
+var PermisosPorRol = map[string][]Permiso{
+	RolAdmin:         {PermisoVer, PermisoPrestar, PermisoEditar, PermisoEliminar, PermisoAdministrarUsuarios, PermisoAdministrarClaves},
+	RolBibliotecario: {PermisoVer, PermisoPrestar, PermisoEditar},
+	RolLector:        {PermisoVer},
+}
+
+// Errores que pueden devolver las operaciones sobre usuarios y sesiones.
+var (
+	ErrCredencialesInvalidas = errors.New("usuario o contraseña incorrectos")
+	ErrUsuarioDuplicado      = nuevoError(ErrConflict, "ya existe un usuario con ese nombre")
+	ErrContrasenaCorta       = nuevoError(ErrValidation, "la contraseña debe tener al menos 8 caracteres")
+	ErrSesionInvalida        = errors.New("la sesión no existe o ya expiró")
+	ErrRolInvalido           = nuevoError(ErrValidation, "el rol debe ser admin, bibliotecario o lector")
+)
+
+// Usuario representa una cuenta con la que un bibliotecario inicia sesión en la interfaz web.
+type Usuario struct {
+	Id             int       // ID único del usuario (clave primaria).
+	Usuario        string    // Nombre con el que inicia sesión, único.
+	Nombre         string    // Nombre completo que se muestra en la barra lateral.
+	Rol            string    // Rol del usuario (ver Roles), define sus permisos.
+	HashContrasena string    `json:"-"` // Hash bcrypt de la contraseña; nunca se guarda ni se envía la contraseña.
+	FechaAlta      time.Time // Fecha de creación, la asigna el repositorio.
+}
+
+// Validar revisa los datos de un usuario nuevo y devuelve un ErroresValidacion con un mensaje por campo inválido.
+// La contraseña se valida aparte, porque el usuario solo guarda su hash.
+func (u Usuario) Validar() error {
+	errores := ErroresValidacion{}
+	validarTexto(errores, CampoUsuario, u.Usuario, LongitudMaximaUsuario)
+	if strings.ContainsAny(u.Usuario, " \t") {
+		errores.Agregar(CampoUsuario, "No puede tener espacios")
+	}
+	validarTexto(errores, CampoNombreUsuario, u.Nombre, LongitudMaximaNombreUsuario)
+	if !RolValido(u.Rol) {
+		errores.Agregar(CampoRol, "Debe ser admin, bibliotecario o lector")
+	}
+	return errores.Err()
+}
+
+// Puede indica si el rol del usuario tiene el permiso recibido.
+func (u Usuario) Puede(permiso Permiso) bool {
+	for _, p := range PermisosPorRol[u.Rol] {
+		if p == permiso {
+			return true
+		}
+	}
+	return false
+}
+
+// hashContrasena calcula el hash bcrypt de una contraseña nueva, que debe tener LongitudMinimaContrasena caracteres.
+func hashContrasena(contrasena string) (string, error) {
+	if len([]rune(contrasena)) < LongitudMinimaContrasena {
+		return "", ErrContrasenaCorta
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(contrasena), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// verificarContrasena indica si la contraseña corresponde al hash guardado del usuario.
+func (u Usuario) verificarContrasena(contrasena string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.HashContrasena), []byte(contrasena)) == nil
+}
+
+// hashFalso se compara cuando el usuario no existe, para que la respuesta tarde lo mismo que con
+// una contraseña incorrecta y no revele qué nombres de usuario están registrados.
+var hashFalso, _ = bcrypt.GenerateFromPassword([]byte("contraseña-inexistente"), bcrypt.DefaultCost)
+
+// nuevoTokenSesion genera el token aleatorio que recibe el navegador y el hash con que se guarda la sesión.
+func nuevoTokenSesion() (token string, hash string, err error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(bytes)
+	return token, hashToken(token), nil
+}
+
+// hashToken calcula el hash SHA-256 del token de una sesión, que es su clave en el almacenamiento.
+func hashToken(token string) string {
+	suma := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(suma[:])
+}
+
+// UsuarioRepository define las operaciones de persistencia disponibles para la entidad Usuario.
+type UsuarioRepository interface {
+	// GetAllUsuarios devuelve una lista de todos los usuarios ordenados por nombre de usuario.
+	GetAllUsuarios() ([]Usuario, error)
+	// CrearUsuario guarda un usuario nuevo con el hash de la contraseña recibida y lo devuelve con su ID.
+	// Devuelve ErrUsuarioDuplicado si el nombre de usuario ya existe, ErrContrasenaCorta si la contraseña es corta
+	// y ErrRolInvalido si el rol no es uno de Roles.
+	CrearUsuario(usuario Usuario, contrasena string) (Usuario, error)
+	// GetUsuarioByID devuelve un usuario específico por su ID.
+	GetUsuarioByID(Id int) (Usuario, error)
+	// ActualizarRol cambia el rol de un usuario existente. Devuelve ErrRolInvalido si el rol no es uno de Roles.
+	ActualizarRol(Id int, rol string) error
+	// EliminarUsuario elimina un usuario junto con sus sesiones.
+	EliminarUsuario(Id int) error
+	// Autenticar devuelve el usuario si la contraseña es correcta, o ErrCredencialesInvalidas.
+	Autenticar(usuario string, contrasena string) (Usuario, error)
+	// ContarUsuarios devuelve la cantidad de usuarios registrados.
+	ContarUsuarios() (int, error)
+}
+
+// SesionRepository define las operaciones sobre las sesiones iniciadas en la interfaz web.
+// Los tokens no se guardan: el almacenamiento solo conoce su hash.
+type SesionRepository interface {
+	// CrearSesion inicia una sesión de DuracionSesion para el usuario y devuelve el token para la cookie.
+	CrearSesion(UsuarioId int) (string, error)
+	// UsuarioDeSesion devuelve el usuario dueño de una sesión vigente, o ErrSesionInvalida.
+	UsuarioDeSesion(token string) (Usuario, error)
+	// EliminarSesion cierra la sesión del token. No es un error si la sesión ya no existe.
+	EliminarSesion(token string) error
+	// EliminarSesionesVencidas borra las sesiones expiradas y devuelve cuántas eran.
+	EliminarSesionesVencidas() (int, error)
+}
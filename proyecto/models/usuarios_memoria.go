@@ -0,0 +1,211 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación en memoria de los repositorios de usuarios y sesiones, usada en las pruebas y en el modo demo.
+*/
+
+package models
+
+import (
+	"fmt"     // Paquete para formatear cadenas.
+	"sort"    // Paquete para ordenar los usuarios por nombre de usuario.
+	"strings" // Paquete para comparar nombres de usuario sin distinguir mayúsculas.
+	"time"    // Paquete para la fecha de alta y el vencimiento de las sesiones.
+)
+
+// MemoryUsuarioRepository implementa UsuarioRepository en memoria.
+type MemoryUsuarioRepository struct {
+	db *MemoriaDB // Almacenamiento en memoria compartido con los demás repositorios.
+}
+
+// NewMemoryUsuarioRepository crea un repositorio de usuarios sobre el almacenamiento en memoria recibido.
+func NewMemoryUsuarioRepository(db *MemoriaDB) *MemoryUsuarioRepository {
+	return &MemoryUsuarioRepository{db: db}
+}
+
+// GetAllUsuarios devuelve todos los usuarios ordenados por nombre de usuario.
+func (repo *MemoryUsuarioRepository) GetAllUsuarios() ([]Usuario, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	var usuarios []Usuario
+	for _, usuario := range repo.db.usuarios {
+		usuarios = append(usuarios, usuario)
+	}
+	sort.Slice(usuarios, func(i, j int) bool { return usuarios[i].Usuario < usuarios[j].Usuario })
+	return usuarios, nil
+}
+
+// CrearUsuario agrega un usuario con el hash de su contraseña, el siguiente ID y la fecha de alta actual.
+func (repo *MemoryUsuarioRepository) CrearUsuario(usuario Usuario, contrasena string) (Usuario, error) {
+	if !RolValido(usuario.Rol) {
+		return Usuario{}, ErrRolInvalido
+	}
+	hash, err := hashContrasena(contrasena)
+	if err != nil {
+		return Usuario{}, err
+	}
+
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	// Emula la restricción UNIQUE de la columna Usuario, que en MySQL no distingue mayúsculas.
+	if _, ok := repo.db.buscarUsuario(usuario.Usuario); ok {
+		return Usuario{}, ErrUsuarioDuplicado
+	}
+	repo.db.nextUsuarioId++
+	usuario.Id = repo.db.nextUsuarioId
+	usuario.HashContrasena = hash
+	usuario.FechaAlta = time.Now().Truncate(time.Second)
+	repo.db.usuarios[usuario.Id] = usuario
+	return usuario, nil
+}
+
+// buscarUsuario devuelve el usuario con el nombre de inicio de sesión recibido.
+// Debe llamarse con el mutex tomado.
+func (db *MemoriaDB) buscarUsuario(nombre string) (Usuario, bool) {
+	for _, usuario := range db.usuarios {
+		if strings.EqualFold(usuario.Usuario, nombre) {
+			return usuario, true
+		}
+	}
+	return Usuario{}, false
+}
+
+// GetUsuarioByID devuelve un usuario específico por su ID.
+func (repo *MemoryUsuarioRepository) GetUsuarioByID(Id int) (Usuario, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	usuario, ok := repo.db.usuarios[Id]
+	if !ok {
+		return usuario, noEncontrado("usuario con ID %d no encontrado", Id)
+	}
+	return usuario, nil
+}
+
+// ActualizarRol cambia el rol de un usuario existente.
+func (repo *MemoryUsuarioRepository) ActualizarRol(Id int, rol string) error {
+	if !RolValido(rol) {
+		return ErrRolInvalido
+	}
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	usuario, ok := repo.db.usuarios[Id]
+	if !ok {
+		return noEncontrado("no se encontró ningún usuario con ID %d", Id)
+	}
+	usuario.Rol = rol
+	repo.db.usuarios[Id] = usuario
+	return nil
+}
+
+// EliminarUsuario elimina un usuario por su ID junto con sus sesiones.
+func (repo *MemoryUsuarioRepository) EliminarUsuario(Id int) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if _, ok := repo.db.usuarios[Id]; !ok {
+		return noEncontrado("no se encontró ningún usuario con ID %d para eliminar", Id)
+	}
+	delete(repo.db.usuarios, Id)
+
+	// Emula el ON DELETE CASCADE de la tabla sesiones.
+	for hash, sesion := range repo.db.sesiones {
+		if sesion.UsuarioId == Id {
+			delete(repo.db.sesiones, hash)
+		}
+	}
+	return nil
+}
+
+// Autenticar busca al usuario por su nombre y verifica la contraseña contra el hash guardado.
+func (repo *MemoryUsuarioRepository) Autenticar(nombre string, contrasena string) (Usuario, error) {
+	repo.db.mu.RLock()
+	usuario, ok := repo.db.buscarUsuario(nombre)
+	repo.db.mu.RUnlock()
+
+	if !ok {
+		usuario.HashContrasena = string(hashFalso)
+	}
+	// bcrypt es lento a propósito, por eso la verificación se hace sin el mutex tomado.
+	if !usuario.verificarContrasena(contrasena) || !ok {
+		return Usuario{}, ErrCredencialesInvalidas
+	}
+	return usuario, nil
+}
+
+// ContarUsuarios devuelve la cantidad de usuarios registrados.
+func (repo *MemoryUsuarioRepository) ContarUsuarios() (int, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+	return len(repo.db.usuarios), nil
+}
+
+// MemorySesionRepository implementa SesionRepository en memoria.
+type MemorySesionRepository struct {
+	db *MemoriaDB // Almacenamiento en memoria compartido con los demás repositorios.
+}
+
+// NewMemorySesionRepository crea un repositorio de sesiones sobre el almacenamiento en memoria recibido.
+func NewMemorySesionRepository(db *MemoriaDB) *MemorySesionRepository {
+	return &MemorySesionRepository{db: db}
+}
+
+// CrearSesion inicia una sesión para un usuario existente y devuelve su token.
+func (repo *MemorySesionRepository) CrearSesion(UsuarioId int) (string, error) {
+	token, hash, err := nuevoTokenSesion()
+	if err != nil {
+		return "", fmt.Errorf("error al generar el token de sesión: %w", err)
+	}
+
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	// Emula la clave foránea de la tabla sesiones.
+	if _, ok := repo.db.usuarios[UsuarioId]; !ok {
+		return "", noEncontrado("usuario con ID %d no encontrado", UsuarioId)
+	}
+	ahora := time.Now()
+	repo.db.sesiones[hash] = sesion{UsuarioId: UsuarioId, FechaCreacion: ahora, FechaExpiracion: ahora.Add(DuracionSesion)}
+	return token, nil
+}
+
+// UsuarioDeSesion devuelve el usuario de la sesión si existe y no expiró.
+func (repo *MemorySesionRepository) UsuarioDeSesion(token string) (Usuario, error) {
+	repo.db.mu.RLock()
+	defer repo.db.mu.RUnlock()
+
+	sesion, ok := repo.db.sesiones[hashToken(token)]
+	if !ok || !time.Now().Before(sesion.FechaExpiracion) {
+		return Usuario{}, ErrSesionInvalida
+	}
+	usuario, ok := repo.db.usuarios[sesion.UsuarioId]
+	if !ok {
+		return Usuario{}, ErrSesionInvalida
+	}
+	return usuario, nil
+}
+
+// EliminarSesion cierra la sesión del token.
+func (repo *MemorySesionRepository) EliminarSesion(token string) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+	delete(repo.db.sesiones, hashToken(token))
+	return nil
+}
+
+// EliminarSesionesVencidas borra las sesiones expiradas.
+func (repo *MemorySesionRepository) EliminarSesionesVencidas() (int, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	ahora, eliminadas := time.Now(), 0
+	for hash, sesion := range repo.db.sesiones {
+		if !ahora.Before(sesion.FechaExpiracion) {
+			delete(repo.db.sesiones, hash)
+			eliminadas++
+		}
+	}
+	return eliminadas, nil
+}
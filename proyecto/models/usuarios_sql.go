@@ -0,0 +1,230 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Implementación de los repositorios de usuarios y sesiones sobre una base de datos SQL (MySQL o SQLite).
+*/
+
+package models
+
+import (
+	"database/sql" // Paquete para interactuar con bases de datos SQL.
+	"fmt"          // Paquete para formatear cadenas.
+	"log"          // Paquete para logging de errores y mensajes.
+	"time"         // Paquete para la fecha de alta y el vencimiento de las sesiones.
+)
+
+// SQLUsuarioRepository implementa UsuarioRepository usando un pool de conexiones compartido.
+type SQLUsuarioRepository struct {
+	db *sql.DB // Pool de conexiones a la base de datos.
+}
+
+// NewSQLUsuarioRepository crea un repositorio de usuarios que usa la conexión recibida.
+func NewSQLUsuarioRepository(db *sql.DB) *SQLUsuarioRepository {
+	return &SQLUsuarioRepository{db: db}
+}
+
+// GetAllUsuarios consulta la base de datos y devuelve todos los usuarios ordenados por nombre de usuario.
+func (repo *SQLUsuarioRepository) GetAllUsuarios() ([]Usuario, error) {
+	rows, err := repo.db.Query("SELECT Id, Usuario, Nombre, Rol, HashContrasena, FechaAlta FROM usuarios ORDER BY Usuario")
+	if err != nil {
+		log.Printf("Error al ejecutar la consulta en GetAllUsuarios: %v", err)
+		return nil, fmt.Errorf("error al ejecutar la consulta: %w", err)
+	}
+	defer rows.Close()
+
+	var usuarios []Usuario
+	for rows.Next() {
+		var usuario Usuario
+		if err := rows.Scan(&usuario.Id, &usuario.Usuario, &usuario.Nombre, &usuario.Rol, &usuario.HashContrasena, &usuario.FechaAlta); err != nil {
+			log.Printf("Error al escanear los resultados en GetAllUsuarios: %v", err)
+			return nil, fmt.Errorf("error al escanear los resultados: %w", err)
+		}
+		usuarios = append(usuarios, usuario)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("Error al procesar los resultados de la base de datos en GetAllUsuarios: %v", err)
+		return nil, fmt.Errorf("error al procesar los resultados: %w", err)
+	}
+	return usuarios, nil
+}
+
+// CrearUsuario inserta un usuario con el hash de su contraseña y la fecha de alta actual.
+func (repo *SQLUsuarioRepository) CrearUsuario(usuario Usuario, contrasena string) (Usuario, error) {
+	if !RolValido(usuario.Rol) {
+		return Usuario{}, ErrRolInvalido
+	}
+	hash, err := hashContrasena(contrasena)
+	if err != nil {
+		return Usuario{}, err
+	}
+
+	// La restricción UNIQUE de la tabla sigue siendo la garantía final; esta consulta solo da un error claro.
+	var existe int
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM usuarios WHERE Usuario = ?", usuario.Usuario).Scan(&existe); err != nil {
+		return Usuario{}, fmt.Errorf("error al consultar el nombre de usuario: %w", err)
+	}
+	if existe > 0 {
+		return Usuario{}, ErrUsuarioDuplicado
+	}
+
+	usuario.HashContrasena = hash
+	usuario.FechaAlta = time.Now().Truncate(time.Second)
+	resultado, err := repo.db.Exec("INSERT INTO usuarios (Usuario, Nombre, Rol, HashContrasena, FechaAlta) VALUES (?, ?, ?, ?, ?)",
+		usuario.Usuario, usuario.Nombre, usuario.Rol, usuario.HashContrasena, usuario.FechaAlta)
+	if err != nil {
+		log.Printf("Error al ejecutar la inserción del usuario: %v", err)
+		return Usuario{}, fmt.Errorf("error al insertar el usuario: %w", err)
+	}
+	lastInsertId, err := resultado.LastInsertId()
+	if err != nil {
+		log.Printf("Error al obtener el ID del último usuario insertado en CrearUsuario: %v", err)
+		return Usuario{}, fmt.Errorf("error al obtener el ID del último usuario insertado: %w", err)
+	}
+	usuario.Id = int(lastInsertId)
+	log.Printf("Usuario insertado con éxito. ID: %d", lastInsertId)
+	return usuario, nil
+}
+
+// GetUsuarioByID consulta la base de datos y devuelve un usuario específico por su ID.
+func (repo *SQLUsuarioRepository) GetUsuarioByID(Id int) (Usuario, error) {
+	var usuario Usuario
+	err := repo.db.QueryRow("SELECT Id, Usuario, Nombre, Rol, HashContrasena, FechaAlta FROM usuarios WHERE Id = ?", Id).
+		Scan(&usuario.Id, &usuario.Usuario, &usuario.Nombre, &usuario.Rol, &usuario.HashContrasena, &usuario.FechaAlta)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return usuario, noEncontrado("usuario con ID %d no encontrado", Id)
+		}
+		log.Printf("Error al escanear el usuario con ID %d: %v", Id, err)
+		return usuario, fmt.Errorf("error al obtener el usuario: %w", err)
+	}
+	return usuario, nil
+}
+
+// ActualizarRol cambia el rol de un usuario existente.
+func (repo *SQLUsuarioRepository) ActualizarRol(Id int, rol string) error {
+	if !RolValido(rol) {
+		return ErrRolInvalido
+	}
+	// MySQL informa 0 filas afectadas también cuando el rol no cambia, así que se confirma antes que el usuario exista.
+	if _, err := repo.GetUsuarioByID(Id); err != nil {
+		return noEncontrado("no se encontró ningún usuario con ID %d", Id)
+	}
+	if _, err := repo.db.Exec("UPDATE usuarios SET Rol = ? WHERE Id = ?", rol, Id); err != nil {
+		log.Printf("Error al actualizar el rol del usuario con ID %d: %v", Id, err)
+		return fmt.Errorf("error al actualizar el rol: %w", err)
+	}
+	log.Printf("Rol del usuario con ID %d cambiado a %q.", Id, rol)
+	return nil
+}
+
+// EliminarUsuario elimina un usuario por su ID. Sus sesiones se borran por el ON DELETE CASCADE.
+func (repo *SQLUsuarioRepository) EliminarUsuario(Id int) error {
+	resultado, err := repo.db.Exec("DELETE FROM usuarios WHERE Id = ?", Id)
+	if err != nil {
+		log.Printf("Error al ejecutar la eliminación del usuario con ID %d: %v", Id, err)
+		return fmt.Errorf("error al eliminar el usuario: %w", err)
+	}
+	filasAfectadas, err := resultado.RowsAffected()
+	if err != nil {
+		log.Printf("Error al obtener las filas afectadas en EliminarUsuario: %v", err)
+		return fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	if filasAfectadas == 0 {
+		return noEncontrado("no se encontró ningún usuario con ID %d para eliminar", Id)
+	}
+	log.Printf("Usuario con ID %d eliminado con éxito.", Id)
+	return nil
+}
+
+// Autenticar busca al usuario por su nombre y verifica la contraseña contra el hash guardado.
+func (repo *SQLUsuarioRepository) Autenticar(nombre string, contrasena string) (Usuario, error) {
+	var usuario Usuario
+	err := repo.db.QueryRow("SELECT Id, Usuario, Nombre, Rol, HashContrasena, FechaAlta FROM usuarios WHERE Usuario = ?", nombre).
+		Scan(&usuario.Id, &usuario.Usuario, &usuario.Nombre, &usuario.Rol, &usuario.HashContrasena, &usuario.FechaAlta)
+	existe := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error al buscar el usuario %q: %v", nombre, err)
+		return Usuario{}, fmt.Errorf("error al obtener el usuario: %w", err)
+	}
+	if !existe {
+		usuario.HashContrasena = string(hashFalso)
+	}
+	if !usuario.verificarContrasena(contrasena) || !existe {
+		return Usuario{}, ErrCredencialesInvalidas
+	}
+	return usuario, nil
+}
+
+// ContarUsuarios devuelve la cantidad de usuarios registrados.
+func (repo *SQLUsuarioRepository) ContarUsuarios() (int, error) {
+	var cantidad int
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM usuarios").Scan(&cantidad); err != nil {
+		return 0, fmt.Errorf("error al contar los usuarios: %w", err)
+	}
+	return cantidad, nil
+}
+
+// SQLSesionRepository implementa SesionRepository usando un pool de conexiones compartido.
+type SQLSesionRepository struct {
+	db *sql.DB // Pool de conexiones a la base de datos.
+}
+
+// NewSQLSesionRepository crea un repositorio de sesiones que usa la conexión recibida.
+func NewSQLSesionRepository(db *sql.DB) *SQLSesionRepository {
+	return &SQLSesionRepository{db: db}
+}
+
+// CrearSesion inserta una sesión identificada por el hash de un token nuevo y devuelve el token.
+func (repo *SQLSesionRepository) CrearSesion(UsuarioId int) (string, error) {
+	token, hash, err := nuevoTokenSesion()
+	if err != nil {
+		return "", fmt.Errorf("error al generar el token de sesión: %w", err)
+	}
+	ahora := time.Now().Truncate(time.Second)
+	_, err = repo.db.Exec("INSERT INTO sesiones (Id, UsuarioId, FechaCreacion, FechaExpiracion) VALUES (?, ?, ?, ?)",
+		hash, UsuarioId, fechaSQL(ahora), fechaSQL(ahora.Add(DuracionSesion)))
+	if err != nil {
+		log.Printf("Error al ejecutar la inserción de la sesión del usuario %d: %v", UsuarioId, err)
+		return "", fmt.Errorf("error al iniciar la sesión: %w", err)
+	}
+	return token, nil
+}
+
+// UsuarioDeSesion devuelve el usuario de la sesión si existe y no expiró.
+func (repo *SQLSesionRepository) UsuarioDeSesion(token string) (Usuario, error) {
+	var usuario Usuario
+	err := repo.db.QueryRow(`SELECT u.Id, u.Usuario, u.Nombre, u.Rol, u.HashContrasena, u.FechaAlta
+		FROM sesiones s JOIN usuarios u ON u.Id = s.UsuarioId
+		WHERE s.Id = ? AND s.FechaExpiracion > ?`, hashToken(token), fechaSQL(time.Now().Truncate(time.Second))).
+		Scan(&usuario.Id, &usuario.Usuario, &usuario.Nombre, &usuario.Rol, &usuario.HashContrasena, &usuario.FechaAlta)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Usuario{}, ErrSesionInvalida
+		}
+		log.Printf("Error al consultar la sesión: %v", err)
+		return Usuario{}, fmt.Errorf("error al consultar la sesión: %w", err)
+	}
+	return usuario, nil
+}
+
+// EliminarSesion borra la sesión del token.
+func (repo *SQLSesionRepository) EliminarSesion(token string) error {
+	if _, err := repo.db.Exec("DELETE FROM sesiones WHERE Id = ?", hashToken(token)); err != nil {
+		log.Printf("Error al eliminar la sesión: %v", err)
+		return fmt.Errorf("error al cerrar la sesión: %w", err)
+	}
+	return nil
+}
+
+// EliminarSesionesVencidas borra las sesiones cuya fecha de expiración ya pasó.
+func (repo *SQLSesionRepository) EliminarSesionesVencidas() (int, error) {
+	resultado, err := repo.db.Exec("DELETE FROM sesiones WHERE FechaExpiracion <= ?", fechaSQL(time.Now().Truncate(time.Second)))
+	if err != nil {
+		log.Printf("Error al eliminar las sesiones vencidas: %v", err)
+		return 0, fmt.Errorf("error al eliminar las sesiones vencidas: %w", err)
+	}
+	eliminadas, err := resultado.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener filas afectadas: %w", err)
+	}
+	return int(eliminadas), nil
+}
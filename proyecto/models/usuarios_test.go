@@ -0,0 +1,181 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// probarUsuariosYSesiones verifica el contrato común de UsuarioRepository y SesionRepository sobre un almacenamiento vacío.
+func probarUsuariosYSesiones(t *testing.T, usuarios UsuarioRepository, sesiones SesionRepository) {
+	t.Helper()
+	if cantidad, err := usuarios.ContarUsuarios(); err != nil || cantidad != 0 {
+		t.Fatalf("ContarUsuarios = %d, %v", cantidad, err)
+	}
+
+	usuario, err := usuarios.CrearUsuario(Usuario{Usuario: "mperez", Nombre: "María Pérez", Rol: RolBibliotecario}, "secreta123")
+	if err != nil || usuario.Id != 1 || usuario.FechaAlta.IsZero() {
+		t.Fatalf("CrearUsuario = %+v, %v", usuario, err)
+	}
+	// Solo se guarda el hash bcrypt de la contraseña.
+	if usuario.HashContrasena == "" || usuario.HashContrasena == "secreta123" {
+		t.Errorf("HashContrasena = %q", usuario.HashContrasena)
+	}
+	if _, err := usuarios.CrearUsuario(Usuario{Usuario: "MPerez", Nombre: "Otra", Rol: RolLector}, "secreta123"); !errors.Is(err, ErrUsuarioDuplicado) {
+		t.Errorf("CrearUsuario duplicado: %v, se esperaba ErrUsuarioDuplicado", err)
+	}
+	if _, err := usuarios.CrearUsuario(Usuario{Usuario: "corta", Nombre: "Corta", Rol: RolLector}, "1234567"); !errors.Is(err, ErrContrasenaCorta) || !errors.Is(err, ErrValidation) {
+		t.Errorf("CrearUsuario con contraseña corta: %v, se esperaba ErrContrasenaCorta", err)
+	}
+	if _, err := usuarios.CrearUsuario(Usuario{Usuario: "sinrol", Nombre: "Sin Rol"}, "secreta123"); !errors.Is(err, ErrRolInvalido) {
+		t.Errorf("CrearUsuario sin rol: %v, se esperaba ErrRolInvalido", err)
+	}
+	if cantidad, _ := usuarios.ContarUsuarios(); cantidad != 1 {
+		t.Errorf("ContarUsuarios = %d, se esperaba 1", cantidad)
+	}
+	if encontrado, err := usuarios.GetUsuarioByID(1); err != nil || encontrado.Nombre != "María Pérez" || encontrado.Rol != RolBibliotecario {
+		t.Errorf("GetUsuarioByID(1) = %+v, %v", encontrado, err)
+	}
+	if _, err := usuarios.GetUsuarioByID(99); err == nil {
+		t.Error("GetUsuarioByID de un usuario inexistente no devolvió error")
+	}
+
+	if autenticado, err := usuarios.Autenticar("mperez", "secreta123"); err != nil || autenticado.Id != 1 {
+		t.Errorf("Autenticar = %+v, %v", autenticado, err)
+	}
+	for _, c := range []struct{ usuario, contrasena string }{{"mperez", "incorrecta"}, {"nadie", "secreta123"}, {"mperez", ""}} {
+		if _, err := usuarios.Autenticar(c.usuario, c.contrasena); !errors.Is(err, ErrCredencialesInvalidas) {
+			t.Errorf("Autenticar(%q, %q): %v, se esperaba ErrCredencialesInvalidas", c.usuario, c.contrasena, err)
+		}
+	}
+
+	token, err := sesiones.CrearSesion(1)
+	if err != nil || token == "" {
+		t.Fatalf("CrearSesion = %q, %v", token, err)
+	}
+	if otro, _ := sesiones.CrearSesion(1); otro == token {
+		t.Error("dos sesiones recibieron el mismo token")
+	}
+	if dueno, err := sesiones.UsuarioDeSesion(token); err != nil || dueno.Usuario != "mperez" {
+		t.Errorf("UsuarioDeSesion = %+v, %v", dueno, err)
+	}
+	if _, err := sesiones.UsuarioDeSesion("token-inventado"); !errors.Is(err, ErrSesionInvalida) {
+		t.Errorf("UsuarioDeSesion de un token inventado: %v, se esperaba ErrSesionInvalida", err)
+	}
+	if _, err := sesiones.CrearSesion(99); err == nil {
+		t.Error("CrearSesion de un usuario inexistente no devolvió error")
+	}
+	if eliminadas, err := sesiones.EliminarSesionesVencidas(); err != nil || eliminadas != 0 {
+		t.Errorf("EliminarSesionesVencidas = %d, %v; ninguna sesión había vencido", eliminadas, err)
+	}
+
+	// El cambio de rol se ve en las sesiones ya iniciadas.
+	if err := usuarios.ActualizarRol(1, RolAdmin); err != nil {
+		t.Fatalf("ActualizarRol: %v", err)
+	}
+	if dueno, _ := sesiones.UsuarioDeSesion(token); dueno.Rol != RolAdmin {
+		t.Errorf("después de ActualizarRol, la sesión tiene el rol %q", dueno.Rol)
+	}
+	if err := usuarios.ActualizarRol(1, "superusuario"); !errors.Is(err, ErrRolInvalido) {
+		t.Errorf("ActualizarRol con un rol inválido: %v", err)
+	}
+	if err := usuarios.ActualizarRol(99, RolLector); err == nil {
+		t.Error("ActualizarRol de un usuario inexistente no devolvió error")
+	}
+
+	if err := sesiones.EliminarSesion(token); err != nil {
+		t.Fatalf("EliminarSesion: %v", err)
+	}
+	if _, err := sesiones.UsuarioDeSesion(token); !errors.Is(err, ErrSesionInvalida) {
+		t.Errorf("la sesión cerrada sigue siendo válida: %v", err)
+	}
+	if err := sesiones.EliminarSesion(token); err != nil {
+		t.Errorf("EliminarSesion de una sesión ya cerrada: %v", err)
+	}
+
+	// Eliminar un usuario cierra también sus sesiones.
+	otro, _ := usuarios.CrearUsuario(Usuario{Usuario: "alector", Nombre: "Ana Lectora", Rol: RolLector}, "secreta123")
+	tokenOtro, _ := sesiones.CrearSesion(otro.Id)
+	if lista, err := usuarios.GetAllUsuarios(); err != nil || len(lista) != 2 || lista[0].Usuario != "alector" {
+		t.Fatalf("GetAllUsuarios = %+v, %v", lista, err)
+	}
+	if err := usuarios.EliminarUsuario(otro.Id); err != nil {
+		t.Fatalf("EliminarUsuario: %v", err)
+	}
+	if _, err := sesiones.UsuarioDeSesion(tokenOtro); !errors.Is(err, ErrSesionInvalida) {
+		t.Errorf("la sesión del usuario eliminado sigue siendo válida: %v", err)
+	}
+	if err := usuarios.EliminarUsuario(otro.Id); err == nil {
+		t.Error("EliminarUsuario de un usuario inexistente no devolvió error")
+	}
+}
+
+func TestPermisosPorRol(t *testing.T) {
+	casos := []struct {
+		rol       string
+		permitido []Permiso
+		denegado  []Permiso
+	}{
+		{RolLector, []Permiso{PermisoVer}, []Permiso{PermisoPrestar, PermisoEditar, PermisoEliminar, PermisoAdministrarUsuarios, PermisoAdministrarClaves}},
+		{RolBibliotecario, []Permiso{PermisoVer, PermisoPrestar, PermisoEditar}, []Permiso{PermisoEliminar, PermisoAdministrarUsuarios, PermisoAdministrarClaves}},
+		{RolAdmin, []Permiso{PermisoVer, PermisoPrestar, PermisoEditar, PermisoEliminar, PermisoAdministrarUsuarios, PermisoAdministrarClaves}, nil},
+		{"", nil, []Permiso{PermisoVer}},
+	}
+	for _, c := range casos {
+		usuario := Usuario{Rol: c.rol}
+		for _, permiso := range c.permitido {
+			if !usuario.Puede(permiso) {
+				t.Errorf("el rol %q no tiene el permiso %q", c.rol, permiso)
+			}
+		}
+		for _, permiso := range c.denegado {
+			if usuario.Puede(permiso) {
+				t.Errorf("el rol %q tiene el permiso %q", c.rol, permiso)
+			}
+		}
+	}
+	for _, rol := range Roles {
+		if !RolValido(rol) {
+			t.Errorf("RolValido(%q) = false", rol)
+		}
+	}
+	if RolValido("Admin") || RolValido("") {
+		t.Error("RolValido aceptó un rol inválido")
+	}
+}
+
+func TestMemoryUsuarioRepository(t *testing.T) {
+	mdb := NewMemoriaDB()
+	probarUsuariosYSesiones(t, NewMemoryUsuarioRepository(mdb), NewMemorySesionRepository(mdb))
+}
+
+func TestSQLUsuarioRepositorySQLite(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	probarUsuariosYSesiones(t, NewSQLUsuarioRepository(conexion), NewSQLSesionRepository(conexion))
+}
+
+// Una sesión creada con la hora local en UTC-12 sigue siendo válida si después la zona local pasa a UTC+14:
+// la expiración se compara como instante y no como el texto de la fecha con su zona.
+func TestSQLSesionCambioDeZona(t *testing.T) {
+	conexion := nuevaDBPrueba(t)
+	usuarios, sesiones := NewSQLUsuarioRepository(conexion), NewSQLSesionRepository(conexion)
+	usuario, err := usuarios.CrearUsuario(Usuario{Usuario: "ana", Nombre: "Ana", Rol: RolBibliotecario}, "contraseña-segura")
+	if err != nil {
+		t.Fatalf("CrearUsuario: %v", err)
+	}
+	local := time.Local
+	t.Cleanup(func() { time.Local = local })
+
+	time.Local = time.FixedZone("UTC-12", -12*60*60)
+	token, err := sesiones.CrearSesion(usuario.Id)
+	if err != nil {
+		t.Fatalf("CrearSesion: %v", err)
+	}
+	time.Local = time.FixedZone("UTC+14", 14*60*60)
+	if _, err := sesiones.UsuarioDeSesion(token); err != nil {
+		t.Errorf("UsuarioDeSesion después del cambio de zona: %v", err)
+	}
+	if eliminadas, err := sesiones.EliminarSesionesVencidas(); err != nil || eliminadas != 0 {
+		t.Errorf("EliminarSesionesVencidas después del cambio de zona = %d, %v", eliminadas, err)
+	}
+}
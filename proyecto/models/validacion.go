@@ -0,0 +1,58 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Módulo que define los errores de validación por campo, compartidos por los formularios HTML y la API.
+*/
+
+package models
+
+import (
+	"sort"    // Paquete para listar los campos con error en un orden estable.
+	"strings" // Paquete para unir los mensajes de error.
+)
+
+// ErroresValidacion agrupa los errores de validación de una entidad, un mensaje por campo.
+// La clave es el nombre del campo tal como se envía en el formulario o en el JSON (por ejemplo "Titulo"),
+// para que la interfaz web pueda mostrar cada mensaje junto a su campo y la API devolverlos tal cual.
+type ErroresValidacion map[string]string
+
+// Agregar registra un error para el campo. Si el campo ya tenía un error se conserva el primero,
+// que suele ser el más básico (por ejemplo, "Es obligatorio" antes que "No puede tener más de 255 caracteres").
+func (e ErroresValidacion) Agregar(campo, mensaje string) {
+	if _, existe := e[campo]; !existe {
+		e[campo] = mensaje
+	}
+}
+
+// Campos devuelve los nombres de los campos con error, ordenados alfabéticamente.
+func (e ErroresValidacion) Campos() []string {
+	campos := make([]string, 0, len(e))
+	for campo := range e {
+		campos = append(campos, campo)
+	}
+	sort.Strings(campos)
+	return campos
+}
+
+// Error une todos los mensajes en un solo texto, para los casos en que se muestran juntos.
+// Cada mensaje es una frase completa que se puede mostrar sola junto a su campo.
+func (e ErroresValidacion) Error() string {
+	mensajes := make([]string, 0, len(e))
+	for _, campo := range e.Campos() {
+		mensajes = append(mensajes, campo+": "+e[campo])
+	}
+	return "datos inválidos: " + strings.Join(mensajes, "; ")
+}
+
+// Is hace que errors.Is(err, ErrValidation) reconozca los errores por campo como datos inválidos.
+func (e ErroresValidacion) Is(objetivo error) bool {
+	return objetivo == ErrValidation
+}
+
+// Err devuelve los errores como error, o nil si no hay ninguno.
+// Evita el clásico error de Go de devolver un mapa vacío dentro de una interfaz error no nula.
+func (e ErroresValidacion) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,67 @@
+/* Sugerencias mientras se escribe en el cuadro de búsqueda y en los campos Autor y Editorial del formulario de libro.
+   Cada input con el atributo data-sugerencias pide GET /api/libros/suggest y llena la lista (datalist) que indica
+   su atributo list; el valor de data-sugerencias restringe el campo (Autor, Editorial) o, vacío, sugiere de todos.
+   Sin JavaScript los inputs siguen funcionando, solo que sin sugerencias. */
+(function () {
+    'use strict';
+
+    var ESPERA_MS = 150;  // Pausa después de la última tecla antes de pedir sugerencias.
+    var LIMITE = 8;       // Cantidad de sugerencias que se muestran.
+
+    function conectar(input) {
+        var lista = document.getElementById(input.getAttribute('list'));
+        if (!lista) {
+            return;
+        }
+        var campo = input.dataset.sugerencias;
+        var temporizador = null;
+        var controlador = null;
+        var ultimo = '';
+
+        function mostrar(sugerencias) {
+            lista.replaceChildren();
+            sugerencias.forEach(function (sugerencia) {
+                var opcion = document.createElement('option');
+                opcion.value = sugerencia.texto;
+                // En el cuadro de búsqueda se indica de qué campo es cada sugerencia.
+                if (!campo) {
+                    opcion.label = sugerencia.campo + ' · ' + sugerencia.libros + (sugerencia.libros === 1 ? ' libro' : ' libros');
+                }
+                lista.appendChild(opcion);
+            });
+        }
+
+        function pedir() {
+            var texto = input.value.trim();
+            if (texto === ultimo) {
+                return;
+            }
+            ultimo = texto;
+            if (controlador) {
+                controlador.abort(); // La respuesta de una tecla anterior ya no sirve.
+            }
+            if (texto === '') {
+                mostrar([]);
+                return;
+            }
+            controlador = new AbortController();
+            var parametros = new URLSearchParams({ q: texto, limite: LIMITE });
+            if (campo) {
+                parametros.set('campo', campo);
+            }
+            fetch('/api/libros/suggest?' + parametros.toString(), { credentials: 'same-origin', signal: controlador.signal })
+                .then(function (respuesta) { return respuesta.ok ? respuesta.json() : []; })
+                .then(mostrar)
+                .catch(function () { /* Solicitud cancelada o sin conexión: se mantienen las sugerencias anteriores. */ });
+        }
+
+        input.addEventListener('input', function () {
+            clearTimeout(temporizador);
+            temporizador = setTimeout(pedir, ESPERA_MS);
+        });
+    }
+
+    document.addEventListener('DOMContentLoaded', function () {
+        document.querySelectorAll('input[data-sugerencias]').forEach(conectar);
+    });
+})();
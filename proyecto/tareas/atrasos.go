@@ -0,0 +1,87 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Tarea en segundo plano que revisa periódicamente los préstamos vencidos y calcula sus multas.
+*/
+
+package tareas
+
+import (
+	"context"         // Paquete para detener la tarea cuando termina la aplicación.
+	"fmt"             // Paquete para formatear los mensajes de error.
+	"log"             // Paquete para logging.
+	"os"              // Paquete para leer las variables de entorno.
+	"proyecto/models" // Importa el paquete models con el repositorio de multas.
+	"strconv"         // Paquete para convertir la tarifa y los días de gracia.
+	"strings"         // Paquete para limpiar los valores de las variables de entorno.
+	"time"            // Paquete para el intervalo entre revisiones.
+)
+
+// IntervaloPorDefecto es el tiempo entre dos revisiones de atrasos cuando no se define REVISION_ATRASOS_INTERVALO.
+const IntervaloPorDefecto = time.Hour
+
+// ConfigDesdeEntorno lee la configuración de las multas y el intervalo de revisión de las variables de entorno:
+//   - MULTA_TARIFA_DIARIA: monto por día de atraso (por defecto models.TarifaDiariaPorDefecto).
+//   - MULTA_DIAS_GRACIA: días de atraso que no se cobran (por defecto models.DiasGraciaPorDefecto).
+//   - REVISION_ATRASOS_INTERVALO: duración de Go, por ejemplo "30m" o "1h" (por defecto IntervaloPorDefecto).
+func ConfigDesdeEntorno() (models.ConfigMultas, time.Duration, error) {
+	config := models.ConfigMultasPorDefecto()
+	intervalo := IntervaloPorDefecto
+
+	if valor := strings.TrimSpace(os.Getenv("MULTA_TARIFA_DIARIA")); valor != "" {
+		tarifa, err := strconv.ParseFloat(valor, 64)
+		if err != nil || tarifa < 0 {
+			return config, intervalo, fmt.Errorf("MULTA_TARIFA_DIARIA inválida: %q (use un número mayor o igual a 0)", valor)
+		}
+		config.TarifaDiaria = tarifa
+	}
+	if valor := strings.TrimSpace(os.Getenv("MULTA_DIAS_GRACIA")); valor != "" {
+		dias, err := strconv.Atoi(valor)
+		if err != nil || dias < 0 {
+			return config, intervalo, fmt.Errorf("MULTA_DIAS_GRACIA inválido: %q (use un número entero mayor o igual a 0)", valor)
+		}
+		config.DiasGracia = dias
+	}
+	if valor := strings.TrimSpace(os.Getenv("REVISION_ATRASOS_INTERVALO")); valor != "" {
+		duracion, err := time.ParseDuration(valor)
+		if err != nil || duracion <= 0 {
+			return config, intervalo, fmt.Errorf("REVISION_ATRASOS_INTERVALO inválido: %q (use una duración como 30m o 1h)", valor)
+		}
+		intervalo = duracion
+	}
+	return config, intervalo, nil
+}
+
+// RevisarAtrasos ejecuta una revisión de los préstamos vencidos y registra el resultado en el log.
+func RevisarAtrasos(multas models.MultaRepository, config models.ConfigMultas) {
+	actualizados, err := multas.ActualizarAtrasos(time.Now(), config)
+	if err != nil {
+		log.Printf("Error en la revisión de atrasos: %v", err)
+		return
+	}
+	if actualizados > 0 {
+		log.Printf("Revisión de atrasos: %d préstamos atrasados actualizados.", actualizados)
+	}
+}
+
+// IniciarRevisionAtrasos lanza en segundo plano la revisión de atrasos: una al iniciar y luego una cada intervalo,
+// hasta que se cancela el contexto recibido.
+func IniciarRevisionAtrasos(ctx context.Context, multas models.MultaRepository, config models.ConfigMultas, intervalo time.Duration) {
+	log.Printf("Revisión de atrasos cada %v (tarifa diaria %.2f, %d días de gracia).", intervalo, config.TarifaDiaria, config.DiasGracia)
+	go repetir(ctx, intervalo, func() { RevisarAtrasos(multas, config) })
+}
+
+// repetir ejecuta la tarea una vez al iniciar y luego una vez por intervalo, hasta que se cancela el contexto.
+func repetir(ctx context.Context, intervalo time.Duration, tarea func()) {
+	tarea()
+
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tarea()
+		}
+	}
+}
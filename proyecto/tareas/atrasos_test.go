@@ -0,0 +1,64 @@
+package tareas
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"proyecto/models"
+)
+
+func TestConfigDesdeEntorno(t *testing.T) {
+	t.Setenv("MULTA_TARIFA_DIARIA", "")
+	t.Setenv("MULTA_DIAS_GRACIA", "")
+	t.Setenv("REVISION_ATRASOS_INTERVALO", "")
+	config, intervalo, err := ConfigDesdeEntorno()
+	if err != nil || config != models.ConfigMultasPorDefecto() || intervalo != IntervaloPorDefecto {
+		t.Errorf("ConfigDesdeEntorno sin variables = %+v, %v, %v", config, intervalo, err)
+	}
+
+	t.Setenv("MULTA_TARIFA_DIARIA", "1.25")
+	t.Setenv("MULTA_DIAS_GRACIA", "0")
+	t.Setenv("REVISION_ATRASOS_INTERVALO", "15m")
+	config, intervalo, err = ConfigDesdeEntorno()
+	if err != nil || config.TarifaDiaria != 1.25 || config.DiasGracia != 0 || intervalo != 15*time.Minute {
+		t.Errorf("ConfigDesdeEntorno = %+v, %v, %v", config, intervalo, err)
+	}
+
+	for variable, valor := range map[string]string{
+		"MULTA_TARIFA_DIARIA":        "-1",
+		"MULTA_DIAS_GRACIA":          "dos",
+		"REVISION_ATRASOS_INTERVALO": "0s",
+	} {
+		t.Run(variable, func(t *testing.T) {
+			t.Setenv(variable, valor)
+			if _, _, err := ConfigDesdeEntorno(); err == nil {
+				t.Errorf("%s=%q no devolvió error", variable, valor)
+			}
+		})
+	}
+}
+
+func TestIniciarRevisionAtrasos(t *testing.T) {
+	mdb := models.NewMemoriaDB()
+	models.NewMemoryLibroRepository(mdb).CreateLibro("Cortázar", "Rayuela", 1963, "Sudamericana")
+	models.NewMemorySocioRepository(mdb).CreateSocio(models.Socio{Nombre: "Ana", Estado: models.EstadoSocioActivo})
+	models.NewMemoryPrestamoRepository(mdb).PrestarLibro(1, 1, time.Now().AddDate(0, 0, -3))
+	multas := models.NewMemoryMultaRepository(mdb)
+
+	ctx, cancelar := context.WithCancel(context.Background())
+	defer cancelar()
+	IniciarRevisionAtrasos(ctx, multas, models.ConfigMultasPorDefecto(), time.Hour)
+
+	// La primera revisión se ejecuta al iniciar, sin esperar al intervalo.
+	limite := time.Now().Add(2 * time.Second)
+	for {
+		if n, _ := multas.ContarAtrasados(); n == 1 {
+			return
+		}
+		if time.Now().After(limite) {
+			t.Fatal("la revisión inicial no marcó el préstamo vencido")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,32 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Tarea en segundo plano que vence las reservas cuya copia apartada no se retiró a tiempo.
+*/
+
+package tareas
+
+import (
+	"context"         // Paquete para detener la tarea cuando termina la aplicación.
+	"log"             // Paquete para logging.
+	"proyecto/models" // Importa el paquete models con el repositorio de reservas.
+	"time"            // Paquete para el intervalo entre revisiones.
+)
+
+// VencerReservas ejecuta una revisión de las reservas asignadas y registra el resultado en el log.
+func VencerReservas(reservas models.ReservaRepository) {
+	vencidas, err := reservas.VencerReservas(time.Now())
+	if err != nil {
+		log.Printf("Error en el vencimiento de reservas: %v", err)
+		return
+	}
+	if vencidas > 0 {
+		log.Printf("Vencimiento de reservas: %d reservas vencidas, sus copias pasaron a la siguiente reserva o al estante.", vencidas)
+	}
+}
+
+// IniciarVencimientoReservas lanza en segundo plano el vencimiento de reservas: uno al iniciar y luego uno
+// cada intervalo, hasta que se cancela el contexto recibido.
+func IniciarVencimientoReservas(ctx context.Context, reservas models.ReservaRepository, intervalo time.Duration) {
+	log.Printf("Vencimiento de reservas cada %v (plazo de retiro de %d días).", intervalo, models.DiasRetencionReserva)
+	go repetir(ctx, intervalo, func() { VencerReservas(reservas) })
+}
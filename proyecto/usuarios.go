@@ -0,0 +1,68 @@
+/*
+@Autor: Kevin Pérez
+@Descripcion: Creación de la primera cuenta al iniciar la aplicación, para poder entrar a la interfaz web,
+y configuración del secreto con que se firman los tokens de la API.
+*/
+
+package main
+
+import (
+	"crypto/rand"     // Paquete para generar un secreto de tokens si no se configuró uno.
+	"fmt"             // Paquete para formatear cadenas.
+	"log"             // Paquete para logging.
+	"os"              // Paquete para leer las variables de entorno.
+	"proyecto/models" // Importa el paquete models que define los usuarios.
+)
+
+// usuarioAdminPorDefecto es el nombre de la primera cuenta si ADMIN_USUARIO no está definida.
+const usuarioAdminPorDefecto = "admin"
+
+// crearUsuarioInicial crea la primera cuenta cuando la base de datos no tiene usuarios, con el nombre de
+// ADMIN_USUARIO (admin por defecto) y la contraseña de ADMIN_CONTRASENA. Si ya hay usuarios no hace nada;
+// si no hay usuarios ni contraseña, avisa que nadie podrá iniciar sesión.
+func crearUsuarioInicial(usuarios models.UsuarioRepository) error {
+	cantidad, err := usuarios.ContarUsuarios()
+	if err != nil {
+		return err
+	}
+	if cantidad > 0 {
+		return nil
+	}
+
+	contrasena := os.Getenv("ADMIN_CONTRASENA")
+	if contrasena == "" {
+		log.Println("Advertencia: no hay usuarios registrados. Defina ADMIN_CONTRASENA (y opcionalmente ADMIN_USUARIO) para crear la primera cuenta.")
+		return nil
+	}
+	nombre := os.Getenv("ADMIN_USUARIO")
+	if nombre == "" {
+		nombre = usuarioAdminPorDefecto
+	}
+	usuario, err := usuarios.CrearUsuario(models.Usuario{Usuario: nombre, Nombre: "Administrador", Rol: models.RolAdmin}, contrasena)
+	if err != nil {
+		return fmt.Errorf("no se pudo crear el usuario %q: %w", nombre, err)
+	}
+	log.Printf("Se creó el usuario inicial %q.", usuario.Usuario)
+	return nil
+}
+
+// longitudMinimaSecretoTokens es la cantidad mínima de bytes recomendada para TOKEN_API_SECRETO (256 bits, como la firma).
+const longitudMinimaSecretoTokens = 32
+
+// nuevoFirmadorTokens crea el firmador de los tokens bearer de la API con el secreto recibido (TOKEN_API_SECRETO).
+// Sin secreto se genera uno aleatorio: los tokens funcionan, pero dejan de valer al reiniciar la aplicación
+// y no sirven entre varias instancias. Las claves de API no dependen del secreto.
+func nuevoFirmadorTokens(secreto string) *models.FirmadorTokens {
+	if secreto == "" {
+		aleatorio := make([]byte, longitudMinimaSecretoTokens)
+		if _, err := rand.Read(aleatorio); err != nil {
+			log.Fatalf("No se pudo generar el secreto de los tokens de la API: %v", err)
+		}
+		log.Println("Advertencia: TOKEN_API_SECRETO no está definida; los tokens de la API dejarán de valer al reiniciar.")
+		return models.NewFirmadorTokens(aleatorio, models.DuracionTokenAPI)
+	}
+	if len(secreto) < longitudMinimaSecretoTokens {
+		log.Printf("Advertencia: TOKEN_API_SECRETO debería tener al menos %d caracteres.", longitudMinimaSecretoTokens)
+	}
+	return models.NewFirmadorTokens([]byte(secreto), models.DuracionTokenAPI)
+}